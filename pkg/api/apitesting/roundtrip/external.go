@@ -0,0 +1,192 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package roundtrip
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+
+	fuzz "github.com/google/gofuzz"
+
+	"k8s.io/apimachinery/pkg/api/apitesting/fuzzer"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metafuzzer "k8s.io/apimachinery/pkg/apis/meta/fuzzer"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	runtimeserializer "k8s.io/apimachinery/pkg/runtime/serializer"
+	"k8s.io/apimachinery/pkg/runtime/serializer/json"
+	"k8s.io/apimachinery/pkg/runtime/serializer/protobuf"
+	"k8s.io/apimachinery/pkg/util/diff"
+)
+
+// ExternalTypesTestOptions holds configuration for testing the external types registered in a scheme
+// with fuzzed objects. For every kind, fuzzed objects are verified to:
+// * deep-copy without aliasing the original
+// * round-trip through JSON, YAML, protobuf and any additional Serializers without loss
+// * be unchanged by defaulting an already defaulted object
+//
+// Example use: `NewExternalTypesTestOptions(scheme).Complete(t).Run(t)`
+type ExternalTypesTestOptions struct {
+	// Scheme is used to create, default and serialize objects.
+	// Required.
+	Scheme *runtime.Scheme
+
+	// Kinds is a list of fully qualified external kinds to test.
+	// Complete() populates this with the external kinds of Scheme.AllKnownTypes() if unset,
+	// excluding GlobalNonRoundTrippableTypes().
+	Kinds []schema.GroupVersionKind
+
+	// SkipKinds is an optional set of kinds not to test.
+	SkipKinds map[schema.GroupVersionKind]bool
+
+	// FuzzerFuncs is an optional set of custom fuzzing functions, merged with the meta fuzzer functions.
+	FuzzerFuncs fuzzer.FuzzerFuncs
+
+	// Fuzzer is used to fuzz objects.
+	// Complete() populates this with a randomly seeded fuzzer using FuzzerFuncs if unset.
+	Fuzzer *fuzz.Fuzzer
+
+	// Iterations is the number of fuzzed objects tested per kind.
+	// Complete() populates this with the value of the --fuzz-iters flag if unset.
+	Iterations int
+
+	// SkipProtobuf disables the protobuf round-trip. Kinds that do not implement protobuf marshaling
+	// are always skipped.
+	SkipProtobuf bool
+	// SkipYAML disables the YAML round-trip.
+	SkipYAML bool
+	// SkipDefaulting disables the defaulting idempotency check.
+	SkipDefaulting bool
+
+	// JSON, YAML and Proto are the serializers used for the respective round-trips.
+	// Complete() populates them with serializers using Scheme if unset.
+	JSON  runtime.Serializer
+	YAML  runtime.Serializer
+	Proto runtime.Serializer
+
+	// Serializers is an optional list of additional serializers to round-trip every kind through,
+	// e.g. for encodings other than JSON, YAML and protobuf.
+	Serializers []runtime.Serializer
+}
+
+func NewExternalTypesTestOptions(scheme *runtime.Scheme) *ExternalTypesTestOptions {
+	return &ExternalTypesTestOptions{Scheme: scheme}
+}
+
+func (o *ExternalTypesTestOptions) Complete(t *testing.T) *ExternalTypesTestOptions {
+	t.Helper()
+
+	if o.Scheme == nil {
+		t.Fatal("scheme is required")
+	}
+
+	if len(o.Kinds) == 0 {
+		for gvk := range o.Scheme.AllKnownTypes() {
+			if gvk.Version == runtime.APIVersionInternal || globalNonRoundTrippableTypes.Has(gvk.Kind) {
+				continue
+			}
+			o.Kinds = append(o.Kinds, gvk)
+		}
+		sort.Slice(o.Kinds, func(i, j int) bool {
+			return makeName(o.Kinds[i]) < makeName(o.Kinds[j])
+		})
+	}
+
+	if o.Fuzzer == nil {
+		o.Fuzzer = fuzzer.FuzzerFor(
+			fuzzer.MergeFuzzerFuncs(metafuzzer.Funcs, o.FuzzerFuncs),
+			rand.NewSource(rand.Int63()),
+			runtimeserializer.NewCodecFactory(o.Scheme),
+		)
+	}
+	if o.Iterations == 0 {
+		o.Iterations = *FuzzIters
+	}
+
+	if o.JSON == nil {
+		o.JSON = json.NewSerializerWithOptions(json.DefaultMetaFactory, o.Scheme, o.Scheme, json.SerializerOptions{})
+	}
+	if o.YAML == nil {
+		o.YAML = json.NewSerializerWithOptions(json.DefaultMetaFactory, o.Scheme, o.Scheme, json.SerializerOptions{Yaml: true})
+	}
+	if o.Proto == nil {
+		o.Proto = protobuf.NewSerializer(o.Scheme, o.Scheme)
+	}
+
+	return o
+}
+
+func (o *ExternalTypesTestOptions) Run(t *testing.T) {
+	for _, gvk := range o.Kinds {
+		if o.SkipKinds[gvk] {
+			t.Logf("skipping %v", gvk)
+			continue
+		}
+		t.Run(makeName(gvk), func(t *testing.T) {
+			for i := 0; i < o.Iterations && !t.Failed(); i++ {
+				o.runKindTest(t, gvk)
+			}
+		})
+	}
+}
+
+func (o *ExternalTypesTestOptions) runKindTest(t *testing.T, gvk schema.GroupVersionKind) {
+	object, err := o.Scheme.New(gvk)
+	if err != nil {
+		t.Fatalf("Couldn't make a %v? %v", gvk, err)
+	}
+	typeAcc, err := apimeta.TypeAccessor(object)
+	if err != nil {
+		t.Fatalf("%q is not a TypeMeta and cannot be tested - add it to SkipKinds: %v", gvk, err)
+	}
+	o.Fuzzer.Fuzz(object)
+	typeAcc.SetKind(gvk.Kind)
+	typeAcc.SetAPIVersion(gvk.GroupVersion().String())
+
+	// roundTrip also verifies that DeepCopy does not alias the original.
+	roundTrip(t, o.Scheme, o.JSON, object)
+	if !o.SkipYAML {
+		roundTrip(t, o.Scheme, o.YAML, object)
+	}
+	if !o.SkipProtobuf {
+		if _, ok := object.(interface{ Marshal() ([]byte, error) }); ok {
+			roundTrip(t, o.Scheme, o.Proto, object)
+		} else {
+			t.Logf("%v does not implement protobuf marshaling, skipping protobuf round-trip", gvk)
+		}
+	}
+	for _, serializer := range o.Serializers {
+		roundTrip(t, o.Scheme, serializer, object)
+	}
+	if !o.SkipDefaulting {
+		o.verifyDefaultingIdempotent(t, gvk, object)
+	}
+}
+
+// verifyDefaultingIdempotent verifies that defaulting an object twice has the same result as defaulting it
+// once, so that objects read back from storage are not modified by defaulting again.
+func (o *ExternalTypesTestOptions) verifyDefaultingIdempotent(t *testing.T, gvk schema.GroupVersionKind, object runtime.Object) {
+	defaulted := object.DeepCopyObject()
+	o.Scheme.Default(defaulted)
+	defaultedTwice := defaulted.DeepCopyObject()
+	o.Scheme.Default(defaultedTwice)
+	if !apiequality.Semantic.DeepEqual(defaulted, defaultedTwice) {
+		t.Errorf("%v: defaulting is not idempotent, diff: %v", gvk, diff.ObjectReflectDiff(defaulted, defaultedTwice))
+	}
+}
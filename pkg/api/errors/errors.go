@@ -20,9 +20,12 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"math"
 	"net/http"
 	"reflect"
+	"strconv"
 	"strings"
+	"time"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -777,21 +780,111 @@ func IsUnexpectedObjectError(err error) bool {
 // SuggestsClientDelay returns true if this error suggests a client delay as well as the
 // suggested seconds to wait, or false if the error does not imply a wait. It does not
 // address whether the error *should* be retried, since some errors (like a 3xx) may
-// request delay without retry.
+// request delay without retry. A Retry-After header captured with WithResponseHeaders
+// is used if the status of the error does not suggest a delay.
 // It supports wrapped errors and returns false when the error is nil.
 func SuggestsClientDelay(err error) (int, bool) {
-	if t := APIStatus(nil); errors.As(err, &t) && t.Status().Details != nil {
-		switch t.Status().Reason {
+	delay, confidence := RetryDelay(err, RetryDelayOptions{})
+	if confidence == NoRetryDelay {
+		return 0, false
+	}
+	return int(math.Ceil(delay.Seconds())), true
+}
+
+// RetryDelayConfidence indicates where the delay returned by RetryDelay comes from. Higher values
+// indicate more authoritative sources.
+type RetryDelayConfidence int
+
+const (
+	// NoRetryDelay indicates that the error does not suggest a delay.
+	NoRetryDelay RetryDelayConfidence = iota
+	// DefaultRetryDelay indicates that the delay is the default configured by the caller for the
+	// reason of the error.
+	DefaultRetryDelay
+	// HeaderRetryDelay indicates that the delay comes from a Retry-After header captured with
+	// WithResponseHeaders.
+	HeaderRetryDelay
+	// StatusRetryDelay indicates that the delay comes from the details of the status of the error.
+	StatusRetryDelay
+)
+
+// RetryDelayOptions configures RetryDelay.
+type RetryDelayOptions struct {
+	// ReasonDefaults holds the delays suggested for errors with a given reason that neither carry a
+	// delay in their status details nor a captured Retry-After header.
+	ReasonDefaults map[metav1.StatusReason]time.Duration
+}
+
+// RetryDelay returns how long a client should wait before retrying the action that failed with err,
+// and where that delay comes from. In order of precedence, the delay comes from the RetryAfterSeconds
+// of the status details of err (a server timeout always suggests a delay, even if it is zero), from a
+// Retry-After header captured with WithResponseHeaders, or from options.ReasonDefaults. Like
+// SuggestsClientDelay, it does not address whether the error should be retried.
+// It supports wrapped errors and returns NoRetryDelay when the error is nil.
+func RetryDelay(err error, options RetryDelayOptions) (time.Duration, RetryDelayConfidence) {
+	if err == nil {
+		return 0, NoRetryDelay
+	}
+	t := APIStatus(nil)
+	hasStatus := errors.As(err, &t)
+	if hasStatus && t.Status().Details != nil {
+		seconds := t.Status().Details.RetryAfterSeconds
 		// this StatusReason explicitly requests the caller to delay the action
-		case metav1.StatusReasonServerTimeout:
-			return int(t.Status().Details.RetryAfterSeconds), true
+		if t.Status().Reason == metav1.StatusReasonServerTimeout || seconds > 0 {
+			return time.Duration(seconds) * time.Second, StatusRetryDelay
 		}
-		// If the client requests that we retry after a certain number of seconds
-		if t.Status().Details.RetryAfterSeconds > 0 {
-			return int(t.Status().Details.RetryAfterSeconds), true
+	}
+	if header := (*retryAfterError)(nil); errors.As(err, &header) {
+		return header.retryAfter, HeaderRetryDelay
+	}
+	if hasStatus {
+		if delay, ok := options.ReasonDefaults[t.Status().Reason]; ok {
+			return delay, DefaultRetryDelay
 		}
 	}
-	return 0, false
+	return 0, NoRetryDelay
+}
+
+// retryAfterError annotates an error with the delay of the Retry-After header of a response.
+type retryAfterError struct {
+	err        error
+	retryAfter time.Duration
+}
+
+func (e *retryAfterError) Error() string {
+	return e.err.Error()
+}
+
+func (e *retryAfterError) Unwrap() error {
+	return e.err
+}
+
+// WithResponseHeaders returns err annotated with the Retry-After header of the response it was created
+// from, for use by RetryDelay and SuggestsClientDelay. The header may hold a number of seconds or an
+// HTTP date, which is converted into a delay relative to the time of the call. err is returned
+// unchanged if it is nil or if header has no valid Retry-After value. The returned error wraps err.
+func WithResponseHeaders(err error, header http.Header) error {
+	if err == nil {
+		return nil
+	}
+	value := strings.TrimSpace(header.Get("Retry-After"))
+	if len(value) == 0 {
+		return err
+	}
+	var delay time.Duration
+	if seconds, parseErr := strconv.Atoi(value); parseErr == nil {
+		if seconds < 0 {
+			return err
+		}
+		delay = time.Duration(seconds) * time.Second
+	} else if date, parseErr := http.ParseTime(value); parseErr == nil {
+		if delay = time.Until(date); delay < 0 {
+			delay = 0
+		}
+	} else {
+		return err
+	}
+	return &retryAfterError{err: err, retryAfter: delay}
 }
 
 // ReasonForError returns the HTTP status for a particular error.
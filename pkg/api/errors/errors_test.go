@@ -22,6 +22,7 @@ import (
 	"net/http"
 	"reflect"
 	"testing"
+	"time"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -534,6 +535,105 @@ func TestSuggestsClientDelaySupportsWrapping(t *testing.T) {
 	}
 }
 
+func TestRetryDelay(t *testing.T) {
+	retryAfter := func(value string) http.Header {
+		return http.Header{"Retry-After": []string{value}}
+	}
+	defaults := RetryDelayOptions{ReasonDefaults: map[metav1.StatusReason]time.Duration{
+		metav1.StatusReasonTooManyRequests:    2 * time.Second,
+		metav1.StatusReasonServiceUnavailable: 5 * time.Second,
+	}}
+	testCases := []struct {
+		name               string
+		err                error
+		options            RetryDelayOptions
+		expectedDelay      time.Duration
+		expectedConfidence RetryDelayConfidence
+	}{
+		{
+			name:               "nil",
+			expectedConfidence: NoRetryDelay,
+		},
+		{
+			name:               "status details",
+			err:                NewTooManyRequests("slow down", 3),
+			options:            defaults,
+			expectedDelay:      3 * time.Second,
+			expectedConfidence: StatusRetryDelay,
+		},
+		{
+			name:               "server timeout without delay",
+			err:                NewServerTimeout(resource("tests"), "get", 0),
+			expectedConfidence: StatusRetryDelay,
+		},
+		{
+			name:               "status details take precedence over headers",
+			err:                WithResponseHeaders(NewTooManyRequests("slow down", 3), retryAfter("7")),
+			expectedDelay:      3 * time.Second,
+			expectedConfidence: StatusRetryDelay,
+		},
+		{
+			name:               "header seconds",
+			err:                fmt.Errorf("wrapped: %w", WithResponseHeaders(NewServiceUnavailable("down"), retryAfter("7"))),
+			options:            defaults,
+			expectedDelay:      7 * time.Second,
+			expectedConfidence: HeaderRetryDelay,
+		},
+		{
+			name:               "header date in the past",
+			err:                WithResponseHeaders(NewServiceUnavailable("down"), retryAfter("Wed, 21 Oct 2015 07:28:00 GMT")),
+			expectedConfidence: HeaderRetryDelay,
+		},
+		{
+			name:               "header on a generic error",
+			err:                WithResponseHeaders(errors.New("connection reset"), retryAfter("1")),
+			expectedDelay:      time.Second,
+			expectedConfidence: HeaderRetryDelay,
+		},
+		{
+			name:               "invalid header falls back to reason default",
+			err:                WithResponseHeaders(NewServiceUnavailable("down"), retryAfter("soon")),
+			options:            defaults,
+			expectedDelay:      5 * time.Second,
+			expectedConfidence: DefaultRetryDelay,
+		},
+		{
+			name:               "reason without default",
+			err:                NewNotFound(resource("tests"), "foo"),
+			options:            defaults,
+			expectedConfidence: NoRetryDelay,
+		},
+		{
+			name:               "generic error",
+			err:                errors.New("other"),
+			options:            defaults,
+			expectedConfidence: NoRetryDelay,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			delay, confidence := RetryDelay(tc.err, tc.options)
+			if delay != tc.expectedDelay || confidence != tc.expectedConfidence {
+				t.Errorf("expected %v with confidence %d, got %v with confidence %d", tc.expectedDelay, tc.expectedConfidence, delay, confidence)
+			}
+		})
+	}
+
+	err := WithResponseHeaders(NewServiceUnavailable("down"), retryAfter(time.Now().Add(time.Hour).UTC().Format(http.TimeFormat)))
+	if delay, confidence := RetryDelay(err, RetryDelayOptions{}); confidence != HeaderRetryDelay || delay < 58*time.Minute || delay > time.Hour {
+		t.Errorf("unexpected delay %v with confidence %d for a header date", delay, confidence)
+	}
+	if seconds, ok := SuggestsClientDelay(err); !ok || seconds < 58*60 || seconds > 60*60 {
+		t.Errorf("unexpected suggested delay %d", seconds)
+	}
+	if !IsServiceUnavailable(err) || err.Error() != "down" {
+		t.Errorf("expected the annotated error to behave like the original, got %v", err)
+	}
+	if original := errors.New("original"); WithResponseHeaders(original, http.Header{}) != original {
+		t.Errorf("expected errors without headers to be returned unchanged")
+	}
+}
+
 func TestIsErrorTypesByReasonAndCode(t *testing.T) {
 	testCases := []struct {
 		name                  string
@@ -0,0 +1,217 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package errors
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// GRPCCode is a gRPC status code. The values are identical to the codes defined by
+// google.golang.org/grpc/codes, so that conversions between the two are plain type conversions.
+type GRPCCode uint32
+
+const (
+	GRPCCodeOK                 GRPCCode = 0
+	GRPCCodeCanceled           GRPCCode = 1
+	GRPCCodeUnknown            GRPCCode = 2
+	GRPCCodeInvalidArgument    GRPCCode = 3
+	GRPCCodeDeadlineExceeded   GRPCCode = 4
+	GRPCCodeNotFound           GRPCCode = 5
+	GRPCCodeAlreadyExists      GRPCCode = 6
+	GRPCCodePermissionDenied   GRPCCode = 7
+	GRPCCodeResourceExhausted  GRPCCode = 8
+	GRPCCodeFailedPrecondition GRPCCode = 9
+	GRPCCodeAborted            GRPCCode = 10
+	GRPCCodeOutOfRange         GRPCCode = 11
+	GRPCCodeUnimplemented      GRPCCode = 12
+	GRPCCodeInternal           GRPCCode = 13
+	GRPCCodeUnavailable        GRPCCode = 14
+	GRPCCodeDataLoss           GRPCCode = 15
+	GRPCCodeUnauthenticated    GRPCCode = 16
+)
+
+// StatusDetailTypeURL is the type URL of the details of gRPC statuses that carry a serialized
+// metav1.Status, allowing the status, including its causes, to be restored without loss.
+const StatusDetailTypeURL = "type.googleapis.com/k8s.io.apimachinery.pkg.apis.meta.v1.Status"
+
+// GRPCStatus holds the content of a google.rpc.Status message. Callers using grpc-go can convert it
+// with status.FromProto(&spb.Status{Code: int32(s.Code), Message: s.Message, Details: details}),
+// building an anypb.Any{TypeUrl: d.TypeURL, Value: d.Value} for every detail d.
+type GRPCStatus struct {
+	Code    GRPCCode
+	Message string
+	Details []GRPCStatusDetail
+}
+
+// GRPCStatusDetail holds the content of a google.protobuf.Any message carried as a detail of a
+// google.rpc.Status message.
+type GRPCStatusDetail struct {
+	TypeURL string
+	Value   []byte
+}
+
+var grpcCodesForReasons = map[metav1.StatusReason]GRPCCode{
+	metav1.StatusReasonUnauthorized:          GRPCCodeUnauthenticated,
+	metav1.StatusReasonForbidden:             GRPCCodePermissionDenied,
+	metav1.StatusReasonNotFound:              GRPCCodeNotFound,
+	metav1.StatusReasonAlreadyExists:         GRPCCodeAlreadyExists,
+	metav1.StatusReasonConflict:              GRPCCodeAborted,
+	metav1.StatusReasonGone:                  GRPCCodeNotFound,
+	metav1.StatusReasonInvalid:               GRPCCodeInvalidArgument,
+	metav1.StatusReasonServerTimeout:         GRPCCodeDeadlineExceeded,
+	metav1.StatusReasonTimeout:               GRPCCodeDeadlineExceeded,
+	metav1.StatusReasonTooManyRequests:       GRPCCodeResourceExhausted,
+	metav1.StatusReasonBadRequest:            GRPCCodeInvalidArgument,
+	metav1.StatusReasonMethodNotAllowed:      GRPCCodeUnimplemented,
+	metav1.StatusReasonNotAcceptable:         GRPCCodeInvalidArgument,
+	metav1.StatusReasonRequestEntityTooLarge: GRPCCodeResourceExhausted,
+	metav1.StatusReasonUnsupportedMediaType:  GRPCCodeInvalidArgument,
+	metav1.StatusReasonInternalError:         GRPCCodeInternal,
+	metav1.StatusReasonExpired:               GRPCCodeOutOfRange,
+	metav1.StatusReasonServiceUnavailable:    GRPCCodeUnavailable,
+}
+
+var reasonsForGRPCCodes = map[GRPCCode]struct {
+	reason metav1.StatusReason
+	code   int32
+}{
+	GRPCCodeCanceled:           {metav1.StatusReasonUnknown, 499},
+	GRPCCodeUnknown:            {metav1.StatusReasonUnknown, http.StatusInternalServerError},
+	GRPCCodeInvalidArgument:    {metav1.StatusReasonBadRequest, http.StatusBadRequest},
+	GRPCCodeDeadlineExceeded:   {metav1.StatusReasonTimeout, http.StatusGatewayTimeout},
+	GRPCCodeNotFound:           {metav1.StatusReasonNotFound, http.StatusNotFound},
+	GRPCCodeAlreadyExists:      {metav1.StatusReasonAlreadyExists, http.StatusConflict},
+	GRPCCodePermissionDenied:   {metav1.StatusReasonForbidden, http.StatusForbidden},
+	GRPCCodeResourceExhausted:  {metav1.StatusReasonTooManyRequests, http.StatusTooManyRequests},
+	GRPCCodeFailedPrecondition: {metav1.StatusReasonBadRequest, http.StatusBadRequest},
+	GRPCCodeAborted:            {metav1.StatusReasonConflict, http.StatusConflict},
+	GRPCCodeOutOfRange:         {metav1.StatusReasonBadRequest, http.StatusBadRequest},
+	GRPCCodeUnimplemented:      {metav1.StatusReasonMethodNotAllowed, http.StatusMethodNotAllowed},
+	GRPCCodeInternal:           {metav1.StatusReasonInternalError, http.StatusInternalServerError},
+	GRPCCodeUnavailable:        {metav1.StatusReasonServiceUnavailable, http.StatusServiceUnavailable},
+	GRPCCodeDataLoss:           {metav1.StatusReasonInternalError, http.StatusInternalServerError},
+	GRPCCodeUnauthenticated:    {metav1.StatusReasonUnauthorized, http.StatusUnauthorized},
+}
+
+// GRPCCodeForError returns the gRPC code corresponding to err. The code is derived from the reason of
+// errors with an API status, or from their HTTP code if the reason is unknown. Context cancellation
+// and deadline errors map to Canceled and DeadlineExceeded, nil to OK and other errors to Unknown.
+func GRPCCodeForError(err error) GRPCCode {
+	if err == nil {
+		return GRPCCodeOK
+	}
+	if status := APIStatus(nil); errors.As(err, &status) {
+		if code, ok := grpcCodesForReasons[status.Status().Reason]; ok {
+			return code
+		}
+		return grpcCodeForHTTPCode(status.Status().Code)
+	}
+	switch {
+	case errors.Is(err, context.Canceled):
+		return GRPCCodeCanceled
+	case errors.Is(err, context.DeadlineExceeded):
+		return GRPCCodeDeadlineExceeded
+	}
+	return GRPCCodeUnknown
+}
+
+func grpcCodeForHTTPCode(code int32) GRPCCode {
+	switch code {
+	case http.StatusBadRequest:
+		return GRPCCodeInvalidArgument
+	case http.StatusUnauthorized:
+		return GRPCCodeUnauthenticated
+	case http.StatusForbidden:
+		return GRPCCodePermissionDenied
+	case http.StatusNotFound:
+		return GRPCCodeNotFound
+	case http.StatusConflict:
+		return GRPCCodeAborted
+	case http.StatusTooManyRequests:
+		return GRPCCodeResourceExhausted
+	case 499:
+		return GRPCCodeCanceled
+	case http.StatusNotImplemented:
+		return GRPCCodeUnimplemented
+	case http.StatusServiceUnavailable:
+		return GRPCCodeUnavailable
+	case http.StatusGatewayTimeout:
+		return GRPCCodeDeadlineExceeded
+	}
+	switch {
+	case code >= 400 && code < 500:
+		return GRPCCodeFailedPrecondition
+	case code >= 500 && code < 600:
+		return GRPCCodeInternal
+	}
+	return GRPCCodeUnknown
+}
+
+// ToGRPCStatus returns the gRPC status corresponding to err. The API status of err, if any, is carried
+// as a detail of type StatusDetailTypeURL so that FromGRPCStatus can restore it, including its causes.
+// A nil error results in an OK status.
+func ToGRPCStatus(err error) GRPCStatus {
+	if err == nil {
+		return GRPCStatus{Code: GRPCCodeOK}
+	}
+	s := GRPCStatus{Code: GRPCCodeForError(err), Message: err.Error()}
+	if status := APIStatus(nil); errors.As(err, &status) {
+		apiStatus := status.Status()
+		if data, err := apiStatus.Marshal(); err == nil {
+			s.Details = append(s.Details, GRPCStatusDetail{TypeURL: StatusDetailTypeURL, Value: data})
+		}
+	}
+	return s
+}
+
+// FromGRPCStatus returns the error corresponding to a gRPC status, or nil if its code is OK. If the
+// status carries a detail of type StatusDetailTypeURL, the serialized API status is restored. Otherwise
+// a StatusError is built with the reason and HTTP code best matching the gRPC code.
+func FromGRPCStatus(s GRPCStatus) error {
+	if s.Code == GRPCCodeOK {
+		return nil
+	}
+	for _, detail := range s.Details {
+		if detail.TypeURL != StatusDetailTypeURL {
+			continue
+		}
+		status := metav1.Status{}
+		if err := status.Unmarshal(detail.Value); err != nil {
+			continue
+		}
+		status.Status = metav1.StatusFailure
+		if len(status.Message) == 0 {
+			status.Message = s.Message
+		}
+		return &StatusError{ErrStatus: status}
+	}
+
+	mapped, ok := reasonsForGRPCCodes[s.Code]
+	if !ok {
+		mapped = reasonsForGRPCCodes[GRPCCodeUnknown]
+	}
+	return &StatusError{ErrStatus: metav1.Status{
+		Status:  metav1.StatusFailure,
+		Code:    mapped.code,
+		Reason:  mapped.reason,
+		Message: s.Message,
+	}}
+}
@@ -0,0 +1,109 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package errors
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+func TestGRPCCodeForError(t *testing.T) {
+	testCases := []struct {
+		err      error
+		expected GRPCCode
+	}{
+		{nil, GRPCCodeOK},
+		{NewNotFound(resource("pods"), "foo"), GRPCCodeNotFound},
+		{NewAlreadyExists(resource("pods"), "foo"), GRPCCodeAlreadyExists},
+		{NewConflict(resource("pods"), "foo", errors.New("stale")), GRPCCodeAborted},
+		{NewInvalid(kind("Pod"), "foo", nil), GRPCCodeInvalidArgument},
+		{NewForbidden(resource("pods"), "foo", errors.New("denied")), GRPCCodePermissionDenied},
+		{NewUnauthorized("who are you"), GRPCCodeUnauthenticated},
+		{NewTooManyRequests("slow down", 1), GRPCCodeResourceExhausted},
+		{NewServiceUnavailable("down"), GRPCCodeUnavailable},
+		{NewInternalError(errors.New("boom")), GRPCCodeInternal},
+		{NewResourceExpired("too old"), GRPCCodeOutOfRange},
+		{fmt.Errorf("wrapped: %w", NewTimeoutError("slow", 1)), GRPCCodeDeadlineExceeded},
+		{&StatusError{metav1.Status{Code: 501}}, GRPCCodeUnimplemented},
+		{NewGenericServerResponse(418, "get", resource("pods"), "foo", "", 0, false), GRPCCodeFailedPrecondition},
+		{context.Canceled, GRPCCodeCanceled},
+		{fmt.Errorf("wrapped: %w", context.DeadlineExceeded), GRPCCodeDeadlineExceeded},
+		{errors.New("other"), GRPCCodeUnknown},
+	}
+	for _, tc := range testCases {
+		if actual := GRPCCodeForError(tc.err); actual != tc.expected {
+			t.Errorf("%v: expected code %d, got %d", tc.err, tc.expected, actual)
+		}
+	}
+}
+
+func TestGRPCStatusRoundTrip(t *testing.T) {
+	original := NewInvalid(kind("Pod"), "foo", field.ErrorList{
+		field.Required(field.NewPath("spec", "containers"), ""),
+		field.Invalid(field.NewPath("metadata", "name"), "Foo", "must be lowercase"),
+	})
+	s := ToGRPCStatus(fmt.Errorf("wrapped: %w", original))
+	if s.Code != GRPCCodeInvalidArgument || len(s.Details) != 1 || s.Details[0].TypeURL != StatusDetailTypeURL {
+		t.Fatalf("unexpected status %#v", s)
+	}
+
+	restored := FromGRPCStatus(s)
+	if !IsInvalid(restored) {
+		t.Fatalf("expected an invalid error, got %v", restored)
+	}
+	if e, a := original.ErrStatus, restored.(*StatusError).ErrStatus; !reflect.DeepEqual(e, a) {
+		t.Errorf("expected %#v, got %#v", e, a)
+	}
+
+	if s := ToGRPCStatus(nil); s.Code != GRPCCodeOK || FromGRPCStatus(s) != nil {
+		t.Errorf("expected nil errors to map to OK, got %#v", s)
+	}
+}
+
+func TestFromGRPCStatus(t *testing.T) {
+	testCases := []struct {
+		status         GRPCStatus
+		expectedReason metav1.StatusReason
+		expectedCode   int32
+	}{
+		{GRPCStatus{Code: GRPCCodeNotFound, Message: "missing"}, metav1.StatusReasonNotFound, 404},
+		{GRPCStatus{Code: GRPCCodeAborted}, metav1.StatusReasonConflict, 409},
+		{GRPCStatus{Code: GRPCCodeDeadlineExceeded}, metav1.StatusReasonTimeout, 504},
+		{GRPCStatus{Code: GRPCCodeUnauthenticated}, metav1.StatusReasonUnauthorized, 401},
+		{GRPCStatus{Code: GRPCCodeUnavailable}, metav1.StatusReasonServiceUnavailable, 503},
+		{GRPCStatus{Code: GRPCCode(100)}, metav1.StatusReasonUnknown, 500},
+		{GRPCStatus{Code: GRPCCodeInternal, Details: []GRPCStatusDetail{{TypeURL: "type.googleapis.com/google.rpc.DebugInfo"}}}, metav1.StatusReasonInternalError, 500},
+		{GRPCStatus{Code: GRPCCodeInternal, Details: []GRPCStatusDetail{{TypeURL: StatusDetailTypeURL, Value: []byte{0xff}}}}, metav1.StatusReasonInternalError, 500},
+	}
+	for _, tc := range testCases {
+		err := FromGRPCStatus(tc.status)
+		status, ok := err.(*StatusError)
+		if !ok {
+			t.Errorf("%d: expected a StatusError, got %#v", tc.status.Code, err)
+			continue
+		}
+		if status.ErrStatus.Reason != tc.expectedReason || status.ErrStatus.Code != tc.expectedCode || status.ErrStatus.Message != tc.status.Message {
+			t.Errorf("%d: unexpected status %#v", tc.status.Code, status.ErrStatus)
+		}
+	}
+}
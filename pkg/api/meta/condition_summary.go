@@ -0,0 +1,207 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package meta
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"text/template"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// ConditionReasonNotReported is the reason of the conditions NewConditionSources creates for
+	// components that do not report the summarized condition type.
+	ConditionReasonNotReported = "NotReported"
+	// ConditionReasonNoSources is the default reason of a summary without sources.
+	ConditionReasonNoSources = "NoSources"
+)
+
+// ConditionSource is a condition reported by a named component, e.g. an object, to be included in a
+// summary condition.
+type ConditionSource struct {
+	// Name identifies the component reporting the condition.
+	Name string
+	// Condition is the condition reported by the component.
+	Condition metav1.Condition
+}
+
+// ConditionSummary is the outcome of summarizing conditions, which is passed to the templates of
+// ConditionSummaryOptions.
+type ConditionSummary struct {
+	// Type is the type of the summary condition.
+	Type string
+	// Status is the most severe status of the sources.
+	Status metav1.ConditionStatus
+	// Sources holds the sources with the summary status, the most severe reason first.
+	Sources []ConditionSource
+	// Total is the number of summarized sources.
+	Total int
+}
+
+// ConditionSummaryOptions configures SummarizeConditions.
+type ConditionSummaryOptions struct {
+	// Type is the type of the summary condition.
+	Type string
+	// StatusPriority orders condition statuses from the most to the least severe. Statuses that are
+	// not listed are less severe than all listed ones. Defaults to False, Unknown, True, which makes
+	// the summary true if all sources are true.
+	StatusPriority []metav1.ConditionStatus
+	// ReasonPriority optionally orders the reasons of sources with the same status from the most to
+	// the least severe. Reasons that are not listed are less severe than all listed ones, and sources
+	// with equally severe reasons keep their order.
+	ReasonPriority []string
+	// EmptyStatus is the status of the summary if there are no sources. Defaults to Unknown.
+	EmptyStatus metav1.ConditionStatus
+	// ReasonTemplate is a text/template executed with the ConditionSummary to produce the reason of
+	// the summary condition. By default, the reason of the most severe source is used.
+	ReasonTemplate string
+	// MessageTemplate is a text/template executed with the ConditionSummary to produce the message of
+	// the summary condition. By default, the names and messages of the sources with the summary status
+	// are listed, unless the summary status is the least severe one.
+	MessageTemplate string
+	// ObservedGeneration is set on the summary condition.
+	ObservedGeneration int64
+}
+
+var defaultConditionStatusPriority = []metav1.ConditionStatus{metav1.ConditionFalse, metav1.ConditionUnknown, metav1.ConditionTrue}
+
+// NewConditionSources returns a source for the condition of conditionType of each component in
+// conditions, which maps component names to the conditions they report, sorted by name. Components
+// that do not report conditionType are included with an Unknown status and the NotReported reason.
+func NewConditionSources(conditionType string, conditions map[string][]metav1.Condition) []ConditionSource {
+	names := make([]string, 0, len(conditions))
+	for name := range conditions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	sources := make([]ConditionSource, 0, len(names))
+	for _, name := range names {
+		condition := FindStatusCondition(conditions[name], conditionType)
+		if condition == nil {
+			condition = &metav1.Condition{
+				Type:    conditionType,
+				Status:  metav1.ConditionUnknown,
+				Reason:  ConditionReasonNotReported,
+				Message: fmt.Sprintf("condition %s is not reported", conditionType),
+			}
+		}
+		sources = append(sources, ConditionSource{Name: name, Condition: *condition})
+	}
+	return sources
+}
+
+// SummarizeConditions aggregates the conditions of sources into a single condition of the type set in
+// options, e.g. a Ready condition that is true only if the Ready conditions of all components are true.
+// The status of the summary is the most severe status of the sources. The LastTransitionTime of the
+// summary is not set, SetStatusCondition sets it when the status of the summary changes.
+// An error is returned if the templates of options are invalid or fail to execute.
+func SummarizeConditions(sources []ConditionSource, options ConditionSummaryOptions) (metav1.Condition, error) {
+	statusPriority := options.StatusPriority
+	if len(statusPriority) == 0 {
+		statusPriority = defaultConditionStatusPriority
+	}
+	statusRank := priorityRanks(statusPriority, func(s metav1.ConditionStatus) string { return string(s) })
+	reasonRank := priorityRanks(options.ReasonPriority, func(s string) string { return s })
+
+	summary := ConditionSummary{Type: options.Type, Total: len(sources)}
+	if len(sources) == 0 {
+		summary.Status = options.EmptyStatus
+		if len(summary.Status) == 0 {
+			summary.Status = metav1.ConditionUnknown
+		}
+	} else {
+		sorted := make([]ConditionSource, len(sources))
+		copy(sorted, sources)
+		sort.SliceStable(sorted, func(i, j int) bool {
+			si, sj := statusRank(string(sorted[i].Condition.Status)), statusRank(string(sorted[j].Condition.Status))
+			if si != sj {
+				return si < sj
+			}
+			return reasonRank(sorted[i].Condition.Reason) < reasonRank(sorted[j].Condition.Reason)
+		})
+		summary.Status = sorted[0].Condition.Status
+		for _, source := range sorted {
+			if source.Condition.Status != summary.Status {
+				break
+			}
+			summary.Sources = append(summary.Sources, source)
+		}
+	}
+
+	condition := metav1.Condition{
+		Type:               options.Type,
+		Status:             summary.Status,
+		ObservedGeneration: options.ObservedGeneration,
+	}
+
+	var err error
+	if len(options.ReasonTemplate) > 0 {
+		if condition.Reason, err = executeConditionTemplate("reason", options.ReasonTemplate, summary); err != nil {
+			return metav1.Condition{}, err
+		}
+	} else if len(summary.Sources) > 0 {
+		condition.Reason = summary.Sources[0].Condition.Reason
+	} else {
+		condition.Reason = ConditionReasonNoSources
+	}
+
+	if len(options.MessageTemplate) > 0 {
+		if condition.Message, err = executeConditionTemplate("message", options.MessageTemplate, summary); err != nil {
+			return metav1.Condition{}, err
+		}
+	} else if len(summary.Sources) > 0 && statusRank(string(summary.Status)) < len(statusPriority)-1 {
+		messages := make([]string, 0, len(summary.Sources))
+		for _, source := range summary.Sources {
+			messages = append(messages, fmt.Sprintf("%s: %s", source.Name, source.Condition.Message))
+		}
+		condition.Message = strings.Join(messages, "; ")
+	}
+	return condition, nil
+}
+
+// priorityRanks returns a function ranking values by their position in priority. Values that are not
+// listed rank after all listed ones.
+func priorityRanks[T any](priority []T, key func(T) string) func(string) int {
+	ranks := make(map[string]int, len(priority))
+	for i, p := range priority {
+		if _, ok := ranks[key(p)]; !ok {
+			ranks[key(p)] = i
+		}
+	}
+	return func(value string) int {
+		if rank, ok := ranks[value]; ok {
+			return rank
+		}
+		return len(priority)
+	}
+}
+
+func executeConditionTemplate(name, text string, summary ConditionSummary) (string, error) {
+	t, err := template.New(name).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("invalid %s template: %w", name, err)
+	}
+	var out strings.Builder
+	if err := t.Execute(&out, summary); err != nil {
+		return "", fmt.Errorf("failed to execute %s template: %w", name, err)
+	}
+	return out.String(), nil
+}
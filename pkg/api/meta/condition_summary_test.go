@@ -0,0 +1,138 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package meta
+
+import (
+	"reflect"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestSummarizeConditions(t *testing.T) {
+	ready := func(name string, status metav1.ConditionStatus, reason, message string) ConditionSource {
+		return ConditionSource{Name: name, Condition: metav1.Condition{Type: "Ready", Status: status, Reason: reason, Message: message}}
+	}
+
+	tests := []struct {
+		name     string
+		sources  []ConditionSource
+		options  ConditionSummaryOptions
+		expected metav1.Condition
+	}{
+		{
+			name:     "all ready",
+			sources:  []ConditionSource{ready("a", metav1.ConditionTrue, "Running", "ok"), ready("b", metav1.ConditionTrue, "Running", "ok")},
+			options:  ConditionSummaryOptions{Type: "Ready", ObservedGeneration: 3},
+			expected: metav1.Condition{Type: "Ready", Status: metav1.ConditionTrue, Reason: "Running", ObservedGeneration: 3},
+		},
+		{
+			name: "false is most severe",
+			sources: []ConditionSource{
+				ready("a", metav1.ConditionUnknown, "Pending", "waiting"),
+				ready("b", metav1.ConditionFalse, "Crashing", "exit 1"),
+				ready("c", metav1.ConditionTrue, "Running", "ok"),
+				ready("d", metav1.ConditionFalse, "Degraded", "slow"),
+			},
+			options:  ConditionSummaryOptions{Type: "Ready"},
+			expected: metav1.Condition{Type: "Ready", Status: metav1.ConditionFalse, Reason: "Crashing", Message: "b: exit 1; d: slow"},
+		},
+		{
+			name: "reason priority",
+			sources: []ConditionSource{
+				ready("b", metav1.ConditionFalse, "Crashing", "exit 1"),
+				ready("d", metav1.ConditionFalse, "Degraded", "slow"),
+			},
+			options:  ConditionSummaryOptions{Type: "Ready", ReasonPriority: []string{"Degraded"}},
+			expected: metav1.Condition{Type: "Ready", Status: metav1.ConditionFalse, Reason: "Degraded", Message: "d: slow; b: exit 1"},
+		},
+		{
+			name: "status priority",
+			sources: []ConditionSource{
+				ready("a", metav1.ConditionUnknown, "Pending", "waiting"),
+				ready("b", metav1.ConditionFalse, "Crashing", "exit 1"),
+			},
+			options:  ConditionSummaryOptions{Type: "Ready", StatusPriority: []metav1.ConditionStatus{metav1.ConditionUnknown, metav1.ConditionFalse, metav1.ConditionTrue}},
+			expected: metav1.Condition{Type: "Ready", Status: metav1.ConditionUnknown, Reason: "Pending", Message: "a: waiting"},
+		},
+		{
+			name: "templates",
+			sources: []ConditionSource{
+				ready("a", metav1.ConditionTrue, "Running", "ok"),
+				ready("b", metav1.ConditionFalse, "Crashing", "exit 1"),
+			},
+			options: ConditionSummaryOptions{
+				Type:            "Available",
+				ReasonTemplate:  "Components{{if eq .Status \"True\"}}Available{{else}}Unavailable{{end}}",
+				MessageTemplate: "{{len .Sources}} of {{.Total}} components are not available{{range .Sources}}, {{.Name}}{{end}}",
+			},
+			expected: metav1.Condition{Type: "Available", Status: metav1.ConditionFalse, Reason: "ComponentsUnavailable", Message: "1 of 2 components are not available, b"},
+		},
+		{
+			name:     "no sources",
+			options:  ConditionSummaryOptions{Type: "Ready"},
+			expected: metav1.Condition{Type: "Ready", Status: metav1.ConditionUnknown, Reason: ConditionReasonNoSources},
+		},
+		{
+			name:     "no sources with empty status",
+			options:  ConditionSummaryOptions{Type: "Ready", EmptyStatus: metav1.ConditionTrue},
+			expected: metav1.Condition{Type: "Ready", Status: metav1.ConditionTrue, Reason: ConditionReasonNoSources},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			original := append([]ConditionSource(nil), test.sources...)
+			summary, err := SummarizeConditions(test.sources, test.options)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(test.expected, summary) {
+				t.Errorf("expected %#v, got %#v", test.expected, summary)
+			}
+			if !reflect.DeepEqual(original, test.sources) {
+				t.Errorf("the sources were modified")
+			}
+		})
+	}
+
+	for _, tmpl := range []string{"{{.Missing}}", "{{"} {
+		if _, err := SummarizeConditions(nil, ConditionSummaryOptions{Type: "Ready", MessageTemplate: tmpl}); err == nil {
+			t.Errorf("expected error for template %q", tmpl)
+		}
+	}
+}
+
+func TestNewConditionSources(t *testing.T) {
+	sources := NewConditionSources("Ready", map[string][]metav1.Condition{
+		"b": {{Type: "Ready", Status: metav1.ConditionTrue, Reason: "Running"}},
+		"a": {{Type: "Synced", Status: metav1.ConditionTrue}},
+	})
+	if len(sources) != 2 || sources[0].Name != "a" || sources[1].Name != "b" {
+		t.Fatalf("expected sources sorted by name, got %#v", sources)
+	}
+	if e, a := ConditionReasonNotReported, sources[0].Condition.Reason; e != a || sources[0].Condition.Status != metav1.ConditionUnknown {
+		t.Errorf("expected a missing condition to be unknown with reason %s, got %#v", e, sources[0].Condition)
+	}
+
+	summary, err := SummarizeConditions(sources, ConditionSummaryOptions{Type: "Ready"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if summary.Status != metav1.ConditionUnknown || summary.Reason != ConditionReasonNotReported {
+		t.Errorf("unexpected summary %#v", summary)
+	}
+}
@@ -0,0 +1,314 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package meta
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"sigs.k8s.io/yaml"
+)
+
+// APIGroupResources is an API group with a mapping of versions to
+// resources.
+type APIGroupResources struct {
+	Group metav1.APIGroup
+	// A mapping of version string to a slice of APIResources for
+	// that version.
+	VersionedResources map[string][]metav1.APIResource
+}
+
+// DiscoveryRESTMapper is a RESTMapper built from the discovery information of an API server,
+// without contacting the server. It is suitable for tools that need to map resources offline,
+// e.g. from discovery documents recorded in files.
+type DiscoveryRESTMapper struct {
+	RESTMapper
+	groupResources []*APIGroupResources
+}
+
+// NewDiscoveryRESTMapper returns a RESTMapper based on the discovered groups and resources passed in.
+// Resources of the legacy "v1" version are preferred, followed by the preferred versions of each group
+// in the order the groups are passed in.
+func NewDiscoveryRESTMapper(groupResources []*APIGroupResources) *DiscoveryRESTMapper {
+	unionMapper := MultiRESTMapper{}
+
+	var groupPriority []string
+	// /v1 is special.  It should always come first
+	resourcePriority := []schema.GroupVersionResource{{Group: "", Version: "v1", Resource: AnyResource}}
+	kindPriority := []schema.GroupVersionKind{{Group: "", Version: "v1", Kind: AnyKind}}
+
+	for _, group := range groupResources {
+		groupPriority = append(groupPriority, group.Group.Name)
+
+		// Make sure the preferred version comes first
+		if len(group.Group.PreferredVersion.Version) != 0 {
+			preferred := group.Group.PreferredVersion.Version
+			if _, ok := group.VersionedResources[preferred]; ok {
+				resourcePriority = append(resourcePriority, schema.GroupVersionResource{
+					Group:    group.Group.Name,
+					Version:  group.Group.PreferredVersion.Version,
+					Resource: AnyResource,
+				})
+
+				kindPriority = append(kindPriority, schema.GroupVersionKind{
+					Group:   group.Group.Name,
+					Version: group.Group.PreferredVersion.Version,
+					Kind:    AnyKind,
+				})
+			}
+		}
+
+		for _, discoveryVersion := range group.Group.Versions {
+			resources, ok := group.VersionedResources[discoveryVersion.Version]
+			if !ok {
+				continue
+			}
+
+			// Add non-preferred versions after the preferred version, in case there are resources that only exist in those versions
+			if discoveryVersion.Version != group.Group.PreferredVersion.Version {
+				resourcePriority = append(resourcePriority, schema.GroupVersionResource{
+					Group:    group.Group.Name,
+					Version:  discoveryVersion.Version,
+					Resource: AnyResource,
+				})
+
+				kindPriority = append(kindPriority, schema.GroupVersionKind{
+					Group:   group.Group.Name,
+					Version: discoveryVersion.Version,
+					Kind:    AnyKind,
+				})
+			}
+
+			gv := schema.GroupVersion{Group: group.Group.Name, Version: discoveryVersion.Version}
+			versionMapper := NewDefaultRESTMapper([]schema.GroupVersion{gv})
+
+			for _, resource := range resources {
+				scope := RESTScopeNamespace
+				if !resource.Namespaced {
+					scope = RESTScopeRoot
+				}
+
+				// if we have a slash, then this is a subresource and we shouldn't create mappings for those.
+				if strings.Contains(resource.Name, "/") {
+					continue
+				}
+
+				plural := gv.WithResource(resource.Name)
+				singular := gv.WithResource(resource.SingularName)
+				// this is for legacy resources and servers which don't list singular forms.
+				// For those we must still guess.
+				if len(resource.SingularName) == 0 {
+					_, singular = UnsafeGuessKindToResource(gv.WithKind(resource.Kind))
+				}
+
+				versionMapper.AddSpecific(gv.WithKind(strings.ToLower(resource.Kind)), plural, singular, scope)
+				versionMapper.AddSpecific(gv.WithKind(resource.Kind), plural, singular, scope)
+				// TODO this is producing unsafe guesses that don't actually work, but it matches previous behavior
+				versionMapper.Add(gv.WithKind(resource.Kind+"List"), scope)
+			}
+			// TODO why is this type not in discovery (at least for "v1")
+			versionMapper.Add(gv.WithKind("List"), RESTScopeRoot)
+			unionMapper = append(unionMapper, versionMapper)
+		}
+	}
+
+	for _, group := range groupPriority {
+		resourcePriority = append(resourcePriority, schema.GroupVersionResource{
+			Group:    group,
+			Version:  AnyVersion,
+			Resource: AnyResource,
+		})
+		kindPriority = append(kindPriority, schema.GroupVersionKind{
+			Group:   group,
+			Version: AnyVersion,
+			Kind:    AnyKind,
+		})
+	}
+
+	return &DiscoveryRESTMapper{
+		RESTMapper: PriorityRESTMapper{
+			Delegate:         unionMapper,
+			ResourcePriority: resourcePriority,
+			KindPriority:     kindPriority,
+		},
+		groupResources: groupResources,
+	}
+}
+
+// NewDiscoveryRESTMapperFromLists returns a RESTMapper for the groups of groupList and the resources of
+// resourceLists, as served by the /apis and /apis/<group>/<version> (or /api/v1) discovery endpoints.
+// groupList may be nil. Groups that only appear in resourceLists, such as the legacy core group that is
+// not part of /apis, are added with their versions in the order they appear, and the first one preferred.
+func NewDiscoveryRESTMapperFromLists(groupList *metav1.APIGroupList, resourceLists []*metav1.APIResourceList) (*DiscoveryRESTMapper, error) {
+	var groupResources []*APIGroupResources
+	groups := map[string]*APIGroupResources{}
+	if groupList != nil {
+		for _, group := range groupList.Groups {
+			if _, ok := groups[group.Name]; ok {
+				return nil, fmt.Errorf("group %q is listed more than once", group.Name)
+			}
+			g := &APIGroupResources{Group: group, VersionedResources: map[string][]metav1.APIResource{}}
+			groups[group.Name] = g
+			groupResources = append(groupResources, g)
+		}
+	}
+
+	for _, resourceList := range resourceLists {
+		gv, err := schema.ParseGroupVersion(resourceList.GroupVersion)
+		if err != nil {
+			return nil, err
+		}
+		g, ok := groups[gv.Group]
+		if !ok {
+			g = &APIGroupResources{
+				Group:              metav1.APIGroup{Name: gv.Group},
+				VersionedResources: map[string][]metav1.APIResource{},
+			}
+			groups[gv.Group] = g
+			groupResources = append(groupResources, g)
+		}
+		if _, ok := g.VersionedResources[gv.Version]; ok {
+			return nil, fmt.Errorf("resources of %s are listed more than once", resourceList.GroupVersion)
+		}
+		g.VersionedResources[gv.Version] = resourceList.APIResources
+
+		if !hasDiscoveryVersion(g.Group, gv.Version) {
+			version := metav1.GroupVersionForDiscovery{GroupVersion: gv.String(), Version: gv.Version}
+			g.Group.Versions = append(g.Group.Versions, version)
+			if len(g.Group.PreferredVersion.Version) == 0 {
+				g.Group.PreferredVersion = version
+			}
+		}
+	}
+	return NewDiscoveryRESTMapper(groupResources), nil
+}
+
+func hasDiscoveryVersion(group metav1.APIGroup, version string) bool {
+	for _, v := range group.Versions {
+		if v.Version == version {
+			return true
+		}
+	}
+	return false
+}
+
+// LoadDiscoveryRESTMapper reads a stream of JSON or YAML discovery documents and returns a RESTMapper for
+// them. The stream may contain any number of APIGroupList and APIResourceList documents, which are
+// identified by their kind.
+func LoadDiscoveryRESTMapper(r io.Reader) (*DiscoveryRESTMapper, error) {
+	var (
+		groupList     *metav1.APIGroupList
+		resourceLists []*metav1.APIResourceList
+	)
+	decoder := utilyaml.NewYAMLOrJSONDecoder(r, 4096)
+	for {
+		var raw json.RawMessage
+		if err := decoder.Decode(&raw); err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, err
+		}
+		if len(raw) == 0 || string(raw) == "null" {
+			continue
+		}
+
+		var typeMeta metav1.TypeMeta
+		if err := json.Unmarshal(raw, &typeMeta); err != nil {
+			return nil, err
+		}
+		switch typeMeta.Kind {
+		case "APIGroupList":
+			list := &metav1.APIGroupList{}
+			if err := json.Unmarshal(raw, list); err != nil {
+				return nil, err
+			}
+			if groupList == nil {
+				groupList = list
+			} else {
+				groupList.Groups = append(groupList.Groups, list.Groups...)
+			}
+		case "APIResourceList":
+			list := &metav1.APIResourceList{}
+			if err := json.Unmarshal(raw, list); err != nil {
+				return nil, err
+			}
+			resourceLists = append(resourceLists, list)
+		default:
+			return nil, fmt.Errorf("unexpected discovery document of kind %q, expected APIGroupList or APIResourceList", typeMeta.Kind)
+		}
+	}
+	return NewDiscoveryRESTMapperFromLists(groupList, resourceLists)
+}
+
+// DiscoveryLists returns the discovery documents the mapper was built from: an APIGroupList containing
+// all groups, including the legacy core group, and an APIResourceList for each group version.
+func (m *DiscoveryRESTMapper) DiscoveryLists() (*metav1.APIGroupList, []*metav1.APIResourceList) {
+	groupList := &metav1.APIGroupList{TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "APIGroupList"}}
+	var resourceLists []*metav1.APIResourceList
+	for _, group := range m.groupResources {
+		groupList.Groups = append(groupList.Groups, group.Group)
+		for _, version := range group.Group.Versions {
+			resources, ok := group.VersionedResources[version.Version]
+			if !ok {
+				continue
+			}
+			resourceLists = append(resourceLists, &metav1.APIResourceList{
+				TypeMeta:     metav1.TypeMeta{APIVersion: "v1", Kind: "APIResourceList"},
+				GroupVersion: schema.GroupVersion{Group: group.Group.Name, Version: version.Version}.String(),
+				APIResources: resources,
+			})
+		}
+	}
+	return groupList, resourceLists
+}
+
+// WriteDiscoveryDocuments writes the discovery documents of the mapper to w as a YAML stream that can be
+// read with LoadDiscoveryRESTMapper.
+func (m *DiscoveryRESTMapper) WriteDiscoveryDocuments(w io.Writer) error {
+	groupList, resourceLists := m.DiscoveryLists()
+	documents := []interface{}{groupList}
+	for _, list := range resourceLists {
+		documents = append(documents, list)
+	}
+	for _, document := range documents {
+		data, err := yaml.Marshal(document)
+		if err != nil {
+			return err
+		}
+		if _, err := w.Write([]byte("---\n")); err != nil {
+			return err
+		}
+		if _, err := w.Write(data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Reset implements ResettableRESTMapper. The mapper is static, but resetting is propagated in
+// case the delegate supports it.
+func (m *DiscoveryRESTMapper) Reset() {
+	MaybeResetRESTMapper(m.RESTMapper)
+}
+
+var _ ResettableRESTMapper = &DiscoveryRESTMapper{}
@@ -0,0 +1,167 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package meta
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+const discoveryDocuments = `
+apiVersion: v1
+kind: APIResourceList
+groupVersion: v1
+resources:
+- name: pods
+  namespaced: true
+  kind: Pod
+- name: pods/status
+  namespaced: true
+  kind: Pod
+- name: nodes
+  singularName: node
+  namespaced: false
+  kind: Node
+---
+{"apiVersion": "v1", "kind": "APIGroupList", "groups": [
+  {"name": "extensions", "versions": [
+    {"groupVersion": "extensions/v1beta1", "version": "v1beta1"},
+    {"groupVersion": "extensions/v1", "version": "v1"}
+  ], "preferredVersion": {"groupVersion": "extensions/v1", "version": "v1"}}
+]}
+---
+apiVersion: v1
+kind: APIResourceList
+groupVersion: extensions/v1beta1
+resources:
+- name: jobs
+  namespaced: true
+  kind: Job
+- name: widgets
+  namespaced: true
+  kind: Widget
+---
+apiVersion: v1
+kind: APIResourceList
+groupVersion: extensions/v1
+resources:
+- name: jobs
+  namespaced: true
+  kind: Job
+`
+
+func TestLoadDiscoveryRESTMapper(t *testing.T) {
+	mapper, err := LoadDiscoveryRESTMapper(strings.NewReader(discoveryDocuments))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	kindTests := []struct {
+		input schema.GroupVersionResource
+		want  schema.GroupVersionKind
+	}{
+		{input: schema.GroupVersionResource{Resource: "pods"}, want: schema.GroupVersionKind{Version: "v1", Kind: "Pod"}},
+		{input: schema.GroupVersionResource{Resource: "pod"}, want: schema.GroupVersionKind{Version: "v1", Kind: "Pod"}},
+		{input: schema.GroupVersionResource{Resource: "node"}, want: schema.GroupVersionKind{Version: "v1", Kind: "Node"}},
+		{input: schema.GroupVersionResource{Resource: "jobs"}, want: schema.GroupVersionKind{Group: "extensions", Version: "v1", Kind: "Job"}},
+		{input: schema.GroupVersionResource{Resource: "widgets"}, want: schema.GroupVersionKind{Group: "extensions", Version: "v1beta1", Kind: "Widget"}},
+	}
+	for _, tc := range kindTests {
+		got, err := mapper.KindFor(tc.input)
+		if err != nil {
+			t.Errorf("%v: unexpected error: %v", tc.input, err)
+			continue
+		}
+		if got != tc.want {
+			t.Errorf("%v: expected %v, got %v", tc.input, tc.want, got)
+		}
+	}
+
+	if _, err := mapper.KindFor(schema.GroupVersionResource{Resource: "pods/status"}); err == nil {
+		t.Errorf("expected subresources not to be mapped")
+	}
+
+	mapping, err := mapper.RESTMapping(schema.GroupKind{Kind: "Node"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mapping.Scope.Name() != RESTScopeNameRoot {
+		t.Errorf("expected nodes to be cluster scoped, got %v", mapping.Scope.Name())
+	}
+}
+
+func TestLoadDiscoveryRESTMapperErrors(t *testing.T) {
+	testCases := map[string]string{
+		"unknown kind":        "apiVersion: v1\nkind: Status\n",
+		"invalid version":     "apiVersion: v1\nkind: APIResourceList\ngroupVersion: a/b/c\n",
+		"duplicate resources": "apiVersion: v1\nkind: APIResourceList\ngroupVersion: v1\n---\napiVersion: v1\nkind: APIResourceList\ngroupVersion: v1\n",
+		"malformed document":  "{\"kind\": ",
+	}
+	for name, input := range testCases {
+		t.Run(name, func(t *testing.T) {
+			if _, err := LoadDiscoveryRESTMapper(strings.NewReader(input)); err == nil {
+				t.Errorf("expected error")
+			}
+		})
+	}
+}
+
+func TestDiscoveryRESTMapperRoundTrip(t *testing.T) {
+	mapper, err := LoadDiscoveryRESTMapper(strings.NewReader(discoveryDocuments))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	groupList, resourceLists := mapper.DiscoveryLists()
+	var groups []string
+	for _, group := range groupList.Groups {
+		groups = append(groups, group.Name)
+	}
+	if e, a := []string{"extensions", ""}, groups; !reflect.DeepEqual(e, a) {
+		t.Errorf("expected groups %v, got %v", e, a)
+	}
+	if e, a := (metav1.GroupVersionForDiscovery{GroupVersion: "v1", Version: "v1"}), groupList.Groups[1].PreferredVersion; e != a {
+		t.Errorf("expected core preferred version %v, got %v", e, a)
+	}
+	var groupVersions []string
+	for _, list := range resourceLists {
+		groupVersions = append(groupVersions, list.GroupVersion)
+	}
+	if e, a := []string{"extensions/v1beta1", "extensions/v1", "v1"}, groupVersions; !reflect.DeepEqual(e, a) {
+		t.Errorf("expected group versions %v, got %v", e, a)
+	}
+
+	buf := &bytes.Buffer{}
+	if err := mapper.WriteDiscoveryDocuments(buf); err != nil {
+		t.Fatal(err)
+	}
+	reloaded, err := LoadDiscoveryRESTMapper(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(mapper.groupResources, reloaded.groupResources) {
+		t.Errorf("unexpected difference after round trip:\n%#v\n%#v", mapper.groupResources, reloaded.groupResources)
+	}
+	if !reflect.DeepEqual(mapper.RESTMapper, reloaded.RESTMapper) {
+		t.Errorf("expected the reloaded mapper to be identical")
+	}
+}
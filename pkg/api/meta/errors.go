@@ -78,9 +78,14 @@ func IsAmbiguousError(err error) bool {
 // NoResourceMatchError is returned if the RESTMapper can't find any match for a resource
 type NoResourceMatchError struct {
 	PartialResource schema.GroupVersionResource
+	// Suggestions optionally holds similarly named resources the user may have meant
+	Suggestions []schema.GroupResource
 }
 
 func (e *NoResourceMatchError) Error() string {
+	if len(e.Suggestions) > 0 {
+		return fmt.Sprintf("no matches for %v, did you mean %v?", e.PartialResource, e.Suggestions)
+	}
 	return fmt.Sprintf("no matches for %v", e.PartialResource)
 }
 
@@ -112,8 +112,27 @@ func getItemsPtr(list runtime.Object) (interface{}, error) {
 
 // EachListItem invokes fn on each runtime.Object in the list. Any error immediately terminates
 // the loop.
+//
+// If items passed to fn are retained for different durations, and you want to avoid
+// retaining all items in obj as long as any item is referenced, use EachListItemWithAlloc instead.
 func EachListItem(obj runtime.Object, fn func(runtime.Object) error) error {
+	return eachListItem(obj, fn, false)
+}
+
+// EachListItemWithAlloc works like EachListItem, but avoids retaining references to the items slice in obj.
+// It does this by making a shallow copy of non-pointer items in obj.
+//
+// If the items passed to fn are not retained, or are retained for the same duration, use EachListItem instead for memory efficiency.
+func EachListItemWithAlloc(obj runtime.Object, fn func(runtime.Object) error) error {
+	return eachListItem(obj, fn, true)
+}
+
+// allocNew: Whether shallow copy is required when the elements in Object.Items are struct
+func eachListItem(obj runtime.Object, fn func(runtime.Object) error, allocNew bool) error {
 	if unstructured, ok := obj.(runtime.Unstructured); ok {
+		if allocNew {
+			return unstructured.EachListItemWithAlloc(fn)
+		}
 		return unstructured.EachListItem(fn)
 	}
 	// TODO: Change to an interface call?
@@ -140,7 +159,16 @@ func EachListItem(obj runtime.Object, fn func(runtime.Object) error) error {
 	for i := 0; i < len; i++ {
 		raw := items.Index(i)
 		if takeAddr {
-			raw = raw.Addr()
+			if allocNew {
+				// shallow copy to avoid retaining a reference to the original list item
+				itemCopy := reflect.New(raw.Type())
+				// assign to itemCopy and type-assert
+				itemCopy.Elem().Set(raw)
+				// reflect.New will guarantee that itemCopy must be a pointer.
+				raw = itemCopy
+			} else {
+				raw = raw.Addr()
+			}
 		}
 		switch item := raw.Interface().(type) {
 		case *runtime.RawExtension:
@@ -166,7 +194,23 @@ func EachListItem(obj runtime.Object, fn func(runtime.Object) error) error {
 
 // ExtractList returns obj's Items element as an array of runtime.Objects.
 // Returns an error if obj is not a List type (does not have an Items member).
+//
+// If items in the returned list are retained for different durations, and you want to avoid
+// retaining all items in obj as long as any item is referenced, use ExtractListWithAlloc instead.
 func ExtractList(obj runtime.Object) ([]runtime.Object, error) {
+	return extractList(obj, false)
+}
+
+// ExtractListWithAlloc works like ExtractList, but avoids retaining references to the items slice in obj.
+// It does this by making a shallow copy of non-pointer items in obj.
+//
+// If the items in the returned list are not retained, or are retained for the same duration, use ExtractList instead for memory efficiency.
+func ExtractListWithAlloc(obj runtime.Object) ([]runtime.Object, error) {
+	return extractList(obj, true)
+}
+
+// allocNew: Whether shallow copy is required when the elements in Object.Items are struct
+func extractList(obj runtime.Object, allocNew bool) ([]runtime.Object, error) {
 	itemsPtr, err := GetItemsPtr(obj)
 	if err != nil {
 		return nil, err
@@ -176,6 +220,9 @@ func ExtractList(obj runtime.Object) ([]runtime.Object, error) {
 		return nil, err
 	}
 	list := make([]runtime.Object, items.Len())
+	if extractTypedItems(items, list, allocNew) {
+		return list, nil
+	}
 	for i := range list {
 		raw := items.Index(i)
 		switch item := raw.Interface().(type) {
@@ -193,7 +240,16 @@ func ExtractList(obj runtime.Object) ([]runtime.Object, error) {
 			list[i] = item
 		default:
 			var found bool
-			if list[i], found = raw.Addr().Interface().(runtime.Object); !found {
+			if allocNew {
+				// shallow copy to avoid retaining a reference to the original list item
+				itemCopy := reflect.New(raw.Type())
+				// assign to itemCopy and type-assert
+				itemCopy.Elem().Set(raw)
+				// reflect.New will guarantee that itemCopy must be a pointer.
+				if list[i], found = itemCopy.Interface().(runtime.Object); !found {
+					return nil, fmt.Errorf("%v: item[%v]: Expected object, got %#v(%s)", obj, i, raw.Interface(), raw.Kind())
+				}
+			} else if list[i], found = raw.Addr().Interface().(runtime.Object); !found {
 				return nil, fmt.Errorf("%v: item[%v]: Expected object, got %#v(%s)", obj, i, raw.Interface(), raw.Kind())
 			}
 		}
@@ -201,8 +257,112 @@ func ExtractList(obj runtime.Object) ([]runtime.Object, error) {
 	return list, nil
 }
 
-// objectSliceType is the type of a slice of Objects
-var objectSliceType = reflect.TypeOf([]runtime.Object{})
+// extractTypedItems fills list with the items when the element type of the items slice allows
+// skipping the per item type switch of extractList, which boxes struct items into interfaces.
+// It returns false if the slow path has to be taken.
+func extractTypedItems(items reflect.Value, list []runtime.Object, allocNew bool) bool {
+	if items.Type() == objectSliceType {
+		copy(list, items.Interface().([]runtime.Object))
+		return true
+	}
+	elemType := items.Type().Elem()
+	switch {
+	case elemType.Kind() == reflect.Ptr && elemType.Implements(objectType):
+		for i := range list {
+			list[i] = items.Index(i).Interface().(runtime.Object)
+		}
+		return true
+	case elemType.Kind() == reflect.Struct && elemType != rawExtensionType && reflect.PtrTo(elemType).Implements(objectType):
+		if !allocNew && !items.CanAddr() {
+			return false
+		}
+		for i := range list {
+			raw := items.Index(i)
+			if allocNew {
+				// shallow copy to avoid retaining a reference to the original list item
+				itemCopy := reflect.New(elemType)
+				itemCopy.Elem().Set(raw)
+				raw = itemCopy
+			} else {
+				raw = raw.Addr()
+			}
+			list[i] = raw.Interface().(runtime.Object)
+		}
+		return true
+	default:
+		return false
+	}
+}
+
+// EachListItemT invokes fn on each item in list, which must all be of type T. Any error,
+// including an item of another type, immediately terminates the loop. It has the semantics
+// of EachListItem.
+func EachListItemT[T runtime.Object](list runtime.Object, fn func(T) error) error {
+	return eachListItemT(list, fn, false)
+}
+
+// EachListItemWithAllocT works like EachListItemT, but avoids retaining references to the items
+// slice in list, like EachListItemWithAlloc.
+func EachListItemWithAllocT[T runtime.Object](list runtime.Object, fn func(T) error) error {
+	return eachListItemT(list, fn, true)
+}
+
+func eachListItemT[T runtime.Object](list runtime.Object, fn func(T) error, allocNew bool) error {
+	i := 0
+	return eachListItem(list, func(obj runtime.Object) error {
+		item, err := listItemAs[T](list, i, obj)
+		if err != nil {
+			return err
+		}
+		i++
+		return fn(item)
+	}, allocNew)
+}
+
+// ExtractListT returns the items of list, which must all be of type T. It has the semantics of
+// ExtractList.
+func ExtractListT[T runtime.Object](list runtime.Object) ([]T, error) {
+	return extractListT[T](list, false)
+}
+
+// ExtractListWithAllocT works like ExtractListT, but avoids retaining references to the items
+// slice in list, like ExtractListWithAlloc.
+func ExtractListWithAllocT[T runtime.Object](list runtime.Object) ([]T, error) {
+	return extractListT[T](list, true)
+}
+
+func extractListT[T runtime.Object](list runtime.Object, allocNew bool) ([]T, error) {
+	objects, err := extractList(list, allocNew)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]T, len(objects))
+	for i, obj := range objects {
+		if items[i], err = listItemAs[T](list, i, obj); err != nil {
+			return nil, err
+		}
+	}
+	return items, nil
+}
+
+// listItemAs returns the i-th item of list as a T.
+func listItemAs[T runtime.Object](list runtime.Object, i int, obj runtime.Object) (T, error) {
+	item, ok := obj.(T)
+	if !ok {
+		var t T
+		return t, fmt.Errorf("%T: item[%v]: expected %T, got %T", list, i, t, obj)
+	}
+	return item, nil
+}
+
+var (
+	// objectSliceType is the type of a slice of Objects
+	objectSliceType = reflect.TypeOf([]runtime.Object{})
+	// objectType is the type of the Object interface
+	objectType = reflect.TypeOf((*runtime.Object)(nil)).Elem()
+	// rawExtensionType is the type of RawExtension items
+	rawExtensionType = reflect.TypeOf(runtime.RawExtension{})
+)
 
 // LenList returns the length of this list or 0 if it is not a list.
 func LenList(list runtime.Object) int {
@@ -221,6 +381,10 @@ func LenList(list runtime.Object) int {
 // objects.
 // Returns an error if list is not a List type (does not have an Items member),
 // or if any of the objects are not of the right type.
+//
+// If objects are pointers to a contiguous range of the list's struct items in order,
+// as returned by ExtractList and possibly resliced, the Items member is resliced to
+// share its backing array instead of copying every item.
 func SetList(list runtime.Object, objects []runtime.Object) error {
 	itemsPtr, err := GetItemsPtr(list)
 	if err != nil {
@@ -234,10 +398,29 @@ func SetList(list runtime.Object, objects []runtime.Object) error {
 		items.Set(reflect.ValueOf(objects))
 		return nil
 	}
+	elemType := items.Type().Elem()
+	if shared, ok := sharedItems(items, objects); ok {
+		items.Set(shared)
+		return nil
+	}
+	ptrType := reflect.PtrTo(elemType)
 	slice := reflect.MakeSlice(items.Type(), len(objects), len(objects))
 	for i := range objects {
 		dest := slice.Index(i)
-		if dest.Type() == reflect.TypeOf(runtime.RawExtension{}) {
+
+		// fast paths for items of exactly the element type, or pointers to it
+		if src := reflect.ValueOf(objects[i]); src.IsValid() && elemType != rawExtensionType {
+			switch {
+			case src.Type() == elemType:
+				dest.Set(src)
+				continue
+			case src.Type() == ptrType && !src.IsNil():
+				dest.Set(src.Elem())
+				continue
+			}
+		}
+
+		if dest.Type() == rawExtensionType {
 			dest = dest.FieldByName("Object")
 		}
 
@@ -262,3 +445,30 @@ func SetList(list runtime.Object, objects []runtime.Object) error {
 	items.Set(slice)
 	return nil
 }
+
+// sharedItems returns a slice of items holding objects, if objects point to the struct items
+// items[k:k+len(objects)] for some k.
+func sharedItems(items reflect.Value, objects []runtime.Object) (reflect.Value, bool) {
+	elemType := items.Type().Elem()
+	size := elemType.Size()
+	if len(objects) == 0 || elemType.Kind() != reflect.Struct || elemType == rawExtensionType || size == 0 || items.Len() == 0 {
+		return reflect.Value{}, false
+	}
+	ptrType := reflect.PtrTo(elemType)
+	base := items.Pointer()
+	first := reflect.ValueOf(objects[0])
+	if !first.IsValid() || first.Type() != ptrType || first.Pointer() < base || (first.Pointer()-base)%size != 0 {
+		return reflect.Value{}, false
+	}
+	start := int((first.Pointer() - base) / size)
+	if start+len(objects) > items.Len() {
+		return reflect.Value{}, false
+	}
+	for i, obj := range objects {
+		v := reflect.ValueOf(obj)
+		if !v.IsValid() || v.Type() != ptrType || v.Pointer() != base+uintptr(start+i)*size {
+			return reflect.Value{}, false
+		}
+	}
+	return items.Slice(start, start+len(objects)), true
+}
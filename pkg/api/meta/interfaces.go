@@ -141,3 +141,16 @@ type ResettableRESTMapper interface {
 	RESTMapper
 	Reset()
 }
+
+// ResourceResolver resolves the resource strings typed by users, like "deploy", "cm" or
+// "foo.bar.example.com", to resources. Besides plural resource names, the strings may be
+// singular names, kinds or short names, and may be qualified by a full or partial group name,
+// optionally preceded by a version.
+type ResourceResolver interface {
+	// ResourceCandidates returns all resources matching input in priority order.
+	ResourceCandidates(input string) ([]schema.GroupVersionResource, error)
+	// ResolveResource returns the single resource input refers to. It returns an
+	// AmbiguousResourceError listing the candidates if input does not identify a single
+	// resource, and a NoResourceMatchError with suggestions if nothing matches.
+	ResolveResource(input string) (schema.GroupVersionResource, error)
+}
@@ -53,6 +53,7 @@ func (o *lazyObject) init() error {
 }
 
 var _ ResettableRESTMapper = &lazyObject{}
+var _ ResourceResolver = &lazyObject{}
 
 func (o *lazyObject) KindFor(resource schema.GroupVersionResource) (schema.GroupVersionKind, error) {
 	if err := o.init(); err != nil {
@@ -103,10 +104,34 @@ func (o *lazyObject) ResourceSingularizer(resource string) (singular string, err
 	return o.mapper.ResourceSingularizer(resource)
 }
 
+// ResourceCandidates implements ResourceResolver by delegating to the loaded mapper.
+func (o *lazyObject) ResourceCandidates(input string) ([]schema.GroupVersionResource, error) {
+	if err := o.init(); err != nil {
+		return nil, err
+	}
+	return ResourceCandidates(o.mapper, input)
+}
+
+// ResolveResource implements ResourceResolver by delegating to the loaded mapper.
+func (o *lazyObject) ResolveResource(input string) (schema.GroupVersionResource, error) {
+	if err := o.init(); err != nil {
+		return schema.GroupVersionResource{}, err
+	}
+	return ResolveResource(o.mapper, input)
+}
+
+// Reset resets the loaded mapper. If loading the mapper failed, the loader is invoked again
+// the next time the mapper is used.
 func (o *lazyObject) Reset() {
 	o.lock.Lock()
 	defer o.lock.Unlock()
-	if o.loaded && o.err == nil {
-		MaybeResetRESTMapper(o.mapper)
+	if !o.loaded {
+		return
+	}
+	if o.err != nil {
+		o.loaded = false
+		o.err = nil
+		return
 	}
+	MaybeResetRESTMapper(o.mapper)
 }
@@ -0,0 +1,89 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package meta
+
+import (
+	"errors"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// resettableRESTMapper counts the calls to Reset.
+type resettableRESTMapper struct {
+	fixedRESTMapper
+	resets *int
+}
+
+func (m resettableRESTMapper) Reset() {
+	*m.resets++
+}
+
+func TestMaybeResetRESTMapperPropagates(t *testing.T) {
+	resets := 0
+	leaf := resettableRESTMapper{fixedRESTMapper: fixedRESTMapper{kindsFor: []schema.GroupVersionKind{{Kind: "Foo"}}}, resets: &resets}
+	lazy := NewLazyRESTMapperLoader(func() (RESTMapper, error) { return MultiRESTMapper{leaf}, nil })
+
+	mappers := map[string]RESTMapper{
+		"multi":     MultiRESTMapper{fixedRESTMapper{}, leaf},
+		"priority":  PriorityRESTMapper{Delegate: leaf},
+		"first hit": FirstHitRESTMapper{MultiRESTMapper{leaf}},
+		"lazy":      lazy,
+		"nested":    PriorityRESTMapper{Delegate: MultiRESTMapper{FirstHitRESTMapper{MultiRESTMapper{lazy}}}},
+	}
+	for name, mapper := range mappers {
+		t.Run(name, func(t *testing.T) {
+			// load the lazy mapper, resetting a mapper that was never loaded is a no-op
+			if _, err := lazy.KindFor(schema.GroupVersionResource{}); err != nil {
+				t.Fatal(err)
+			}
+			resets = 0
+			MaybeResetRESTMapper(mapper)
+			if resets != 1 {
+				t.Errorf("expected the wrapped mapper to be reset once, got %d", resets)
+			}
+		})
+	}
+}
+
+func TestLazyRESTMapperResetRetriesFailedLoad(t *testing.T) {
+	loads := 0
+	loadErr := errors.New("discovery failed")
+	mapper := NewLazyRESTMapperLoader(func() (RESTMapper, error) {
+		loads++
+		if loads == 1 {
+			return nil, loadErr
+		}
+		return fixedRESTMapper{kindFor: schema.GroupVersionKind{Kind: "Foo"}}, nil
+	})
+
+	if _, err := mapper.KindFor(schema.GroupVersionResource{}); err != loadErr {
+		t.Fatalf("expected load error, got %v", err)
+	}
+	if _, err := mapper.KindFor(schema.GroupVersionResource{}); err != loadErr || loads != 1 {
+		t.Fatalf("expected the load error to be cached, got %v after %d loads", err, loads)
+	}
+
+	MaybeResetRESTMapper(mapper)
+	kind, err := mapper.KindFor(schema.GroupVersionResource{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if kind.Kind != "Foo" || loads != 2 {
+		t.Errorf("expected the mapper to be loaded again, got %v after %d loads", kind, loads)
+	}
+}
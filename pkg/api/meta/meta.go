@@ -19,6 +19,7 @@ package meta
 import (
 	"fmt"
 	"reflect"
+	"strings"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/conversion"
@@ -137,6 +138,51 @@ func AsPartialObjectMetadata(m metav1.Object) *metav1.PartialObjectMetadata {
 	}
 }
 
+// AsPartialObjectMetadataList returns the metadata of a typed or unstructured list and of its
+// items. The list metadata, including the continue token and remaining item count, is
+// preserved, and items keep their apiVersion and kind. Items without them, as usual for typed
+// lists, get the kind of the list without its List suffix. Items share the maps and slices of
+// their metadata with the items of list.
+func AsPartialObjectMetadataList(list runtime.Object) (*metav1.PartialObjectMetadataList, error) {
+	listMeta, err := ListAccessor(list)
+	if err != nil {
+		return nil, err
+	}
+	result := &metav1.PartialObjectMetadataList{
+		ListMeta: metav1.ListMeta{
+			SelfLink:           listMeta.GetSelfLink(),
+			ResourceVersion:    listMeta.GetResourceVersion(),
+			Continue:           listMeta.GetContinue(),
+			RemainingItemCount: listMeta.GetRemainingItemCount(),
+		},
+		Items: make([]metav1.PartialObjectMetadata, 0, LenList(list)),
+	}
+	result.SetGroupVersionKind(metav1.SchemeGroupVersion.WithKind("PartialObjectMetadataList"))
+
+	var itemGVK schema.GroupVersionKind
+	if listGVK := list.GetObjectKind().GroupVersionKind(); strings.HasSuffix(listGVK.Kind, "List") {
+		itemGVK = listGVK.GroupVersion().WithKind(strings.TrimSuffix(listGVK.Kind, "List"))
+	}
+	err = EachListItem(list, func(obj runtime.Object) error {
+		m, err := Accessor(obj)
+		if err != nil {
+			return err
+		}
+		item := AsPartialObjectMetadata(m)
+		if gvk := obj.GetObjectKind().GroupVersionKind(); !gvk.Empty() {
+			item.SetGroupVersionKind(gvk)
+		} else if len(itemGVK.Kind) > 0 {
+			item.SetGroupVersionKind(itemGVK)
+		}
+		result.Items = append(result.Items, *item)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
 // TypeAccessor returns an interface that allows retrieving and modifying the APIVersion
 // and Kind of an in-memory internal object.
 // TODO: this interface is used to test code that does not have ObjectMeta or ListMeta
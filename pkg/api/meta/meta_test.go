@@ -22,7 +22,9 @@ import (
 	"testing"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	metav1beta1 "k8s.io/apimachinery/pkg/apis/meta/v1beta1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/util/diff"
 
 	fuzz "github.com/google/gofuzz"
@@ -49,3 +51,61 @@ func TestAsPartialObjectMetadata(t *testing.T) {
 		}
 	}
 }
+
+func TestAsPartialObjectMetadataList(t *testing.T) {
+	remaining := int64(3)
+	list := &unstructured.UnstructuredList{Items: []unstructured.Unstructured{
+		{Object: map[string]interface{}{"apiVersion": "example.com/v1", "kind": "Widget", "metadata": map[string]interface{}{"name": "a", "labels": map[string]interface{}{"app": "a"}}}},
+		{Object: map[string]interface{}{"metadata": map[string]interface{}{"name": "b", "namespace": "ns"}}},
+	}}
+	list.SetAPIVersion("example.com/v1")
+	list.SetKind("GadgetList")
+	list.SetResourceVersion("10")
+	list.SetContinue("next")
+	list.SetRemainingItemCount(&remaining)
+
+	partial, err := AsPartialObjectMetadataList(list)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e, a := metav1.SchemeGroupVersion.WithKind("PartialObjectMetadataList"), partial.GroupVersionKind(); e != a {
+		t.Errorf("expected %v, got %v", e, a)
+	}
+	if partial.ResourceVersion != "10" || partial.Continue != "next" || partial.RemainingItemCount == nil || *partial.RemainingItemCount != 3 {
+		t.Errorf("unexpected list metadata %#v", partial.ListMeta)
+	}
+	if len(partial.Items) != 2 {
+		t.Fatalf("expected 2 items, got %d", len(partial.Items))
+	}
+	if e, a := (schema.GroupVersionKind{Group: "example.com", Version: "v1", Kind: "Widget"}), partial.Items[0].GroupVersionKind(); e != a {
+		t.Errorf("expected %v, got %v", e, a)
+	}
+	if e, a := map[string]string{"app": "a"}, partial.Items[0].Labels; !reflect.DeepEqual(e, a) {
+		t.Errorf("expected %v, got %v", e, a)
+	}
+	if e, a := (schema.GroupVersionKind{Group: "example.com", Version: "v1", Kind: "Gadget"}), partial.Items[1].GroupVersionKind(); e != a {
+		t.Errorf("expected the kind of the list, got %v", a)
+	}
+	if partial.Items[1].Name != "b" || partial.Items[1].Namespace != "ns" {
+		t.Errorf("unexpected item metadata %#v", partial.Items[1].ObjectMeta)
+	}
+
+	typed := &metav1.PartialObjectMetadataList{
+		ListMeta: metav1.ListMeta{ResourceVersion: "5"},
+		Items:    []metav1.PartialObjectMetadata{{ObjectMeta: metav1.ObjectMeta{Name: "c", Finalizers: []string{"f"}}}},
+	}
+	partial, err = AsPartialObjectMetadataList(typed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if partial.ResourceVersion != "5" || len(partial.Items) != 1 || !reflect.DeepEqual(partial.Items[0].ObjectMeta, typed.Items[0].ObjectMeta) {
+		t.Errorf("unexpected list %#v", partial)
+	}
+	if !partial.Items[0].GroupVersionKind().Empty() {
+		t.Errorf("expected no kind for items of a list without kind, got %v", partial.Items[0].GroupVersionKind())
+	}
+
+	if _, err := AsPartialObjectMetadataList(&metav1.PartialObjectMetadata{}); err == nil {
+		t.Errorf("expected an error for an object that is not a list")
+	}
+}
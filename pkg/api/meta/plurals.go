@@ -0,0 +1,100 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package meta
+
+import (
+	"strings"
+	"sync"
+)
+
+// PluralRegistry holds the plurals of irregular nouns, used when guessing the resources of kinds.
+// Plurals are registered for singular suffixes of kinds, so that a plural registered for "index"
+// applies to "SearchIndex" as well. The longest matching suffix is used.
+// A PluralRegistry is safe for concurrent use.
+type PluralRegistry struct {
+	lock    sync.RWMutex
+	plurals map[string]string
+}
+
+// NewPluralRegistry returns an empty registry.
+func NewPluralRegistry() *PluralRegistry {
+	return &PluralRegistry{plurals: map[string]string{}}
+}
+
+// DefaultPluralRegistry is consulted by UnsafeGuessKindToResource and by DefaultRESTMappers
+// without a registry of their own.
+var DefaultPluralRegistry = NewPluralRegistry()
+
+// RegisterIrregularPlural registers plural as the plural of singular in DefaultPluralRegistry.
+func RegisterIrregularPlural(singular, plural string) {
+	DefaultPluralRegistry.Register(singular, plural)
+}
+
+// Register registers plural as the plural of kinds ending with singular, case-insensitively.
+// Registering the same plural and singular marks a noun as uncountable.
+func (r *PluralRegistry) Register(singular, plural string) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.plurals[strings.ToLower(singular)] = strings.ToLower(plural)
+}
+
+// Plural returns the plural of singular, a lowercase name, if it ends with a registered noun.
+func (r *PluralRegistry) Plural(singular string) (string, bool) {
+	if r == nil {
+		return "", false
+	}
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	longest := ""
+	found := false
+	for suffix := range r.plurals {
+		// suffixes of the same length matching singular are identical.
+		if (!found || len(suffix) > len(longest)) && strings.HasSuffix(singular, suffix) {
+			longest, found = suffix, true
+		}
+	}
+	if !found {
+		return "", false
+	}
+	return strings.TrimSuffix(singular, longest) + r.plurals[longest], true
+}
+
+// Singular returns the singular of plural, a lowercase name, if it ends with the plural of a
+// registered noun.
+func (r *PluralRegistry) Singular(plural string) (string, bool) {
+	if r == nil {
+		return "", false
+	}
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	longest, singular := "", ""
+	found := false
+	for s, p := range r.plurals {
+		if !strings.HasSuffix(plural, p) || (found && len(p) < len(longest)) {
+			continue
+		}
+		// nouns sharing a plural are ordered for determinism.
+		if found && len(p) == len(longest) && s > singular {
+			continue
+		}
+		longest, singular, found = p, s, true
+	}
+	if !found {
+		return "", false
+	}
+	return strings.TrimSuffix(plural, longest) + singular, true
+}
@@ -0,0 +1,327 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package meta
+
+import (
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// maxSuggestionDistance is the largest edit distance between the input and a resource name for
+// the resource to be suggested when nothing matches.
+const maxSuggestionDistance = 2
+
+// ResourceCandidates returns all resources matching input in priority order. If mapper implements
+// ResourceResolver it is used, otherwise input is parsed with schema.ParseResourceArg and the
+// candidates are those returned by the mapper's ResourcesFor.
+func ResourceCandidates(mapper RESTMapper, input string) ([]schema.GroupVersionResource, error) {
+	if resolver, ok := mapper.(ResourceResolver); ok {
+		return resolver.ResourceCandidates(input)
+	}
+
+	var candidates []schema.GroupVersionResource
+	fullySpecifiedGVR, groupResource := schema.ParseResourceArg(strings.ToLower(input))
+	partials := []schema.GroupVersionResource{groupResource.WithVersion("")}
+	if fullySpecifiedGVR != nil {
+		partials = append([]schema.GroupVersionResource{*fullySpecifiedGVR}, partials...)
+	}
+	for _, partial := range partials {
+		resources, err := mapper.ResourcesFor(partial)
+		if err != nil {
+			if IsNoMatchError(err) {
+				continue
+			}
+			return nil, err
+		}
+		for _, resource := range resources {
+			if !containsResource(candidates, resource) {
+				candidates = append(candidates, resource)
+			}
+		}
+	}
+	return candidates, nil
+}
+
+// ResolveResource returns the single resource input refers to. If mapper implements
+// ResourceResolver it is used, otherwise input is parsed with schema.ParseResourceArg and resolved
+// with the mapper's ResourceFor, preferring the fully specified interpretation of the input.
+func ResolveResource(mapper RESTMapper, input string) (schema.GroupVersionResource, error) {
+	if resolver, ok := mapper.(ResourceResolver); ok {
+		return resolver.ResolveResource(input)
+	}
+
+	fullySpecifiedGVR, groupResource := schema.ParseResourceArg(strings.ToLower(input))
+	if fullySpecifiedGVR != nil {
+		if gvr, err := mapper.ResourceFor(*fullySpecifiedGVR); err == nil {
+			return gvr, nil
+		}
+	}
+	return mapper.ResourceFor(groupResource.WithVersion(""))
+}
+
+// resourceCandidate is a resource matching the input of a resolution.
+type resourceCandidate struct {
+	resource schema.GroupVersionResource
+	// exact is true if the input named the resource, and its group if any, in full. Candidates
+	// that were found through short names or partial group names are not exact.
+	exact bool
+}
+
+// ResourceCandidates implements ResourceResolver. Matches are ordered like the priorities of the
+// mapper: the legacy core group first, followed by the other groups in discovery order, and the
+// preferred version of a group before its other versions. Unless input specifies a version, only
+// the first matching version of each resource is returned.
+func (m *DiscoveryRESTMapper) ResourceCandidates(input string) ([]schema.GroupVersionResource, error) {
+	var resources []schema.GroupVersionResource
+	for _, candidate := range m.resourceCandidates(input) {
+		resources = append(resources, candidate.resource)
+	}
+	return resources, nil
+}
+
+// ResolveResource implements ResourceResolver. A resource that is named exactly is resolved by
+// priority, like the mapper's ResourceFor does, so "jobs" is resolved even if several groups serve
+// jobs. When input only matches through short names or partial group names, it must match a
+// single resource.
+func (m *DiscoveryRESTMapper) ResolveResource(input string) (schema.GroupVersionResource, error) {
+	candidates := m.resourceCandidates(input)
+	if len(candidates) == 0 {
+		return schema.GroupVersionResource{}, &NoResourceMatchError{
+			PartialResource: partialResource(input),
+			Suggestions:     m.resourceSuggestions(input),
+		}
+	}
+
+	var matches []schema.GroupVersionResource
+	for _, candidate := range candidates {
+		if candidate.exact {
+			return candidate.resource, nil
+		}
+		if !containsGroupResource(matches, candidate.resource.GroupResource()) {
+			matches = append(matches, candidate.resource)
+		}
+	}
+	if len(matches) > 1 {
+		return schema.GroupVersionResource{}, &AmbiguousResourceError{PartialResource: partialResource(input), MatchingResources: matches}
+	}
+	return matches[0], nil
+}
+
+func (m *DiscoveryRESTMapper) resourceCandidates(input string) []resourceCandidate {
+	input = strings.ToLower(strings.TrimSpace(input))
+	if len(input) == 0 {
+		return nil
+	}
+	fullySpecifiedGVR, groupResource := schema.ParseResourceArg(input)
+
+	var candidates []resourceCandidate
+	add := func(candidate resourceCandidate, pinnedVersion bool) {
+		for i, existing := range candidates {
+			if existing.resource.GroupResource() != candidate.resource.GroupResource() {
+				continue
+			}
+			if pinnedVersion && existing.resource.Version != candidate.resource.Version {
+				continue
+			}
+			candidates[i].exact = existing.exact || candidate.exact
+			return
+		}
+		candidates = append(candidates, candidate)
+	}
+
+	for _, group := range m.prioritizedGroups() {
+		for _, version := range prioritizedVersions(group) {
+			for _, resource := range group.VersionedResources[version] {
+				if strings.Contains(resource.Name, "/") {
+					continue
+				}
+				gvr := schema.GroupVersionResource{Group: group.Group.Name, Version: version, Resource: resource.Name}
+
+				if fullySpecifiedGVR != nil && fullySpecifiedGVR.Version == version {
+					if groupExact, ok := matchGroup(group.Group.Name, fullySpecifiedGVR.Group); ok {
+						if nameExact, ok := matchResourceName(resource.Name, resource.SingularName, resource.Kind, resource.ShortNames, fullySpecifiedGVR.Resource); ok {
+							add(resourceCandidate{resource: gvr, exact: groupExact && nameExact}, true)
+						}
+					}
+				}
+				if groupExact, ok := matchGroup(group.Group.Name, groupResource.Group); ok {
+					if nameExact, ok := matchResourceName(resource.Name, resource.SingularName, resource.Kind, resource.ShortNames, groupResource.Resource); ok {
+						add(resourceCandidate{resource: gvr, exact: groupExact && nameExact}, false)
+					}
+				}
+			}
+		}
+	}
+	return candidates
+}
+
+// resourceSuggestions returns the resources with a name or short name that starts with, or is
+// similar to, the resource requested in input, in the groups matching the requested group.
+func (m *DiscoveryRESTMapper) resourceSuggestions(input string) []schema.GroupResource {
+	requested := schema.ParseGroupResource(strings.ToLower(strings.TrimSpace(input)))
+	if len(requested.Resource) == 0 {
+		return nil
+	}
+
+	var suggestions []schema.GroupResource
+	for _, group := range m.prioritizedGroups() {
+		if _, ok := matchGroup(group.Group.Name, requested.Group); !ok {
+			continue
+		}
+		for _, version := range prioritizedVersions(group) {
+			for _, resource := range group.VersionedResources[version] {
+				if strings.Contains(resource.Name, "/") {
+					continue
+				}
+				gr := schema.GroupResource{Group: group.Group.Name, Resource: resource.Name}
+				if containsGroupResourceSuggestion(suggestions, gr) {
+					continue
+				}
+				for _, name := range append([]string{resource.Name, resource.SingularName}, resource.ShortNames...) {
+					if len(name) == 0 {
+						continue
+					}
+					if strings.HasPrefix(name, requested.Resource) || editDistance(name, requested.Resource) <= maxSuggestionDistance {
+						suggestions = append(suggestions, gr)
+						break
+					}
+				}
+			}
+		}
+	}
+	return suggestions
+}
+
+// prioritizedGroups returns the groups of the mapper with the legacy core group first.
+func (m *DiscoveryRESTMapper) prioritizedGroups() []*APIGroupResources {
+	groups := make([]*APIGroupResources, len(m.groupResources))
+	copy(groups, m.groupResources)
+	sort.SliceStable(groups, func(i, j int) bool {
+		return len(groups[i].Group.Name) == 0 && len(groups[j].Group.Name) != 0
+	})
+	return groups
+}
+
+// prioritizedVersions returns the versions of group with resources, the preferred version first.
+func prioritizedVersions(group *APIGroupResources) []string {
+	var versions []string
+	preferred := group.Group.PreferredVersion.Version
+	if _, ok := group.VersionedResources[preferred]; ok {
+		versions = append(versions, preferred)
+	}
+	for _, version := range group.Group.Versions {
+		if _, ok := group.VersionedResources[version.Version]; ok && version.Version != preferred {
+			versions = append(versions, version.Version)
+		}
+	}
+	return versions
+}
+
+// matchGroup returns whether group is matched by the requested group, and whether it is matched
+// exactly. An empty request matches all groups exactly, and a request that is a prefix of the
+// dot separated segments of group matches partially, e.g. "example" matches "example.com".
+func matchGroup(group, requested string) (exact, ok bool) {
+	if len(requested) == 0 || group == requested {
+		return true, true
+	}
+	return false, strings.HasPrefix(group, requested+".")
+}
+
+// matchResourceName returns whether the requested resource name refers to a resource, and
+// whether it does so exactly instead of through a short name.
+func matchResourceName(plural, singular, kind string, shortNames []string, requested string) (exact, ok bool) {
+	if requested == plural || requested == singular || requested == strings.ToLower(kind) {
+		return true, true
+	}
+	if len(singular) == 0 {
+		if _, guessed := UnsafeGuessKindToResource(schema.GroupVersionKind{Kind: kind}); guessed.Resource == requested {
+			return true, true
+		}
+	}
+	for _, shortName := range shortNames {
+		if requested == shortName {
+			return false, true
+		}
+	}
+	return false, false
+}
+
+func partialResource(input string) schema.GroupVersionResource {
+	return schema.ParseGroupResource(strings.ToLower(strings.TrimSpace(input))).WithVersion("")
+}
+
+func containsResource(resources []schema.GroupVersionResource, resource schema.GroupVersionResource) bool {
+	for _, r := range resources {
+		if r == resource {
+			return true
+		}
+	}
+	return false
+}
+
+func containsGroupResource(resources []schema.GroupVersionResource, gr schema.GroupResource) bool {
+	for _, r := range resources {
+		if r.GroupResource() == gr {
+			return true
+		}
+	}
+	return false
+}
+
+func containsGroupResourceSuggestion(suggestions []schema.GroupResource, gr schema.GroupResource) bool {
+	for _, s := range suggestions {
+		if s == gr {
+			return true
+		}
+	}
+	return false
+}
+
+// editDistance returns the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min3(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(b)]
+}
+
+func min3(a, b, c int) int {
+	if b < a {
+		a = b
+	}
+	if c < a {
+		a = c
+	}
+	return a
+}
+
+var _ ResourceResolver = &DiscoveryRESTMapper{}
@@ -0,0 +1,182 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package meta
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func newResolveTestMapper() *DiscoveryRESTMapper {
+	return NewDiscoveryRESTMapper([]*APIGroupResources{
+		{
+			Group: metav1.APIGroup{
+				Name:             "apps",
+				Versions:         []metav1.GroupVersionForDiscovery{{Version: "v1"}, {Version: "v1beta1"}},
+				PreferredVersion: metav1.GroupVersionForDiscovery{Version: "v1"},
+			},
+			VersionedResources: map[string][]metav1.APIResource{
+				"v1":      {{Name: "deployments", SingularName: "deployment", Kind: "Deployment", ShortNames: []string{"deploy"}, Namespaced: true}},
+				"v1beta1": {{Name: "deployments", SingularName: "deployment", Kind: "Deployment", ShortNames: []string{"deploy"}, Namespaced: true}},
+			},
+		},
+		{
+			Group: metav1.APIGroup{
+				Name:             "bar.example.com",
+				Versions:         []metav1.GroupVersionForDiscovery{{Version: "v1"}},
+				PreferredVersion: metav1.GroupVersionForDiscovery{Version: "v1"},
+			},
+			VersionedResources: map[string][]metav1.APIResource{
+				"v1": {
+					{Name: "foo", Kind: "Foo", ShortNames: []string{"cm"}, Namespaced: true},
+					{Name: "jobs", Kind: "Job", Namespaced: true},
+				},
+			},
+		},
+		{
+			Group: metav1.APIGroup{
+				Name:             "bar.other.io",
+				Versions:         []metav1.GroupVersionForDiscovery{{Version: "v1"}},
+				PreferredVersion: metav1.GroupVersionForDiscovery{Version: "v1"},
+			},
+			VersionedResources: map[string][]metav1.APIResource{
+				"v1": {{Name: "foo", Kind: "Foo", Namespaced: true}},
+			},
+		},
+		{
+			Group: metav1.APIGroup{
+				Name:             "",
+				Versions:         []metav1.GroupVersionForDiscovery{{Version: "v1"}},
+				PreferredVersion: metav1.GroupVersionForDiscovery{Version: "v1"},
+			},
+			VersionedResources: map[string][]metav1.APIResource{
+				"v1": {
+					{Name: "configmaps", SingularName: "configmap", Kind: "ConfigMap", ShortNames: []string{"cm"}, Namespaced: true},
+					{Name: "jobs", Kind: "Job", Namespaced: true},
+				},
+			},
+		},
+	})
+}
+
+func TestDiscoveryRESTMapperResolveResource(t *testing.T) {
+	mapper := newResolveTestMapper()
+
+	testCases := []struct {
+		input       string
+		want        schema.GroupVersionResource
+		ambiguous   bool
+		noMatch     bool
+		suggestions []schema.GroupResource
+	}{
+		{input: "deployments", want: schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}},
+		{input: "Deployment", want: schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}},
+		{input: "deploy", want: schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}},
+		{input: "deploy.v1beta1.apps", want: schema.GroupVersionResource{Group: "apps", Version: "v1beta1", Resource: "deployments"}},
+		{input: "configmap", want: schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}},
+		{input: "foo.bar.example.com", want: schema.GroupVersionResource{Group: "bar.example.com", Version: "v1", Resource: "foo"}},
+		{input: "foo.bar.example", want: schema.GroupVersionResource{Group: "bar.example.com", Version: "v1", Resource: "foo"}},
+		{input: "cm.bar", want: schema.GroupVersionResource{Group: "bar.example.com", Version: "v1", Resource: "foo"}},
+		// exact names are resolved by priority, which prefers the legacy core group
+		{input: "jobs", want: schema.GroupVersionResource{Version: "v1", Resource: "jobs"}},
+		{input: "jobs.bar", want: schema.GroupVersionResource{Group: "bar.example.com", Version: "v1", Resource: "jobs"}},
+		{input: "foo", want: schema.GroupVersionResource{Group: "bar.example.com", Version: "v1", Resource: "foo"}},
+		{input: "cm", ambiguous: true},
+		{input: "foo.bar", ambiguous: true},
+		{input: "deploymnts", noMatch: true, suggestions: []schema.GroupResource{{Group: "apps", Resource: "deployments"}}},
+		{input: "config", noMatch: true, suggestions: []schema.GroupResource{{Resource: "configmaps"}}},
+		{input: "pods.apps", noMatch: true},
+		{input: "", noMatch: true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.input, func(t *testing.T) {
+			got, err := mapper.ResolveResource(tc.input)
+			switch {
+			case tc.ambiguous:
+				if !IsAmbiguousError(err) {
+					t.Fatalf("expected ambiguity error, got %v, %v", got, err)
+				}
+			case tc.noMatch:
+				if !IsNoMatchError(err) {
+					t.Fatalf("expected no match error, got %v, %v", got, err)
+				}
+				if e, a := tc.suggestions, err.(*NoResourceMatchError).Suggestions; !reflect.DeepEqual(e, a) {
+					t.Errorf("expected suggestions %v, got %v", e, a)
+				}
+			case err != nil:
+				t.Fatal(err)
+			case got != tc.want:
+				t.Errorf("expected %v, got %v", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestDiscoveryRESTMapperResourceCandidates(t *testing.T) {
+	mapper := newResolveTestMapper()
+
+	candidates, err := mapper.ResourceCandidates("cm")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []schema.GroupVersionResource{
+		{Version: "v1", Resource: "configmaps"},
+		{Group: "bar.example.com", Version: "v1", Resource: "foo"},
+	}
+	if !reflect.DeepEqual(expected, candidates) {
+		t.Errorf("expected %v, got %v", expected, candidates)
+	}
+
+	_, err = mapper.ResolveResource("cm")
+	if err == nil || !strings.Contains(err.Error(), "configmaps") || !strings.Contains(err.Error(), "bar.example.com") {
+		t.Errorf("expected the ambiguity error to list the candidates, got %v", err)
+	}
+}
+
+func TestResolveResourceFallback(t *testing.T) {
+	gv := schema.GroupVersion{Group: "apps", Version: "v1"}
+	defaultMapper := NewDefaultRESTMapper([]schema.GroupVersion{gv})
+	defaultMapper.Add(gv.WithKind("Deployment"), RESTScopeNamespace)
+
+	for _, mapper := range []RESTMapper{defaultMapper, NewLazyRESTMapperLoader(func() (RESTMapper, error) { return defaultMapper, nil })} {
+		for _, input := range []string{"deployments", "deployment.apps", "deployments.v1.apps"} {
+			got, err := ResolveResource(mapper, input)
+			if err != nil {
+				t.Fatalf("%s: %v", input, err)
+			}
+			if e := gv.WithResource("deployments"); got != e {
+				t.Errorf("%s: expected %v, got %v", input, e, got)
+			}
+		}
+		candidates, err := ResourceCandidates(mapper, "deployments.v1.apps")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if e := []schema.GroupVersionResource{gv.WithResource("deployments")}; !reflect.DeepEqual(e, candidates) {
+			t.Errorf("expected %v, got %v", e, candidates)
+		}
+	}
+
+	lazy := NewLazyRESTMapperLoader(func() (RESTMapper, error) { return newResolveTestMapper(), nil })
+	if got, err := ResolveResource(lazy, "deploy"); err != nil || got.Resource != "deployments" {
+		t.Errorf("expected the lazy mapper to delegate to the discovery mapper, got %v, %v", got, err)
+	}
+}
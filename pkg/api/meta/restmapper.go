@@ -62,6 +62,9 @@ type DefaultRESTMapper struct {
 	kindToScope          map[schema.GroupVersionKind]RESTScope
 	singularToPlural     map[schema.GroupVersionResource]schema.GroupVersionResource
 	pluralToSingular     map[schema.GroupVersionResource]schema.GroupVersionResource
+
+	// plurals is consulted to guess the resources of kinds passed to Add, DefaultPluralRegistry if nil.
+	plurals *PluralRegistry
 }
 
 func (m *DefaultRESTMapper) String() string {
@@ -96,8 +99,20 @@ func NewDefaultRESTMapper(defaultGroupVersions []schema.GroupVersion) *DefaultRE
 	}
 }
 
+// NewDefaultRESTMapperWithPlurals is like NewDefaultRESTMapper, but guesses the resources of
+// kinds passed to Add with the irregular plurals of plurals rather than of DefaultPluralRegistry.
+func NewDefaultRESTMapperWithPlurals(defaultGroupVersions []schema.GroupVersion, plurals *PluralRegistry) *DefaultRESTMapper {
+	m := NewDefaultRESTMapper(defaultGroupVersions)
+	m.plurals = plurals
+	return m
+}
+
 func (m *DefaultRESTMapper) Add(kind schema.GroupVersionKind, scope RESTScope) {
-	plural, singular := UnsafeGuessKindToResource(kind)
+	plurals := m.plurals
+	if plurals == nil {
+		plurals = DefaultPluralRegistry
+	}
+	plural, singular := GuessKindToResourceWithPlurals(kind, plurals)
 	m.AddSpecific(kind, plural, singular, scope)
 }
 
@@ -123,7 +138,14 @@ var unpluralizedSuffixes = []string{
 // UnsafeGuessKindToResource converts Kind to a resource name.
 // Broken. This method only "sort of" works when used outside of this package.  It assumes that Kinds and Resources match
 // and they aren't guaranteed to do so.
+// Irregular plurals registered in DefaultPluralRegistry are respected.
 func UnsafeGuessKindToResource(kind schema.GroupVersionKind) ( /*plural*/ schema.GroupVersionResource /*singular*/, schema.GroupVersionResource) {
+	return GuessKindToResourceWithPlurals(kind, DefaultPluralRegistry)
+}
+
+// GuessKindToResourceWithPlurals is like UnsafeGuessKindToResource, but respects the irregular
+// plurals of plurals instead of DefaultPluralRegistry. plurals may be nil.
+func GuessKindToResourceWithPlurals(kind schema.GroupVersionKind, plurals *PluralRegistry) ( /*plural*/ schema.GroupVersionResource /*singular*/, schema.GroupVersionResource) {
 	kindName := kind.Kind
 	if len(kindName) == 0 {
 		return schema.GroupVersionResource{}, schema.GroupVersionResource{}
@@ -131,6 +153,10 @@ func UnsafeGuessKindToResource(kind schema.GroupVersionKind) ( /*plural*/ schema
 	singularName := strings.ToLower(kindName)
 	singular := kind.GroupVersion().WithResource(singularName)
 
+	if pluralName, ok := plurals.Plural(singularName); ok {
+		return kind.GroupVersion().WithResource(pluralName), singular
+	}
+
 	for _, skip := range unpluralizedSuffixes {
 		if strings.HasSuffix(singularName, skip) {
 			return singular, singular
@@ -141,7 +167,10 @@ func UnsafeGuessKindToResource(kind schema.GroupVersionKind) ( /*plural*/ schema
 	case "s":
 		return kind.GroupVersion().WithResource(singularName + "es"), singular
 	case "y":
-		return kind.GroupVersion().WithResource(strings.TrimSuffix(singularName, "y") + "ies"), singular
+		// a vowel before the y is kept, e.g. gateways.
+		if len(singularName) < 2 || !strings.ContainsAny(singularName[len(singularName)-2:len(singularName)-1], "aeiou") {
+			return kind.GroupVersion().WithResource(strings.TrimSuffix(singularName, "y") + "ies"), singular
+		}
 	}
 
 	return kind.GroupVersion().WithResource(singularName + "s"), singular
@@ -448,6 +448,8 @@ func TestKindToResource(t *testing.T) {
 
 		// Add "ies" when ending with "y"
 		{Kind: "ImageRepository", Plural: "imagerepositories", Singular: "imagerepository"},
+		// Add "s" when ending with a vowel followed by "y"
+		{Kind: "Gateway", Plural: "gateways", Singular: "gateway"},
 		// Add "es" when ending with "s"
 		{Kind: "miss", Plural: "misses", Singular: "miss"},
 		// Add "s" otherwise
@@ -463,6 +465,61 @@ func TestKindToResource(t *testing.T) {
 	}
 }
 
+func TestKindToResourceWithPlurals(t *testing.T) {
+	plurals := NewPluralRegistry()
+	plurals.Register("Index", "indices")
+	plurals.Register("matrix", "matrices")
+	plurals.Register("Sheep", "sheep")
+	plurals.Register("Child", "children")
+
+	testCases := []struct {
+		Kind             string
+		Plural, Singular string
+	}{
+		{Kind: "Index", Plural: "indices", Singular: "index"},
+		{Kind: "SearchIndex", Plural: "searchindices", Singular: "searchindex"},
+		{Kind: "Sheep", Plural: "sheep", Singular: "sheep"},
+		{Kind: "Pod", Plural: "pods", Singular: "pod"},
+		{Kind: "Endpoints", Plural: "endpoints", Singular: "endpoints"},
+	}
+	for _, testCase := range testCases {
+		version := schema.GroupVersion{Group: "example.com", Version: "v1"}
+		plural, singular := GuessKindToResourceWithPlurals(version.WithKind(testCase.Kind), plurals)
+		if singular != version.WithResource(testCase.Singular) || plural != version.WithResource(testCase.Plural) {
+			t.Errorf("%s: unexpected plural and singular: %v %v", testCase.Kind, plural, singular)
+		}
+	}
+
+	for plural, expected := range map[string]string{"searchindices": "searchindex", "children": "child", "sheep": "sheep"} {
+		if singular, ok := plurals.Singular(plural); !ok || singular != expected {
+			t.Errorf("%s: expected singular %q, got %q", plural, expected, singular)
+		}
+	}
+	if _, ok := plurals.Singular("pods"); ok {
+		t.Errorf("expected no singular of a regular plural")
+	}
+
+	mapper := NewDefaultRESTMapperWithPlurals(nil, plurals)
+	gvk := schema.GroupVersionKind{Group: "example.com", Version: "v1", Kind: "Child"}
+	mapper.Add(gvk, RESTScopeNamespace)
+	if mapping, err := mapper.RESTMapping(gvk.GroupKind(), gvk.Version); err != nil || mapping.Resource.Resource != "children" {
+		t.Errorf("expected the mapper to use the registry, got %v %v", mapping, err)
+	}
+	if singular, err := mapper.ResourceSingularizer("children"); err != nil || singular != "child" {
+		t.Errorf("expected the singular child, got %q %v", singular, err)
+	}
+
+	// the default registry only affects mappers without a registry.
+	RegisterIrregularPlural("Octopus", "octopodes")
+	defer delete(DefaultPluralRegistry.plurals, "octopus")
+	if plural, _ := UnsafeGuessKindToResource(gvk.GroupVersion().WithKind("Octopus")); plural.Resource != "octopodes" {
+		t.Errorf("expected the default registry to be consulted, got %v", plural)
+	}
+	if plural, _ := GuessKindToResourceWithPlurals(gvk.GroupVersion().WithKind("Octopus"), plurals); plural.Resource != "octopuses" {
+		t.Errorf("expected the default registry not to be consulted, got %v", plural)
+	}
+}
+
 func TestRESTMapperResourceSingularizer(t *testing.T) {
 	testGroupVersion := schema.GroupVersion{Group: "tgroup", Version: "test"}
 
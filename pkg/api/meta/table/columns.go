@@ -0,0 +1,199 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package table
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// PrinterColumn describes a table column whose cells are extracted from objects with a JSONPath
+// expression, like the additionalPrinterColumns of a CustomResourceDefinition.
+type PrinterColumn struct {
+	// Name is a human readable name for the column.
+	Name string
+	// Type is the OpenAPI type of the column: integer, number, string, boolean or date.
+	Type string
+	// Format is an optional OpenAPI format of the column, shown to clients.
+	Format string
+	// Description is a human readable description of the column.
+	Description string
+	// Priority is an integer defining the relative importance of this column compared to others.
+	// Lower numbers are considered higher priority.
+	Priority int32
+	// JSONPath is a simple JSONPath expression evaluated against each object to produce the cell of
+	// the column, e.g. .spec.replicas or .status.conditions[?(@.type=="Ready")].status.
+	JSONPath string
+}
+
+// PrinterColumns converts objects into table rows with a Name column followed by the provided printer
+// columns, rendering tables consistently with the additionalPrinterColumns of CustomResourceDefinitions.
+type PrinterColumns struct {
+	definitions []metav1.TableColumnDefinition
+	columns     []PrinterColumn
+	paths       []jsonPath
+}
+
+var validColumnTypes = map[string]bool{"integer": true, "number": true, "string": true, "boolean": true, "date": true}
+
+// DefaultPrinterColumns are the columns shown if no printer columns are defined.
+var DefaultPrinterColumns = []PrinterColumn{
+	{Name: "Age", Type: "date", Description: metav1.ObjectMeta{}.SwaggerDoc()["creationTimestamp"], JSONPath: ".metadata.creationTimestamp"},
+}
+
+// NewPrinterColumns returns PrinterColumns for columns, or for DefaultPrinterColumns if columns is
+// empty. It returns an error if a column has an unsupported type or an invalid JSONPath.
+func NewPrinterColumns(columns []PrinterColumn) (*PrinterColumns, error) {
+	if len(columns) == 0 {
+		columns = DefaultPrinterColumns
+	}
+	c := &PrinterColumns{
+		definitions: []metav1.TableColumnDefinition{
+			{Name: "Name", Type: "string", Format: "name", Description: metav1.ObjectMeta{}.SwaggerDoc()["name"]},
+		},
+		columns: columns,
+	}
+	for i, column := range columns {
+		if !validColumnTypes[column.Type] {
+			return nil, fmt.Errorf("column %d %q: unsupported type %q, must be one of integer, number, string, boolean or date", i, column.Name, column.Type)
+		}
+		path, err := parseJSONPath(column.JSONPath)
+		if err != nil {
+			return nil, fmt.Errorf("column %d %q: %v", i, column.Name, err)
+		}
+		c.paths = append(c.paths, path)
+		c.definitions = append(c.definitions, metav1.TableColumnDefinition{
+			Name:        column.Name,
+			Type:        column.Type,
+			Format:      column.Format,
+			Description: column.Description,
+			Priority:    column.Priority,
+		})
+	}
+	return c, nil
+}
+
+// ColumnDefinitions returns the definitions of the columns of a table, starting with the Name column.
+func (c *PrinterColumns) ColumnDefinitions() []metav1.TableColumnDefinition {
+	definitions := make([]metav1.TableColumnDefinition, len(c.definitions))
+	copy(definitions, c.definitions)
+	return definitions
+}
+
+// Cells returns the cells of the columns for content, the unstructured content of an object, without
+// the Name column. A cell is nil if the JSONPath of its column does not match, or matches a value that
+// does not have the type of the column. Only the first value matched by a JSONPath is used.
+func (c *PrinterColumns) Cells(content map[string]interface{}) []interface{} {
+	cells := make([]interface{}, 0, len(c.paths))
+	for i, path := range c.paths {
+		values := path.evaluate(content)
+		if len(values) == 0 {
+			cells = append(cells, nil)
+			continue
+		}
+		cells = append(cells, cellForJSONValue(c.columns[i].Type, values[0]))
+	}
+	return cells
+}
+
+// ConvertToTable converts obj, an unstructured object or list, into a table with a row for each object.
+func (c *PrinterColumns) ConvertToTable(obj runtime.Object) (*metav1.Table, error) {
+	table := &metav1.Table{ColumnDefinitions: c.ColumnDefinitions()}
+	if m, err := meta.ListAccessor(obj); err == nil {
+		table.ResourceVersion = m.GetResourceVersion()
+		table.Continue = m.GetContinue()
+		table.RemainingItemCount = m.GetRemainingItemCount()
+	} else if common, err := meta.CommonAccessor(obj); err == nil {
+		table.ResourceVersion = common.GetResourceVersion()
+	}
+
+	var err error
+	table.Rows, err = MetaToTableRow(obj, func(obj runtime.Object, m metav1.Object, name, age string) ([]interface{}, error) {
+		u, ok := obj.(runtime.Unstructured)
+		if !ok {
+			return nil, fmt.Errorf("expected an unstructured object, got %T", obj)
+		}
+		return append([]interface{}{name}, c.Cells(u.UnstructuredContent())...), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return table, nil
+}
+
+// cellForJSONValue converts value into a cell of a column of headerType, or nil if value does not
+// match the type.
+func cellForJSONValue(headerType string, value interface{}) interface{} {
+	if value == nil {
+		return nil
+	}
+
+	switch headerType {
+	case "integer":
+		switch typed := value.(type) {
+		case int64:
+			return typed
+		case float64:
+			return int64(typed)
+		case json.Number:
+			if i64, err := typed.Int64(); err == nil {
+				return i64
+			}
+		}
+	case "number":
+		switch typed := value.(type) {
+		case int64:
+			return float64(typed)
+		case float64:
+			return typed
+		case json.Number:
+			if f, err := typed.Float64(); err == nil {
+				return f
+			}
+		}
+	case "boolean":
+		if b, ok := value.(bool); ok {
+			return b
+		}
+	case "string":
+		switch typed := value.(type) {
+		case string:
+			return typed
+		case map[string]interface{}, []interface{}:
+			if data, err := json.Marshal(typed); err == nil {
+				return string(data)
+			}
+		default:
+			return fmt.Sprint(typed)
+		}
+	case "date":
+		if typed, ok := value.(string); ok {
+			var timestamp metav1.Time
+			err := timestamp.UnmarshalQueryParameter(typed)
+			if err != nil {
+				return "<invalid>"
+			}
+			return ConvertToHumanReadableDateType(timestamp)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,152 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package table
+
+import (
+	"reflect"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestPrinterColumnsCells(t *testing.T) {
+	content := map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"name":        "foo",
+			"annotations": map[string]interface{}{"example.com/owner": "team-a"},
+		},
+		"spec": map[string]interface{}{
+			"replicas": int64(3),
+			"ratio":    0.5,
+			"paused":   false,
+			"containers": []interface{}{
+				map[string]interface{}{"name": "app", "image": "app:v1"},
+				map[string]interface{}{"name": "sidecar", "image": "proxy:v2"},
+			},
+			"selector": map[string]interface{}{"app": "foo"},
+		},
+		"status": map[string]interface{}{
+			"conditions": []interface{}{
+				map[string]interface{}{"type": "Synced", "status": "True"},
+				map[string]interface{}{"type": "Ready", "status": "False", "observedGeneration": int64(2)},
+			},
+			"lastUpdate": "invalid",
+		},
+	}
+
+	testCases := []struct {
+		column   PrinterColumn
+		expected interface{}
+	}{
+		{column: PrinterColumn{Type: "integer", JSONPath: ".spec.replicas"}, expected: int64(3)},
+		{column: PrinterColumn{Type: "number", JSONPath: ".spec.replicas"}, expected: float64(3)},
+		{column: PrinterColumn{Type: "integer", JSONPath: ".spec.ratio"}, expected: int64(0)},
+		{column: PrinterColumn{Type: "boolean", JSONPath: "{.spec.paused}"}, expected: false},
+		{column: PrinterColumn{Type: "string", JSONPath: ".spec.replicas"}, expected: "3"},
+		{column: PrinterColumn{Type: "string", JSONPath: ".spec.selector"}, expected: `{"app":"foo"}`},
+		{column: PrinterColumn{Type: "string", JSONPath: ".spec.containers[1].image"}, expected: "proxy:v2"},
+		{column: PrinterColumn{Type: "string", JSONPath: ".spec.containers[-1].name"}, expected: "sidecar"},
+		{column: PrinterColumn{Type: "string", JSONPath: ".spec.containers[*].name"}, expected: "app"},
+		{column: PrinterColumn{Type: "string", JSONPath: `.spec.containers[?(@.name=="sidecar")].image`}, expected: "proxy:v2"},
+		{column: PrinterColumn{Type: "string", JSONPath: `.spec.containers[?(@.name != 'app')].image`}, expected: "proxy:v2"},
+		{column: PrinterColumn{Type: "string", JSONPath: `.status.conditions[?(@.type=="Ready")].status`}, expected: "False"},
+		{column: PrinterColumn{Type: "string", JSONPath: `.status.conditions[?(@.observedGeneration==2)].type`}, expected: "Ready"},
+		{column: PrinterColumn{Type: "string", JSONPath: `.status.conditions[?(@.observedGeneration)].type`}, expected: "Ready"},
+		{column: PrinterColumn{Type: "string", JSONPath: `.metadata.annotations['example.com/owner']`}, expected: "team-a"},
+		{column: PrinterColumn{Type: "string", JSONPath: `$.metadata["name"]`}, expected: "foo"},
+		{column: PrinterColumn{Type: "date", JSONPath: ".status.lastUpdate"}, expected: "<invalid>"},
+		{column: PrinterColumn{Type: "boolean", JSONPath: ".spec.replicas"}, expected: nil},
+		{column: PrinterColumn{Type: "string", JSONPath: ".spec.missing"}, expected: nil},
+		{column: PrinterColumn{Type: "string", JSONPath: ".spec.containers[5].name"}, expected: nil},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.column.JSONPath, func(t *testing.T) {
+			tc.column.Name = "Column"
+			columns, err := NewPrinterColumns([]PrinterColumn{tc.column})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if cells := columns.Cells(content); !reflect.DeepEqual([]interface{}{tc.expected}, cells) {
+				t.Errorf("expected %#v, got %#v", tc.expected, cells)
+			}
+		})
+	}
+}
+
+func TestNewPrinterColumnsErrors(t *testing.T) {
+	for _, column := range []PrinterColumn{
+		{Name: "a", Type: "object", JSONPath: ".spec"},
+		{Name: "b", Type: "string", JSONPath: ""},
+		{Name: "c", Type: "string", JSONPath: "spec"},
+		{Name: "d", Type: "string", JSONPath: ".spec..replicas"},
+		{Name: "e", Type: "string", JSONPath: ".spec[foo]"},
+		{Name: "f", Type: "string", JSONPath: ".spec['foo"},
+		{Name: "g", Type: "string", JSONPath: `.items[?(@.a=="b"]`},
+	} {
+		if _, err := NewPrinterColumns([]PrinterColumn{column}); err == nil {
+			t.Errorf("%s: expected an error for %#v", column.Name, column)
+		}
+	}
+}
+
+func TestPrinterColumnsConvertToTable(t *testing.T) {
+	columns, err := NewPrinterColumns([]PrinterColumn{
+		{Name: "Replicas", Type: "integer", Priority: 1, Description: "desired replicas", JSONPath: ".spec.replicas"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	definitions := columns.ColumnDefinitions()
+	if len(definitions) != 2 || definitions[0].Name != "Name" || definitions[0].Format != "name" || definitions[1].Name != "Replicas" || definitions[1].Priority != 1 {
+		t.Errorf("unexpected column definitions %#v", definitions)
+	}
+
+	list := &unstructured.UnstructuredList{Items: []unstructured.Unstructured{
+		{Object: map[string]interface{}{"metadata": map[string]interface{}{"name": "a"}, "spec": map[string]interface{}{"replicas": int64(1)}}},
+		{Object: map[string]interface{}{"metadata": map[string]interface{}{"name": "b"}}},
+	}}
+	list.SetResourceVersion("10")
+	list.SetContinue("next")
+	table, err := columns.ConvertToTable(list)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if table.ResourceVersion != "10" || table.Continue != "next" {
+		t.Errorf("expected list metadata to be copied, got %#v", table.ListMeta)
+	}
+	if len(table.Rows) != 2 {
+		t.Fatalf("expected 2 rows, got %d", len(table.Rows))
+	}
+	if e, a := []interface{}{"a", int64(1)}, table.Rows[0].Cells; !reflect.DeepEqual(e, a) {
+		t.Errorf("expected %#v, got %#v", e, a)
+	}
+	if e, a := []interface{}{"b", nil}, table.Rows[1].Cells; !reflect.DeepEqual(e, a) {
+		t.Errorf("expected %#v, got %#v", e, a)
+	}
+
+	defaults, err := NewPrinterColumns(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	table, err = defaults.ConvertToTable(&list.Items[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e, a := []interface{}{"a", nil}, table.Rows[0].Cells; !reflect.DeepEqual(e, a) {
+		t.Errorf("expected the default Age column, got %#v", a)
+	}
+}
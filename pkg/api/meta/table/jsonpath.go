@@ -0,0 +1,288 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package table
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// jsonPath is a parsed JSONPath expression of the simple form used by printer columns. It supports
+// field access (.name or ['name']), list indexes ([0], [-1]), wildcards ([*]) and filters comparing a
+// field of list items to a literal ([?(@.type=="Ready")], [?(@.ready)]).
+type jsonPath []jsonPathStep
+
+type jsonPathStep struct {
+	field    *string
+	index    *int
+	wildcard bool
+	filter   *jsonPathFilter
+}
+
+type jsonPathFilter struct {
+	path  jsonPath
+	op    string
+	value interface{}
+}
+
+// parseJSONPath parses expression, optionally wrapped in braces and prefixed with $ as in
+// client-go's JSONPath templates.
+func parseJSONPath(expression string) (jsonPath, error) {
+	s := strings.TrimSpace(expression)
+	if strings.HasPrefix(s, "{") && strings.HasSuffix(s, "}") {
+		s = strings.TrimSpace(s[1 : len(s)-1])
+	}
+	s = strings.TrimPrefix(s, "$")
+	if len(s) == 0 {
+		return nil, fmt.Errorf("empty JSONPath %q", expression)
+	}
+	path, rest, err := parseJSONPathSteps(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid JSONPath %q: %v", expression, err)
+	}
+	if len(rest) > 0 {
+		return nil, fmt.Errorf("invalid JSONPath %q: unexpected %q", expression, rest)
+	}
+	return path, nil
+}
+
+// parseJSONPathSteps parses steps from s until it ends or reaches a character that can't start a step.
+func parseJSONPathSteps(s string) (jsonPath, string, error) {
+	var path jsonPath
+	for len(s) > 0 {
+		switch s[0] {
+		case '.':
+			end := strings.IndexAny(s[1:], ".[ =!)")
+			if end < 0 {
+				end = len(s) - 1
+			}
+			name := s[1 : end+1]
+			if len(name) == 0 {
+				return nil, "", fmt.Errorf("empty field name")
+			}
+			path = append(path, jsonPathStep{field: &name})
+			s = s[end+1:]
+		case '[':
+			step, rest, err := parseJSONPathBracket(s[1:])
+			if err != nil {
+				return nil, "", err
+			}
+			path = append(path, step)
+			s = rest
+		default:
+			return path, s, nil
+		}
+	}
+	return path, s, nil
+}
+
+// parseJSONPathBracket parses the content of a bracket step and its closing bracket.
+func parseJSONPathBracket(s string) (jsonPathStep, string, error) {
+	switch {
+	case strings.HasPrefix(s, "*]"):
+		return jsonPathStep{wildcard: true}, s[2:], nil
+	case strings.HasPrefix(s, "'") || strings.HasPrefix(s, `"`):
+		value, rest, err := parseJSONPathString(s)
+		if err != nil {
+			return jsonPathStep{}, "", err
+		}
+		if !strings.HasPrefix(rest, "]") {
+			return jsonPathStep{}, "", fmt.Errorf("expected ] after %q", value)
+		}
+		return jsonPathStep{field: &value}, rest[1:], nil
+	case strings.HasPrefix(s, "?(@"):
+		filter, rest, err := parseJSONPathFilter(s[3:])
+		if err != nil {
+			return jsonPathStep{}, "", err
+		}
+		return jsonPathStep{filter: filter}, rest, nil
+	default:
+		end := strings.Index(s, "]")
+		if end < 0 {
+			return jsonPathStep{}, "", fmt.Errorf("unterminated [")
+		}
+		index, err := strconv.Atoi(strings.TrimSpace(s[:end]))
+		if err != nil {
+			return jsonPathStep{}, "", fmt.Errorf("unsupported index %q", s[:end])
+		}
+		return jsonPathStep{index: &index}, s[end+1:], nil
+	}
+}
+
+// parseJSONPathFilter parses a filter following "?(@" up to and including the closing ")]".
+func parseJSONPathFilter(s string) (*jsonPathFilter, string, error) {
+	path, rest, err := parseJSONPathSteps(s)
+	if err != nil {
+		return nil, "", err
+	}
+	filter := &jsonPathFilter{path: path}
+	rest = strings.TrimSpace(rest)
+	if strings.HasPrefix(rest, "==") || strings.HasPrefix(rest, "!=") {
+		filter.op = rest[:2]
+		literal, after, err := parseJSONPathLiteral(strings.TrimSpace(rest[2:]))
+		if err != nil {
+			return nil, "", err
+		}
+		filter.value = literal
+		rest = strings.TrimSpace(after)
+	}
+	if !strings.HasPrefix(rest, ")]") {
+		return nil, "", fmt.Errorf("expected )] to end filter, got %q", rest)
+	}
+	return filter, rest[2:], nil
+}
+
+func parseJSONPathLiteral(s string) (interface{}, string, error) {
+	if strings.HasPrefix(s, "'") || strings.HasPrefix(s, `"`) {
+		return parseJSONPathString(s)
+	}
+	end := strings.IndexAny(s, " )")
+	if end < 0 {
+		end = len(s)
+	}
+	token := s[:end]
+	switch token {
+	case "true":
+		return true, s[end:], nil
+	case "false":
+		return false, s[end:], nil
+	case "null":
+		return nil, s[end:], nil
+	}
+	if _, err := strconv.ParseFloat(token, 64); err != nil {
+		return nil, "", fmt.Errorf("unsupported literal %q", token)
+	}
+	return json.Number(token), s[end:], nil
+}
+
+// parseJSONPathString parses a single or double quoted string with backslash escapes.
+func parseJSONPathString(s string) (string, string, error) {
+	quote := s[0]
+	var b strings.Builder
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			if i+1 < len(s) {
+				i++
+				b.WriteByte(s[i])
+			}
+		case quote:
+			return b.String(), s[i+1:], nil
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	return "", "", fmt.Errorf("unterminated string %s", s)
+}
+
+// evaluate returns the values matched by the path in data, an unstructured object.
+func (p jsonPath) evaluate(data interface{}) []interface{} {
+	values := []interface{}{data}
+	for _, step := range p {
+		var next []interface{}
+		for _, value := range values {
+			next = append(next, step.evaluate(value)...)
+		}
+		values = next
+	}
+	return values
+}
+
+func (s jsonPathStep) evaluate(value interface{}) []interface{} {
+	switch {
+	case s.field != nil:
+		if m, ok := value.(map[string]interface{}); ok {
+			if v, ok := m[*s.field]; ok {
+				return []interface{}{v}
+			}
+		}
+	case s.index != nil:
+		if l, ok := value.([]interface{}); ok {
+			i := *s.index
+			if i < 0 {
+				i += len(l)
+			}
+			if i >= 0 && i < len(l) {
+				return []interface{}{l[i]}
+			}
+		}
+	case s.wildcard:
+		switch typed := value.(type) {
+		case []interface{}:
+			return typed
+		case map[string]interface{}:
+			keys := make([]string, 0, len(typed))
+			for k := range typed {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			values := make([]interface{}, 0, len(keys))
+			for _, k := range keys {
+				values = append(values, typed[k])
+			}
+			return values
+		}
+	case s.filter != nil:
+		var matches []interface{}
+		if l, ok := value.([]interface{}); ok {
+			for _, item := range l {
+				if s.filter.matches(item) {
+					matches = append(matches, item)
+				}
+			}
+		}
+		return matches
+	}
+	return nil
+}
+
+func (f *jsonPathFilter) matches(item interface{}) bool {
+	values := f.path.evaluate(item)
+	if len(f.op) == 0 {
+		return len(values) > 0
+	}
+	equal := len(values) > 0 && jsonValuesEqual(values[0], f.value)
+	if f.op == "==" {
+		return equal
+	}
+	return !equal
+}
+
+// jsonValuesEqual compares an unstructured value to a literal, comparing numbers by value.
+func jsonValuesEqual(value, literal interface{}) bool {
+	if number, ok := literal.(json.Number); ok {
+		want, err := number.Float64()
+		if err != nil {
+			return false
+		}
+		switch typed := value.(type) {
+		case int64:
+			return float64(typed) == want
+		case float64:
+			return typed == want
+		case json.Number:
+			got, err := typed.Float64()
+			return err == nil && got == want
+		}
+		return false
+	}
+	return reflect.DeepEqual(value, literal)
+}
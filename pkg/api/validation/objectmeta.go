@@ -43,27 +43,73 @@ var BannedOwners = map[schema.GroupVersionKind]struct{}{
 // ValidateClusterName can be used to check whether the given cluster name is valid.
 var ValidateClusterName = NameIsDNS1035Label
 
+// ObjectMetaValidationOptions configures the validation of object metadata by
+// ValidateObjectMetaWithOptions and related functions. The zero value validates
+// cluster-scoped objects with DNS subdomain names.
+type ObjectMetaValidationOptions struct {
+	// RequiresNamespace requires objects to be namespaced. If false, objects must not have a namespace.
+	RequiresNamespace bool
+	// NameFn validates the name and generateName of objects. Defaults to NameIsDNSSubdomain.
+	NameFn ValidateNameFunc
+	// NamespaceFn validates the namespace of objects. Defaults to ValidateNamespaceName.
+	NamespaceFn ValidateNameFunc
+	// ForbidGenerateName rejects objects that set generateName.
+	ForbidGenerateName bool
+	// MaxAnnotationsSizeBytes overrides TotalAnnotationSizeLimitB as the limit of the total size
+	// of the keys and values of annotations, if positive.
+	MaxAnnotationsSizeBytes int
+}
+
+func (o ObjectMetaValidationOptions) nameFn() ValidateNameFunc {
+	if o.NameFn != nil {
+		return o.NameFn
+	}
+	return NameIsDNSSubdomain
+}
+
+func (o ObjectMetaValidationOptions) namespaceFn() ValidateNameFunc {
+	if o.NamespaceFn != nil {
+		return o.NamespaceFn
+	}
+	return ValidateNamespaceName
+}
+
+func (o ObjectMetaValidationOptions) annotationsSizeLimit() int {
+	if o.MaxAnnotationsSizeBytes > 0 {
+		return o.MaxAnnotationsSizeBytes
+	}
+	return TotalAnnotationSizeLimitB
+}
+
 // ValidateAnnotations validates that a set of annotations are correctly defined.
 func ValidateAnnotations(annotations map[string]string, fldPath *field.Path) field.ErrorList {
+	return validateAnnotations(annotations, TotalAnnotationSizeLimitB, fldPath)
+}
+
+func validateAnnotations(annotations map[string]string, sizeLimit int, fldPath *field.Path) field.ErrorList {
 	allErrs := field.ErrorList{}
 	for k := range annotations {
 		for _, msg := range validation.IsQualifiedName(strings.ToLower(k)) {
 			allErrs = append(allErrs, field.Invalid(fldPath, k, msg))
 		}
 	}
-	if err := ValidateAnnotationsSize(annotations); err != nil {
-		allErrs = append(allErrs, field.TooLong(fldPath, "", TotalAnnotationSizeLimitB))
+	if err := validateAnnotationsSize(annotations, sizeLimit); err != nil {
+		allErrs = append(allErrs, field.TooLong(fldPath, "", sizeLimit))
 	}
 	return allErrs
 }
 
 func ValidateAnnotationsSize(annotations map[string]string) error {
+	return validateAnnotationsSize(annotations, TotalAnnotationSizeLimitB)
+}
+
+func validateAnnotationsSize(annotations map[string]string, sizeLimit int) error {
 	var totalSize int64
 	for k, v := range annotations {
 		totalSize += (int64)(len(k)) + (int64)(len(v))
 	}
-	if totalSize > (int64)(TotalAnnotationSizeLimitB) {
-		return fmt.Errorf("annotations size %d is larger than limit %d", totalSize, TotalAnnotationSizeLimitB)
+	if totalSize > (int64)(sizeLimit) {
+		return fmt.Errorf("annotations size %d is larger than limit %d", totalSize, sizeLimit)
 	}
 	return nil
 }
@@ -141,41 +187,62 @@ func ValidateImmutableField(newVal, oldVal interface{}, fldPath *field.Path) fie
 // been performed.
 // It doesn't return an error for rootscoped resources with namespace, because namespace should already be cleared before.
 func ValidateObjectMeta(objMeta *metav1.ObjectMeta, requiresNamespace bool, nameFn ValidateNameFunc, fldPath *field.Path) field.ErrorList {
+	return ValidateObjectMetaWithOptions(objMeta, ObjectMetaValidationOptions{RequiresNamespace: requiresNamespace, NameFn: nameFn}, fldPath)
+}
+
+// ValidateObjectMetaWithOptions validates an object's metadata on creation as configured by opts. It expects
+// that name generation has already been performed.
+func ValidateObjectMetaWithOptions(objMeta *metav1.ObjectMeta, opts ObjectMetaValidationOptions, fldPath *field.Path) field.ErrorList {
 	metadata, err := meta.Accessor(objMeta)
 	if err != nil {
 		var allErrs field.ErrorList
 		allErrs = append(allErrs, field.Invalid(fldPath, objMeta, err.Error()))
 		return allErrs
 	}
-	return ValidateObjectMetaAccessor(metadata, requiresNamespace, nameFn, fldPath)
+	return ValidateObjectMetaAccessorWithOptions(metadata, opts, fldPath)
 }
 
 // ValidateObjectMetaAccessor validates an object's metadata on creation. It expects that name generation has already
 // been performed.
 // It doesn't return an error for rootscoped resources with namespace, because namespace should already be cleared before.
 func ValidateObjectMetaAccessor(meta metav1.Object, requiresNamespace bool, nameFn ValidateNameFunc, fldPath *field.Path) field.ErrorList {
+	return ValidateObjectMetaAccessorWithOptions(meta, ObjectMetaValidationOptions{RequiresNamespace: requiresNamespace, NameFn: nameFn}, fldPath)
+}
+
+// ValidateObjectMetaAccessorWithOptions validates an object's metadata on creation as configured by opts. It
+// expects that name generation has already been performed.
+func ValidateObjectMetaAccessorWithOptions(meta metav1.Object, opts ObjectMetaValidationOptions, fldPath *field.Path) field.ErrorList {
 	var allErrs field.ErrorList
 
+	nameFn := opts.nameFn()
 	if len(meta.GetGenerateName()) != 0 {
-		for _, msg := range nameFn(meta.GetGenerateName(), true) {
-			allErrs = append(allErrs, field.Invalid(fldPath.Child("generateName"), meta.GetGenerateName(), msg))
+		if opts.ForbidGenerateName {
+			allErrs = append(allErrs, field.Forbidden(fldPath.Child("generateName"), "not allowed on this type"))
+		} else {
+			for _, msg := range nameFn(meta.GetGenerateName(), true) {
+				allErrs = append(allErrs, field.Invalid(fldPath.Child("generateName"), meta.GetGenerateName(), msg))
+			}
 		}
 	}
 	// If the generated name validates, but the calculated value does not, it's a problem with generation, and we
 	// report it here. This may confuse users, but indicates a programming bug and still must be validated.
 	// If there are multiple fields out of which one is required then add an or as a separator
 	if len(meta.GetName()) == 0 {
-		allErrs = append(allErrs, field.Required(fldPath.Child("name"), "name or generateName is required"))
+		if opts.ForbidGenerateName {
+			allErrs = append(allErrs, field.Required(fldPath.Child("name"), ""))
+		} else {
+			allErrs = append(allErrs, field.Required(fldPath.Child("name"), "name or generateName is required"))
+		}
 	} else {
 		for _, msg := range nameFn(meta.GetName(), false) {
 			allErrs = append(allErrs, field.Invalid(fldPath.Child("name"), meta.GetName(), msg))
 		}
 	}
-	if requiresNamespace {
+	if opts.RequiresNamespace {
 		if len(meta.GetNamespace()) == 0 {
 			allErrs = append(allErrs, field.Required(fldPath.Child("namespace"), ""))
 		} else {
-			for _, msg := range ValidateNamespaceName(meta.GetNamespace(), false) {
+			for _, msg := range opts.namespaceFn()(meta.GetNamespace(), false) {
 				allErrs = append(allErrs, field.Invalid(fldPath.Child("namespace"), meta.GetNamespace(), msg))
 			}
 		}
@@ -192,7 +259,7 @@ func ValidateObjectMetaAccessor(meta metav1.Object, requiresNamespace bool, name
 
 	allErrs = append(allErrs, ValidateNonnegativeField(meta.GetGeneration(), fldPath.Child("generation"))...)
 	allErrs = append(allErrs, v1validation.ValidateLabels(meta.GetLabels(), fldPath.Child("labels"))...)
-	allErrs = append(allErrs, ValidateAnnotations(meta.GetAnnotations(), fldPath.Child("annotations"))...)
+	allErrs = append(allErrs, validateAnnotations(meta.GetAnnotations(), opts.annotationsSizeLimit(), fldPath.Child("annotations"))...)
 	allErrs = append(allErrs, ValidateOwnerReferences(meta.GetOwnerReferences(), fldPath.Child("ownerReferences"))...)
 	allErrs = append(allErrs, ValidateFinalizers(meta.GetFinalizers(), fldPath.Child("finalizers"))...)
 	allErrs = append(allErrs, v1validation.ValidateManagedFields(meta.GetManagedFields(), fldPath.Child("managedFields"))...)
@@ -238,6 +305,12 @@ func ValidateObjectMetaUpdate(newMeta, oldMeta *metav1.ObjectMeta, fldPath *fiel
 
 // ValidateObjectMetaAccessorUpdate validates an object's metadata when updated.
 func ValidateObjectMetaAccessorUpdate(newMeta, oldMeta metav1.Object, fldPath *field.Path) field.ErrorList {
+	return ValidateObjectMetaAccessorUpdateWithOptions(newMeta, oldMeta, ObjectMetaValidationOptions{}, fldPath)
+}
+
+// ValidateObjectMetaAccessorUpdateWithOptions validates an object's metadata when updated as configured by
+// opts. Names and namespaces are immutable, so only the limits of opts apply.
+func ValidateObjectMetaAccessorUpdateWithOptions(newMeta, oldMeta metav1.Object, opts ObjectMetaValidationOptions, fldPath *field.Path) field.ErrorList {
 	var allErrs field.ErrorList
 
 	// Finalizers cannot be added if the object is already being deleted.
@@ -264,9 +337,22 @@ func ValidateObjectMetaAccessorUpdate(newMeta, oldMeta metav1.Object, fldPath *f
 	allErrs = append(allErrs, ValidateImmutableField(newMeta.GetClusterName(), oldMeta.GetClusterName(), fldPath.Child("clusterName"))...)
 
 	allErrs = append(allErrs, v1validation.ValidateLabels(newMeta.GetLabels(), fldPath.Child("labels"))...)
-	allErrs = append(allErrs, ValidateAnnotations(newMeta.GetAnnotations(), fldPath.Child("annotations"))...)
+	allErrs = append(allErrs, validateAnnotations(newMeta.GetAnnotations(), opts.annotationsSizeLimit(), fldPath.Child("annotations"))...)
 	allErrs = append(allErrs, ValidateOwnerReferences(newMeta.GetOwnerReferences(), fldPath.Child("ownerReferences"))...)
 	allErrs = append(allErrs, v1validation.ValidateManagedFields(newMeta.GetManagedFields(), fldPath.Child("managedFields"))...)
 
 	return allErrs
 }
+
+// ValidateListMeta validates the metadata of a list. The remaining item count must not be negative and
+// may only be set for partial lists, which have a continue token.
+func ValidateListMeta(listMeta metav1.ListInterface, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	if count := listMeta.GetRemainingItemCount(); count != nil {
+		if len(listMeta.GetContinue()) == 0 {
+			allErrs = append(allErrs, field.Forbidden(fldPath.Child("remainingItemCount"), "may only be set when continue is set"))
+		}
+		allErrs = append(allErrs, ValidateNonnegativeField(*count, fldPath.Child("remainingItemCount"))...)
+	}
+	return allErrs
+}
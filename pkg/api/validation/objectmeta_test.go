@@ -498,3 +498,104 @@ func TestValidateAnnotations(t *testing.T) {
 		}
 	}
 }
+
+func TestValidateObjectMetaWithOptions(t *testing.T) {
+	upperCaseName := func(name string, prefix bool) []string {
+		if strings.ToUpper(name) != name {
+			return []string{"must be upper case"}
+		}
+		return nil
+	}
+	largeAnnotations := map[string]string{"a": strings.Repeat("b", 100)}
+
+	testCases := []struct {
+		name           string
+		meta           metav1.ObjectMeta
+		opts           ObjectMetaValidationOptions
+		expectedFields []string
+	}{
+		{
+			name: "defaults",
+			meta: metav1.ObjectMeta{Name: "foo", GenerateName: "foo-"},
+		},
+		{
+			name:           "default name validation",
+			meta:           metav1.ObjectMeta{Name: "Foo"},
+			expectedFields: []string{"metadata.name"},
+		},
+		{
+			name: "custom name validation",
+			meta: metav1.ObjectMeta{Name: "FOO", GenerateName: "FOO-"},
+			opts: ObjectMetaValidationOptions{NameFn: upperCaseName},
+		},
+		{
+			name:           "forbidden generateName",
+			meta:           metav1.ObjectMeta{Name: "foo", GenerateName: "foo-"},
+			opts:           ObjectMetaValidationOptions{ForbidGenerateName: true},
+			expectedFields: []string{"metadata.generateName"},
+		},
+		{
+			name:           "custom namespace validation",
+			meta:           metav1.ObjectMeta{Name: "foo", Namespace: "ns"},
+			opts:           ObjectMetaValidationOptions{RequiresNamespace: true, NamespaceFn: upperCaseName},
+			expectedFields: []string{"metadata.namespace"},
+		},
+		{
+			name: "annotations within the default limit",
+			meta: metav1.ObjectMeta{Name: "foo", Annotations: largeAnnotations},
+		},
+		{
+			name:           "annotations above a lower limit",
+			meta:           metav1.ObjectMeta{Name: "foo", Annotations: largeAnnotations},
+			opts:           ObjectMetaValidationOptions{MaxAnnotationsSizeBytes: 100},
+			expectedFields: []string{"metadata.annotations"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			errs := ValidateObjectMetaWithOptions(&tc.meta, tc.opts, field.NewPath("metadata"))
+			var fields []string
+			for _, err := range errs {
+				fields = append(fields, err.Field)
+			}
+			if !reflect.DeepEqual(tc.expectedFields, fields) {
+				t.Errorf("expected errors for %v, got %v", tc.expectedFields, errs)
+			}
+		})
+	}
+
+	old := metav1.ObjectMeta{Name: "foo", ResourceVersion: "1"}
+	errs := ValidateObjectMetaAccessorUpdateWithOptions(&metav1.ObjectMeta{Name: "foo", ResourceVersion: "1", Annotations: largeAnnotations}, &old, ObjectMetaValidationOptions{MaxAnnotationsSizeBytes: 100}, field.NewPath("metadata"))
+	if len(errs) != 1 || errs[0].Field != "metadata.annotations" {
+		t.Errorf("expected an annotations size error on update, got %v", errs)
+	}
+}
+
+func TestValidateListMeta(t *testing.T) {
+	count := int64(3)
+	negative := int64(-1)
+	testCases := []struct {
+		name      string
+		meta      metav1.ListMeta
+		errorType field.ErrorType
+	}{
+		{name: "empty", meta: metav1.ListMeta{}},
+		{name: "partial list", meta: metav1.ListMeta{Continue: "token", RemainingItemCount: &count}},
+		{name: "count without continue", meta: metav1.ListMeta{RemainingItemCount: &count}, errorType: field.ErrorTypeForbidden},
+		{name: "negative count", meta: metav1.ListMeta{Continue: "token", RemainingItemCount: &negative}, errorType: field.ErrorTypeInvalid},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			errs := ValidateListMeta(&tc.meta, field.NewPath("metadata"))
+			if len(tc.errorType) == 0 {
+				if len(errs) != 0 {
+					t.Errorf("unexpected errors %v", errs)
+				}
+				return
+			}
+			if len(errs) != 1 || errs[0].Type != tc.errorType || errs[0].Field != "metadata.remainingItemCount" {
+				t.Errorf("expected a single %s error, got %v", tc.errorType, errs)
+			}
+		})
+	}
+}
@@ -0,0 +1,53 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package structural validates unstructured objects against OpenAPI v3 structural schemas, as
+// used by CustomResourceDefinitions, without depending on the apiextensions validation stack.
+package structural // import "k8s.io/apimachinery/pkg/api/validation/structural"
+
+// Schema is the subset of an OpenAPI v3 structural schema checked by Validate. It can be
+// unmarshaled from the openAPIV3Schema of a CustomResourceDefinition version, ignoring the
+// keywords it does not support.
+type Schema struct {
+	// Type is one of object, array, string, integer, number or boolean. An empty type accepts
+	// any value, which is only valid for x-kubernetes-int-or-string and
+	// x-kubernetes-preserve-unknown-fields schemas.
+	Type string `json:"type,omitempty"`
+	// Format is checked for strings and integers. Unknown formats are ignored.
+	Format string `json:"format,omitempty"`
+	// Enum lists the allowed values, if not empty.
+	Enum []interface{} `json:"enum,omitempty"`
+	// Nullable allows null values.
+	Nullable bool `json:"nullable,omitempty"`
+
+	// Properties are the schemas of the known fields of an object.
+	Properties map[string]Schema `json:"properties,omitempty"`
+	// Required lists the fields an object must have.
+	Required []string `json:"required,omitempty"`
+	// AdditionalProperties is the schema of the values of an object without properties, as
+	// for maps.
+	AdditionalProperties *Schema `json:"additionalProperties,omitempty"`
+	// Items is the schema of the items of an array.
+	Items *Schema `json:"items,omitempty"`
+
+	// XPreserveUnknownFields allows fields of an object not listed in Properties.
+	XPreserveUnknownFields bool `json:"x-kubernetes-preserve-unknown-fields,omitempty"`
+	// XEmbeddedResource marks an object as a resource, which has implicit apiVersion, kind and
+	// metadata fields.
+	XEmbeddedResource bool `json:"x-kubernetes-embedded-resource,omitempty"`
+	// XIntOrString allows either integers or strings.
+	XIntOrString bool `json:"x-kubernetes-int-or-string,omitempty"`
+}
@@ -0,0 +1,266 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package structural
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// resourceFields are the fields every resource has, whether its schema declares them or not.
+var resourceFields = map[string]bool{"apiVersion": true, "kind": true, "metadata": true}
+
+var uuidRegexp = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// Validate validates the content of an unstructured object, such as the one returned by
+// Unstructured.UnstructuredContent, against the schema of its resource. The apiVersion, kind and
+// metadata fields are allowed without being declared, and are only validated if the schema
+// declares them.
+func Validate(s *Schema, obj map[string]interface{}) field.ErrorList {
+	return validateObject(s, obj, nil, true)
+}
+
+// ValidateValue validates an unstructured value, decoded from JSON or YAML, against s. fldPath is
+// the path of the value, used in the returned errors.
+func ValidateValue(s *Schema, value interface{}, fldPath *field.Path) field.ErrorList {
+	if value == nil {
+		if s.Nullable {
+			return nil
+		}
+		return field.ErrorList{field.Invalid(fldPath, nil, "must not be null")}
+	}
+
+	var allErrs field.ErrorList
+	switch {
+	case s.XIntOrString:
+		_, isString := value.(string)
+		if _, isInteger := integerValue(value); !isString && !isInteger {
+			return field.ErrorList{field.Invalid(fldPath, value, "must be an integer or a string")}
+		}
+	case len(s.Type) == 0:
+	default:
+		if errs := validateType(s, value, fldPath); len(errs) > 0 {
+			return errs
+		}
+	}
+
+	if len(s.Enum) > 0 && !enumContains(s.Enum, value) {
+		allErrs = append(allErrs, field.NotSupported(fldPath, value, enumValues(s.Enum)))
+	}
+	return allErrs
+}
+
+func validateType(s *Schema, value interface{}, fldPath *field.Path) field.ErrorList {
+	switch s.Type {
+	case "object":
+		obj, ok := value.(map[string]interface{})
+		if !ok {
+			return field.ErrorList{typeError(fldPath, value, s.Type)}
+		}
+		return validateObject(s, obj, fldPath, false)
+	case "array":
+		items, ok := value.([]interface{})
+		if !ok {
+			return field.ErrorList{typeError(fldPath, value, s.Type)}
+		}
+		if s.Items == nil {
+			return nil
+		}
+		var allErrs field.ErrorList
+		for i, item := range items {
+			allErrs = append(allErrs, ValidateValue(s.Items, item, fldPath.Index(i))...)
+		}
+		return allErrs
+	case "string":
+		str, ok := value.(string)
+		if !ok {
+			return field.ErrorList{typeError(fldPath, value, s.Type)}
+		}
+		return validateStringFormat(s.Format, str, fldPath)
+	case "integer":
+		i, ok := integerValue(value)
+		if !ok {
+			return field.ErrorList{typeError(fldPath, value, s.Type)}
+		}
+		if s.Format == "int32" && (i < math.MinInt32 || i > math.MaxInt32) {
+			return field.ErrorList{field.Invalid(fldPath, value, "must be a 32 bit integer")}
+		}
+		return nil
+	case "number":
+		if _, ok := numberValue(value); !ok {
+			return field.ErrorList{typeError(fldPath, value, s.Type)}
+		}
+		return nil
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return field.ErrorList{typeError(fldPath, value, s.Type)}
+		}
+		return nil
+	}
+	return field.ErrorList{field.InternalError(fldPath, fmt.Errorf("unsupported schema type %q", s.Type))}
+}
+
+func validateObject(s *Schema, obj map[string]interface{}, fldPath *field.Path, resource bool) field.ErrorList {
+	var allErrs field.ErrorList
+	resource = resource || s.XEmbeddedResource
+
+	for _, name := range s.Required {
+		if _, ok := obj[name]; !ok {
+			allErrs = append(allErrs, field.Required(fldPath.Child(name), ""))
+		}
+	}
+
+	keys := make([]string, 0, len(obj))
+	for key := range obj {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		value := obj[key]
+		if property, ok := s.Properties[key]; ok {
+			allErrs = append(allErrs, ValidateValue(&property, value, fldPath.Child(key))...)
+			continue
+		}
+		switch {
+		case s.AdditionalProperties != nil:
+			allErrs = append(allErrs, ValidateValue(s.AdditionalProperties, value, fldPath.Key(key))...)
+		case s.XPreserveUnknownFields, resource && resourceFields[key]:
+		default:
+			allErrs = append(allErrs, field.Forbidden(fldPath.Child(key), "field is not declared in the schema"))
+		}
+	}
+	return allErrs
+}
+
+func validateStringFormat(format, value string, fldPath *field.Path) field.ErrorList {
+	var msgs []string
+	switch format {
+	case "byte":
+		if _, err := base64.StdEncoding.DecodeString(value); err != nil {
+			msgs = []string{"must be base64 encoded"}
+		}
+	case "date":
+		if _, err := time.Parse("2006-01-02", value); err != nil {
+			msgs = []string{"must be a date in the format 2006-01-02"}
+		}
+	case "date-time":
+		if _, err := time.Parse(time.RFC3339, value); err != nil {
+			msgs = []string{"must be a date and time in RFC 3339 format"}
+		}
+	case "uuid":
+		if !uuidRegexp.MatchString(value) {
+			msgs = []string{"must be a UUID"}
+		}
+	case "hostname":
+		msgs = validation.IsDNS1123Subdomain(value)
+	case "ipv4":
+		if ip := net.ParseIP(value); ip == nil || ip.To4() == nil {
+			msgs = []string{"must be a valid IPv4 address"}
+		}
+	case "ipv6":
+		if ip := net.ParseIP(value); ip == nil || ip.To4() != nil {
+			msgs = []string{"must be a valid IPv6 address"}
+		}
+	case "cidr":
+		if _, _, err := net.ParseCIDR(value); err != nil {
+			msgs = []string{"must be a valid CIDR"}
+		}
+	}
+	if len(msgs) == 0 {
+		return nil
+	}
+	return field.ErrorList{field.Invalid(fldPath, value, strings.Join(msgs, "; "))}
+}
+
+func typeError(fldPath *field.Path, value interface{}, expected string) *field.Error {
+	return field.Invalid(fldPath, value, fmt.Sprintf("must be of type %s", expected))
+}
+
+// integerValue returns the value of the integer types produced by JSON and YAML decoders,
+// including floating point numbers without a fractional part.
+func integerValue(value interface{}) (int64, bool) {
+	switch v := value.(type) {
+	case int64:
+		return v, true
+	case int:
+		return int64(v), true
+	case int32:
+		return int64(v), true
+	case float64:
+		if v == math.Trunc(v) && v >= math.MinInt64 && v <= math.MaxInt64 {
+			return int64(v), true
+		}
+	case json.Number:
+		i, err := v.Int64()
+		return i, err == nil
+	}
+	return 0, false
+}
+
+func numberValue(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	case json.Number:
+		f, err := v.Float64()
+		return f, err == nil
+	}
+	i, ok := integerValue(value)
+	return float64(i), ok
+}
+
+// enumContains reports whether value is one of values, comparing numbers by value.
+func enumContains(values []interface{}, value interface{}) bool {
+	number, isNumber := numberValue(value)
+	for _, v := range values {
+		if isNumber {
+			if n, ok := numberValue(v); ok && n == number {
+				return true
+			}
+			continue
+		}
+		if reflect.DeepEqual(v, value) {
+			return true
+		}
+	}
+	return false
+}
+
+func enumValues(values []interface{}) []string {
+	result := make([]string, 0, len(values))
+	for _, v := range values {
+		if data, err := json.Marshal(v); err == nil {
+			result = append(result, string(data))
+		} else {
+			result = append(result, fmt.Sprintf("%v", v))
+		}
+	}
+	return result
+}
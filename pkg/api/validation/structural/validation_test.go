@@ -0,0 +1,228 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package structural
+
+import (
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/yaml"
+)
+
+const widgetSchema = `
+type: object
+properties:
+  spec:
+    type: object
+    required: ["size"]
+    properties:
+      size:
+        type: string
+        enum: ["small", "large"]
+      replicas:
+        type: integer
+        format: int32
+      ratio:
+        type: number
+      paused:
+        type: boolean
+      port:
+        x-kubernetes-int-or-string: true
+      created:
+        type: string
+        format: date-time
+      address:
+        type: string
+        format: ipv4
+      host:
+        type: string
+        format: hostname
+      id:
+        type: string
+        format: uuid
+      data:
+        type: string
+        format: byte
+      labels:
+        type: object
+        additionalProperties:
+          type: string
+      tags:
+        type: array
+        items:
+          type: string
+      priority:
+        type: integer
+        enum: [1, 2]
+      note:
+        type: string
+        nullable: true
+      extra:
+        type: object
+        x-kubernetes-preserve-unknown-fields: true
+      template:
+        type: object
+        x-kubernetes-embedded-resource: true
+        properties:
+          spec:
+            type: object
+`
+
+func TestValidate(t *testing.T) {
+	schema := &Schema{}
+	if err := yaml.Unmarshal([]byte(widgetSchema), schema); err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		name     string
+		spec     string
+		expected []string
+	}{
+		{
+			name: "valid",
+			spec: `
+size: small
+replicas: 3
+ratio: 0.5
+paused: false
+port: http
+created: "2022-01-02T03:04:05Z"
+address: 10.0.0.1
+host: example.com
+id: 1b4e28ba-2fa1-11d2-883f-0016d3cca427
+data: aGVsbG8=
+labels: {app: foo}
+tags: [a, b]
+priority: 2
+note: null
+extra: {anything: [1, 2]}
+template: {apiVersion: v1, kind: Pod, metadata: {name: foo}, spec: {}}
+`,
+		},
+		{
+			name: "integral numbers are integers",
+			spec: `{"size": "large", "replicas": 3.0, "ratio": 1, "port": 8080}`,
+		},
+		{
+			name:     "missing required field",
+			spec:     `replicas: 1`,
+			expected: []string{"Required value: spec.size"},
+		},
+		{
+			name: "wrong types",
+			spec: `
+size: small
+replicas: "3"
+ratio: "0.5"
+paused: "false"
+port: true
+labels: {app: 1}
+tags: a
+`,
+			expected: []string{
+				"Invalid value: spec.labels[app]: must be of type string",
+				"Invalid value: spec.paused: must be of type boolean",
+				"Invalid value: spec.port: must be an integer or a string",
+				"Invalid value: spec.ratio: must be of type number",
+				"Invalid value: spec.replicas: must be of type integer",
+				"Invalid value: spec.tags: must be of type array",
+			},
+		},
+		{
+			name:     "enums",
+			spec:     `{"size": "medium", "priority": 3}`,
+			expected: []string{"Unsupported value: spec.priority", "Unsupported value: spec.size"},
+		},
+		{
+			name: "formats",
+			spec: `
+size: small
+replicas: 3000000000
+created: yesterday
+address: ::1
+host: Example_Host
+id: not-a-uuid
+data: "!"
+`,
+			expected: []string{
+				"Invalid value: spec.address: must be a valid IPv4 address",
+				"Invalid value: spec.created: must be a date and time",
+				"Invalid value: spec.data: must be base64 encoded",
+				"Invalid value: spec.host: a lowercase RFC 1123 subdomain",
+				"Invalid value: spec.id: must be a UUID",
+				"Invalid value: spec.replicas: must be a 32 bit integer",
+			},
+		},
+		{
+			name:     "null values",
+			spec:     `{"size": null, "note": null}`,
+			expected: []string{"Invalid value: spec.size: must not be null"},
+		},
+		{
+			name: "unknown fields",
+			spec: `{"size": "small", "color": "red", "template": {"kind": "Pod", "status": {}}}`,
+			expected: []string{
+				"Forbidden: spec.color: field is not declared in the schema",
+				"Forbidden: spec.template.status: field is not declared in the schema",
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			spec := map[string]interface{}{}
+			if err := yaml.Unmarshal([]byte(tc.spec), &spec); err != nil {
+				t.Fatal(err)
+			}
+			obj := map[string]interface{}{
+				"apiVersion": "example.com/v1",
+				"kind":       "Widget",
+				"metadata":   map[string]interface{}{"name": "foo"},
+				"spec":       spec,
+			}
+			errs := Validate(schema, obj)
+			if len(errs) != len(tc.expected) {
+				t.Fatalf("expected %d errors, got %v", len(tc.expected), errs)
+			}
+			for i, err := range errs {
+				if actual := describe(err); !strings.Contains(actual, tc.expected[i]) {
+					t.Errorf("expected error %d to match %q, got %q", i, tc.expected[i], actual)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateValue(t *testing.T) {
+	schema := &Schema{Type: "array", Items: &Schema{Type: "integer"}}
+	errs := ValidateValue(schema, []interface{}{int64(1), "two"}, field.NewPath("values"))
+	if len(errs) != 1 || errs[0].Field != "values[1]" || errs[0].Type != field.ErrorTypeInvalid {
+		t.Errorf("unexpected errors %v", errs)
+	}
+	if errs := ValidateValue(&Schema{Type: "object"}, map[string]interface{}{"a": "b"}, nil); len(errs) != 1 || errs[0].Type != field.ErrorTypeForbidden {
+		t.Errorf("expected unknown fields to be forbidden outside of resources, got %v", errs)
+	}
+	if errs := ValidateValue(&Schema{Type: "map"}, "a", nil); len(errs) != 1 || errs[0].Type != field.ErrorTypeInternal {
+		t.Errorf("expected an internal error for an unsupported type, got %v", errs)
+	}
+}
+
+// describe formats err as "<type>: <field>: <detail>" for matching in tests.
+func describe(err *field.Error) string {
+	return err.Type.String() + ": " + err.Field + ": " + err.Detail
+}
@@ -252,6 +252,70 @@ func NewRVDeletionPrecondition(rv string) *DeleteOptions {
 	return &DeleteOptions{Preconditions: &p}
 }
 
+// NewObjectPreconditions returns Preconditions requiring the UID and ResourceVersion of obj to be
+// unchanged when the request is processed. Fields that are not set on obj are not included.
+func NewObjectPreconditions(obj Object) *Preconditions {
+	p := &Preconditions{}
+	if uid := obj.GetUID(); len(uid) > 0 {
+		p.UID = &uid
+	}
+	if rv := obj.GetResourceVersion(); len(rv) > 0 {
+		p.ResourceVersion = &rv
+	}
+	return p
+}
+
+// NewForegroundDeleteOptions returns a DeleteOptions deleting the resource after all of its
+// dependents with blockOwnerDeletion set have been deleted.
+func NewForegroundDeleteOptions() *DeleteOptions {
+	return newPropagationDeleteOptions(DeletePropagationForeground)
+}
+
+// NewBackgroundDeleteOptions returns a DeleteOptions deleting the resource immediately and
+// leaving its dependents to be deleted by the garbage collector.
+func NewBackgroundDeleteOptions() *DeleteOptions {
+	return newPropagationDeleteOptions(DeletePropagationBackground)
+}
+
+// NewOrphanDeleteOptions returns a DeleteOptions deleting the resource and orphaning its dependents.
+func NewOrphanDeleteOptions() *DeleteOptions {
+	return newPropagationDeleteOptions(DeletePropagationOrphan)
+}
+
+func newPropagationDeleteOptions(policy DeletionPropagation) *DeleteOptions {
+	return &DeleteOptions{PropagationPolicy: &policy}
+}
+
+// NewDeleteOptionsWithPropagation returns a DeleteOptions deleting the resource with the provided
+// propagation policy. grace is the grace period in seconds, zero indicating immediate deletion, or
+// nil to use the default grace period of the resource. preconditions are optional. An error is
+// returned for unknown propagation policies, negative grace periods and preconditions with empty values.
+func NewDeleteOptionsWithPropagation(policy DeletionPropagation, grace *int64, preconditions *Preconditions) (*DeleteOptions, error) {
+	switch policy {
+	case DeletePropagationForeground, DeletePropagationBackground, DeletePropagationOrphan:
+	default:
+		return nil, fmt.Errorf("unsupported propagation policy %q, must be one of %q, %q or %q", policy, DeletePropagationForeground, DeletePropagationBackground, DeletePropagationOrphan)
+	}
+	options := newPropagationDeleteOptions(policy)
+	if grace != nil {
+		if *grace < 0 {
+			return nil, fmt.Errorf("grace period must not be negative, got %d", *grace)
+		}
+		g := *grace
+		options.GracePeriodSeconds = &g
+	}
+	if preconditions != nil {
+		if preconditions.UID != nil && len(*preconditions.UID) == 0 {
+			return nil, errors.New("UID precondition must not be empty")
+		}
+		if preconditions.ResourceVersion != nil && len(*preconditions.ResourceVersion) == 0 {
+			return nil, errors.New("ResourceVersion precondition must not be empty")
+		}
+		options.Preconditions = preconditions.DeepCopy()
+	}
+	return options, nil
+}
+
 // HasObjectMetaSystemFieldValues returns true if fields that are managed by the system on ObjectMeta have values.
 func HasObjectMetaSystemFieldValues(meta Object) bool {
 	return !meta.GetCreationTimestamp().Time.IsZero() ||
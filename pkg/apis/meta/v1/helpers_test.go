@@ -250,3 +250,73 @@ func TestSetMetaDataLabel(t *testing.T) {
 		}
 	}
 }
+
+func TestNewObjectPreconditions(t *testing.T) {
+	uid := types.UID("uid")
+	rv := "42"
+	obj := &ObjectMeta{UID: uid, ResourceVersion: rv}
+	if e, a := (&Preconditions{UID: &uid, ResourceVersion: &rv}), NewObjectPreconditions(obj); !reflect.DeepEqual(e, a) {
+		t.Errorf("expected %v, got %v", e, a)
+	}
+	if e, a := (&Preconditions{UID: &uid}), NewObjectPreconditions(&ObjectMeta{UID: uid}); !reflect.DeepEqual(e, a) {
+		t.Errorf("expected %v, got %v", e, a)
+	}
+	obj.UID = "other"
+	if *NewObjectPreconditions(&ObjectMeta{UID: uid}).UID != uid {
+		t.Errorf("expected preconditions not to alias the object")
+	}
+}
+
+func TestNewDeleteOptionsWithPropagation(t *testing.T) {
+	zero, negative := int64(0), int64(-1)
+	uid, empty := types.UID("uid"), types.UID("")
+
+	for _, preset := range []struct {
+		options *DeleteOptions
+		policy  DeletionPropagation
+	}{
+		{NewForegroundDeleteOptions(), DeletePropagationForeground},
+		{NewBackgroundDeleteOptions(), DeletePropagationBackground},
+		{NewOrphanDeleteOptions(), DeletePropagationOrphan},
+	} {
+		if preset.options.PropagationPolicy == nil || *preset.options.PropagationPolicy != preset.policy {
+			t.Errorf("expected propagation policy %s, got %v", preset.policy, preset.options.PropagationPolicy)
+		}
+	}
+
+	testCases := []struct {
+		name          string
+		policy        DeletionPropagation
+		grace         *int64
+		preconditions *Preconditions
+		expectErr     bool
+	}{
+		{name: "default grace period", policy: DeletePropagationBackground},
+		{name: "immediate", policy: DeletePropagationForeground, grace: &zero},
+		{name: "preconditions", policy: DeletePropagationOrphan, preconditions: &Preconditions{UID: &uid}},
+		{name: "unknown policy", policy: "Cascade", expectErr: true},
+		{name: "negative grace period", policy: DeletePropagationBackground, grace: &negative, expectErr: true},
+		{name: "empty precondition", policy: DeletePropagationBackground, preconditions: &Preconditions{UID: &empty}, expectErr: true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			options, err := NewDeleteOptionsWithPropagation(tc.policy, tc.grace, tc.preconditions)
+			if tc.expectErr {
+				if err == nil {
+					t.Fatalf("expected error, got %v", options)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			expected := &DeleteOptions{PropagationPolicy: &tc.policy, GracePeriodSeconds: tc.grace, Preconditions: tc.preconditions}
+			if !reflect.DeepEqual(expected, options) {
+				t.Errorf("expected %v, got %v", expected, options)
+			}
+			if tc.grace != nil && options.GracePeriodSeconds == tc.grace {
+				t.Errorf("expected the grace period to be copied")
+			}
+		})
+	}
+}
@@ -0,0 +1,152 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"reflect"
+	"sort"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// NewLabelSelectorRequirement returns the requirement that the label key matches values according to
+// op. It returns an aggregate of field errors if the key or values are not valid label keys and values,
+// or if the number of values is not valid for op: In and NotIn require values, while Exists and
+// DoesNotExist may not have any.
+func NewLabelSelectorRequirement(key string, op LabelSelectorOperator, values ...string) (LabelSelectorRequirement, error) {
+	requirement := LabelSelectorRequirement{Key: key, Operator: op}
+	if len(values) > 0 {
+		requirement.Values = append([]string(nil), values...)
+	}
+	if errs := validateLabelSelectorRequirement(requirement, nil); len(errs) > 0 {
+		return LabelSelectorRequirement{}, errs.ToAggregate()
+	}
+	return requirement, nil
+}
+
+// NewLabelSelector returns a label selector matching the provided labels and requirements. It returns an
+// aggregate of field errors if any label or requirement is invalid, so every selector it returns can be
+// converted with LabelSelectorAsSelector.
+func NewLabelSelector(matchLabels map[string]string, requirements ...LabelSelectorRequirement) (*LabelSelector, error) {
+	selector := &LabelSelector{}
+	if len(matchLabels) > 0 {
+		selector.MatchLabels = make(map[string]string, len(matchLabels))
+		for k, v := range matchLabels {
+			selector.MatchLabels[k] = v
+		}
+	}
+	for _, requirement := range requirements {
+		selector.MatchExpressions = append(selector.MatchExpressions, *requirement.DeepCopy())
+	}
+	if errs := validateLabelSelector(selector, nil); len(errs) > 0 {
+		return nil, errs.ToAggregate()
+	}
+	return selector, nil
+}
+
+// MergeLabelSelectors returns a label selector that matches the labels matched by all of the provided
+// selectors. As a nil selector matches nothing, the result is nil if any of the selectors is nil.
+// Conflicting match labels for the same key are kept as In requirements, so the result matches nothing
+// in that case as well. The provided selectors are not modified.
+func MergeLabelSelectors(selectors ...*LabelSelector) *LabelSelector {
+	merged := &LabelSelector{}
+	for _, selector := range selectors {
+		if selector == nil {
+			return nil
+		}
+	}
+	for _, selector := range selectors {
+		keys := make([]string, 0, len(selector.MatchLabels))
+		for k := range selector.MatchLabels {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			v := selector.MatchLabels[k]
+			existing, ok := merged.MatchLabels[k]
+			switch {
+			case !ok:
+				if merged.MatchLabels == nil {
+					merged.MatchLabels = map[string]string{}
+				}
+				merged.MatchLabels[k] = v
+			case existing != v:
+				merged.MatchExpressions = appendRequirement(merged.MatchExpressions, LabelSelectorRequirement{Key: k, Operator: LabelSelectorOpIn, Values: []string{v}})
+			}
+		}
+		for _, requirement := range selector.MatchExpressions {
+			merged.MatchExpressions = appendRequirement(merged.MatchExpressions, *requirement.DeepCopy())
+		}
+	}
+	return merged
+}
+
+// appendRequirement appends requirement to requirements unless it is already present.
+func appendRequirement(requirements []LabelSelectorRequirement, requirement LabelSelectorRequirement) []LabelSelectorRequirement {
+	for _, existing := range requirements {
+		if reflect.DeepEqual(existing, requirement) {
+			return requirements
+		}
+	}
+	return append(requirements, requirement)
+}
+
+// validateLabelSelector validates selector like LabelSelectorAsSelector does, additionally checking
+// the number of values of the requirements.
+func validateLabelSelector(selector *LabelSelector, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	labelsPath := fldPath.Child("matchLabels")
+	for k, v := range selector.MatchLabels {
+		for _, msg := range validation.IsQualifiedName(k) {
+			allErrs = append(allErrs, field.Invalid(labelsPath, k, msg))
+		}
+		for _, msg := range validation.IsValidLabelValue(v) {
+			allErrs = append(allErrs, field.Invalid(labelsPath.Key(k), v, msg))
+		}
+	}
+	for i, requirement := range selector.MatchExpressions {
+		allErrs = append(allErrs, validateLabelSelectorRequirement(requirement, fldPath.Child("matchExpressions").Index(i))...)
+	}
+	return allErrs
+}
+
+func validateLabelSelectorRequirement(requirement LabelSelectorRequirement, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	switch requirement.Operator {
+	case LabelSelectorOpIn, LabelSelectorOpNotIn:
+		if len(requirement.Values) == 0 {
+			allErrs = append(allErrs, field.Required(fldPath.Child("values"), "must be specified when `operator` is 'In' or 'NotIn'"))
+		}
+	case LabelSelectorOpExists, LabelSelectorOpDoesNotExist:
+		if len(requirement.Values) > 0 {
+			allErrs = append(allErrs, field.Forbidden(fldPath.Child("values"), "may not be specified when `operator` is 'Exists' or 'DoesNotExist'"))
+		}
+	default:
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("operator"), requirement.Operator,
+			[]string{string(LabelSelectorOpIn), string(LabelSelectorOpNotIn), string(LabelSelectorOpExists), string(LabelSelectorOpDoesNotExist)}))
+	}
+	for _, msg := range validation.IsQualifiedName(requirement.Key) {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("key"), requirement.Key, msg))
+	}
+	for i, v := range requirement.Values {
+		for _, msg := range validation.IsValidLabelValue(v) {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("values").Index(i), v, msg))
+		}
+	}
+	return allErrs
+}
@@ -0,0 +1,138 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+func TestNewLabelSelectorRequirement(t *testing.T) {
+	testCases := []struct {
+		name     string
+		key      string
+		op       LabelSelectorOperator
+		values   []string
+		expected string
+	}{
+		{name: "in", key: "foo", op: LabelSelectorOpIn, values: []string{"a", "b"}},
+		{name: "not in", key: "example.com/foo", op: LabelSelectorOpNotIn, values: []string{"a"}},
+		{name: "exists", key: "foo", op: LabelSelectorOpExists},
+		{name: "does not exist", key: "foo", op: LabelSelectorOpDoesNotExist},
+		{name: "in without values", key: "foo", op: LabelSelectorOpIn, expected: "values: Required value"},
+		{name: "exists with values", key: "foo", op: LabelSelectorOpExists, values: []string{"a"}, expected: "values: Forbidden"},
+		{name: "unknown operator", key: "foo", op: "Equals", values: []string{"a"}, expected: "operator: Unsupported value"},
+		{name: "invalid key", key: "foo bar", op: LabelSelectorOpExists, expected: "key: Invalid value"},
+		{name: "invalid value", key: "foo", op: LabelSelectorOpIn, values: []string{"a", "b c"}, expected: "values[1]: Invalid value"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			requirement, err := NewLabelSelectorRequirement(tc.key, tc.op, tc.values...)
+			if len(tc.expected) > 0 {
+				if err == nil || !strings.Contains(err.Error(), tc.expected) {
+					t.Fatalf("expected error containing %q, got %v", tc.expected, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			expected := LabelSelectorRequirement{Key: tc.key, Operator: tc.op, Values: tc.values}
+			if !reflect.DeepEqual(expected, requirement) {
+				t.Errorf("expected %#v, got %#v", expected, requirement)
+			}
+			if _, err := LabelSelectorAsSelector(&LabelSelector{MatchExpressions: []LabelSelectorRequirement{requirement}}); err != nil {
+				t.Errorf("expected the requirement to be convertible: %v", err)
+			}
+		})
+	}
+}
+
+func TestNewLabelSelector(t *testing.T) {
+	requirement, err := NewLabelSelectorRequirement("tier", LabelSelectorOpIn, "frontend")
+	if err != nil {
+		t.Fatal(err)
+	}
+	selector, err := NewLabelSelector(map[string]string{"app": "web"}, requirement)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e, a := "app=web,tier in (frontend)", FormatLabelSelector(selector); e != a {
+		t.Errorf("expected %q, got %q", e, a)
+	}
+
+	_, err = NewLabelSelector(map[string]string{"app": "not valid"}, LabelSelectorRequirement{Key: "tier", Operator: LabelSelectorOpIn})
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	for _, expected := range []string{"matchLabels[app]: Invalid value", "matchExpressions[0].values: Required value"} {
+		if !strings.Contains(err.Error(), expected) {
+			t.Errorf("expected error to contain %q, got %v", expected, err)
+		}
+	}
+}
+
+func TestMergeLabelSelectors(t *testing.T) {
+	a := &LabelSelector{
+		MatchLabels:      map[string]string{"app": "web", "env": "prod"},
+		MatchExpressions: []LabelSelectorRequirement{{Key: "tier", Operator: LabelSelectorOpExists}},
+	}
+	b := &LabelSelector{
+		MatchLabels: map[string]string{"app": "web", "team": "a"},
+		MatchExpressions: []LabelSelectorRequirement{
+			{Key: "tier", Operator: LabelSelectorOpExists},
+			{Key: "zone", Operator: LabelSelectorOpNotIn, Values: []string{"east"}},
+		},
+	}
+	original := a.DeepCopy()
+
+	merged := MergeLabelSelectors(a, b)
+	expected := &LabelSelector{
+		MatchLabels: map[string]string{"app": "web", "env": "prod", "team": "a"},
+		MatchExpressions: []LabelSelectorRequirement{
+			{Key: "tier", Operator: LabelSelectorOpExists},
+			{Key: "zone", Operator: LabelSelectorOpNotIn, Values: []string{"east"}},
+		},
+	}
+	if !reflect.DeepEqual(expected, merged) {
+		t.Errorf("expected %#v, got %#v", expected, merged)
+	}
+	if !reflect.DeepEqual(original, a) {
+		t.Errorf("the merged selector was modified")
+	}
+
+	conflicting := MergeLabelSelectors(a, &LabelSelector{MatchLabels: map[string]string{"env": "dev"}})
+	selector, err := LabelSelectorAsSelector(conflicting)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, set := range []labels.Set{{"app": "web", "env": "prod", "tier": "x"}, {"app": "web", "env": "dev", "tier": "x"}} {
+		if selector.Matches(set) {
+			t.Errorf("expected conflicting selectors to match nothing, matched %v", set)
+		}
+	}
+
+	if MergeLabelSelectors(a, nil) != nil {
+		t.Errorf("expected merging with a nil selector to match nothing")
+	}
+	if merged := MergeLabelSelectors(); merged == nil || len(merged.MatchLabels)+len(merged.MatchExpressions) != 0 {
+		t.Errorf("expected merging no selectors to match everything, got %#v", merged)
+	}
+}
@@ -21,9 +21,18 @@ import (
 
 	"k8s.io/apimachinery/pkg/api/apitesting/roundtrip"
 	testapigroupfuzzer "k8s.io/apimachinery/pkg/apis/testapigroup/fuzzer"
+	"k8s.io/apimachinery/pkg/runtime"
 )
 
 func TestRoundTrip(t *testing.T) {
 	roundtrip.RoundTripTestForAPIGroup(t, Install, testapigroupfuzzer.Funcs)
 	roundtrip.RoundTripProtobufTestForAPIGroup(t, Install, testapigroupfuzzer.Funcs)
 }
+
+func TestExternalTypes(t *testing.T) {
+	scheme := runtime.NewScheme()
+	Install(scheme)
+	options := roundtrip.NewExternalTypesTestOptions(scheme)
+	options.FuzzerFuncs = testapigroupfuzzer.Funcs
+	options.Complete(t).Run(t)
+}
@@ -0,0 +1,443 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package conversion
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+)
+
+// AutoConverter converts between nearly identical struct types by matching their exported
+// fields by name. Fields of embedded structs are matched as if they were fields of the
+// embedding struct. Matched fields must have identical or compatible types: pointers are
+// dereferenced or allocated as needed, structs, slices, arrays and maps are converted
+// element by element, and numbers are converted to the same or a wider kind. Fields that
+// exist in only one of the types are ignored.
+//
+// The conversion plan of a pair of types is computed once and cached, so converting is
+// cheaper than reflecting over the types every time. Convert has the signature of a
+// ConversionFunc, so it can be registered for type pairs that need no custom logic.
+// AutoConverter is safe for concurrent use.
+type AutoConverter struct {
+	lock    sync.RWMutex
+	renames map[typePair]map[string]string
+	plans   map[typePair]*structPlan
+}
+
+// NewAutoConverter returns an AutoConverter without field renames.
+func NewAutoConverter() *AutoConverter {
+	return &AutoConverter{
+		renames: map[typePair]map[string]string{},
+		plans:   map[typePair]*structPlan{},
+	}
+}
+
+// RenameFields declares that the fields of struct type a named by the keys of renames are
+// converted to the fields of struct type b named by the values. a and b must be pointers.
+// The names of fields of embedded structs may be used. Renames apply to conversions from a
+// to b; conversions from b to a need their own inverse renames.
+func (c *AutoConverter) RenameFields(a, b interface{}, renames map[string]string) error {
+	tA, tB := reflect.TypeOf(a), reflect.TypeOf(b)
+	if tA == nil || tA.Kind() != reflect.Ptr || tA.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("the type %T must be a pointer to a struct to rename fields", a)
+	}
+	if tB == nil || tB.Kind() != reflect.Ptr || tB.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("the type %T must be a pointer to a struct to rename fields", b)
+	}
+	copied := make(map[string]string, len(renames))
+	for k, v := range renames {
+		copied[k] = v
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.renames[typePair{tA.Elem(), tB.Elem()}] = copied
+	// plans may depend on the renames of nested types, so they are recomputed.
+	c.plans = map[typePair]*structPlan{}
+	return nil
+}
+
+// Convert converts src into dest, which must be pointers to convertible types. The scope is
+// not used, matched fields are converted by the AutoConverter itself.
+func (c *AutoConverter) Convert(src, dest interface{}, _ Scope) error {
+	sv, err := EnforcePtr(src)
+	if err != nil {
+		return err
+	}
+	dv, err := EnforcePtr(dest)
+	if err != nil {
+		return err
+	}
+	convert, err := c.converterFor(sv.Type(), dv.Type())
+	if err != nil {
+		return err
+	}
+	return convert(sv, dv)
+}
+
+// valueConverter converts src into dest, which is settable.
+type valueConverter func(src, dest reflect.Value) error
+
+type structPlan struct {
+	// ready is closed once fields is populated, fields may not be used before.
+	ready  chan struct{}
+	fields []fieldPlan
+	err    error
+}
+
+type fieldPlan struct {
+	src, dest []int
+	convert   valueConverter
+}
+
+func (c *AutoConverter) converterFor(src, dest reflect.Type) (valueConverter, error) {
+	if src == dest {
+		return func(s, d reflect.Value) error {
+			d.Set(s)
+			return nil
+		}, nil
+	}
+
+	switch {
+	case src.Kind() == reflect.Ptr && dest.Kind() == reflect.Ptr:
+		elem, err := c.converterFor(src.Elem(), dest.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return func(s, d reflect.Value) error {
+			if s.IsNil() {
+				d.Set(reflect.Zero(d.Type()))
+				return nil
+			}
+			d.Set(reflect.New(d.Type().Elem()))
+			return elem(s.Elem(), d.Elem())
+		}, nil
+	case src.Kind() == reflect.Ptr:
+		elem, err := c.converterFor(src.Elem(), dest)
+		if err != nil {
+			return nil, err
+		}
+		return func(s, d reflect.Value) error {
+			if s.IsNil() {
+				d.Set(reflect.Zero(d.Type()))
+				return nil
+			}
+			return elem(s.Elem(), d)
+		}, nil
+	case dest.Kind() == reflect.Ptr:
+		elem, err := c.converterFor(src, dest.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return func(s, d reflect.Value) error {
+			d.Set(reflect.New(d.Type().Elem()))
+			return elem(s, d.Elem())
+		}, nil
+	}
+
+	if src.Kind() != dest.Kind() {
+		if isWideningNumberConversion(src, dest) {
+			return convertBasic, nil
+		}
+		return nil, fmt.Errorf("cannot convert %v to %v", src, dest)
+	}
+
+	switch src.Kind() {
+	case reflect.Struct:
+		return c.structConverter(src, dest)
+	case reflect.Slice:
+		elem, err := c.converterFor(src.Elem(), dest.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return func(s, d reflect.Value) error {
+			if s.IsNil() {
+				d.Set(reflect.Zero(d.Type()))
+				return nil
+			}
+			d.Set(reflect.MakeSlice(d.Type(), s.Len(), s.Len()))
+			for i := 0; i < s.Len(); i++ {
+				if err := elem(s.Index(i), d.Index(i)); err != nil {
+					return err
+				}
+			}
+			return nil
+		}, nil
+	case reflect.Array:
+		if src.Len() != dest.Len() {
+			return nil, fmt.Errorf("cannot convert %v to %v", src, dest)
+		}
+		elem, err := c.converterFor(src.Elem(), dest.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return func(s, d reflect.Value) error {
+			for i := 0; i < s.Len(); i++ {
+				if err := elem(s.Index(i), d.Index(i)); err != nil {
+					return err
+				}
+			}
+			return nil
+		}, nil
+	case reflect.Map:
+		key, err := c.converterFor(src.Key(), dest.Key())
+		if err != nil {
+			return nil, err
+		}
+		value, err := c.converterFor(src.Elem(), dest.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return func(s, d reflect.Value) error {
+			if s.IsNil() {
+				d.Set(reflect.Zero(d.Type()))
+				return nil
+			}
+			d.Set(reflect.MakeMapWithSize(d.Type(), s.Len()))
+			iter := s.MapRange()
+			for iter.Next() {
+				k := reflect.New(d.Type().Key()).Elem()
+				if err := key(iter.Key(), k); err != nil {
+					return err
+				}
+				v := reflect.New(d.Type().Elem()).Elem()
+				if err := value(iter.Value(), v); err != nil {
+					return err
+				}
+				d.SetMapIndex(k, v)
+			}
+			return nil
+		}, nil
+	case reflect.Interface, reflect.Func, reflect.Chan, reflect.UnsafePointer:
+		if src.AssignableTo(dest) {
+			return func(s, d reflect.Value) error {
+				d.Set(s)
+				return nil
+			}, nil
+		}
+		return nil, fmt.Errorf("cannot convert %v to %v", src, dest)
+	}
+	// the remaining kinds are basic kinds, which are convertible between types of the same kind.
+	return convertBasic, nil
+}
+
+func convertBasic(s, d reflect.Value) error {
+	d.Set(s.Convert(d.Type()))
+	return nil
+}
+
+// isWideningNumberConversion returns true if src and dest are both signed integers, unsigned
+// integers or floats, and dest can represent all values of src.
+func isWideningNumberConversion(src, dest reflect.Type) bool {
+	family := func(k reflect.Kind) int {
+		switch k {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			return 1
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			return 2
+		case reflect.Float32, reflect.Float64:
+			return 3
+		}
+		return 0
+	}
+	f := family(src.Kind())
+	return f != 0 && f == family(dest.Kind()) && dest.Bits() >= src.Bits()
+}
+
+// structConverter returns a converter using the cached plan of src and dest, computing it if
+// needed. Plans are cached before their fields are computed, so that recursive types resolve
+// to the plan being computed.
+func (c *AutoConverter) structConverter(src, dest reflect.Type) (valueConverter, error) {
+	pair := typePair{src, dest}
+	c.lock.Lock()
+	plan, ok := c.plans[pair]
+	if !ok {
+		plan = &structPlan{ready: make(chan struct{})}
+		c.plans[pair] = plan
+	}
+	renames := c.renames[pair]
+	c.lock.Unlock()
+
+	if !ok {
+		plan.fields, plan.err = c.planFields(src, dest, renames)
+		close(plan.ready)
+		if plan.err != nil {
+			c.lock.Lock()
+			if c.plans[pair] == plan {
+				delete(c.plans, pair)
+			}
+			c.lock.Unlock()
+			return nil, plan.err
+		}
+	} else {
+		select {
+		case <-plan.ready:
+			if plan.err != nil {
+				return nil, plan.err
+			}
+		default:
+			// the plan is being computed, either by a recursive call or concurrently,
+			// so it is waited for when converting.
+		}
+	}
+
+	return func(s, d reflect.Value) error {
+		<-plan.ready
+		if plan.err != nil {
+			return plan.err
+		}
+		for _, field := range plan.fields {
+			sf, ok := fieldByIndex(s, field.src)
+			if !ok {
+				continue
+			}
+			if err := field.convert(sf, allocFieldByIndex(d, field.dest)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}, nil
+}
+
+func (c *AutoConverter) planFields(src, dest reflect.Type, renames map[string]string) ([]fieldPlan, error) {
+	srcFields := exportedFields(src)
+	destFields := exportedFields(dest)
+
+	// fields are converted in the order of the source struct.
+	names := make([]string, 0, len(srcFields))
+	for name := range srcFields {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		a, b := srcFields[names[i]], srcFields[names[j]]
+		for k := 0; k < len(a) && k < len(b); k++ {
+			if a[k] != b[k] {
+				return a[k] < b[k]
+			}
+		}
+		return len(a) < len(b)
+	})
+
+	var plans []fieldPlan
+	for _, name := range names {
+		srcIndex := srcFields[name]
+		destName := name
+		if renamed, ok := renames[name]; ok {
+			destName = renamed
+		}
+		destIndex, ok := destFields[destName]
+		if !ok {
+			continue
+		}
+		convert, err := c.converterFor(src.FieldByIndex(srcIndex).Type, dest.FieldByIndex(destIndex).Type)
+		if err != nil {
+			return nil, fmt.Errorf("converting %v.%s to %v.%s: %v", src, name, dest, destName, err)
+		}
+		plans = append(plans, fieldPlan{src: srcIndex, dest: destIndex, convert: convert})
+	}
+	return plans, nil
+}
+
+// exportedFields returns the index paths of the exported fields of t by name, including the
+// promoted fields of embedded structs but not the embedded structs themselves. As in Go, a
+// field shadows fields of the same name at a greater depth, and ambiguous fields are omitted.
+// As in encoding/json, fields of embedded pointers to unexported structs are omitted.
+func exportedFields(t reflect.Type) map[string][]int {
+	type candidate struct {
+		index     []int
+		depth     int
+		ambiguous bool
+	}
+	candidates := map[string]*candidate{}
+	var collect func(t reflect.Type, prefix []int, visited map[reflect.Type]bool)
+	collect = func(t reflect.Type, prefix []int, visited map[reflect.Type]bool) {
+		if visited[t] {
+			return
+		}
+		visited[t] = true
+		defer delete(visited, t)
+
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			index := append(append([]int(nil), prefix...), i)
+			if f.Anonymous {
+				ft := f.Type
+				if ft.Kind() == reflect.Ptr {
+					// pointers to embedded unexported structs can't be allocated.
+					if len(f.PkgPath) != 0 {
+						continue
+					}
+					ft = ft.Elem()
+				}
+				if ft.Kind() == reflect.Struct {
+					collect(ft, index, visited)
+					continue
+				}
+			}
+			if len(f.PkgPath) != 0 {
+				continue
+			}
+			existing, ok := candidates[f.Name]
+			switch {
+			case !ok || len(index) < existing.depth:
+				candidates[f.Name] = &candidate{index: index, depth: len(index)}
+			case len(index) == existing.depth:
+				existing.ambiguous = true
+			}
+		}
+	}
+	collect(t, nil, map[reflect.Type]bool{})
+
+	fields := make(map[string][]int, len(candidates))
+	for name, c := range candidates {
+		if !c.ambiguous {
+			fields[name] = c.index
+		}
+	}
+	return fields
+}
+
+// fieldByIndex is like reflect.Value.FieldByIndex, but returns false instead of panicking
+// if the path traverses a nil embedded pointer.
+func fieldByIndex(v reflect.Value, index []int) (reflect.Value, bool) {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				return reflect.Value{}, false
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v, true
+}
+
+// allocFieldByIndex is like reflect.Value.FieldByIndex, but allocates nil embedded pointers.
+func allocFieldByIndex(v reflect.Value, index []int) reflect.Value {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v
+}
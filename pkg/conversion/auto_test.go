@@ -0,0 +1,154 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package conversion
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type autoMeta struct {
+	Name   string
+	Labels map[string]string
+}
+
+type AutoMetaV2 struct {
+	Name   string
+	Labels map[string]string
+}
+
+type autoSourceItem struct {
+	Value int32
+	Next  *autoSourceItem
+}
+
+type autoDestItem struct {
+	Value int32
+	Next  *autoDestItem
+}
+
+type autoSource struct {
+	autoMeta
+	Replicas  *int32
+	Paused    bool
+	Image     string
+	Items     []autoSourceItem
+	Selector  map[string]autoSourceItem
+	Ignored   string
+	unexposed string
+}
+
+type autoDest struct {
+	*AutoMetaV2
+	Replicas  int32
+	Paused    *bool
+	Container string
+	Items     []*autoDestItem
+	Selector  map[string]*autoDestItem
+	unexposed string
+}
+
+func TestAutoConverter(t *testing.T) {
+	c := NewAutoConverter()
+	if err := c.RenameFields(&autoSource{}, &autoDest{}, map[string]string{"Image": "Container"}); err != nil {
+		t.Fatal(err)
+	}
+
+	replicas := int32(3)
+	src := &autoSource{
+		autoMeta:  autoMeta{Name: "foo", Labels: map[string]string{"a": "b"}},
+		Replicas:  &replicas,
+		Paused:    true,
+		Image:     "app:v1",
+		Items:     []autoSourceItem{{Value: 1, Next: &autoSourceItem{Value: 2}}},
+		Selector:  map[string]autoSourceItem{"x": {Value: 5}},
+		Ignored:   "dropped",
+		unexposed: "dropped",
+	}
+	dest := &autoDest{}
+	if err := c.Convert(src, dest, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	paused := true
+	expected := &autoDest{
+		AutoMetaV2: &AutoMetaV2{Name: "foo", Labels: map[string]string{"a": "b"}},
+		Replicas:   3,
+		Paused:     &paused,
+		Container:  "app:v1",
+		Items:      []*autoDestItem{{Value: 1, Next: &autoDestItem{Value: 2}}},
+		Selector:   map[string]*autoDestItem{"x": {Value: 5}},
+	}
+	if !reflect.DeepEqual(expected, dest) {
+		t.Errorf("expected %#v, got %#v", expected, dest)
+	}
+
+	// converting back uses a separate plan, with nil pointers converted to zero values.
+	back := &autoSource{Image: "unchanged"}
+	expected.AutoMetaV2 = nil
+	expected.Paused = nil
+	if err := c.Convert(expected, back, nil); err != nil {
+		t.Fatal(err)
+	}
+	if back.Name != "" || back.Paused || back.Replicas == nil || *back.Replicas != 3 || back.Image != "unchanged" || back.Items[0].Next.Value != 2 {
+		t.Errorf("unexpected conversion %#v", back)
+	}
+}
+
+func TestAutoConverterErrors(t *testing.T) {
+	type narrow struct{ Value int32 }
+	type wide struct{ Value int64 }
+	type text struct{ Value string }
+
+	c := NewAutoConverter()
+	if err := c.Convert(&wide{Value: 1}, &narrow{}, nil); err == nil || !strings.Contains(err.Error(), "Value") {
+		t.Errorf("expected an error naming the narrowing field, got %v", err)
+	}
+	if err := c.Convert(&narrow{Value: 1}, &text{}, nil); err == nil {
+		t.Errorf("expected an error converting a number to a string")
+	}
+	if err := c.Convert(narrow{}, &wide{}, nil); err == nil {
+		t.Errorf("expected an error for a non-pointer source")
+	}
+	if err := c.RenameFields(narrow{}, &wide{}, nil); err == nil {
+		t.Errorf("expected an error renaming fields of a non-pointer")
+	}
+}
+
+func TestAutoConverterRegistered(t *testing.T) {
+	type A struct {
+		Foo string
+		Bar []int
+	}
+	type B struct {
+		Foo string
+		Bar []int64
+	}
+	auto := NewAutoConverter()
+	c := NewConverter(nil)
+	if err := c.RegisterUntypedConversionFunc((*A)(nil), (*B)(nil), auto.Convert); err != nil {
+		t.Fatal(err)
+	}
+	b := &B{}
+	if err := c.Convert(&A{Foo: "foo", Bar: []int{1}}, b, nil); err != nil {
+		t.Fatal(err)
+	}
+	if e, a := (&B{Foo: "foo", Bar: []int64{1}}), b; !reflect.DeepEqual(e, a) {
+		t.Errorf("expected %#v, got %#v", e, a)
+	}
+}
@@ -19,6 +19,7 @@ package conversion
 import (
 	"fmt"
 	"reflect"
+	"sync"
 )
 
 type typePair struct {
@@ -45,6 +46,10 @@ type Converter struct {
 
 	// Set of conversions that should be treated as a no-op
 	ignoredUntypedConversions map[typePair]struct{}
+
+	// lock guards the conversion funcs once EnableConcurrentRegistration is called, and is nil
+	// otherwise.
+	lock *sync.RWMutex
 }
 
 // NewConverter creates a new Converter object.
@@ -64,11 +69,55 @@ func NewConverter(NameFunc) *Converter {
 	return c
 }
 
+// EnableConcurrentRegistration makes the converter safe for registering conversion funcs while
+// it converts objects, at the cost of a read lock for every lookup. Conversion funcs are never
+// invoked with the lock held. It must be called before the converter is used concurrently.
+func (c *Converter) EnableConcurrentRegistration() {
+	if c.lock == nil {
+		c.lock = &sync.RWMutex{}
+	}
+}
+
+func (c *Converter) rLock() {
+	if c.lock != nil {
+		c.lock.RLock()
+	}
+}
+
+func (c *Converter) rUnlock() {
+	if c.lock != nil {
+		c.lock.RUnlock()
+	}
+}
+
+func (c *Converter) wLock() {
+	if c.lock != nil {
+		c.lock.Lock()
+	}
+}
+
+func (c *Converter) wUnlock() {
+	if c.lock != nil {
+		c.lock.Unlock()
+	}
+}
+
 // WithConversions returns a Converter that is a copy of c but with the additional
 // fns merged on top.
 func (c *Converter) WithConversions(fns ConversionFuncs) *Converter {
+	c.rLock()
+	defer c.rUnlock()
 	copied := *c
 	copied.conversionFuncs = c.conversionFuncs.Merge(fns)
+	if c.lock != nil {
+		// the copy has its own lock, so it must not share the maps guarded by the lock of c
+		copied.lock = &sync.RWMutex{}
+		copied.generatedConversionFuncs = c.generatedConversionFuncs.Merge(NewConversionFuncs())
+		copied.ignoredUntypedConversions = make(map[typePair]struct{}, len(c.ignoredUntypedConversions))
+		for pair := range c.ignoredUntypedConversions {
+			copied.ignoredUntypedConversions[pair] = struct{}{}
+		}
+	}
 	return &copied
 }
 
@@ -164,6 +213,8 @@ func (s *scope) Meta() *Meta {
 // types to the provided function. The function *must* accept objects of a and b - this machinery will not enforce
 // any other guarantee.
 func (c *Converter) RegisterUntypedConversionFunc(a, b interface{}, fn ConversionFunc) error {
+	c.wLock()
+	defer c.wUnlock()
 	return c.conversionFuncs.AddUntyped(a, b, fn)
 }
 
@@ -171,6 +222,8 @@ func (c *Converter) RegisterUntypedConversionFunc(a, b interface{}, fn Conversio
 // types to the provided function. The function *must* accept objects of a and b - this machinery will not enforce
 // any other guarantee.
 func (c *Converter) RegisterGeneratedUntypedConversionFunc(a, b interface{}, fn ConversionFunc) error {
+	c.wLock()
+	defer c.wUnlock()
 	return c.generatedConversionFuncs.AddUntyped(a, b, fn)
 }
 
@@ -185,10 +238,28 @@ func (c *Converter) RegisterIgnoredConversion(from, to interface{}) error {
 	if typeTo.Kind() != reflect.Ptr {
 		return fmt.Errorf("expected pointer arg for 'to' param 1, got: %v", typeTo)
 	}
+	c.wLock()
+	defer c.wUnlock()
 	c.ignoredUntypedConversions[typePair{typeFrom, typeTo}] = struct{}{}
 	return nil
 }
 
+// HasConversionFunc returns true if a conversion function, generated or not, or a no-op conversion
+// is registered from the type of a to the type of b. Both must be pointers.
+func (c *Converter) HasConversionFunc(a, b interface{}) bool {
+	pair := typePair{reflect.TypeOf(a), reflect.TypeOf(b)}
+	c.rLock()
+	defer c.rUnlock()
+	if _, ok := c.ignoredUntypedConversions[pair]; ok {
+		return true
+	}
+	if _, ok := c.conversionFuncs.untyped[pair]; ok {
+		return true
+	}
+	_, ok := c.generatedConversionFuncs.untyped[pair]
+	return ok
+}
+
 // Convert will translate src to dest if it knows how. Both must be pointers.
 // If no conversion func is registered and the default copying mechanism
 // doesn't work on this type pair, an error will be returned.
@@ -203,13 +274,13 @@ func (c *Converter) Convert(src, dest interface{}, meta *Meta) error {
 	}
 
 	// ignore conversions of this type
-	if _, ok := c.ignoredUntypedConversions[pair]; ok {
+	c.rLock()
+	_, ignored := c.ignoredUntypedConversions[pair]
+	c.rUnlock()
+	if ignored {
 		return nil
 	}
-	if fn, ok := c.conversionFuncs.untyped[pair]; ok {
-		return fn(src, dest, scope)
-	}
-	if fn, ok := c.generatedConversionFuncs.untyped[pair]; ok {
+	if fn, ok := c.conversionFunc(pair); ok {
 		return fn(src, dest, scope)
 	}
 
@@ -223,3 +294,15 @@ func (c *Converter) Convert(src, dest interface{}, meta *Meta) error {
 	}
 	return fmt.Errorf("converting (%s) to (%s): unknown conversion", sv.Type(), dv.Type())
 }
+
+// conversionFunc returns the conversion func registered for pair, preferring manual conversion
+// funcs over generated ones.
+func (c *Converter) conversionFunc(pair typePair) (ConversionFunc, bool) {
+	c.rLock()
+	defer c.rUnlock()
+	if fn, ok := c.conversionFuncs.untyped[pair]; ok {
+		return fn, true
+	}
+	fn, ok := c.generatedConversionFuncs.untyped[pair]
+	return fn, ok
+}
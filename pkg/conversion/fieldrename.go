@@ -0,0 +1,177 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package conversion
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	utiljson "k8s.io/apimachinery/pkg/util/json"
+)
+
+// FieldValueTransform transforms the value of a renamed field. The value is in its unstructured
+// form, as decoded from JSON.
+type FieldValueTransform func(value interface{}) (interface{}, error)
+
+// FieldRename is the destination of a field renamed by a FieldRenameMap.
+type FieldRename struct {
+	// To is the path of the field in the destination, in the form ".spec.newName".
+	To string
+	// Transform optionally transforms the value of the field.
+	Transform FieldValueTransform
+}
+
+// FieldRenameMap moves fields between two versions of an object, keyed by the path of the field
+// in the source, in the form ".spec.oldName". Paths only address fields of nested objects, not
+// items of lists. Fields missing from the source are skipped, and all fields are removed before
+// any is set, so that fields can be swapped.
+type FieldRenameMap map[string]FieldRename
+
+// unstructuredObject is implemented by unstructured objects and lists.
+type unstructuredObject interface {
+	UnstructuredContent() map[string]interface{}
+	SetUnstructuredContent(map[string]interface{})
+}
+
+// Apply renames the fields of the unstructured content obj in place.
+func (m FieldRenameMap) Apply(obj map[string]interface{}) error {
+	froms := make([]string, 0, len(m))
+	for from := range m {
+		froms = append(froms, from)
+	}
+	sort.Strings(froms)
+
+	type move struct {
+		to    []string
+		value interface{}
+	}
+	moves := make([]move, 0, len(froms))
+	for _, from := range froms {
+		rename := m[from]
+		fromPath, err := parseFieldPath(from)
+		if err != nil {
+			return err
+		}
+		toPath, err := parseFieldPath(rename.To)
+		if err != nil {
+			return err
+		}
+		value, found := removeField(obj, fromPath)
+		if !found {
+			continue
+		}
+		if rename.Transform != nil {
+			if value, err = rename.Transform(value); err != nil {
+				return fmt.Errorf("unable to transform %s: %v", from, err)
+			}
+		}
+		moves = append(moves, move{to: toPath, value: value})
+	}
+	for _, mv := range moves {
+		if err := setField(obj, mv.to, mv.value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Convert converts in into out, renaming fields. in and out can be typed objects, which are
+// converted through their JSON representation, unstructured objects or unstructured content.
+// in is not modified.
+func (m FieldRenameMap) Convert(in, out interface{}) error {
+	if u, ok := in.(unstructuredObject); ok {
+		in = u.UnstructuredContent()
+	}
+	data, err := json.Marshal(in)
+	if err != nil {
+		return err
+	}
+	content := map[string]interface{}{}
+	if err := utiljson.Unmarshal(data, &content); err != nil {
+		return err
+	}
+	if err := m.Apply(content); err != nil {
+		return err
+	}
+
+	switch t := out.(type) {
+	case unstructuredObject:
+		t.SetUnstructuredContent(content)
+		return nil
+	case *map[string]interface{}:
+		*t = content
+		return nil
+	}
+	if data, err = json.Marshal(content); err != nil {
+		return err
+	}
+	return json.Unmarshal(data, out)
+}
+
+// ConversionFunc returns a ConversionFunc converting with Convert, to be registered with
+// RegisterUntypedConversionFunc.
+func (m FieldRenameMap) ConversionFunc() ConversionFunc {
+	return func(a, b interface{}, _ Scope) error {
+		return m.Convert(a, b)
+	}
+}
+
+func parseFieldPath(path string) ([]string, error) {
+	if !strings.HasPrefix(path, ".") || len(path) == 1 {
+		return nil, fmt.Errorf("invalid field path %q, must be of the form .field.subfield", path)
+	}
+	fields := strings.Split(path[1:], ".")
+	for _, f := range fields {
+		if len(f) == 0 {
+			return nil, fmt.Errorf("invalid field path %q, field names must not be empty", path)
+		}
+	}
+	return fields, nil
+}
+
+func removeField(obj map[string]interface{}, path []string) (interface{}, bool) {
+	for _, f := range path[:len(path)-1] {
+		next, ok := obj[f].(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		obj = next
+	}
+	last := path[len(path)-1]
+	value, ok := obj[last]
+	delete(obj, last)
+	return value, ok
+}
+
+func setField(obj map[string]interface{}, path []string, value interface{}) error {
+	for i, f := range path[:len(path)-1] {
+		switch next := obj[f].(type) {
+		case map[string]interface{}:
+			obj = next
+		case nil:
+			created := map[string]interface{}{}
+			obj[f] = created
+			obj = created
+		default:
+			return fmt.Errorf("unable to set .%s: .%s is not an object", strings.Join(path, "."), strings.Join(path[:i+1], "."))
+		}
+	}
+	obj[path[len(path)-1]] = value
+	return nil
+}
@@ -0,0 +1,147 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package conversion
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type renameSourceV1 struct {
+	Spec renameSourceSpecV1 `json:"spec"`
+}
+
+type renameSourceSpecV1 struct {
+	OldName  string `json:"oldName,omitempty"`
+	Replicas int32  `json:"replicas,omitempty"`
+	Paused   bool   `json:"paused,omitempty"`
+}
+
+type renameDestV2 struct {
+	Spec   renameDestSpecV2   `json:"spec"`
+	Status renameDestStatusV2 `json:"status"`
+}
+
+type renameDestSpecV2 struct {
+	NewName  string `json:"newName,omitempty"`
+	Replicas int32  `json:"replicas,omitempty"`
+}
+
+type renameDestStatusV2 struct {
+	Suspended string `json:"suspended,omitempty"`
+}
+
+type fakeUnstructured struct {
+	content map[string]interface{}
+}
+
+func (f *fakeUnstructured) UnstructuredContent() map[string]interface{} { return f.content }
+func (f *fakeUnstructured) SetUnstructuredContent(content map[string]interface{}) {
+	f.content = content
+}
+
+func TestFieldRenameMapApply(t *testing.T) {
+	obj := map[string]interface{}{
+		"spec": map[string]interface{}{
+			"a":    "first",
+			"b":    "second",
+			"size": int64(2),
+		},
+	}
+	renames := FieldRenameMap{
+		".spec.a":       {To: ".spec.b"},
+		".spec.b":       {To: ".spec.a"},
+		".spec.size":    {To: ".status.capacity.size", Transform: func(v interface{}) (interface{}, error) { return v.(int64) * 10, nil }},
+		".spec.missing": {To: ".spec.other"},
+	}
+	if err := renames.Apply(obj); err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]interface{}{
+		"spec":   map[string]interface{}{"a": "second", "b": "first"},
+		"status": map[string]interface{}{"capacity": map[string]interface{}{"size": int64(20)}},
+	}
+	if !reflect.DeepEqual(expected, obj) {
+		t.Errorf("expected %v, got %v", expected, obj)
+	}
+}
+
+func TestFieldRenameMapErrors(t *testing.T) {
+	testCases := []struct {
+		renames  FieldRenameMap
+		expected string
+	}{
+		{FieldRenameMap{"spec.a": {To: ".spec.b"}}, "invalid field path"},
+		{FieldRenameMap{".spec.a": {To: ".spec..b"}}, "must not be empty"},
+		{FieldRenameMap{".spec.a": {To: ".spec.name.b"}}, "is not an object"},
+		{FieldRenameMap{".spec.a": {To: ".spec.b", Transform: func(interface{}) (interface{}, error) { return nil, fmt.Errorf("bad value") }}}, "bad value"},
+	}
+	for _, tc := range testCases {
+		obj := map[string]interface{}{"spec": map[string]interface{}{"a": "value", "name": "foo"}}
+		if err := tc.renames.Apply(obj); err == nil || !strings.Contains(err.Error(), tc.expected) {
+			t.Errorf("%v: expected an error containing %q, got %v", tc.renames, tc.expected, err)
+		}
+	}
+}
+
+func TestFieldRenameMapConvert(t *testing.T) {
+	renames := FieldRenameMap{
+		".spec.oldName": {To: ".spec.newName"},
+		".spec.paused": {To: ".status.suspended", Transform: func(v interface{}) (interface{}, error) {
+			return fmt.Sprintf("%v", v), nil
+		}},
+	}
+	in := &renameSourceV1{Spec: renameSourceSpecV1{OldName: "foo", Replicas: 3, Paused: true}}
+
+	typed := &renameDestV2{}
+	if err := renames.Convert(in, typed); err != nil {
+		t.Fatal(err)
+	}
+	if e := (&renameDestV2{Spec: renameDestSpecV2{NewName: "foo", Replicas: 3}, Status: renameDestStatusV2{Suspended: "true"}}); !reflect.DeepEqual(e, typed) {
+		t.Errorf("expected %#v, got %#v", e, typed)
+	}
+	if in.Spec.OldName != "foo" {
+		t.Errorf("expected the source to be unmodified, got %#v", in)
+	}
+
+	unstructured := &fakeUnstructured{}
+	if err := renames.Convert(in, unstructured); err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]interface{}{
+		"spec":   map[string]interface{}{"newName": "foo", "replicas": int64(3)},
+		"status": map[string]interface{}{"suspended": "true"},
+	}
+	if !reflect.DeepEqual(expected, unstructured.content) {
+		t.Errorf("expected %v, got %v", expected, unstructured.content)
+	}
+
+	back := &renameSourceV1{}
+	inverse := FieldRenameMap{".spec.newName": {To: ".spec.oldName"}}
+	c := NewConverter(DefaultNameFunc)
+	if err := c.RegisterUntypedConversionFunc((*fakeUnstructured)(nil), (*renameSourceV1)(nil), inverse.ConversionFunc()); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Convert(unstructured, back, nil); err != nil {
+		t.Fatal(err)
+	}
+	if e := (&renameSourceV1{Spec: renameSourceSpecV1{OldName: "foo", Replicas: 3}}); !reflect.DeepEqual(e, back) {
+		t.Errorf("expected %#v, got %#v", e, back)
+	}
+}
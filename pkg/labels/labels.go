@@ -21,6 +21,7 @@ import (
 	"sort"
 	"strings"
 
+	"k8s.io/apimachinery/pkg/util/intern"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 )
 
@@ -125,6 +126,20 @@ func Merge(labels1, labels2 Set) Set {
 	return mergedMap
 }
 
+// CopyInterned returns a copy of ls whose keys and values are interned by interner, so that
+// copies of the labels of many objects share the storage of recurring keys and values.
+// A nil interner returns a plain copy.
+func CopyInterned(ls Set, interner *intern.Interner) Set {
+	if ls == nil {
+		return nil
+	}
+	copied := make(Set, len(ls))
+	for k, v := range ls {
+		copied[interner.Intern(k)] = interner.Intern(v)
+	}
+	return copied
+}
+
 // Equals returns true if the given maps are equal
 func Equals(labels1, labels2 Set) bool {
 	if len(labels1) != len(labels2) {
@@ -18,6 +18,8 @@ package labels
 
 import (
 	"testing"
+
+	"k8s.io/apimachinery/pkg/util/intern"
 )
 
 func matches(t *testing.T, ls Set, want string) {
@@ -229,3 +231,25 @@ func TestLabelSelectorParse(t *testing.T) {
 		}
 	}
 }
+
+func TestCopyInterned(t *testing.T) {
+	interner := intern.New()
+	original := Set{"app": "web", "tier": "web"}
+	copied := CopyInterned(original, interner)
+	if !Equals(original, copied) {
+		t.Errorf("expected %v, got %v", original, copied)
+	}
+	copied["app"] = "db"
+	if original["app"] != "web" {
+		t.Errorf("expected a copy")
+	}
+	if interner.Len() != 3 {
+		t.Errorf("expected 3 interned strings, got %d", interner.Len())
+	}
+	if CopyInterned(nil, interner) != nil {
+		t.Errorf("expected nil for a nil set")
+	}
+	if copied := CopyInterned(original, nil); !Equals(original, copied) {
+		t.Errorf("expected a plain copy for a nil interner, got %v", copied)
+	}
+}
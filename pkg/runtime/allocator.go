@@ -0,0 +1,104 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package runtime
+
+import (
+	"io"
+	"sync"
+)
+
+// AllocatorPool stores Allocator objects so that the buffers they hold can be reused
+// across serializations, relieving pressure on the garbage collector.
+//
+// Usage:
+//
+//	memoryAllocator := runtime.AllocatorPool.Get().(*runtime.Allocator)
+//	defer runtime.AllocatorPool.Put(memoryAllocator)
+var AllocatorPool = sync.Pool{
+	New: func() interface{} {
+		return &Allocator{}
+	},
+}
+
+// WithAllocator returns an Encoder that encodes with e, passing memAlloc to EncodeWithAllocator if e
+// implements EncoderWithAllocator, such as the JSON and protobuf serializers and the codecs returned by
+// CodecFactory. This lets callers holding a plain Encoder reuse buffers across Encode calls:
+//
+//	memoryAllocator := runtime.AllocatorPool.Get().(*runtime.Allocator)
+//	defer runtime.AllocatorPool.Put(memoryAllocator)
+//	err := runtime.WithAllocator(codec, memoryAllocator).Encode(obj, w)
+//
+// If e doesn't implement EncoderWithAllocator or memAlloc is nil, e is returned.
+func WithAllocator(e Encoder, memAlloc MemoryAllocator) Encoder {
+	encoder, ok := e.(EncoderWithAllocator)
+	if !ok || memAlloc == nil {
+		return e
+	}
+	return allocatorEncoder{encoder: encoder, memAlloc: memAlloc}
+}
+
+type allocatorEncoder struct {
+	encoder  EncoderWithAllocator
+	memAlloc MemoryAllocator
+}
+
+func (e allocatorEncoder) Encode(obj Object, w io.Writer) error {
+	return e.encoder.EncodeWithAllocator(obj, w, e.memAlloc)
+}
+
+func (e allocatorEncoder) Identifier() Identifier {
+	return e.encoder.Identifier()
+}
+
+// Allocator knows how to allocate memory. It exists to make the cost of object
+// serialization cheaper: once its buffer is large enough, the same block of memory
+// is handed out on every call.
+//
+// An Allocator is not safe for concurrent use, and the memory returned by Allocate
+// is only valid until the next call to Allocate.
+type Allocator struct {
+	buf []byte
+}
+
+var _ MemoryAllocator = &Allocator{}
+
+// Allocate reserves memory for n bytes only if the underlying array doesn't have enough capacity,
+// otherwise it returns the previously allocated block of memory.
+//
+// Note that the returned array is not zeroed, it is the caller's
+// responsibility to clean the memory if needed.
+func (a *Allocator) Allocate(n uint64) []byte {
+	if uint64(cap(a.buf)) >= n {
+		a.buf = a.buf[:n]
+		return a.buf
+	}
+	// grow the buffer
+	size := uint64(2*cap(a.buf)) + n
+	a.buf = make([]byte, size)
+	a.buf = a.buf[:n]
+	return a.buf
+}
+
+// SimpleAllocator is a wrapper around make([]byte) that conforms to the MemoryAllocator interface.
+type SimpleAllocator struct{}
+
+var _ MemoryAllocator = &SimpleAllocator{}
+
+// Allocate returns a newly allocated slice of n bytes.
+func (sa *SimpleAllocator) Allocate(n uint64) []byte {
+	return make([]byte, n)
+}
@@ -0,0 +1,128 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package runtime
+
+import (
+	"bytes"
+	"io"
+	"testing"
+)
+
+func TestAllocatorRandomInputs(t *testing.T) {
+	maxBytes := 5 * 1000000 // 5 MB
+	iterations := 1000
+	target := &Allocator{}
+
+	for i := 0; i < iterations; i++ {
+		bytesToAllocate := uint64(i * maxBytes / iterations)
+		buff := target.Allocate(bytesToAllocate)
+		if uint64(cap(buff)) < bytesToAllocate {
+			t.Fatalf("expected the buffer to allocate: %v bytes whereas it allocated: %v bytes", bytesToAllocate, cap(buff))
+		}
+		if uint64(len(buff)) != bytesToAllocate {
+			t.Fatalf("unexpected length of the buffer, expected: %v, got: %v", bytesToAllocate, len(buff))
+		}
+	}
+}
+
+func TestAllocatorNeverShrinks(t *testing.T) {
+	target := &Allocator{}
+	initialSize := 1000000 // 1MB
+	initialBuff := target.Allocate(uint64(initialSize))
+	if cap(initialBuff) < initialSize {
+		t.Fatalf("unexpected size of the buffer, expected at least 1MB, got: %v", cap(initialBuff))
+	}
+
+	for i := initialSize; i > 0; i = i / 10 {
+		newBuff := target.Allocate(uint64(i))
+		if cap(newBuff) < initialSize {
+			t.Fatalf("allocator is now allowed to shrink memory")
+		}
+		if len(newBuff) != i {
+			t.Fatalf("unexpected length of the buffer, expected: %v, got: %v", i, len(newBuff))
+		}
+	}
+}
+
+func TestAllocatorZero(t *testing.T) {
+	target := &Allocator{}
+	initialSize := 1000000 // 1MB
+	buff := target.Allocate(uint64(initialSize))
+	if cap(buff) < initialSize {
+		t.Fatalf("unexpected size of the buffer, expected at least 1MB, got: %v", cap(buff))
+	}
+	if len(buff) != initialSize {
+		t.Fatalf("unexpected length of the buffer, expected: %v, got: %v", initialSize, len(buff))
+	}
+
+	buff = target.Allocate(0)
+	if cap(buff) < initialSize {
+		t.Fatalf("unexpected size of the buffer, expected at least 1MB, got: %v", cap(buff))
+	}
+	if len(buff) != 0 {
+		t.Fatalf("unexpected length of the buffer, expected: 0, got: %v", len(buff))
+	}
+}
+
+type allocatingEncoder struct {
+	memAllocs []MemoryAllocator
+}
+
+func (e *allocatingEncoder) Encode(obj Object, w io.Writer) error {
+	return e.EncodeWithAllocator(obj, w, nil)
+}
+
+func (e *allocatingEncoder) EncodeWithAllocator(obj Object, w io.Writer, memAlloc MemoryAllocator) error {
+	e.memAllocs = append(e.memAllocs, memAlloc)
+	_, err := w.Write([]byte("encoded"))
+	return err
+}
+
+func (e *allocatingEncoder) Identifier() Identifier {
+	return "allocating"
+}
+
+type plainEncoder struct{}
+
+func (plainEncoder) Encode(obj Object, w io.Writer) error { return nil }
+func (plainEncoder) Identifier() Identifier               { return "plain" }
+
+func TestWithAllocator(t *testing.T) {
+	if e := WithAllocator(plainEncoder{}, &Allocator{}); e != (plainEncoder{}) {
+		t.Errorf("expected encoders without allocator support to be returned as is, got %#v", e)
+	}
+	encoder := &allocatingEncoder{}
+	if e := WithAllocator(encoder, nil); e != encoder {
+		t.Errorf("expected the encoder to be returned as is without an allocator, got %#v", e)
+	}
+
+	memAlloc := &Allocator{}
+	wrapped := WithAllocator(encoder, memAlloc)
+	if e, a := encoder.Identifier(), wrapped.Identifier(); e != a {
+		t.Errorf("expected identifier %q, got %q", e, a)
+	}
+	buf := &bytes.Buffer{}
+	if err := wrapped.Encode(&Unknown{}, buf); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != "encoded" {
+		t.Errorf("unexpected output %q", buf.String())
+	}
+	if len(encoder.memAllocs) != 1 || encoder.memAllocs[0] != memAlloc {
+		t.Errorf("expected the allocator to be passed to EncodeWithAllocator, got %#v", encoder.memAllocs)
+	}
+}
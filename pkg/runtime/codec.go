@@ -71,6 +71,35 @@ func DecodeInto(d Decoder, data []byte, into Object) error {
 	return nil
 }
 
+// DecodeT decodes data with d, without a default kind or object to decode into, and returns the
+// result as a T. It fails if data decodes to an object of another type.
+func DecodeT[T Object](d Decoder, data []byte) (T, error) {
+	obj, _, err := d.Decode(data, nil, nil)
+	if err != nil {
+		var t T
+		return t, err
+	}
+	return AsTyped[T](obj)
+}
+
+// AsTyped returns obj as a T, or an error naming the expected type and the type and kind of obj
+// if it is of another type, or nil.
+func AsTyped[T Object](obj Object) (T, error) {
+	typed, ok := obj.(T)
+	if ok {
+		return typed, nil
+	}
+	expected := reflect.TypeOf((*T)(nil)).Elem()
+	if obj == nil {
+		return typed, fmt.Errorf("expected %v, got nil", expected)
+	}
+	kind := obj.GetObjectKind().GroupVersionKind()
+	if kind.Empty() {
+		return typed, fmt.Errorf("expected %v, got %T", expected, obj)
+	}
+	return typed, fmt.Errorf("expected %v, got %T (%v)", expected, obj, kind)
+}
+
 // EncodeOrDie is a version of Encode which will panic instead of returning an error. For tests.
 func EncodeOrDie(e Encoder, obj Object) string {
 	bytes, err := Encode(e, obj)
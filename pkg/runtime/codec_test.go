@@ -18,10 +18,12 @@ package runtime_test
 
 import (
 	"io"
+	"strings"
 	"testing"
 
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
 	runtimetesting "k8s.io/apimachinery/pkg/runtime/testing"
 )
 
@@ -102,3 +104,57 @@ func TestCacheableObject(t *testing.T) {
 	serializer := runtime.NewBase64Serializer(&mockEncoder{}, nil)
 	runtimetesting.CacheableObjectTest(t, serializer)
 }
+
+func TestDecodeT(t *testing.T) {
+	internalGV := schema.GroupVersion{Group: "test.group", Version: runtime.APIVersionInternal}
+	externalGV := schema.GroupVersion{Group: "test.group", Version: "testExternal"}
+	scheme := runtime.NewScheme()
+	scheme.AddKnownTypes(internalGV, &runtimetesting.InternalSimple{})
+	scheme.AddKnownTypeWithName(externalGV.WithKind("Simple"), &runtimetesting.ExternalSimple{})
+	codecs := serializer.NewCodecFactory(scheme)
+	decoder := codecs.UniversalDeserializer()
+
+	data := []byte(`{"apiVersion":"test.group/testExternal","kind":"Simple","testString":"foo"}`)
+	simple, err := runtime.DecodeT[*runtimetesting.ExternalSimple](decoder, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if simple.TestString != "foo" {
+		t.Errorf("unexpected object %#v", simple)
+	}
+
+	_, err = runtime.DecodeT[*runtimetesting.InternalSimple](decoder, data)
+	if err == nil || err.Error() != "expected *testing.InternalSimple, got *testing.ExternalSimple (test.group/testExternal, Kind=Simple)" {
+		t.Errorf("unexpected error %v", err)
+	}
+	if _, err := runtime.DecodeT[*runtimetesting.ExternalSimple](decoder, []byte(`{"kind":"Missing"}`)); err == nil {
+		t.Errorf("expected a decoding error")
+	}
+}
+
+func TestAsTyped(t *testing.T) {
+	obj := runtime.Object(&runtimetesting.ExternalSimple{TestString: "foo"})
+	if simple, err := runtime.AsTyped[*runtimetesting.ExternalSimple](obj); err != nil || simple != obj {
+		t.Errorf("expected %v, got %v (%v)", obj, simple, err)
+	}
+	// interfaces are accepted
+	if _, err := runtime.AsTyped[runtime.Object](obj); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+
+	testCases := []struct {
+		obj      runtime.Object
+		expected string
+	}{
+		{obj: nil, expected: "expected *testing.InternalSimple, got nil"},
+		{obj: obj, expected: "expected *testing.InternalSimple, got *testing.ExternalSimple"},
+	}
+	for _, tc := range testCases {
+		if _, err := runtime.AsTyped[*runtimetesting.InternalSimple](tc.obj); err == nil || err.Error() != tc.expected {
+			t.Errorf("expected error %q, got %v", tc.expected, err)
+		}
+	}
+	if _, err := runtime.AsTyped[runtime.Unstructured](obj); err == nil || !strings.HasPrefix(err.Error(), "expected runtime.Unstructured") {
+		t.Errorf("unexpected error %v", err)
+	}
+}
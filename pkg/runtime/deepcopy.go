@@ -0,0 +1,418 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package runtime
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DeepCopy returns a deep copy of in. It is intended for simple API types that do not
+// have generated deep copy functions: maps, slices, arrays, pointers and interfaces are
+// copied recursively, while time.Time and values without references are copied by value.
+//
+// Any type in the graph whose pointer has a generated DeepCopyInto method (as produced by
+// deepcopy-gen) is copied with that method instead of with reflection, so nested
+// metav1.ObjectMeta, metav1.Time or resource.Quantity values take the generated fast path.
+// Consequently, DeepCopyInto must not be implemented in terms of DeepCopy, while DeepCopy
+// and DeepCopyObject may be:
+//
+//	func (in *Foo) DeepCopyObject() runtime.Object {
+//		return runtime.DeepCopy(in)
+//	}
+//
+// Unexported struct fields are copied by value, so any references they hold are shared
+// between in and the returned copy. Values with reference cycles are not supported.
+func DeepCopy[T any](in T) T {
+	var out T
+	src, dst := reflect.ValueOf(&in).Elem(), reflect.ValueOf(&out).Elem()
+	deepCopyFuncFor(src.Type())(dst, src)
+	return out
+}
+
+// deepCopyFunc copies src into dst, which must be settable.
+type deepCopyFunc func(dst, src reflect.Value)
+
+type deepCopyCacheMap map[reflect.Type]deepCopyFunc
+
+type deepCopyCache struct {
+	sync.Mutex
+	value atomic.Value
+}
+
+func newDeepCopyCache() *deepCopyCache {
+	cache := &deepCopyCache{}
+	cache.value.Store(make(deepCopyCacheMap))
+	return cache
+}
+
+var (
+	deepCopyFuncs = newDeepCopyCache()
+	timeType      = reflect.TypeOf(time.Time{})
+)
+
+// deepCopyFuncFor returns the cached copy function for t, computing it on a cache miss.
+func deepCopyFuncFor(t reflect.Type) deepCopyFunc {
+	if fn, ok := deepCopyFuncs.value.Load().(deepCopyCacheMap)[t]; ok {
+		return fn
+	}
+
+	// Cache miss - compute the function outside of the lock, the result does not depend
+	// on who computes it.
+	fn := newDeepCopyFunc(t, map[reflect.Type]*deepCopyFunc{})
+
+	deepCopyFuncs.Lock()
+	defer deepCopyFuncs.Unlock()
+	cacheMap := deepCopyFuncs.value.Load().(deepCopyCacheMap)
+	newCacheMap := make(deepCopyCacheMap, len(cacheMap)+1)
+	for k, v := range cacheMap {
+		newCacheMap[k] = v
+	}
+	newCacheMap[t] = fn
+	deepCopyFuncs.value.Store(newCacheMap)
+	return fn
+}
+
+// newDeepCopyFunc builds the copy function for t. inProgress holds the types whose functions are
+// being built further up the stack, which allows recursive types such as trees to be described.
+func newDeepCopyFunc(t reflect.Type, inProgress map[reflect.Type]*deepCopyFunc) deepCopyFunc {
+	if fn, ok := deepCopyFuncs.value.Load().(deepCopyCacheMap)[t]; ok {
+		return fn
+	}
+	if fn, ok := inProgress[t]; ok {
+		return func(dst, src reflect.Value) { (*fn)(dst, src) }
+	}
+	fn := new(deepCopyFunc)
+	inProgress[t] = fn
+	defer delete(inProgress, t)
+
+	*fn = buildDeepCopyFunc(t, inProgress)
+	return *fn
+}
+
+func buildDeepCopyFunc(t reflect.Type, inProgress map[reflect.Type]*deepCopyFunc) deepCopyFunc {
+	if t == timeType || !hasReferences(t, map[reflect.Type]bool{}) {
+		return copyValue
+	}
+	if fn, ok := generatedDeepCopyInto(t); ok {
+		return fn
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		elemFn := newDeepCopyFunc(t.Elem(), inProgress)
+		return func(dst, src reflect.Value) {
+			if src.IsNil() {
+				dst.Set(reflect.Zero(t))
+				return
+			}
+			out := reflect.New(t.Elem())
+			elemFn(out.Elem(), src.Elem())
+			dst.Set(out)
+		}
+
+	case reflect.Slice:
+		elemFn := newDeepCopyFunc(t.Elem(), inProgress)
+		return func(dst, src reflect.Value) {
+			if src.IsNil() {
+				dst.Set(reflect.Zero(t))
+				return
+			}
+			out := reflect.MakeSlice(t, src.Len(), src.Len())
+			for i := 0; i < src.Len(); i++ {
+				elemFn(out.Index(i), src.Index(i))
+			}
+			dst.Set(out)
+		}
+
+	case reflect.Array:
+		elemFn := newDeepCopyFunc(t.Elem(), inProgress)
+		return func(dst, src reflect.Value) {
+			for i := 0; i < src.Len(); i++ {
+				elemFn(dst.Index(i), src.Index(i))
+			}
+		}
+
+	case reflect.Map:
+		keyFn := newDeepCopyFunc(t.Key(), inProgress)
+		elemFn := newDeepCopyFunc(t.Elem(), inProgress)
+		return func(dst, src reflect.Value) {
+			if src.IsNil() {
+				dst.Set(reflect.Zero(t))
+				return
+			}
+			out := reflect.MakeMapWithSize(t, src.Len())
+			iter := src.MapRange()
+			for iter.Next() {
+				key := reflect.New(t.Key()).Elem()
+				keyFn(key, iter.Key())
+				elem := reflect.New(t.Elem()).Elem()
+				elemFn(elem, iter.Value())
+				out.SetMapIndex(key, elem)
+			}
+			dst.Set(out)
+		}
+
+	case reflect.Interface:
+		return func(dst, src reflect.Value) {
+			if src.IsNil() {
+				dst.Set(reflect.Zero(t))
+				return
+			}
+			concrete := src.Elem()
+			out := reflect.New(concrete.Type()).Elem()
+			deepCopyFuncFor(concrete.Type())(out, concrete)
+			dst.Set(out)
+		}
+
+	case reflect.Struct:
+		type fieldCopy struct {
+			index int
+			fn    deepCopyFunc
+		}
+		var fields []fieldCopy
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if len(field.PkgPath) > 0 || !hasReferences(field.Type, map[reflect.Type]bool{}) {
+				// unexported fields and fields without references are covered by the value copy below
+				continue
+			}
+			fields = append(fields, fieldCopy{index: i, fn: newDeepCopyFunc(field.Type, inProgress)})
+		}
+		return func(dst, src reflect.Value) {
+			dst.Set(src)
+			for _, field := range fields {
+				field.fn(dst.Field(field.index), src.Field(field.index))
+			}
+		}
+
+	default:
+		// channels, functions and unsafe pointers can not be meaningfully copied
+		return copyValue
+	}
+}
+
+func copyValue(dst, src reflect.Value) {
+	dst.Set(src)
+}
+
+// generatedDeepCopyInto returns a copy function calling the DeepCopyInto method of *t, if present.
+// Methods promoted from embedded fields are ignored because their argument type does not match.
+func generatedDeepCopyInto(t reflect.Type) (deepCopyFunc, bool) {
+	method, ok := reflect.PtrTo(t).MethodByName("DeepCopyInto")
+	if !ok {
+		return nil, false
+	}
+	methodType := method.Type
+	if methodType.NumIn() != 2 || methodType.In(1) != reflect.PtrTo(t) || methodType.NumOut() != 0 {
+		return nil, false
+	}
+	return func(dst, src reflect.Value) {
+		if !src.CanAddr() {
+			addressable := reflect.New(t).Elem()
+			addressable.Set(src)
+			src = addressable
+		}
+		method.Func.Call([]reflect.Value{src.Addr(), dst.Addr()})
+	}, true
+}
+
+// hasReferences returns true if copying a value of type t by assignment could share memory with
+// the original, through exported or unexported fields. Types such as resource.Quantity, which hold
+// references in unexported fields only, must be copied with their generated DeepCopyInto method.
+// Channels, functions and unsafe pointers count as references, although DeepCopy copies them by
+// value and DeepCopyReflect rejects them.
+func hasReferences(t reflect.Type, visited map[reflect.Type]bool) bool {
+	switch t.Kind() {
+	case reflect.Ptr, reflect.Slice, reflect.Map, reflect.Interface, reflect.Chan, reflect.Func, reflect.UnsafePointer:
+		return true
+	case reflect.Array:
+		return hasReferences(t.Elem(), visited)
+	case reflect.Struct:
+		if t == timeType || visited[t] {
+			return false
+		}
+		visited[t] = true
+		for i := 0; i < t.NumField(); i++ {
+			if hasReferences(t.Field(i).Type, visited) {
+				return true
+			}
+		}
+		return false
+	default:
+		return false
+	}
+}
+
+// DeepCopyReflect returns a deep copy of obj computed with reflection, for objects without generated
+// deep copy functions such as prototypes and test doubles. Unlike DeepCopy, it supports reference
+// cycles: pointers, maps and slices referenced several times in obj are copied once, so that the copy
+// has the same shape as obj. Slices are identified by their first element and length, slices sharing
+// an array with different bounds are copied separately.
+//
+// Rather than sharing memory between obj and the copy, an error is returned if obj holds unexported
+// struct fields with references, or non-nil channels, functions or unsafe pointers. As with DeepCopy,
+// types whose pointer has a generated DeepCopyInto method are copied with that method.
+func DeepCopyReflect(obj Object) (Object, error) {
+	if obj == nil {
+		return nil, nil
+	}
+	c := &reflectCopier{copies: map[reflectCopyKey]reflect.Value{}}
+	out, err := c.copy(reflect.ValueOf(obj))
+	if err != nil {
+		return nil, err
+	}
+	return out.Interface().(Object), nil
+}
+
+// reflectCopyKey identifies a pointer, map or slice already copied by a reflectCopier.
+type reflectCopyKey struct {
+	t       reflect.Type
+	pointer uintptr
+	// len is the length of slices
+	len int
+}
+
+type reflectCopier struct {
+	copies map[reflectCopyKey]reflect.Value
+}
+
+// copy returns a deep copy of src, of the same type.
+func (c *reflectCopier) copy(src reflect.Value) (reflect.Value, error) {
+	t := src.Type()
+	if t == timeType || !hasReferences(t, map[reflect.Type]bool{}) {
+		return src, nil
+	}
+	if fn, ok := generatedDeepCopyInto(t); ok {
+		out := reflect.New(t).Elem()
+		fn(out, src)
+		return out, nil
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		if src.IsNil() {
+			return reflect.Zero(t), nil
+		}
+		key := reflectCopyKey{t: t, pointer: src.Pointer()}
+		if out, ok := c.copies[key]; ok {
+			return out, nil
+		}
+		out := reflect.New(t.Elem())
+		c.copies[key] = out
+		elem, err := c.copy(src.Elem())
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		out.Elem().Set(elem)
+		return out, nil
+
+	case reflect.Map:
+		if src.IsNil() {
+			return reflect.Zero(t), nil
+		}
+		key := reflectCopyKey{t: t, pointer: src.Pointer()}
+		if out, ok := c.copies[key]; ok {
+			return out, nil
+		}
+		out := reflect.MakeMapWithSize(t, src.Len())
+		c.copies[key] = out
+		iter := src.MapRange()
+		for iter.Next() {
+			k, err := c.copy(iter.Key())
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			v, err := c.copy(iter.Value())
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			out.SetMapIndex(k, v)
+		}
+		return out, nil
+
+	case reflect.Slice:
+		if src.IsNil() {
+			return reflect.Zero(t), nil
+		}
+		key := reflectCopyKey{t: t, pointer: src.Pointer(), len: src.Len()}
+		if out, ok := c.copies[key]; ok {
+			return out, nil
+		}
+		out := reflect.MakeSlice(t, src.Len(), src.Len())
+		c.copies[key] = out
+		for i := 0; i < src.Len(); i++ {
+			elem, err := c.copy(src.Index(i))
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			out.Index(i).Set(elem)
+		}
+		return out, nil
+
+	case reflect.Array:
+		out := reflect.New(t).Elem()
+		for i := 0; i < src.Len(); i++ {
+			elem, err := c.copy(src.Index(i))
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			out.Index(i).Set(elem)
+		}
+		return out, nil
+
+	case reflect.Interface:
+		if src.IsNil() {
+			return reflect.Zero(t), nil
+		}
+		elem, err := c.copy(src.Elem())
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		out := reflect.New(t).Elem()
+		out.Set(elem)
+		return out, nil
+
+	case reflect.Struct:
+		out := reflect.New(t).Elem()
+		out.Set(src)
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if !hasReferences(field.Type, map[reflect.Type]bool{}) {
+				continue
+			}
+			if len(field.PkgPath) > 0 {
+				return reflect.Value{}, fmt.Errorf("unable to deep copy %v: unexported field %s holds references", t, field.Name)
+			}
+			value, err := c.copy(src.Field(i))
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			out.Field(i).Set(value)
+		}
+		return out, nil
+
+	default:
+		if src.IsNil() {
+			return reflect.Zero(t), nil
+		}
+		return reflect.Value{}, fmt.Errorf("unable to deep copy %v values", t)
+	}
+}
@@ -0,0 +1,241 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package runtime_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	inf "gopkg.in/inf.v0"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+type deepCopyGenerated struct {
+	Values []string
+	copied *int
+}
+
+func (in *deepCopyGenerated) DeepCopyInto(out *deepCopyGenerated) {
+	*out = *in
+	out.Values = append([]string(nil), in.Values...)
+	if in.copied != nil {
+		*in.copied++
+	}
+}
+
+type deepCopyTree struct {
+	Name     string
+	Children []*deepCopyTree
+}
+
+type deepCopyObject struct {
+	runtime.TypeMeta
+	Labels    map[string]string
+	Ports     []int32
+	Owner     *string
+	Created   time.Time
+	Nested    map[string][]deepCopyTree
+	Arbitrary interface{}
+	Generated deepCopyGenerated
+}
+
+func (in *deepCopyObject) GetObjectKind() schema.ObjectKind { return &in.TypeMeta }
+
+func (in *deepCopyObject) DeepCopyObject() runtime.Object {
+	return runtime.DeepCopy(in)
+}
+
+func TestDeepCopy(t *testing.T) {
+	owner := "owner"
+	copied := 0
+	in := &deepCopyObject{
+		TypeMeta:  runtime.TypeMeta{APIVersion: "test/v1", Kind: "Test"},
+		Labels:    map[string]string{"a": "b"},
+		Ports:     []int32{80, 443},
+		Owner:     &owner,
+		Created:   time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC),
+		Nested:    map[string][]deepCopyTree{"x": {{Name: "root", Children: []*deepCopyTree{{Name: "leaf"}}}}},
+		Arbitrary: map[string]interface{}{"list": []interface{}{"a", int64(1)}},
+		Generated: deepCopyGenerated{Values: []string{"v"}, copied: &copied},
+	}
+
+	out := in.DeepCopyObject().(*deepCopyObject)
+	if !reflect.DeepEqual(in, out) {
+		t.Fatalf("copy differs from original:\n%#v\n%#v", in, out)
+	}
+	if copied != 1 {
+		t.Errorf("expected generated DeepCopyInto to be called once, got %d", copied)
+	}
+
+	out.Labels["a"] = "changed"
+	out.Ports[0] = 8080
+	*out.Owner = "changed"
+	out.Nested["x"][0].Children[0].Name = "changed"
+	out.Arbitrary.(map[string]interface{})["list"].([]interface{})[0] = "changed"
+	out.Generated.Values[0] = "changed"
+
+	if in.Labels["a"] != "b" || in.Ports[0] != 80 || owner != "owner" ||
+		in.Nested["x"][0].Children[0].Name != "leaf" ||
+		in.Arbitrary.(map[string]interface{})["list"].([]interface{})[0] != "a" ||
+		in.Generated.Values[0] != "v" {
+		t.Errorf("mutating the copy changed the original: %#v", in)
+	}
+}
+
+func TestDeepCopyUnexportedReferences(t *testing.T) {
+	type quantities struct {
+		CPU resource.Quantity
+	}
+	// the decimal of the quantity is held by an unexported pointer
+	in := quantities{CPU: *resource.NewDecimalQuantity(*inf.NewDec(15, 1), resource.DecimalSI)}
+	out := runtime.DeepCopy(in)
+	if !reflect.DeepEqual(in, out) {
+		t.Fatalf("copy differs from original:\n%#v\n%#v", in, out)
+	}
+	out.CPU.Add(resource.MustParse("1"))
+	if e, a := "1500m", in.CPU.String(); e != a {
+		t.Errorf("mutating the copy changed the original to %s", a)
+	}
+}
+
+func TestDeepCopyNilAndZeroValues(t *testing.T) {
+	if out := runtime.DeepCopy((*deepCopyObject)(nil)); out != nil {
+		t.Errorf("expected nil, got %#v", out)
+	}
+	var obj runtime.Object
+	if out := runtime.DeepCopy(obj); out != nil {
+		t.Errorf("expected nil, got %#v", out)
+	}
+	out := runtime.DeepCopy(deepCopyObject{})
+	if out.Labels != nil || out.Ports != nil || out.Nested != nil {
+		t.Errorf("expected nil maps and slices to stay nil, got %#v", out)
+	}
+	if out := runtime.DeepCopy(map[string]string{}); out == nil {
+		t.Errorf("expected empty map to stay non-nil")
+	}
+}
+
+func TestDeepCopyInterface(t *testing.T) {
+	var in runtime.Object = &deepCopyObject{Labels: map[string]string{"a": "b"}}
+	out := runtime.DeepCopy(in)
+	if out == in {
+		t.Fatalf("expected a new object")
+	}
+	out.(*deepCopyObject).Labels["a"] = "changed"
+	if in.(*deepCopyObject).Labels["a"] != "b" {
+		t.Errorf("mutating the copy changed the original")
+	}
+}
+
+type deepCopyNode struct {
+	Name   string
+	Parent *deepCopyNode
+	Peers  map[string]*deepCopyNode
+}
+
+type deepCopyCyclicObject struct {
+	runtime.TypeMeta
+	Root  *deepCopyNode
+	Nodes []*deepCopyNode
+}
+
+func (in *deepCopyCyclicObject) GetObjectKind() schema.ObjectKind { return &in.TypeMeta }
+
+func (in *deepCopyCyclicObject) DeepCopyObject() runtime.Object {
+	panic("not implemented")
+}
+
+type deepCopyUnexportedObject struct {
+	runtime.TypeMeta
+	count  int
+	labels map[string]string
+	Notify func()
+}
+
+func (in *deepCopyUnexportedObject) GetObjectKind() schema.ObjectKind { return &in.TypeMeta }
+
+func (in *deepCopyUnexportedObject) DeepCopyObject() runtime.Object {
+	panic("not implemented")
+}
+
+func TestDeepCopyReflect(t *testing.T) {
+	root := &deepCopyNode{Name: "root"}
+	child := &deepCopyNode{Name: "child", Parent: root}
+	root.Peers = map[string]*deepCopyNode{"self": root, "child": child}
+	child.Peers = root.Peers
+	in := &deepCopyCyclicObject{TypeMeta: runtime.TypeMeta{Kind: "Cyclic"}, Root: root, Nodes: []*deepCopyNode{root, child}}
+
+	obj, err := runtime.DeepCopyReflect(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := obj.(*deepCopyCyclicObject)
+	if out.Kind != "Cyclic" || out.Root == root || out.Root.Name != "root" {
+		t.Fatalf("unexpected copy %#v", out)
+	}
+	if out.Nodes[0] != out.Root || out.Root.Peers["self"] != out.Root || out.Nodes[1].Parent != out.Root {
+		t.Errorf("expected the copy to preserve the shared pointers and cycles")
+	}
+	out.Nodes[1].Peers["new"] = out.Root
+	if len(root.Peers) != 2 || len(out.Root.Peers) != 3 {
+		t.Errorf("expected the copied map to be shared within the copy only")
+	}
+
+	// slices and maps referencing themselves through interfaces
+	items := make([]interface{}, 2)
+	items[0], items[1] = "item", items
+	fields := map[string]interface{}{"items": items}
+	fields["self"] = fields
+	obj, err = runtime.DeepCopyReflect(&deepCopyObject{Arbitrary: fields})
+	if err != nil {
+		t.Fatal(err)
+	}
+	copiedFields := obj.(*deepCopyObject).Arbitrary.(map[string]interface{})
+	copiedItems := copiedFields["items"].([]interface{})
+	if reflect.ValueOf(copiedFields["self"]).Pointer() != reflect.ValueOf(copiedFields).Pointer() ||
+		reflect.ValueOf(copiedItems[1]).Pointer() != reflect.ValueOf(copiedItems).Pointer() {
+		t.Errorf("expected the copy to preserve the cycles through interfaces")
+	}
+	copiedItems[0] = "copy"
+	if items[0] != "item" || reflect.ValueOf(copiedItems).Pointer() == reflect.ValueOf(items).Pointer() {
+		t.Errorf("expected the slice to be copied")
+	}
+
+	if _, err := runtime.DeepCopyReflect(&deepCopyUnexportedObject{count: 1}); err == nil {
+		t.Errorf("expected an error for unexported fields with references")
+	}
+	if _, err := runtime.DeepCopyReflect(&deepCopyUnexportedObject{Notify: func() {}}); err == nil {
+		t.Errorf("expected an error for functions")
+	}
+	if obj, err := runtime.DeepCopyReflect(nil); obj != nil || err != nil {
+		t.Errorf("expected nil, got %#v, %v", obj, err)
+	}
+
+	// generated deep copy functions are used
+	copied := 0
+	generated, err := runtime.DeepCopyReflect(&deepCopyObject{Generated: deepCopyGenerated{copied: &copied}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if copied != 1 || generated.(*deepCopyObject).Generated.copied != &copied {
+		t.Errorf("expected generated DeepCopyInto to be called once, got %d", copied)
+	}
+}
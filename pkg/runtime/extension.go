@@ -18,8 +18,10 @@ package runtime
 
 import (
 	"bytes"
+	"container/list"
 	"encoding/json"
 	"errors"
+	"sync"
 )
 
 func (re *RawExtension) UnmarshalJSON(in []byte) error {
@@ -49,3 +51,106 @@ func (re RawExtension) MarshalJSON() ([]byte, error) {
 	// TODO: Check whether ContentType is actually JSON before returning it.
 	return re.Raw, nil
 }
+
+// GetObject returns Object if it is set, and otherwise the object decoded from Raw with decoder,
+// without setting Object. Nil is returned if Raw is empty or null. Use a RawExtensionCache to avoid
+// decoding Raw again on every call.
+func (re *RawExtension) GetObject(decoder Decoder) (Object, error) {
+	if re.Object != nil {
+		return re.Object, nil
+	}
+	if len(re.Raw) == 0 || bytes.Equal(re.Raw, []byte("null")) {
+		return nil, nil
+	}
+	obj, _, err := decoder.Decode(re.Raw, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	return obj, nil
+}
+
+// RawExtensionCache caches the objects decoded from the Raw data of RawExtensions, e.g. for the time
+// an admission request holding extensions is processed. The cache is kept outside of RawExtension,
+// so that the extensions are unchanged and can be compared and copied as before. It holds the objects
+// of a bounded number of extensions, evicting the least recently used ones first.
+type RawExtensionCache struct {
+	decoder    Decoder
+	maxEntries int
+
+	lock    sync.Mutex
+	entries map[*RawExtension]*list.Element
+	// recent holds the entries, most recently used first
+	recent *list.List
+}
+
+// rawExtensionCacheEntry holds the object decoded from the raw data of a RawExtension.
+type rawExtensionCacheEntry struct {
+	extension *RawExtension
+
+	lock sync.Mutex
+	// data and length identify the Raw slice obj was decoded from, without copying it
+	data   *byte
+	length int
+	obj    Object
+}
+
+// NewRawExtensionCache returns a RawExtensionCache decoding extensions with decoder, and caching the
+// objects of up to maxEntries extensions. Nothing is cached if maxEntries isn't positive.
+func NewRawExtensionCache(decoder Decoder, maxEntries int) *RawExtensionCache {
+	return &RawExtensionCache{
+		decoder:    decoder,
+		maxEntries: maxEntries,
+		entries:    map[*RawExtension]*list.Element{},
+		recent:     list.New(),
+	}
+}
+
+// GetObject returns the object of re like re.GetObject does, decoding Raw on first use only. The
+// decoded object is returned by later calls for re until Raw is reassigned. Modifying the data of Raw
+// in place doesn't invalidate the decoded object.
+//
+// GetObject is safe for concurrent use, as long as the Raw and Object fields of re are not modified
+// concurrently. Callers must not modify the returned object, which is shared by all callers, but should
+// copy it instead.
+func (c *RawExtensionCache) GetObject(re *RawExtension) (Object, error) {
+	if re.Object != nil {
+		return re.Object, nil
+	}
+	if len(re.Raw) == 0 || bytes.Equal(re.Raw, []byte("null")) {
+		return nil, nil
+	}
+	if c.maxEntries <= 0 {
+		return re.GetObject(c.decoder)
+	}
+
+	entry := c.entry(re)
+	entry.lock.Lock()
+	defer entry.lock.Unlock()
+	if entry.obj != nil && entry.data == &re.Raw[0] && entry.length == len(re.Raw) {
+		return entry.obj, nil
+	}
+	obj, _, err := c.decoder.Decode(re.Raw, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	entry.data, entry.length, entry.obj = &re.Raw[0], len(re.Raw), obj
+	return obj, nil
+}
+
+// entry returns the cache entry of re, adding it and evicting the least recently used entry if needed.
+func (c *RawExtensionCache) entry(re *RawExtension) *rawExtensionCacheEntry {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if element, ok := c.entries[re]; ok {
+		c.recent.MoveToFront(element)
+		return element.Value.(*rawExtensionCacheEntry)
+	}
+	entry := &rawExtensionCacheEntry{extension: re}
+	c.entries[re] = c.recent.PushFront(entry)
+	if c.recent.Len() > c.maxEntries {
+		oldest := c.recent.Back()
+		c.recent.Remove(oldest)
+		delete(c.entries, oldest.Value.(*rawExtensionCacheEntry).extension)
+	}
+	return entry
+}
@@ -19,10 +19,13 @@ package runtime_test
 import (
 	"bytes"
 	"encoding/json"
+	"fmt"
 	"reflect"
+	"sync"
 	"testing"
 
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 )
 
 func TestEmbeddedRawExtensionMarshal(t *testing.T) {
@@ -111,3 +114,135 @@ func TestEmbeddedRawExtensionRoundTrip(t *testing.T) {
 		}
 	}
 }
+
+type countingDecoder struct {
+	lock  sync.Mutex
+	calls int
+}
+
+func (d *countingDecoder) Decode(data []byte, defaults *schema.GroupVersionKind, into runtime.Object) (runtime.Object, *schema.GroupVersionKind, error) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	d.calls++
+	if !json.Valid(data) {
+		return nil, nil, fmt.Errorf("invalid data %q", data)
+	}
+	return &runtime.Unknown{Raw: append([]byte(nil), data...)}, nil, nil
+}
+
+func TestRawExtensionGetObject(t *testing.T) {
+	decoder := &countingDecoder{}
+	ext := &runtime.RawExtension{Raw: []byte(`{"foo":"bar"}`)}
+	for i := 0; i < 2; i++ {
+		obj, err := ext.GetObject(decoder)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if e, a := `{"foo":"bar"}`, string(obj.(*runtime.Unknown).Raw); e != a {
+			t.Errorf("expected %s, got %s", e, a)
+		}
+	}
+	if decoder.calls != 2 {
+		t.Errorf("expected a decode per call, got %d", decoder.calls)
+	}
+	if ext.Object != nil {
+		t.Errorf("expected Object not to be set, got %#v", ext.Object)
+	}
+
+	ext.Raw = []byte(`{`)
+	if _, err := ext.GetObject(decoder); err == nil {
+		t.Errorf("expected a decoding error")
+	}
+	set := &runtime.Unknown{}
+	for _, ext := range []*runtime.RawExtension{{}, {Raw: []byte("null")}, {Raw: []byte(`{`), Object: set}} {
+		obj, err := ext.GetObject(decoder)
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		if ext.Object == nil && obj != nil || ext.Object != nil && obj != set {
+			t.Errorf("unexpected object %#v for %#v", obj, ext)
+		}
+	}
+}
+
+func TestRawExtensionCache(t *testing.T) {
+	decoder := &countingDecoder{}
+	cache := runtime.NewRawExtensionCache(decoder, 2)
+	ext := &runtime.RawExtension{Raw: []byte(`{"foo":"bar"}`)}
+	original := ext.DeepCopy()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := cache.GetObject(ext); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+	obj, err := cache.GetObject(ext)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e, a := `{"foo":"bar"}`, string(obj.(*runtime.Unknown).Raw); e != a {
+		t.Errorf("expected %s, got %s", e, a)
+	}
+	if decoder.calls != 1 {
+		t.Errorf("expected a single decode, got %d", decoder.calls)
+	}
+	// the extension is left unchanged
+	if !reflect.DeepEqual(original, ext) {
+		t.Errorf("expected the extension to be unchanged, got %#v", ext)
+	}
+
+	// reassigning Raw invalidates the cached object
+	ext.Raw = []byte(`{"foo":"baz"}`)
+	if obj, _ := cache.GetObject(ext); string(obj.(*runtime.Unknown).Raw) != `{"foo":"baz"}` {
+		t.Errorf("expected the object to be decoded from the new data, got %s", obj.(*runtime.Unknown).Raw)
+	}
+	ext.Raw = ext.Raw[:len(ext.Raw)-1]
+	if _, err := cache.GetObject(ext); err == nil {
+		t.Errorf("expected the shortened data to be decoded")
+	}
+	ext.Raw = []byte(`{"foo":"baz"}`)
+	if _, err := cache.GetObject(ext); err != nil {
+		t.Fatal(err)
+	}
+	if decoder.calls != 4 {
+		t.Errorf("expected 4 decodes, got %d", decoder.calls)
+	}
+
+	// copies are cached separately, and the least recently used extensions are evicted
+	other, copied := &runtime.RawExtension{Raw: []byte(`{"foo":"other"}`)}, ext.DeepCopy()
+	for _, re := range []*runtime.RawExtension{copied, ext, other, ext, copied} {
+		if _, err := cache.GetObject(re); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if decoder.calls != 7 {
+		t.Errorf("expected the copy to be decoded twice and the other extension once, got %d decodes", decoder.calls-4)
+	}
+
+	ext.Raw = []byte(`{`)
+	if _, err := cache.GetObject(ext); err == nil {
+		t.Errorf("expected a decoding error")
+	}
+	set := &runtime.Unknown{}
+	if obj, err := cache.GetObject(&runtime.RawExtension{Raw: []byte(`{`), Object: set}); err != nil || obj != set {
+		t.Errorf("expected the set object, got %#v (%v)", obj, err)
+	}
+
+	// nothing is cached without entries
+	cache = runtime.NewRawExtensionCache(decoder, 0)
+	decoder.calls = 0
+	for i := 0; i < 2; i++ {
+		if _, err := cache.GetObject(other); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if decoder.calls != 2 {
+		t.Errorf("expected a decode per call, got %d", decoder.calls)
+	}
+}
@@ -220,10 +220,17 @@ type WithVersionEncoder struct {
 
 // Encode does not do conversion. It sets the gvk during serialization.
 func (e WithVersionEncoder) Encode(obj Object, stream io.Writer) error {
+	return e.EncodeWithAllocator(obj, stream, nil)
+}
+
+// EncodeWithAllocator works like Encode, passing memAlloc to the wrapped Encoder if it implements
+// EncoderWithAllocator.
+func (e WithVersionEncoder) EncodeWithAllocator(obj Object, stream io.Writer, memAlloc MemoryAllocator) error {
+	encoder := WithAllocator(e.Encoder, memAlloc)
 	gvks, _, err := e.ObjectTyper.ObjectKinds(obj)
 	if err != nil {
 		if IsNotRegisteredError(err) {
-			return e.Encoder.Encode(obj, stream)
+			return encoder.Encode(obj, stream)
 		}
 		return err
 	}
@@ -237,7 +244,7 @@ func (e WithVersionEncoder) Encode(obj Object, stream io.Writer) error {
 		}
 	}
 	kind.SetGroupVersionKind(gvk)
-	err = e.Encoder.Encode(obj, stream)
+	err = encoder.Encode(obj, stream)
 	kind.SetGroupVersionKind(oldGVK)
 	return err
 }
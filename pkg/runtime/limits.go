@@ -0,0 +1,69 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package runtime
+
+import (
+	"fmt"
+)
+
+// DecodingLimits bounds the resources serializers spend decoding untrusted data, so that
+// pathological inputs are rejected before they are expanded in memory. A zero value disables
+// the corresponding limit.
+type DecodingLimits struct {
+	// MaxBytes is the maximum size of the data passed to Decode.
+	MaxBytes int64
+	// MaxExpandedBytes is the maximum size of YAML data once its aliases are expanded, measured as
+	// the size of the JSON it converts to.
+	MaxExpandedBytes int64
+	// MaxDocuments is the maximum number of YAML documents in the data, or in a stream of YAML
+	// documents. Data holding a single object should be limited to a single document.
+	MaxDocuments int
+	// MaxDepth is the maximum nesting depth of objects and arrays in JSON and YAML data.
+	MaxDepth int
+}
+
+// CheckSize returns an error if size exceeds MaxBytes.
+func (l DecodingLimits) CheckSize(size int) error {
+	if l.MaxBytes > 0 && int64(size) > l.MaxBytes {
+		return NewDecodingLimitError(fmt.Sprintf("data is %d bytes long, the maximum is %d bytes", size, l.MaxBytes))
+	}
+	return nil
+}
+
+// decodingLimitError is returned by serializers when data exceeds their DecodingLimits.
+type decodingLimitError struct {
+	message string
+}
+
+// NewDecodingLimitError creates a new decodingLimitError object.
+func NewDecodingLimitError(message string) error {
+	return &decodingLimitError{message: message}
+}
+
+func (e *decodingLimitError) Error() string {
+	return "decoding limit exceeded: " + e.message
+}
+
+// IsDecodingLimitError returns true if the error indicates that the decoded data exceeds the
+// DecodingLimits of a serializer.
+func IsDecodingLimitError(err error) bool {
+	if err == nil {
+		return false
+	}
+	_, ok := err.(*decodingLimitError)
+	return ok
+}
@@ -18,6 +18,10 @@ package runtime
 
 import (
 	"fmt"
+	"mime"
+	"sort"
+	"strconv"
+	"strings"
 
 	"k8s.io/apimachinery/pkg/runtime/schema"
 )
@@ -45,7 +49,7 @@ func (n *clientNegotiator) Encoder(contentType string, params map[string]string)
 	// TODO: `pretty=1` is handled in NegotiateOutputMediaType, consider moving it to this method
 	// if client negotiators truly need to use it
 	mediaTypes := n.serializer.SupportedMediaTypes()
-	info, ok := SerializerInfoForMediaType(mediaTypes, contentType)
+	info, ok := serializerInfoForContentType(mediaTypes, contentType)
 	if !ok {
 		if len(contentType) != 0 || len(mediaTypes) == 0 {
 			return nil, NegotiateError{ContentType: contentType}
@@ -57,7 +61,7 @@ func (n *clientNegotiator) Encoder(contentType string, params map[string]string)
 
 func (n *clientNegotiator) Decoder(contentType string, params map[string]string) (Decoder, error) {
 	mediaTypes := n.serializer.SupportedMediaTypes()
-	info, ok := SerializerInfoForMediaType(mediaTypes, contentType)
+	info, ok := serializerInfoForContentType(mediaTypes, contentType)
 	if !ok {
 		if len(contentType) != 0 || len(mediaTypes) == 0 {
 			return nil, NegotiateError{ContentType: contentType}
@@ -69,7 +73,7 @@ func (n *clientNegotiator) Decoder(contentType string, params map[string]string)
 
 func (n *clientNegotiator) StreamDecoder(contentType string, params map[string]string) (Decoder, Serializer, Framer, error) {
 	mediaTypes := n.serializer.SupportedMediaTypes()
-	info, ok := SerializerInfoForMediaType(mediaTypes, contentType)
+	info, ok := serializerInfoForContentType(mediaTypes, contentType)
 	if !ok {
 		if len(contentType) != 0 || len(mediaTypes) == 0 {
 			return nil, nil, nil, NegotiateError{ContentType: contentType, Stream: true}
@@ -82,8 +86,121 @@ func (n *clientNegotiator) StreamDecoder(contentType string, params map[string]s
 	return n.serializer.DecoderToVersion(info.Serializer, n.decode), info.StreamSerializer.Serializer, info.StreamSerializer.Framer, nil
 }
 
+// serializerInfoForContentType returns the serializer for contentType, which is either a media type or
+// a list of media ranges in the format of an Accept header, such as "application/*;q=0.9, */*;q=0.1".
+func serializerInfoForContentType(types []SerializerInfo, contentType string) (SerializerInfo, bool) {
+	if info, ok := SerializerInfoForMediaType(types, contentType); ok {
+		return info, true
+	}
+	if !strings.ContainsAny(contentType, "*,;") {
+		return SerializerInfo{}, false
+	}
+	return NegotiateSerializerInfo(types, contentType)
+}
+
+// AcceptedMediaType is a media range of an Accept header, such as application/json, application/* or */*.
+type AcceptedMediaType struct {
+	// Type is the type of the media range, or * for any type.
+	Type string
+	// SubType is the subtype of the media range, or * for any subtype.
+	SubType string
+	// Params holds the parameters of the media range, excluding the quality.
+	Params map[string]string
+	// Quality is the relative preference of the media range, between 0 and 1. A quality of 0 marks
+	// media types as not acceptable.
+	Quality float64
+}
+
+// Matches returns whether mediaType (e.g. application/json) is within the media range. Parameters of
+// the media range are not taken into account.
+func (a AcceptedMediaType) Matches(mediaType string) bool {
+	parts := strings.SplitN(mediaType, "/", 2)
+	if len(parts) != 2 {
+		return false
+	}
+	return (a.Type == "*" || a.Type == parts[0]) && (a.SubType == "*" || a.SubType == parts[1])
+}
+
+// specificity ranks media ranges by how specific they are, so that the most specific matching range
+// determines the quality of a media type.
+func (a AcceptedMediaType) specificity() int {
+	switch {
+	case a.Type == "*":
+		return 0
+	case a.SubType == "*":
+		return 1
+	case len(a.Params) == 0:
+		return 2
+	default:
+		return 3
+	}
+}
+
+// ParseAcceptHeader parses the media ranges of an Accept header. Ranges are ordered by decreasing
+// quality, and ranges with the same quality keep the order of the header. Invalid ranges, including
+// ranges with an invalid quality, are ignored. A range without a quality has a quality of 1.
+func ParseAcceptHeader(header string) []AcceptedMediaType {
+	var accepted []AcceptedMediaType
+	for _, value := range strings.Split(header, ",") {
+		value = strings.TrimSpace(value)
+		if len(value) == 0 {
+			continue
+		}
+		mediaType, params, err := mime.ParseMediaType(value)
+		if err != nil {
+			continue
+		}
+		parts := strings.SplitN(mediaType, "/", 2)
+		if len(parts) != 2 || len(parts[0]) == 0 || len(parts[1]) == 0 || (parts[0] == "*" && parts[1] != "*") {
+			continue
+		}
+		a := AcceptedMediaType{Type: parts[0], SubType: parts[1], Quality: 1}
+		if q, ok := params["q"]; ok {
+			quality, err := strconv.ParseFloat(q, 64)
+			if err != nil || quality < 0 || quality > 1 {
+				continue
+			}
+			a.Quality = quality
+			delete(params, "q")
+		}
+		if len(params) > 0 {
+			a.Params = params
+		}
+		accepted = append(accepted, a)
+	}
+	sort.SliceStable(accepted, func(i, j int) bool {
+		return accepted[i].Quality > accepted[j].Quality
+	})
+	return accepted
+}
+
+// NegotiateSerializerInfo returns the serializer preferred by an Accept header. The quality of each
+// serializer is that of the most specific media range matching its media type, and the serializer with
+// the highest quality is returned. Serializers with the same quality are preferred in the order of types.
+// False is returned if no serializer is acceptable.
+func NegotiateSerializerInfo(types []SerializerInfo, accept string) (SerializerInfo, bool) {
+	accepted := ParseAcceptHeader(accept)
+	best, bestQuality := -1, 0.0
+	for i, info := range types {
+		quality, specificity := 0.0, -1
+		for _, a := range accepted {
+			if a.Matches(info.MediaType) && a.specificity() > specificity {
+				quality, specificity = a.Quality, a.specificity()
+			}
+		}
+		if quality > bestQuality {
+			best, bestQuality = i, quality
+		}
+	}
+	if best < 0 {
+		return SerializerInfo{}, false
+	}
+	return types[best], true
+}
+
 // NewClientNegotiator will attempt to retrieve the appropriate encoder, decoder, or
-// stream decoder for a given content type. Does not perform any conversion, but will
+// stream decoder for a given content type. The content type may also list media ranges
+// in the format of an Accept header, see NegotiateSerializerInfo. Does not perform any conversion, but will
 // encode the object to the desired group, version, and kind. Use when creating a client.
 func NewClientNegotiator(serializer NegotiatedSerializer, gv schema.GroupVersion) ClientNegotiator {
 	return &clientNegotiator{
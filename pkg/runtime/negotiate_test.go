@@ -0,0 +1,78 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package runtime_test
+
+import (
+	"reflect"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+)
+
+func TestParseAcceptHeader(t *testing.T) {
+	accepted := runtime.ParseAcceptHeader("text/html;q=0.5, application/json;pretty=1, bad, */*;q=0.1, application/*;q=2, application/yaml;q=0.5")
+	expected := []runtime.AcceptedMediaType{
+		{Type: "application", SubType: "json", Params: map[string]string{"pretty": "1"}, Quality: 1},
+		{Type: "text", SubType: "html", Quality: 0.5},
+		{Type: "application", SubType: "yaml", Quality: 0.5},
+		{Type: "*", SubType: "*", Quality: 0.1},
+	}
+	if !reflect.DeepEqual(expected, accepted) {
+		t.Errorf("expected %#v, got %#v", expected, accepted)
+	}
+}
+
+func TestNegotiateSerializerInfo(t *testing.T) {
+	infos := serializer.NewCodecFactory(runtime.NewScheme()).SupportedMediaTypes()
+	testCases := []struct {
+		accept   string
+		expected string
+	}{
+		{accept: "*/*", expected: runtime.ContentTypeJSON},
+		{accept: "application/*", expected: runtime.ContentTypeJSON},
+		{accept: "application/yaml", expected: runtime.ContentTypeYAML},
+		{accept: "application/json;q=0.5, application/yaml", expected: runtime.ContentTypeYAML},
+		{accept: "application/*;q=0.8, application/json;q=0.1", expected: runtime.ContentTypeYAML},
+		{accept: "application/vnd.kubernetes.protobuf, */*;q=0.9", expected: runtime.ContentTypeProtobuf},
+		{accept: "*/*, application/json;q=0", expected: runtime.ContentTypeYAML},
+		{accept: "text/html, application/json;q=0.1", expected: runtime.ContentTypeJSON},
+		{accept: "text/html"},
+		{accept: "*/*;q=0"},
+		{accept: ""},
+	}
+	for _, tc := range testCases {
+		info, ok := runtime.NegotiateSerializerInfo(infos, tc.accept)
+		if ok != (len(tc.expected) > 0) || info.MediaType != tc.expected {
+			t.Errorf("%q: expected %q, got %q (%t)", tc.accept, tc.expected, info.MediaType, ok)
+		}
+	}
+}
+
+func TestClientNegotiatorMediaRanges(t *testing.T) {
+	n := runtime.NewClientNegotiator(serializer.NewCodecFactory(runtime.NewScheme()).WithoutConversion(), schema.GroupVersion{})
+	if _, err := n.Encoder("application/*;q=0.9", nil); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if _, _, _, err := n.StreamDecoder("application/json, */*;q=0.1", nil); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if _, err := n.Decoder("text/*", nil); err == nil {
+		t.Errorf("expected an error for an unsupported media range")
+	}
+}
@@ -0,0 +1,126 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package runtime
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/conversion"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// versionPattern matches the API versions of the Kubernetes conventions, e.g. v1, v2beta1 or v1alpha3.
+var versionPattern = regexp.MustCompile(`^v[1-9][0-9]*((alpha|beta)[1-9][0-9]*)?$`)
+
+// PathObjectTyper is an ObjectTyper deriving the group, version and kind of types that are not
+// recognized by another ObjectTyper, typically a Scheme, from the conventions of API packages: the
+// kind is the name of the struct type, the version is the last element of the import path of its
+// package, and the group is the element before it. The "core" group is mapped to the empty group,
+// so that k8s.io/api/core/v1.Pod is typed as /v1, Kind=Pod and k8s.io/api/apps/v1.Deployment as
+// apps/v1, Kind=Deployment. Groups can be overridden per package with Groups, e.g. to map the
+// package of custom resources to a fully qualified group.
+//
+// It allows tools to decode objects of types they can't register in advance. Types whose package
+// doesn't follow the conventions are not recognized.
+type PathObjectTyper struct {
+	typer ObjectTyper
+	// groups maps the import paths of versioned packages to API groups.
+	groups map[string]string
+
+	lock sync.RWMutex
+	// inferred holds the kinds derived from the types passed to ObjectKinds.
+	inferred map[schema.GroupVersionKind]bool
+}
+
+var _ ObjectTyper = &PathObjectTyper{}
+
+// NewPathObjectTyper returns a PathObjectTyper consulting typer first, if it isn't nil. The group of
+// the types of the packages listed in groups, by import path (e.g. example.com/api/widgets/v1), is
+// taken from groups instead of the import path.
+func NewPathObjectTyper(typer ObjectTyper, groups map[string]string) *PathObjectTyper {
+	return &PathObjectTyper{
+		typer:    typer,
+		groups:   groups,
+		inferred: map[schema.GroupVersionKind]bool{},
+	}
+}
+
+// ObjectKinds returns the kinds of obj according to the wrapped ObjectTyper, or the kind derived from
+// its type if the wrapped ObjectTyper doesn't recognize it. Derived kinds are never unversioned.
+func (t *PathObjectTyper) ObjectKinds(obj Object) ([]schema.GroupVersionKind, bool, error) {
+	if t.typer != nil {
+		gvks, unversioned, err := t.typer.ObjectKinds(obj)
+		if !IsNotRegisteredError(err) {
+			return gvks, unversioned, err
+		}
+	}
+	if _, ok := obj.(Unstructured); ok {
+		return nil, false, fmt.Errorf("unable to derive the kind of unstructured objects from their type")
+	}
+	v, err := conversion.EnforcePtr(obj)
+	if err != nil {
+		return nil, false, err
+	}
+	gvk, ok := t.kindForType(v.Type())
+	if !ok {
+		return nil, false, NewNotRegisteredErrForType("PathObjectTyper", v.Type())
+	}
+
+	t.lock.Lock()
+	t.inferred[gvk] = true
+	t.lock.Unlock()
+	return []schema.GroupVersionKind{gvk}, false, nil
+}
+
+// Recognizes returns true if the wrapped ObjectTyper recognizes gvk, or if gvk was derived from the
+// type of an object passed to ObjectKinds.
+func (t *PathObjectTyper) Recognizes(gvk schema.GroupVersionKind) bool {
+	if t.typer != nil && t.typer.Recognizes(gvk) {
+		return true
+	}
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+	return t.inferred[gvk]
+}
+
+// kindForType derives the kind of the struct type typ from its package path.
+func (t *PathObjectTyper) kindForType(typ reflect.Type) (schema.GroupVersionKind, bool) {
+	if typ.Kind() != reflect.Struct || len(typ.Name()) == 0 {
+		return schema.GroupVersionKind{}, false
+	}
+	pkgPath := typ.PkgPath()
+	elements := strings.Split(pkgPath, "/")
+	version := elements[len(elements)-1]
+	if !versionPattern.MatchString(version) {
+		return schema.GroupVersionKind{}, false
+	}
+	group, ok := t.groups[pkgPath]
+	if !ok {
+		if len(elements) < 2 {
+			return schema.GroupVersionKind{}, false
+		}
+		group = elements[len(elements)-2]
+		if group == "core" {
+			group = ""
+		}
+	}
+	return schema.GroupVersionKind{Group: group, Version: version, Kind: typ.Name()}, true
+}
@@ -0,0 +1,76 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package runtime_test
+
+import (
+	"reflect"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	metav1beta1 "k8s.io/apimachinery/pkg/apis/meta/v1beta1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/runtime/serializer/json"
+	runtimetesting "k8s.io/apimachinery/pkg/runtime/testing"
+)
+
+func TestPathObjectTyper(t *testing.T) {
+	scheme := runtime.NewScheme()
+	registered := schema.GroupVersionKind{Group: "test.group", Version: "v1", Kind: "Simple"}
+	scheme.AddKnownTypeWithName(registered, &runtimetesting.ExternalSimple{})
+	typer := runtime.NewPathObjectTyper(scheme, map[string]string{
+		"k8s.io/apimachinery/pkg/apis/meta/v1beta1": "meta.k8s.io",
+	})
+
+	testCases := []struct {
+		obj      runtime.Object
+		expected schema.GroupVersionKind
+	}{
+		{obj: &runtimetesting.ExternalSimple{}, expected: registered},
+		{obj: &metav1.Status{}, expected: schema.GroupVersionKind{Group: "meta", Version: "v1", Kind: "Status"}},
+		{obj: &metav1beta1.PartialObjectMetadataList{}, expected: schema.GroupVersionKind{Group: "meta.k8s.io", Version: "v1beta1", Kind: "PartialObjectMetadataList"}},
+	}
+	for _, tc := range testCases {
+		if typer.Recognizes(tc.expected) != (tc.expected == registered) {
+			t.Errorf("%v: unexpected recognition before typing", tc.expected)
+		}
+		gvks, unversioned, err := typer.ObjectKinds(tc.obj)
+		if err != nil {
+			t.Fatalf("%T: %v", tc.obj, err)
+		}
+		if unversioned || !reflect.DeepEqual([]schema.GroupVersionKind{tc.expected}, gvks) {
+			t.Errorf("%T: expected %v, got %v (unversioned %t)", tc.obj, tc.expected, gvks, unversioned)
+		}
+		if !typer.Recognizes(tc.expected) {
+			t.Errorf("%v: expected the kind to be recognized", tc.expected)
+		}
+	}
+
+	if _, _, err := typer.ObjectKinds(&runtimetesting.InternalSimple{}); !runtime.IsNotRegisteredError(err) {
+		t.Errorf("expected types of unversioned packages not to be recognized, got %v", err)
+	}
+
+	// objects of unregistered types can be decoded
+	serializer := json.NewSerializerWithOptions(json.DefaultMetaFactory, scheme, typer, json.SerializerOptions{})
+	status := &metav1.Status{}
+	if _, _, err := serializer.Decode([]byte(`{"message":"decoded"}`), nil, status); err != nil {
+		t.Fatal(err)
+	}
+	if status.Message != "decoded" {
+		t.Errorf("unexpected object %#v", status)
+	}
+}
@@ -0,0 +1,281 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package runtime
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// protobufEnvelopePrefix is the magic number preceding the runtime.Unknown envelopes written by the
+// protobuf serializer, see k8s.io/apimachinery/pkg/runtime/serializer/protobuf.
+var protobufEnvelopePrefix = []byte{0x6b, 0x38, 0x73, 0x00}
+
+// PeekTypeMeta returns the apiVersion and kind of the object serialized in data with contentType,
+// without decoding the rest of the object. JSON, YAML and protobuf (runtime.Unknown envelopes
+// written by the protobuf serializer) content types are supported, and an empty content type means
+// JSON. Scanning stops as soon as both fields have been found, and missing fields are returned empty.
+//
+// For YAML, only top-level fields in block style are recognized, or the fields of a document in
+// JSON syntax. Only the first document of a multi-document stream is considered.
+func PeekTypeMeta(data []byte, contentType string) (TypeMeta, error) {
+	if i := strings.IndexByte(contentType, ';'); i >= 0 {
+		contentType = contentType[:i]
+	}
+	switch strings.TrimSpace(contentType) {
+	case "", ContentTypeJSON:
+		return peekJSONTypeMeta(data)
+	case ContentTypeYAML:
+		return peekYAMLTypeMeta(data)
+	case ContentTypeProtobuf:
+		return peekProtobufTypeMeta(data)
+	default:
+		return TypeMeta{}, fmt.Errorf("unable to peek at the type of %q content", contentType)
+	}
+}
+
+func peekJSONTypeMeta(data []byte) (TypeMeta, error) {
+	d := json.NewDecoder(bytes.NewReader(data))
+	if token, err := d.Token(); err != nil {
+		return TypeMeta{}, fmt.Errorf("couldn't get version/kind; json parse error: %v", err)
+	} else if token != json.Delim('{') {
+		return TypeMeta{}, fmt.Errorf("couldn't get version/kind; expected a JSON object")
+	}
+	var typeMeta TypeMeta
+	var foundVersion, foundKind bool
+	for d.More() && !(foundVersion && foundKind) {
+		token, err := d.Token()
+		if err != nil {
+			return TypeMeta{}, fmt.Errorf("couldn't get version/kind; json parse error: %v", err)
+		}
+		var target *string
+		switch token {
+		case "apiVersion":
+			target, foundVersion = &typeMeta.APIVersion, true
+		case "kind":
+			target, foundKind = &typeMeta.Kind, true
+		}
+		if target == nil {
+			if err := skipJSONValue(d); err != nil {
+				return TypeMeta{}, fmt.Errorf("couldn't get version/kind; json parse error: %v", err)
+			}
+			continue
+		}
+		value, err := d.Token()
+		if err != nil {
+			return TypeMeta{}, fmt.Errorf("couldn't get version/kind; json parse error: %v", err)
+		}
+		switch value := value.(type) {
+		case string:
+			*target = value
+		case nil:
+		default:
+			return TypeMeta{}, fmt.Errorf("couldn't get version/kind; %s must be a string", token)
+		}
+	}
+	return typeMeta, nil
+}
+
+// skipJSONValue reads the next value from d without building it.
+func skipJSONValue(d *json.Decoder) error {
+	depth := 0
+	for {
+		token, err := d.Token()
+		if err != nil {
+			return err
+		}
+		switch token {
+		case json.Delim('{'), json.Delim('['):
+			depth++
+		case json.Delim('}'), json.Delim(']'):
+			depth--
+		}
+		if depth == 0 {
+			return nil
+		}
+	}
+}
+
+// yamlWhitespace holds the characters YAML considers as white space around scalars.
+const yamlWhitespace = " \t\r"
+
+func peekYAMLTypeMeta(data []byte) (TypeMeta, error) {
+	var typeMeta TypeMeta
+	var foundVersion, foundKind, inDocument bool
+	for offset := 0; offset < len(data) && !(foundVersion && foundKind); {
+		line := data[offset:]
+		if i := bytes.IndexByte(line, '\n'); i >= 0 {
+			line = line[:i]
+		}
+		lineOffset := offset
+		offset += len(line) + 1
+
+		trimmed := bytes.Trim(line, yamlWhitespace)
+		if bytes.HasPrefix(line, []byte("---")) {
+			if inDocument {
+				break
+			}
+			continue
+		}
+		if len(trimmed) == 0 || trimmed[0] == '#' {
+			continue
+		}
+		if !inDocument && trimmed[0] == '{' {
+			// the document uses the JSON syntax
+			return peekJSONTypeMeta(data[lineOffset:])
+		}
+		inDocument = true
+		if line[0] == ' ' || line[0] == '\t' {
+			continue
+		}
+		key, value, ok := splitYAMLField(string(line))
+		if !ok {
+			continue
+		}
+		switch key {
+		case "apiVersion":
+			typeMeta.APIVersion, foundVersion = value, true
+		case "kind":
+			typeMeta.Kind, foundKind = value, true
+		}
+	}
+	return typeMeta, nil
+}
+
+// splitYAMLField splits a top-level YAML line holding a scalar field into its key and value.
+func splitYAMLField(line string) (key, value string, ok bool) {
+	i := strings.Index(line, ":")
+	if i < 0 {
+		return "", "", false
+	}
+	key, value = unquoteYAML(strings.Trim(line[:i], yamlWhitespace)), strings.Trim(line[i+1:], yamlWhitespace)
+	if len(value) > 0 && (value[0] == '"' || value[0] == '\'') {
+		return key, unquoteYAML(value[:quotedYAMLLength(value)]), true
+	}
+	if i := strings.Index(value, " #"); i >= 0 {
+		value = strings.Trim(value[:i], yamlWhitespace)
+	}
+	if value == "~" || value == "null" {
+		value = ""
+	}
+	return key, value, true
+}
+
+// quotedYAMLLength returns the length of the quoted scalar that value starts with, or the length
+// of value if the scalar is not terminated.
+func quotedYAMLLength(value string) int {
+	quote := value[0]
+	for i := 1; i < len(value); i++ {
+		switch {
+		case quote == '"' && value[i] == '\\':
+			i++
+		case value[i] == quote && quote == '\'' && i+1 < len(value) && value[i+1] == '\'':
+			i++
+		case value[i] == quote:
+			return i + 1
+		}
+	}
+	return len(value)
+}
+
+func unquoteYAML(s string) string {
+	if len(s) < 2 || s[0] != s[len(s)-1] {
+		return s
+	}
+	switch s[0] {
+	case '"':
+		if unquoted, err := strconv.Unquote(s); err == nil {
+			return unquoted
+		}
+	case '\'':
+		return strings.ReplaceAll(s[1:len(s)-1], "''", "'")
+	}
+	return s
+}
+
+func peekProtobufTypeMeta(data []byte) (TypeMeta, error) {
+	if !bytes.HasPrefix(data, protobufEnvelopePrefix) {
+		return TypeMeta{}, fmt.Errorf("provided data does not appear to be a protobuf message, expected prefix %v", protobufEnvelopePrefix)
+	}
+	// the envelope is a runtime.Unknown message, whose field 1 holds the TypeMeta message
+	typeMetaData, found, err := protobufField(data[len(protobufEnvelopePrefix):], 1)
+	if err != nil || !found {
+		return TypeMeta{}, err
+	}
+	var typeMeta TypeMeta
+	apiVersion, _, err := protobufField(typeMetaData, 1)
+	if err != nil {
+		return TypeMeta{}, err
+	}
+	kind, _, err := protobufField(typeMetaData, 2)
+	if err != nil {
+		return TypeMeta{}, err
+	}
+	typeMeta.APIVersion, typeMeta.Kind = string(apiVersion), string(kind)
+	return typeMeta, nil
+}
+
+var errInvalidProtobuf = errors.New("couldn't get version/kind; invalid protobuf message")
+
+// protobufField returns the content of the last length-delimited field with the given number in
+// the protobuf message data, skipping the other fields.
+func protobufField(data []byte, number uint64) ([]byte, bool, error) {
+	var content []byte
+	found := false
+	r := bytes.NewReader(data)
+	for r.Len() > 0 {
+		tag, err := binary.ReadUvarint(r)
+		if err != nil {
+			return nil, false, errInvalidProtobuf
+		}
+		var length uint64
+		switch tag & 0x7 {
+		case 0: // varint
+			if _, err := binary.ReadUvarint(r); err != nil {
+				return nil, false, errInvalidProtobuf
+			}
+			continue
+		case 1: // 64 bits
+			length = 8
+		case 2: // length-delimited
+			if length, err = binary.ReadUvarint(r); err != nil {
+				return nil, false, errInvalidProtobuf
+			}
+		case 5: // 32 bits
+			length = 4
+		default:
+			return nil, false, errInvalidProtobuf
+		}
+		if length > uint64(r.Len()) {
+			return nil, false, errInvalidProtobuf
+		}
+		offset := len(data) - r.Len()
+		if tag>>3 == number && tag&0x7 == 2 {
+			content, found = data[offset:offset+int(length)], true
+		}
+		if _, err := r.Seek(int64(length), io.SeekCurrent); err != nil {
+			return nil, false, errInvalidProtobuf
+		}
+	}
+	return content, found, nil
+}
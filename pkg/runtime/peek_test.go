@@ -0,0 +1,197 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package runtime_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	fuzz "github.com/google/gofuzz"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/yaml"
+)
+
+var protobufPrefix = []byte{0x6b, 0x38, 0x73, 0x00}
+
+func protobufEnvelope(t testing.TB, typeMeta runtime.TypeMeta, raw []byte) []byte {
+	data, err := (&runtime.Unknown{TypeMeta: typeMeta, Raw: raw, ContentType: runtime.ContentTypeProtobuf}).Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return append(append([]byte{}, protobufPrefix...), data...)
+}
+
+func TestPeekTypeMeta(t *testing.T) {
+	testCases := []struct {
+		name        string
+		data        []byte
+		contentType string
+		expected    runtime.TypeMeta
+		expectErr   bool
+	}{
+		{
+			name:        "json",
+			data:        []byte(`{"metadata":{"kind":"Nested","labels":{"a":"b"}},"items":[{"apiVersion":"x"}],"apiVersion":"v1","kind":"Pod"}`),
+			contentType: runtime.ContentTypeJSON,
+			expected:    runtime.TypeMeta{APIVersion: "v1", Kind: "Pod"},
+		},
+		{
+			name:     "json without content type",
+			data:     []byte(`{"kind":"Pod"}`),
+			expected: runtime.TypeMeta{Kind: "Pod"},
+		},
+		{
+			name:        "json with parameters",
+			data:        []byte(`{"apiVersion":"apps/v1","kind":"Deployment","spec":{`),
+			contentType: "application/json;charset=utf-8",
+			expected:    runtime.TypeMeta{APIVersion: "apps/v1", Kind: "Deployment"},
+		},
+		{
+			name:        "json null fields",
+			data:        []byte(`{"apiVersion":null,"kind":"Pod"}`),
+			contentType: runtime.ContentTypeJSON,
+			expected:    runtime.TypeMeta{Kind: "Pod"},
+		},
+		{
+			name:        "json non-string kind",
+			data:        []byte(`{"kind":1}`),
+			contentType: runtime.ContentTypeJSON,
+			expectErr:   true,
+		},
+		{
+			name:        "json array",
+			data:        []byte(`[]`),
+			contentType: runtime.ContentTypeJSON,
+			expectErr:   true,
+		},
+		{
+			name:        "json invalid",
+			data:        []byte(`{"metadata":{]`),
+			contentType: runtime.ContentTypeJSON,
+			expectErr:   true,
+		},
+		{
+			name:        "yaml",
+			data:        []byte("# leading comment\n---\nmetadata:\n  kind: Nested\napiVersion: \"v1\" \nkind: Pod # trailing comment\n"),
+			contentType: runtime.ContentTypeYAML,
+			expected:    runtime.TypeMeta{APIVersion: "v1", Kind: "Pod"},
+		},
+		{
+			name:        "yaml first document only",
+			data:        []byte("apiVersion: 'v1'\n---\nkind: Pod\n"),
+			contentType: runtime.ContentTypeYAML,
+			expected:    runtime.TypeMeta{APIVersion: "v1"},
+		},
+		{
+			name:        "yaml in json syntax",
+			data:        []byte("# comment {\n{\"apiVersion\": \"v1\", \"kind\": \"Pod\"}"),
+			contentType: runtime.ContentTypeYAML,
+			expected:    runtime.TypeMeta{APIVersion: "v1", Kind: "Pod"},
+		},
+		{
+			name:        "protobuf",
+			data:        protobufEnvelope(t, runtime.TypeMeta{APIVersion: "v1", Kind: "Pod"}, []byte{0x0a, 0x01, 0x78}),
+			contentType: runtime.ContentTypeProtobuf,
+			expected:    runtime.TypeMeta{APIVersion: "v1", Kind: "Pod"},
+		},
+		{
+			name:        "protobuf without prefix",
+			data:        []byte{0x0a, 0x00},
+			contentType: runtime.ContentTypeProtobuf,
+			expectErr:   true,
+		},
+		{
+			name:        "protobuf truncated",
+			data:        protobufEnvelope(t, runtime.TypeMeta{APIVersion: "v1", Kind: "Pod"}, nil)[:8],
+			contentType: runtime.ContentTypeProtobuf,
+			expectErr:   true,
+		},
+		{
+			name:        "unsupported content type",
+			data:        []byte(`{}`),
+			contentType: "text/plain",
+			expectErr:   true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			typeMeta, err := runtime.PeekTypeMeta(tc.data, tc.contentType)
+			if tc.expectErr {
+				if err == nil {
+					t.Fatalf("expected an error, got %#v", typeMeta)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if typeMeta != tc.expected {
+				t.Errorf("expected %#v, got %#v", tc.expected, typeMeta)
+			}
+		})
+	}
+}
+
+type peekObject struct {
+	APIVersion string            `json:"apiVersion"`
+	Kind       string            `json:"kind"`
+	Labels     map[string]string `json:"labels,omitempty"`
+	Items      []string          `json:"items,omitempty"`
+}
+
+// TestPeekTypeMetaRoundTrip checks that PeekTypeMeta finds the type fields of random objects.
+func TestPeekTypeMetaRoundTrip(t *testing.T) {
+	f := fuzz.NewWithSeed(1).NilChance(0.2)
+	for i := 0; i < 1000; i++ {
+		var obj peekObject
+		f.Fuzz(&obj)
+		expected := runtime.TypeMeta{APIVersion: obj.APIVersion, Kind: obj.Kind}
+
+		jsonData, err := json.Marshal(obj)
+		if err != nil {
+			t.Fatal(err)
+		}
+		yamlData, err := yaml.JSONToYAML(jsonData)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for contentType, data := range map[string][]byte{
+			runtime.ContentTypeJSON:     jsonData,
+			runtime.ContentTypeYAML:     yamlData,
+			runtime.ContentTypeProtobuf: protobufEnvelope(t, expected, jsonData),
+		} {
+			typeMeta, err := runtime.PeekTypeMeta(data, contentType)
+			if err != nil {
+				t.Fatalf("%s: unexpected error for %q: %v", contentType, data, err)
+			}
+			if typeMeta != expected {
+				t.Fatalf("%s: expected %#v, got %#v from %q", contentType, expected, typeMeta, data)
+			}
+		}
+	}
+}
+
+func FuzzPeekTypeMeta(f *testing.F) {
+	f.Add([]byte(`{"apiVersion":"v1","kind":"Pod"}`), runtime.ContentTypeJSON)
+	f.Add([]byte("apiVersion: v1\nkind: Pod\n"), runtime.ContentTypeYAML)
+	f.Add(protobufEnvelope(f, runtime.TypeMeta{APIVersion: "v1", Kind: "Pod"}, []byte("raw")), runtime.ContentTypeProtobuf)
+	f.Fuzz(func(t *testing.T, data []byte, contentType string) {
+		// PeekTypeMeta is used on untrusted input, so it must never panic
+		runtime.PeekTypeMeta(data, contentType)
+	})
+}
@@ -17,9 +17,12 @@ limitations under the License.
 package runtime
 
 import (
+	"context"
 	"fmt"
 	"reflect"
+	"sort"
 	"strings"
+	"sync"
 
 	"k8s.io/apimachinery/pkg/conversion"
 	"k8s.io/apimachinery/pkg/runtime/schema"
@@ -42,7 +45,7 @@ import (
 // to break in the future).
 //
 // Schemes are not expected to change at runtime and are only threadsafe after
-// registration is complete.
+// registration is complete, unless EnableConcurrentRegistration is called.
 type Scheme struct {
 	// gvkToType allows one to figure out the go type of an object with
 	// the given version and name.
@@ -68,10 +71,23 @@ type Scheme struct {
 	// the provided object must be a pointer.
 	defaulterFuncs map[reflect.Type]func(interface{})
 
+	// contextDefaulterFuncs holds, for each type, the funcs to be called in order with a context
+	// and an object to provide defaulting after the func in defaulterFuncs.
+	contextDefaulterFuncs map[reflect.Type][]func(context.Context, interface{})
+
 	// converter stores all registered conversion functions. It also has
 	// default converting behavior.
 	converter *conversion.Converter
 
+	// conversionTargets holds, for every type, the types that conversion funcs were registered to
+	// with AddConversionFunc or AddGeneratedConversionFunc. It is used to find conversion paths
+	// between versions of a kind that have no direct conversion func.
+	conversionTargets map[reflect.Type][]reflect.Type
+
+	// conversionPaths caches the conversion paths found between versions of a kind. It is reset
+	// whenever a type or a conversion func is registered.
+	conversionPaths *conversionPathCache
+
 	// versionPriority is a map of groups to ordered lists of versions for those groups indicating the
 	// default priorities of these versions as registered in the scheme
 	versionPriority map[string][]string
@@ -82,6 +98,9 @@ type Scheme struct {
 	// schemeName is the name of this scheme.  If you don't specify a name, the stack of the NewScheme caller will be used.
 	// This is useful for error reporting to indicate the origin of the scheme.
 	schemeName string
+
+	// lock guards the fields above once EnableConcurrentRegistration is called, and is nil otherwise.
+	lock *sync.RWMutex
 }
 
 // FieldLabelConversionFunc converts a field selector to internal representation.
@@ -96,7 +115,10 @@ func NewScheme() *Scheme {
 		unversionedKinds:          map[string]reflect.Type{},
 		fieldLabelConversionFuncs: map[schema.GroupVersionKind]FieldLabelConversionFunc{},
 		defaulterFuncs:            map[reflect.Type]func(interface{}){},
+		contextDefaulterFuncs:     map[reflect.Type][]func(context.Context, interface{}){},
 		versionPriority:           map[string][]string{},
+		conversionTargets:         map[reflect.Type][]reflect.Type{},
+		conversionPaths:           newConversionPathCache(),
 		schemeName:                naming.GetNameFromCallsite(internalPackages...),
 	}
 	s.converter = conversion.NewConverter(nil)
@@ -112,6 +134,46 @@ func (s *Scheme) Converter() *conversion.Converter {
 	return s.converter
 }
 
+// EnableConcurrentRegistration makes the scheme safe for registering types and functions, like with
+// AddKnownTypes, while it is used to create, convert, default or identify objects, e.g. to install
+// APIs dynamically after a server started. It must be called before the scheme is used concurrently.
+//
+// Every lookup in the scheme and in its converter then acquires a read lock, and every registration
+// a write lock. This slows down lookups, in particular when many goroutines use the scheme at once,
+// so it should only be enabled for schemes that need it. Conversion and defaulting functions are
+// never invoked with the lock held, so they may use the scheme. AllKnownTypes returns a copy of the
+// known types.
+func (s *Scheme) EnableConcurrentRegistration() {
+	if s.lock == nil {
+		s.lock = &sync.RWMutex{}
+		s.converter.EnableConcurrentRegistration()
+	}
+}
+
+func (s *Scheme) rLock() {
+	if s.lock != nil {
+		s.lock.RLock()
+	}
+}
+
+func (s *Scheme) rUnlock() {
+	if s.lock != nil {
+		s.lock.RUnlock()
+	}
+}
+
+func (s *Scheme) wLock() {
+	if s.lock != nil {
+		s.lock.Lock()
+	}
+}
+
+func (s *Scheme) wUnlock() {
+	if s.lock != nil {
+		s.lock.Unlock()
+	}
+}
+
 // AddUnversionedTypes registers the provided types as "unversioned", which means that they follow special rules.
 // Whenever an object of this type is serialized, it is serialized with the provided group version and is not
 // converted. Thus unversioned objects are expected to remain backwards compatible forever, as if they were in an
@@ -120,8 +182,9 @@ func (s *Scheme) Converter() *conversion.Converter {
 // TODO: there is discussion about removing unversioned and replacing it with objects that are manifest into
 //   every version with particular schemas. Resolve this method at that point.
 func (s *Scheme) AddUnversionedTypes(version schema.GroupVersion, types ...Object) {
-	s.addObservedVersion(version)
 	s.AddKnownTypes(version, types...)
+	s.wLock()
+	defer s.wUnlock()
 	for _, obj := range types {
 		t := reflect.TypeOf(obj).Elem()
 		gvk := version.WithKind(t.Name())
@@ -138,7 +201,9 @@ func (s *Scheme) AddUnversionedTypes(version schema.GroupVersion, types ...Objec
 // the struct becomes the "kind" field when encoding. Version may not be empty - use the
 // APIVersionInternal constant if you have a type that does not have a formal version.
 func (s *Scheme) AddKnownTypes(gv schema.GroupVersion, types ...Object) {
+	s.wLock()
 	s.addObservedVersion(gv)
+	s.wUnlock()
 	for _, obj := range types {
 		t := reflect.TypeOf(obj)
 		if t.Kind() != reflect.Ptr {
@@ -154,6 +219,19 @@ func (s *Scheme) AddKnownTypes(gv schema.GroupVersion, types ...Object) {
 // your structs. Version may not be empty - use the APIVersionInternal constant if you have a
 // type that does not have a formal version.
 func (s *Scheme) AddKnownTypeWithName(gvk schema.GroupVersionKind, obj Object) {
+	added := func() bool {
+		s.wLock()
+		defer s.wUnlock()
+		return s.addKnownTypeWithName(gvk, obj)
+	}()
+	if added {
+		s.addSelfConversion(obj)
+	}
+}
+
+// addKnownTypeWithName registers obj for gvk, returning false if it was already registered. The
+// caller must hold the write lock.
+func (s *Scheme) addKnownTypeWithName(gvk schema.GroupVersionKind, obj Object) bool {
 	s.addObservedVersion(gvk.GroupVersion())
 	t := reflect.TypeOf(obj)
 	if len(gvk.Version) == 0 {
@@ -172,14 +250,19 @@ func (s *Scheme) AddKnownTypeWithName(gvk schema.GroupVersionKind, obj Object) {
 	}
 
 	s.gvkToType[gvk] = t
+	s.conversionPaths.reset()
 
 	for _, existingGvk := range s.typeToGVK[t] {
 		if existingGvk == gvk {
-			return
+			return false
 		}
 	}
 	s.typeToGVK[t] = append(s.typeToGVK[t], gvk)
+	return true
+}
 
+// addSelfConversion registers a conversion from the type of obj to itself if it implements DeepCopyInto.
+func (s *Scheme) addSelfConversion(obj Object) {
 	// if the type implements DeepCopyInto(<obj>), register a self-conversion
 	if m := reflect.ValueOf(obj).MethodByName("DeepCopyInto"); m.IsValid() && m.Type().NumIn() == 1 && m.Type().NumOut() == 0 && m.Type().In(0) == reflect.TypeOf(obj) {
 		if err := s.AddGeneratedConversionFunc(obj, obj, func(a, b interface{}, scope conversion.Scope) error {
@@ -194,8 +277,85 @@ func (s *Scheme) AddKnownTypeWithName(gvk schema.GroupVersionKind, obj Object) {
 	}
 }
 
+// Unregister removes the type registered for gvk from the scheme, along with its unversioned status
+// and its field label conversion function. The version of gvk is no longer reported as registered
+// once it has no types left. Conversion and defaulting functions, which are registered per type,
+// are kept. Unregistering a kind that isn't registered is a no-op. Like the registration methods,
+// Unregister must not be called concurrently with other uses of the scheme, unless
+// EnableConcurrentRegistration was called.
+func (s *Scheme) Unregister(gvk schema.GroupVersionKind) {
+	s.wLock()
+	defer s.wUnlock()
+	if !s.removeKnownType(gvk) {
+		return
+	}
+	delete(s.fieldLabelConversionFuncs, gvk)
+
+	gv := gvk.GroupVersion()
+	for existing := range s.gvkToType {
+		if existing.GroupVersion() == gv {
+			return
+		}
+	}
+	for i, observedVersion := range s.observedVersions {
+		if observedVersion == gv {
+			s.observedVersions = append(s.observedVersions[:i:i], s.observedVersions[i+1:]...)
+			break
+		}
+	}
+}
+
+// Override registers obj for gvk like AddKnownTypeWithName, replacing the type previously registered
+// for gvk, if any, instead of panicking. The field label conversion function of gvk is kept, but a
+// replaced unversioned type is not carried over: use AddUnversionedTypes to register obj as such.
+func (s *Scheme) Override(gvk schema.GroupVersionKind, obj Object) {
+	added := func() bool {
+		s.wLock()
+		defer s.wUnlock()
+		s.removeKnownType(gvk)
+		return s.addKnownTypeWithName(gvk, obj)
+	}()
+	if added {
+		s.addSelfConversion(obj)
+	}
+}
+
+// removeKnownType removes gvk from the type maps of the scheme, returning false if it wasn't
+// registered. The caller must hold the write lock.
+func (s *Scheme) removeKnownType(gvk schema.GroupVersionKind) bool {
+	t, found := s.gvkToType[gvk]
+	if !found {
+		return false
+	}
+	delete(s.gvkToType, gvk)
+	s.conversionPaths.reset()
+
+	gvks := s.typeToGVK[t]
+	for i, existing := range gvks {
+		if existing == gvk {
+			gvks = append(gvks[:i:i], gvks[i+1:]...)
+			break
+		}
+	}
+	if len(gvks) == 0 {
+		delete(s.typeToGVK, t)
+	} else {
+		s.typeToGVK[t] = gvks
+	}
+
+	if unversioned, ok := s.unversionedTypes[t]; ok && unversioned == gvk {
+		delete(s.unversionedTypes, t)
+		if s.unversionedKinds[gvk.Kind] == t {
+			delete(s.unversionedKinds, gvk.Kind)
+		}
+	}
+	return true
+}
+
 // KnownTypes returns the types known for the given version.
 func (s *Scheme) KnownTypes(gv schema.GroupVersion) map[string]reflect.Type {
+	s.rLock()
+	defer s.rUnlock()
 	types := make(map[string]reflect.Type)
 	for gvk, t := range s.gvkToType {
 		if gv != gvk.GroupVersion() {
@@ -211,6 +371,7 @@ func (s *Scheme) KnownTypes(gv schema.GroupVersion) map[string]reflect.Type {
 // A GroupKind might be converted to a different group. That information is available in EquivalentResourceMapper.
 func (s *Scheme) VersionsForGroupKind(gk schema.GroupKind) []schema.GroupVersion {
 	availableVersions := []schema.GroupVersion{}
+	s.rLock()
 	for gvk := range s.gvkToType {
 		if gk != gvk.GroupKind() {
 			continue
@@ -218,6 +379,7 @@ func (s *Scheme) VersionsForGroupKind(gk schema.GroupKind) []schema.GroupVersion
 
 		availableVersions = append(availableVersions, gvk.GroupVersion())
 	}
+	s.rUnlock()
 
 	// order the return for stability
 	ret := []schema.GroupVersion{}
@@ -233,9 +395,93 @@ func (s *Scheme) VersionsForGroupKind(gk schema.GroupKind) []schema.GroupVersion
 	return ret
 }
 
-// AllKnownTypes returns the all known types.
+// AllKnownTypes returns the all known types. If EnableConcurrentRegistration was called, the
+// returned map is a copy.
 func (s *Scheme) AllKnownTypes() map[schema.GroupVersionKind]reflect.Type {
-	return s.gvkToType
+	if s.lock == nil {
+		return s.gvkToType
+	}
+	s.rLock()
+	defer s.rUnlock()
+	types := make(map[schema.GroupVersionKind]reflect.Type, len(s.gvkToType))
+	for gvk, t := range s.gvkToType {
+		types[gvk] = t
+	}
+	return types
+}
+
+// AllKinds returns the group, version and kind of all registered types, sorted by group, version and
+// kind. Unlike AllKnownTypes, it does not expose the internal state of the scheme.
+func (s *Scheme) AllKinds() []schema.GroupVersionKind {
+	s.rLock()
+	kinds := make([]schema.GroupVersionKind, 0, len(s.gvkToType))
+	for gvk := range s.gvkToType {
+		kinds = append(kinds, gvk)
+	}
+	s.rUnlock()
+	sort.Slice(kinds, func(i, j int) bool {
+		if kinds[i].Group != kinds[j].Group {
+			return kinds[i].Group < kinds[j].Group
+		}
+		if kinds[i].Version != kinds[j].Version {
+			return kinds[i].Version < kinds[j].Version
+		}
+		return kinds[i].Kind < kinds[j].Kind
+	})
+	return kinds
+}
+
+// VersionsForKind returns the group versions in which a type is registered with the given kind, in
+// any group. The versions are sorted by group, and within a group by priority as returned by
+// PrioritizedVersionsForGroup, followed by the internal version if registered.
+func (s *Scheme) VersionsForKind(kind string) []schema.GroupVersion {
+	versions := map[string]map[string]bool{}
+	s.rLock()
+	for gvk := range s.gvkToType {
+		if gvk.Kind != kind {
+			continue
+		}
+		if versions[gvk.Group] == nil {
+			versions[gvk.Group] = map[string]bool{}
+		}
+		versions[gvk.Group][gvk.Version] = true
+	}
+	s.rUnlock()
+	groups := make([]string, 0, len(versions))
+	for group := range versions {
+		groups = append(groups, group)
+	}
+	sort.Strings(groups)
+
+	ret := []schema.GroupVersion{}
+	for _, group := range groups {
+		for _, gv := range s.PrioritizedVersionsForGroup(group) {
+			if versions[group][gv.Version] {
+				ret = append(ret, gv)
+			}
+		}
+		if versions[group][APIVersionInternal] {
+			ret = append(ret, schema.GroupVersion{Group: group, Version: APIVersionInternal})
+		}
+	}
+	return ret
+}
+
+// HasDefaulterFunc returns true if a defaulting function is registered for the type of obj with
+// AddTypeDefaultingFunc or AddDefaultingFuncWithContext.
+func (s *Scheme) HasDefaulterFunc(obj Object) bool {
+	s.rLock()
+	defer s.rUnlock()
+	t := reflect.TypeOf(obj)
+	_, ok := s.defaulterFuncs[t]
+	return ok || len(s.contextDefaulterFuncs[t]) > 0
+}
+
+// HasConversionFunc returns true if a conversion function, generated or not, or an ignored
+// conversion is registered from the type of in to the type of out. Both must be pointers.
+// Conversions performed by the default conversion mechanism are not reported.
+func (s *Scheme) HasConversionFunc(in, out interface{}) bool {
+	return s.converter.HasConversionFunc(in, out)
 }
 
 // ObjectKinds returns all possible group,version,kind of the go object, true if the
@@ -260,6 +506,8 @@ func (s *Scheme) ObjectKinds(obj Object) ([]schema.GroupVersionKind, bool, error
 	}
 	t := v.Type()
 
+	s.rLock()
+	defer s.rUnlock()
 	gvks, ok := s.typeToGVK[t]
 	if !ok {
 		return nil, false, NewNotRegisteredErrForType(s.schemeName, t)
@@ -272,6 +520,8 @@ func (s *Scheme) ObjectKinds(obj Object) ([]schema.GroupVersionKind, bool, error
 // Recognizes returns true if the scheme is able to handle the provided group,version,kind
 // of an object.
 func (s *Scheme) Recognizes(gvk schema.GroupVersionKind) bool {
+	s.rLock()
+	defer s.rUnlock()
 	_, exists := s.gvkToType[gvk]
 	return exists
 }
@@ -283,6 +533,8 @@ func (s *Scheme) IsUnversioned(obj Object) (bool, bool) {
 	}
 	t := v.Type()
 
+	s.rLock()
+	defer s.rUnlock()
 	if _, ok := s.typeToGVK[t]; !ok {
 		return false, false
 	}
@@ -293,6 +545,8 @@ func (s *Scheme) IsUnversioned(obj Object) (bool, bool) {
 // New returns a new API object of the given version and name, or an error if it hasn't
 // been registered. The version and kind fields must be specified.
 func (s *Scheme) New(kind schema.GroupVersionKind) (Object, error) {
+	s.rLock()
+	defer s.rUnlock()
 	if t, exists := s.gvkToType[kind]; exists {
 		return reflect.New(t).Interface().(Object), nil
 	}
@@ -314,19 +568,47 @@ func (s *Scheme) AddIgnoredConversionType(from, to interface{}) error {
 // types to the provided function. The function *must* accept objects of a and b - this machinery will not enforce
 // any other guarantee.
 func (s *Scheme) AddConversionFunc(a, b interface{}, fn conversion.ConversionFunc) error {
-	return s.converter.RegisterUntypedConversionFunc(a, b, fn)
+	if err := s.converter.RegisterUntypedConversionFunc(a, b, fn); err != nil {
+		return err
+	}
+	s.addConversionTarget(a, b)
+	return nil
 }
 
 // AddGeneratedConversionFunc registers a function that converts between a and b by passing objects of those
 // types to the provided function. The function *must* accept objects of a and b - this machinery will not enforce
 // any other guarantee.
 func (s *Scheme) AddGeneratedConversionFunc(a, b interface{}, fn conversion.ConversionFunc) error {
-	return s.converter.RegisterGeneratedUntypedConversionFunc(a, b, fn)
+	if err := s.converter.RegisterGeneratedUntypedConversionFunc(a, b, fn); err != nil {
+		return err
+	}
+	s.addConversionTarget(a, b)
+	return nil
+}
+
+// addConversionTarget records that a conversion func from the type of a to the type of b is
+// registered.
+func (s *Scheme) addConversionTarget(a, b interface{}) {
+	source, dest := reflect.TypeOf(a), reflect.TypeOf(b)
+	s.wLock()
+	defer s.wUnlock()
+	s.conversionPaths.reset()
+	if source == dest {
+		return
+	}
+	for _, existing := range s.conversionTargets[source] {
+		if existing == dest {
+			return
+		}
+	}
+	s.conversionTargets[source] = append(s.conversionTargets[source], dest)
 }
 
 // AddFieldLabelConversionFunc adds a conversion function to convert field selectors
 // of the given kind from the given version to internal version representation.
 func (s *Scheme) AddFieldLabelConversionFunc(gvk schema.GroupVersionKind, conversionFunc FieldLabelConversionFunc) error {
+	s.wLock()
+	defer s.wUnlock()
 	s.fieldLabelConversionFuncs[gvk] = conversionFunc
 	return nil
 }
@@ -337,14 +619,43 @@ func (s *Scheme) AddFieldLabelConversionFunc(gvk schema.GroupVersionKind, conver
 // defaulted object matches srcType. If this function is invoked twice with the
 // same srcType, the fn passed to the later call will be used instead.
 func (s *Scheme) AddTypeDefaultingFunc(srcType Object, fn func(interface{})) {
+	s.wLock()
+	defer s.wUnlock()
 	s.defaulterFuncs[reflect.TypeOf(srcType)] = fn
 }
 
-// Default sets defaults on the provided Object.
+// AddDefaultingFuncWithContext registers a function that is passed the context of the
+// defaulting and a pointer to an object, and can default fields on the object. Unlike with
+// AddTypeDefaultingFunc, several functions can be registered for the same srcType. They are
+// invoked in the order they were registered, after the function registered with
+// AddTypeDefaultingFunc, when Default() or DefaultWithContext() is called.
+func (s *Scheme) AddDefaultingFuncWithContext(srcType Object, fn func(ctx context.Context, obj interface{})) {
+	t := reflect.TypeOf(srcType)
+	s.wLock()
+	defer s.wUnlock()
+	s.contextDefaulterFuncs[t] = append(s.contextDefaulterFuncs[t], fn)
+}
+
+// Default sets defaults on the provided Object. Functions registered with
+// AddDefaultingFuncWithContext are passed context.Background().
 func (s *Scheme) Default(src Object) {
-	if fn, ok := s.defaulterFuncs[reflect.TypeOf(src)]; ok {
+	s.DefaultWithContext(context.Background(), src)
+}
+
+// DefaultWithContext sets defaults on the provided Object, passing ctx to the functions
+// registered with AddDefaultingFuncWithContext, e.g. to provide request-scoped data.
+func (s *Scheme) DefaultWithContext(ctx context.Context, src Object) {
+	t := reflect.TypeOf(src)
+	s.rLock()
+	fn, ok := s.defaulterFuncs[t]
+	contextFns := s.contextDefaulterFuncs[t]
+	s.rUnlock()
+	if ok {
 		fn(src)
 	}
+	for _, fn := range contextFns {
+		fn(ctx, src)
+	}
 }
 
 // Convert will attempt to convert in into out. Both must be pointers. For easy
@@ -352,7 +663,9 @@ func (s *Scheme) Default(src Object) {
 // possible. You can call this with types that haven't been registered (for example,
 // a to test conversion of types that are nested within registered types). The
 // context interface is passed to the convertor. Convert also supports Unstructured
-// types and will convert them intelligently.
+// types and will convert them intelligently. Registered versions of a kind without
+// a conversion func between them are converted through other registered versions
+// of the kind, as described in ConvertToVersion.
 func (s *Scheme) Convert(in, out interface{}, context interface{}) error {
 	unstructuredIn, okIn := in.(Unstructured)
 	unstructuredOut, okOut := out.(Unstructured)
@@ -416,13 +729,15 @@ func (s *Scheme) Convert(in, out interface{}, context interface{}) error {
 
 	meta := s.generateConvertMeta(in)
 	meta.Context = context
-	return s.converter.Convert(in, out, meta)
+	return s.convert(in, out, meta)
 }
 
 // ConvertFieldLabel alters the given field label and value for an kind field selector from
 // versioned representation to an unversioned one or returns an error.
 func (s *Scheme) ConvertFieldLabel(gvk schema.GroupVersionKind, label, value string) (string, string, error) {
+	s.rLock()
 	conversionFunc, ok := s.fieldLabelConversionFuncs[gvk]
+	s.rUnlock()
 	if !ok {
 		return DefaultMetaV1FieldSelectorConversion(label, value)
 	}
@@ -434,6 +749,9 @@ func (s *Scheme) ConvertFieldLabel(gvk schema.GroupVersionKind, label, value str
 // contain the inKind (or a mapping by name defined with AddKnownTypeWithName). Will also
 // return an error if the conversion does not result in a valid Object being
 // returned. Passes target down to the conversion methods as the Context on the scope.
+// If no conversion func is registered from the type of in to the type of the target
+// version, in is converted through the shortest chain of conversion funcs between
+// other registered versions of its kind, e.g. from v1alpha1 to v1 through v1beta1.
 func (s *Scheme) ConvertToVersion(in Object, target GroupVersioner) (Object, error) {
 	return s.convertToVersion(true, in, target)
 }
@@ -471,7 +789,10 @@ func (s *Scheme) convertToVersion(copy bool, in Object, target GroupVersioner) (
 		}
 	}
 
+	s.rLock()
 	kinds, ok := s.typeToGVK[t]
+	unversionedKind, unversioned := s.unversionedTypes[t]
+	s.rUnlock()
 	if !ok || len(kinds) == 0 {
 		return nil, NewNotRegisteredErrForType(s.schemeName, t)
 	}
@@ -480,7 +801,7 @@ func (s *Scheme) convertToVersion(copy bool, in Object, target GroupVersioner) (
 	if !ok {
 		// try to see if this type is listed as unversioned (for legacy support)
 		// TODO: when we move to server API versions, we should completely remove the unversioned concept
-		if unversionedKind, ok := s.unversionedTypes[t]; ok {
+		if unversioned {
 			if gvk, ok := target.KindForGroupVersionKinds([]schema.GroupVersionKind{unversionedKind}); ok {
 				return copyAndSetTargetKind(copy, in, gvk)
 			}
@@ -497,7 +818,7 @@ func (s *Scheme) convertToVersion(copy bool, in Object, target GroupVersioner) (
 	}
 
 	// type is unversioned, no conversion necessary
-	if unversionedKind, ok := s.unversionedTypes[t]; ok {
+	if unversioned {
 		if gvk, ok := target.KindForGroupVersionKinds([]schema.GroupVersionKind{unversionedKind}); ok {
 			return copyAndSetTargetKind(copy, in, gvk)
 		}
@@ -515,7 +836,7 @@ func (s *Scheme) convertToVersion(copy bool, in Object, target GroupVersioner) (
 
 	meta := s.generateConvertMeta(in)
 	meta.Context = target
-	if err := s.converter.Convert(in, out, meta); err != nil {
+	if err := s.convert(in, out, meta); err != nil {
 		return nil, err
 	}
 
@@ -542,6 +863,129 @@ func (s *Scheme) unstructuredToTyped(in Unstructured) (Object, error) {
 	return typed, nil
 }
 
+// conversionPair is a pair of types to convert between.
+type conversionPair struct {
+	source reflect.Type
+	dest   reflect.Type
+}
+
+// conversionPathCache caches, for pairs of types, the intermediate types of the shortest chain of
+// conversion funcs between them, or nil if there is none. It has its own lock, since paths are
+// cached by conversions, which don't take the write lock of the scheme.
+type conversionPathCache struct {
+	lock  sync.RWMutex
+	paths map[conversionPair][]reflect.Type
+}
+
+func newConversionPathCache() *conversionPathCache {
+	return &conversionPathCache{paths: map[conversionPair][]reflect.Type{}}
+}
+
+func (c *conversionPathCache) reset() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.paths = map[conversionPair][]reflect.Type{}
+}
+
+// convert converts in to out with the conversion func registered between their types or, if
+// there is none, through the shortest chain of conversion funcs between registered versions of
+// their kind.
+func (s *Scheme) convert(in, out interface{}, meta *conversion.Meta) error {
+	if !s.converter.HasConversionFunc(in, out) {
+		if path := s.conversionPath(reflect.TypeOf(in), reflect.TypeOf(out)); path != nil {
+			return s.convertAlong(path, in, out, meta)
+		}
+	}
+	return s.converter.Convert(in, out, meta)
+}
+
+// conversionPath returns the intermediate types of the shortest chain of conversion funcs from
+// source to dest, or nil if there is none. Both must be pointers to types registered for the same
+// group and kind, and the chain only goes through other types registered for that group and kind,
+// so that unrelated conversion funcs, e.g. between primitive types, are never chained.
+func (s *Scheme) conversionPath(source, dest reflect.Type) []reflect.Type {
+	if source == nil || dest == nil || source.Kind() != reflect.Ptr || dest.Kind() != reflect.Ptr {
+		return nil
+	}
+	pair := conversionPair{source, dest}
+	s.conversionPaths.lock.RLock()
+	path, found := s.conversionPaths.paths[pair]
+	s.conversionPaths.lock.RUnlock()
+	if found {
+		return path
+	}
+
+	// hold the read lock until the path is cached, so that registering a type or a conversion
+	// func resets the cache only after paths found before are stored
+	s.rLock()
+	defer s.rUnlock()
+	groupKinds := map[schema.GroupKind]bool{}
+	for _, gvk := range s.typeToGVK[source.Elem()] {
+		groupKinds[gvk.GroupKind()] = false
+	}
+	for _, gvk := range s.typeToGVK[dest.Elem()] {
+		if _, ok := groupKinds[gvk.GroupKind()]; ok {
+			groupKinds[gvk.GroupKind()] = true
+		}
+	}
+	routable := func(t reflect.Type) bool {
+		if t.Kind() != reflect.Ptr {
+			return false
+		}
+		for _, gvk := range s.typeToGVK[t.Elem()] {
+			if groupKinds[gvk.GroupKind()] {
+				return true
+			}
+		}
+		return false
+	}
+
+	// breadth-first search, visiting every type once so that cycles terminate
+	previous := map[reflect.Type]reflect.Type{source: nil}
+	var queue []reflect.Type
+	if routable(dest) {
+		queue = []reflect.Type{source}
+	}
+	for len(queue) > 0 && path == nil {
+		current := queue[0]
+		queue = queue[1:]
+		for _, t := range s.conversionTargets[current] {
+			if _, visited := previous[t]; visited || !routable(t) {
+				continue
+			}
+			previous[t] = current
+			if t == dest {
+				for hop := current; hop != source; hop = previous[hop] {
+					path = append([]reflect.Type{hop}, path...)
+				}
+				break
+			}
+			queue = append(queue, t)
+		}
+	}
+
+	s.conversionPaths.lock.Lock()
+	s.conversionPaths.paths[pair] = path
+	s.conversionPaths.lock.Unlock()
+	return path
+}
+
+// convertAlong converts in to out through new objects of the intermediate types of path.
+func (s *Scheme) convertAlong(path []reflect.Type, in, out interface{}, meta *conversion.Meta) error {
+	current := in
+	for i := 0; i <= len(path); i++ {
+		target := out
+		if i < len(path) {
+			target = reflect.New(path[i].Elem()).Interface()
+		}
+		if err := s.converter.Convert(current, target, meta); err != nil {
+			return fmt.Errorf("converting (%T) to (%T) through (%T): %v", in, out, target, err)
+		}
+		current = target
+	}
+	return nil
+}
+
 // generateConvertMeta constructs the meta value we pass to Convert.
 func (s *Scheme) generateConvertMeta(in interface{}) *conversion.Meta {
 	return s.converter.DefaultMeta(reflect.TypeOf(in))
@@ -584,12 +1028,16 @@ func (s *Scheme) SetVersionPriority(versions ...schema.GroupVersion) error {
 		return fmt.Errorf("must register versions for exactly one group: %v", strings.Join(groups.List(), ", "))
 	}
 
+	s.wLock()
+	defer s.wUnlock()
 	s.versionPriority[groups.List()[0]] = order
 	return nil
 }
 
 // PrioritizedVersionsForGroup returns versions for a single group in priority order
 func (s *Scheme) PrioritizedVersionsForGroup(group string) []schema.GroupVersion {
+	s.rLock()
+	defer s.rUnlock()
 	ret := []schema.GroupVersion{}
 	for _, version := range s.versionPriority[group] {
 		ret = append(ret, schema.GroupVersion{Group: group, Version: version})
@@ -616,6 +1064,8 @@ func (s *Scheme) PrioritizedVersionsForGroup(group string) []schema.GroupVersion
 // PrioritizedVersionsAllGroups returns all known versions in their priority order.  Groups are random, but
 // versions for a single group are prioritized
 func (s *Scheme) PrioritizedVersionsAllGroups() []schema.GroupVersion {
+	s.rLock()
+	defer s.rUnlock()
 	ret := []schema.GroupVersion{}
 	for group, versions := range s.versionPriority {
 		for _, version := range versions {
@@ -640,6 +1090,8 @@ func (s *Scheme) PrioritizedVersionsAllGroups() []schema.GroupVersion {
 // PreferredVersionAllGroups returns the most preferred version for every group.
 // group ordering is random.
 func (s *Scheme) PreferredVersionAllGroups() []schema.GroupVersion {
+	s.rLock()
+	defer s.rUnlock()
 	ret := []schema.GroupVersion{}
 	for group, versions := range s.versionPriority {
 		for _, version := range versions {
@@ -665,6 +1117,8 @@ func (s *Scheme) PreferredVersionAllGroups() []schema.GroupVersion {
 
 // IsGroupRegistered returns true if types for the group have been registered with the scheme
 func (s *Scheme) IsGroupRegistered(group string) bool {
+	s.rLock()
+	defer s.rUnlock()
 	for _, observedVersion := range s.observedVersions {
 		if observedVersion.Group == group {
 			return true
@@ -675,6 +1129,8 @@ func (s *Scheme) IsGroupRegistered(group string) bool {
 
 // IsVersionRegistered returns true if types for the version have been registered with the scheme
 func (s *Scheme) IsVersionRegistered(version schema.GroupVersion) bool {
+	s.rLock()
+	defer s.rUnlock()
 	for _, observedVersion := range s.observedVersions {
 		if observedVersion == version {
 			return true
@@ -684,6 +1140,7 @@ func (s *Scheme) IsVersionRegistered(version schema.GroupVersion) bool {
 	return false
 }
 
+// addObservedVersion records version as observed. The caller must hold the write lock.
 func (s *Scheme) addObservedVersion(version schema.GroupVersion) {
 	if len(version.Version) == 0 || version.Version == APIVersionInternal {
 		return
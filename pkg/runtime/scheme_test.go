@@ -17,11 +17,14 @@ limitations under the License.
 package runtime_test
 
 import (
+	"context"
 	"fmt"
 	"reflect"
 	"strings"
+	"sync"
 	"testing"
 
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/conversion"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
@@ -502,6 +505,244 @@ func TestKnownTypes(t *testing.T) {
 	}
 }
 
+func TestSchemeIntrospection(t *testing.T) {
+	s := runtime.NewScheme()
+	internalGV := schema.GroupVersion{Group: "foo", Version: runtime.APIVersionInternal}
+	v1 := schema.GroupVersion{Group: "foo", Version: "v1"}
+	v2 := schema.GroupVersion{Group: "foo", Version: "v2"}
+	other := schema.GroupVersion{Group: "bar", Version: "v1"}
+	s.AddKnownTypes(internalGV, &runtimetesting.InternalSimple{})
+	s.AddKnownTypeWithName(v1.WithKind("Simple"), &runtimetesting.ExternalSimple{})
+	s.AddKnownTypeWithName(v2.WithKind("Simple"), &runtimetesting.ExternalSimple{})
+	s.AddKnownTypeWithName(internalGV.WithKind("Simple"), &runtimetesting.InternalSimple{})
+	s.AddKnownTypeWithName(other.WithKind("Simple"), &runtimetesting.ExternalSimple{})
+	if err := s.SetVersionPriority(v2, v1); err != nil {
+		t.Fatal(err)
+	}
+
+	expectedKinds := []schema.GroupVersionKind{
+		other.WithKind("Simple"),
+		internalGV.WithKind("InternalSimple"),
+		internalGV.WithKind("Simple"),
+		v1.WithKind("Simple"),
+		v2.WithKind("Simple"),
+	}
+	if e, a := expectedKinds, s.AllKinds(); !reflect.DeepEqual(e, a) {
+		t.Errorf("expected %v, got %v", e, a)
+	}
+	if e, a := []schema.GroupVersion{other, v2, v1, internalGV}, s.VersionsForKind("Simple"); !reflect.DeepEqual(e, a) {
+		t.Errorf("expected %v, got %v", e, a)
+	}
+	if a := s.VersionsForKind("Missing"); len(a) != 0 {
+		t.Errorf("expected no versions, got %v", a)
+	}
+
+	if s.HasDefaulterFunc(&runtimetesting.ExternalSimple{}) {
+		t.Errorf("expected no defaulting function")
+	}
+	s.AddTypeDefaultingFunc(&runtimetesting.ExternalSimple{}, func(interface{}) {})
+	if !s.HasDefaulterFunc(&runtimetesting.ExternalSimple{}) {
+		t.Errorf("expected a defaulting function")
+	}
+
+	internal, external := (*runtimetesting.InternalSimple)(nil), (*runtimetesting.ExternalSimple)(nil)
+	if s.HasConversionFunc(internal, external) {
+		t.Errorf("expected no conversion function")
+	}
+	if err := (&testConversions{}).registerConversions(s); err != nil {
+		t.Fatal(err)
+	}
+	if !s.HasConversionFunc(internal, external) || !s.HasConversionFunc(external, internal) {
+		t.Errorf("expected conversion functions in both directions")
+	}
+	if !s.HasConversionFunc(external, external) {
+		t.Errorf("expected the self-conversion registered with the type")
+	}
+	complexType := (*runtimetesting.ExternalComplex)(nil)
+	if err := s.AddIgnoredConversionType(internal, complexType); err != nil {
+		t.Fatal(err)
+	}
+	if !s.HasConversionFunc(internal, complexType) || s.HasConversionFunc(complexType, internal) {
+		t.Errorf("expected only the ignored conversion to be reported")
+	}
+}
+
+func TestSchemeUnregister(t *testing.T) {
+	s := runtime.NewScheme()
+	v1 := schema.GroupVersion{Group: "foo", Version: "v1"}
+	v2 := schema.GroupVersion{Group: "foo", Version: "v2"}
+	s.AddKnownTypes(v1, &runtimetesting.ExternalSimple{})
+	s.AddKnownTypeWithName(v2.WithKind("ExternalSimple"), &runtimetesting.ExternalSimple{})
+	s.AddUnversionedTypes(v2, &runtimetesting.InternalSimple{})
+	if err := s.AddFieldLabelConversionFunc(v1.WithKind("ExternalSimple"), func(label, value string) (string, string, error) {
+		return label, value, nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	s.Unregister(v1.WithKind("ExternalSimple"))
+	if s.Recognizes(v1.WithKind("ExternalSimple")) || s.IsVersionRegistered(v1) {
+		t.Errorf("expected %v to be unregistered", v1)
+	}
+	if _, _, err := s.ConvertFieldLabel(v1.WithKind("ExternalSimple"), "a", "b"); err == nil {
+		t.Errorf("expected the field label conversion to be removed")
+	}
+	kinds, _, err := s.ObjectKinds(&runtimetesting.ExternalSimple{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e, a := []schema.GroupVersionKind{v2.WithKind("ExternalSimple")}, kinds; !reflect.DeepEqual(e, a) {
+		t.Errorf("expected %v, got %v", e, a)
+	}
+	if e, a := []schema.GroupVersion{v2}, s.PrioritizedVersionsAllGroups(); !reflect.DeepEqual(e, a) {
+		t.Errorf("expected %v, got %v", e, a)
+	}
+
+	s.Unregister(v2.WithKind("InternalSimple"))
+	if unversioned, registered := s.IsUnversioned(&runtimetesting.InternalSimple{}); unversioned || registered {
+		t.Errorf("expected InternalSimple to be unregistered")
+	}
+	if _, err := s.New(schema.GroupVersionKind{Group: "other", Version: "v1", Kind: "InternalSimple"}); err == nil {
+		t.Errorf("expected the unversioned kind to be unregistered")
+	}
+
+	s.Unregister(v2.WithKind("ExternalSimple"))
+	s.Unregister(v2.WithKind("ExternalSimple"))
+	if _, _, err := s.ObjectKinds(&runtimetesting.ExternalSimple{}); !runtime.IsNotRegisteredError(err) {
+		t.Errorf("expected a not registered error, got %v", err)
+	}
+	if len(s.AllKnownTypes()) != 0 || s.IsGroupRegistered("foo") {
+		t.Errorf("expected no types left, got %v", s.AllKnownTypes())
+	}
+}
+
+func TestSchemeOverride(t *testing.T) {
+	s := runtime.NewScheme()
+	gvk := schema.GroupVersionKind{Group: "foo", Version: "v1", Kind: "Simple"}
+	s.AddKnownTypeWithName(gvk, &runtimetesting.InternalSimple{})
+	s.Override(gvk, &runtimetesting.ExternalSimple{})
+
+	obj, err := s.New(gvk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := obj.(*runtimetesting.ExternalSimple); !ok {
+		t.Errorf("expected the overriding type, got %T", obj)
+	}
+	if _, _, err := s.ObjectKinds(&runtimetesting.InternalSimple{}); !runtime.IsNotRegisteredError(err) {
+		t.Errorf("expected the overridden type to be unregistered, got %v", err)
+	}
+	kinds, _, err := s.ObjectKinds(&runtimetesting.ExternalSimple{})
+	if err != nil || !reflect.DeepEqual([]schema.GroupVersionKind{gvk}, kinds) {
+		t.Errorf("expected %v, got %v (%v)", gvk, kinds, err)
+	}
+
+	other := schema.GroupVersionKind{Group: "foo", Version: "v1", Kind: "Other"}
+	s.Override(other, &runtimetesting.ExternalSimple{})
+	if !s.Recognizes(other) {
+		t.Errorf("expected %v to be registered", other)
+	}
+}
+
+func TestConcurrentRegistration(t *testing.T) {
+	internalGV := schema.GroupVersion{Group: "test.group", Version: runtime.APIVersionInternal}
+	externalGV := schema.GroupVersion{Group: "test.group", Version: "v1"}
+	s := runtime.NewScheme()
+	s.EnableConcurrentRegistration()
+	s.AddKnownTypeWithName(internalGV.WithKind("Simple"), &runtimetesting.InternalSimple{})
+	s.AddKnownTypeWithName(externalGV.WithKind("Simple"), &runtimetesting.ExternalSimple{})
+	registerConversions := func() {
+		if err := s.AddConversionFunc((*runtimetesting.InternalSimple)(nil), (*runtimetesting.ExternalSimple)(nil), func(a, b interface{}, scope conversion.Scope) error {
+			b.(*runtimetesting.ExternalSimple).TestString = a.(*runtimetesting.InternalSimple).TestString
+			return nil
+		}); err != nil {
+			t.Error(err)
+		}
+		if err := s.AddConversionFunc((*runtimetesting.ExternalSimple)(nil), (*runtimetesting.InternalSimple)(nil), func(a, b interface{}, scope conversion.Scope) error {
+			b.(*runtimetesting.InternalSimple).TestString = a.(*runtimetesting.ExternalSimple).TestString
+			return nil
+		}); err != nil {
+			t.Error(err)
+		}
+	}
+	registerConversions()
+	codec := serializer.NewCodecFactory(s).LegacyCodec(externalGV)
+
+	const kinds = 50
+	wg := sync.WaitGroup{}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < kinds; i++ {
+			kind := fmt.Sprintf("Simple%d", i)
+			s.AddKnownTypeWithName(internalGV.WithKind(kind), &runtimetesting.InternalSimple{})
+			s.AddKnownTypeWithName(externalGV.WithKind(kind), &runtimetesting.ExternalSimple{})
+			s.AddTypeDefaultingFunc(&runtimetesting.ExternalSimple{}, func(obj interface{}) {})
+			if err := s.SetVersionPriority(externalGV); err != nil {
+				t.Error(err)
+			}
+			registerConversions()
+		}
+	}()
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < kinds; j++ {
+				data, err := runtime.Encode(codec, &runtimetesting.InternalSimple{TestString: "value"})
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				obj, err := runtime.Decode(codec, data)
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				if simple, ok := obj.(*runtimetesting.InternalSimple); !ok || simple.TestString != "value" {
+					t.Errorf("unexpected object %#v", obj)
+				}
+				if _, err := s.New(externalGV.WithKind("Simple")); err != nil {
+					t.Error(err)
+				}
+				s.Default(&runtimetesting.ExternalSimple{})
+				for range s.AllKnownTypes() {
+				}
+				s.PrioritizedVersionsAllGroups()
+			}
+		}()
+	}
+	wg.Wait()
+
+	for i := 0; i < kinds; i++ {
+		if gvk := externalGV.WithKind(fmt.Sprintf("Simple%d", i)); !s.Recognizes(gvk) {
+			t.Errorf("expected %v to be registered", gvk)
+		}
+	}
+}
+
+func BenchmarkSchemeObjectKinds(b *testing.B) {
+	for _, concurrent := range []bool{false, true} {
+		b.Run(fmt.Sprintf("concurrent registration %t", concurrent), func(b *testing.B) {
+			s := runtime.NewScheme()
+			if concurrent {
+				s.EnableConcurrentRegistration()
+			}
+			s.AddKnownTypeWithName(schema.GroupVersionKind{Group: "test.group", Version: "v1", Kind: "Simple"}, &runtimetesting.ExternalSimple{})
+			obj := &runtimetesting.ExternalSimple{}
+			b.ReportAllocs()
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					if _, _, err := s.ObjectKinds(obj); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		})
+	}
+}
+
 func TestAddKnownTypesIdemPotent(t *testing.T) {
 	s := runtime.NewScheme()
 
@@ -923,6 +1164,87 @@ func TestConvert(t *testing.T) {
 	}
 }
 
+func TestConvertThroughVersions(t *testing.T) {
+	v1alpha1 := schema.GroupVersion{Group: "test.group", Version: "v1alpha1"}
+	v1beta1 := schema.GroupVersion{Group: "test.group", Version: "v1beta1"}
+	v1 := schema.GroupVersion{Group: "test.group", Version: "v1"}
+	s := runtime.NewScheme()
+	utilruntime.Must(metav1.RegisterConversions(s))
+	s.AddKnownTypeWithName(v1alpha1.WithKind("Simple"), &runtimetesting.InternalSimple{})
+	s.AddKnownTypeWithName(v1beta1.WithKind("Simple"), &runtimetesting.ExternalSimple{})
+	s.AddKnownTypeWithName(v1.WithKind("Simple"), &runtimetesting.ExternalComplex{})
+	s.AddKnownTypeWithName(v1.WithKind("Other"), &runtimetesting.InternalComplex{})
+
+	calls := 0
+	register := func(a, b interface{}, suffix string) {
+		if err := s.AddConversionFunc(a, b, func(in, out interface{}, scope conversion.Scope) error {
+			calls++
+			value := reflect.ValueOf(in).Elem().Field(1).String()
+			if value == "fail" {
+				return fmt.Errorf("invalid value")
+			}
+			reflect.ValueOf(out).Elem().Field(1).SetString(value + suffix)
+			return nil
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	register((*runtimetesting.InternalSimple)(nil), (*runtimetesting.ExternalSimple)(nil), "+v1beta1")
+	register((*runtimetesting.ExternalSimple)(nil), (*runtimetesting.InternalSimple)(nil), "+v1alpha1")
+	register((*runtimetesting.ExternalSimple)(nil), (*runtimetesting.ExternalComplex)(nil), "+v1")
+	register((*runtimetesting.ExternalSimple)(nil), (*runtimetesting.InternalComplex)(nil), "+other")
+
+	obj, err := s.ConvertToVersion(&runtimetesting.InternalSimple{TestString: "a"}, v1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, ok := obj.(*runtimetesting.ExternalComplex)
+	if !ok || out.String != "a+v1beta1+v1" || calls != 2 {
+		t.Errorf("expected %q in 2 calls, got %#v in %d calls", "a+v1beta1+v1", obj, calls)
+	}
+	if err := s.Convert(&runtimetesting.InternalSimple{TestString: "fail"}, &runtimetesting.ExternalComplex{}, nil); err == nil || !strings.Contains(err.Error(), "invalid value") {
+		t.Errorf("expected the error of the failing hop, got %v", err)
+	}
+	if _, err := s.ConvertToVersion(&runtimetesting.ExternalComplex{}, v1alpha1); err == nil {
+		t.Errorf("expected an error without a path back from v1")
+	}
+	// conversions are only chained between versions of the same kind
+	if err := s.Convert(&runtimetesting.InternalSimple{}, &runtimetesting.InternalComplex{}, nil); err == nil {
+		t.Errorf("expected an error converting to another kind")
+	}
+	// or between registered kinds at all
+	i, i64 := 1, int64(0)
+	if err := s.Convert(&i, &i64, nil); err == nil {
+		t.Errorf("expected an error chaining conversions of unregistered types")
+	}
+	var strs []string
+	var ptr *int64
+	if err := s.Convert(&strs, &ptr, nil); err == nil {
+		t.Errorf("expected an error chaining conversions of unregistered types")
+	}
+
+	// registering a direct conversion replaces the cached path
+	register((*runtimetesting.InternalSimple)(nil), (*runtimetesting.ExternalComplex)(nil), "+direct")
+	calls = 0
+	out = &runtimetesting.ExternalComplex{}
+	if err := s.Convert(&runtimetesting.InternalSimple{TestString: "a"}, out, nil); err != nil {
+		t.Fatal(err)
+	}
+	if out.String != "a+direct" || calls != 1 {
+		t.Errorf("expected %q in 1 call, got %q in %d calls", "a+direct", out.String, calls)
+	}
+
+	// and registering a new hop makes new paths available
+	register((*runtimetesting.ExternalComplex)(nil), (*runtimetesting.ExternalSimple)(nil), "+v1beta1")
+	back := &runtimetesting.InternalSimple{}
+	if err := s.Convert(&runtimetesting.ExternalComplex{String: "v"}, back, nil); err != nil {
+		t.Fatal(err)
+	}
+	if back.TestString != "v+v1beta1+v1alpha1" {
+		t.Errorf("expected %q, got %q", "v+v1beta1+v1alpha1", back.TestString)
+	}
+}
+
 func TestMetaValues(t *testing.T) {
 	internalGV := schema.GroupVersion{Group: "test.group", Version: runtime.APIVersionInternal}
 	externalGV := schema.GroupVersion{Group: "test.group", Version: "externalVersion"}
@@ -1008,3 +1330,41 @@ func TestMetaValuesUnregisteredConvert(t *testing.T) {
 		t.Errorf("Expected %v, got %v", e, a)
 	}
 }
+
+func TestDefaultWithContext(t *testing.T) {
+	type contextKey struct{}
+	s := runtime.NewScheme()
+	var calls []string
+	s.AddDefaultingFuncWithContext(&runtimetesting.ExternalSimple{}, func(ctx context.Context, obj interface{}) {
+		value, _ := ctx.Value(contextKey{}).(string)
+		calls = append(calls, "first "+value)
+		obj.(*runtimetesting.ExternalSimple).TestString += value
+	})
+	if !s.HasDefaulterFunc(&runtimetesting.ExternalSimple{}) {
+		t.Errorf("expected a defaulting function to be registered")
+	}
+	s.AddTypeDefaultingFunc(&runtimetesting.ExternalSimple{}, func(obj interface{}) {
+		calls = append(calls, "type")
+	})
+	s.AddDefaultingFuncWithContext(&runtimetesting.ExternalSimple{}, func(ctx context.Context, obj interface{}) {
+		calls = append(calls, "second")
+	})
+	s.AddDefaultingFuncWithContext(&runtimetesting.InternalSimple{}, func(ctx context.Context, obj interface{}) {
+		calls = append(calls, "other type")
+	})
+
+	obj := &runtimetesting.ExternalSimple{}
+	s.DefaultWithContext(context.WithValue(context.Background(), contextKey{}, "value"), obj)
+	if e, a := []string{"type", "first value", "second"}, calls; !reflect.DeepEqual(e, a) {
+		t.Errorf("expected %v, got %v", e, a)
+	}
+	if obj.TestString != "value" {
+		t.Errorf("expected the object to be defaulted, got %#v", obj)
+	}
+
+	calls = nil
+	s.Default(&runtimetesting.ExternalSimple{})
+	if e, a := []string{"type", "first ", "second"}, calls; !reflect.DeepEqual(e, a) {
+		t.Errorf("expected %v, got %v", e, a)
+	}
+}
@@ -0,0 +1,112 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package schemevalidation verifies the wiring of the types registered in a runtime.Scheme.
+package schemevalidation
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// ConversionResult describes the functions missing for an external type whose kind is also
+// registered in the internal version of its group.
+type ConversionResult struct {
+	// Kind is the group, version and kind of the external type.
+	Kind schema.GroupVersionKind
+	// ExternalType and InternalType are the types registered for Kind in its version and in the
+	// internal version of its group.
+	ExternalType reflect.Type
+	InternalType reflect.Type
+
+	// MissingToInternal is true if no conversion function is registered from the external type
+	// to the internal type.
+	MissingToInternal bool
+	// MissingFromInternal is true if no conversion function is registered from the internal type
+	// to the external type.
+	MissingFromInternal bool
+	// MissingDefaulter is true if no defaulting function is registered for the external type.
+	MissingDefaulter bool
+}
+
+// MissingConversion returns true if a conversion function is missing in either direction.
+func (r ConversionResult) MissingConversion() bool {
+	return r.MissingToInternal || r.MissingFromInternal
+}
+
+func (r ConversionResult) String() string {
+	var missing []string
+	if r.MissingToInternal {
+		missing = append(missing, fmt.Sprintf("conversion from %v to %v", r.ExternalType, r.InternalType))
+	}
+	if r.MissingFromInternal {
+		missing = append(missing, fmt.Sprintf("conversion from %v to %v", r.InternalType, r.ExternalType))
+	}
+	if r.MissingDefaulter {
+		missing = append(missing, fmt.Sprintf("defaulting function for %v", r.ExternalType))
+	}
+	return fmt.Sprintf("%v: missing %s", r.Kind, strings.Join(missing, ", "))
+}
+
+// CheckConversions returns a result for every external type of scheme whose kind is also
+// registered in the internal version of its group and that is missing a conversion function
+// to or from the internal type, or a defaulting function. Types registered with the same Go
+// type in both versions and unversioned types are ignored, and only conversion functions
+// registered for the exact pair of types are considered, not chains of conversions. Results are
+// sorted by kind.
+//
+// Many external types don't need defaulting, so callers typically filter the results for
+// MissingConversion and check MissingDefaulter only for the kinds known to have defaults.
+func CheckConversions(scheme *runtime.Scheme) []ConversionResult {
+	var results []ConversionResult
+	known := scheme.AllKnownTypes()
+	for gvk, externalType := range known {
+		if gvk.Version == runtime.APIVersionInternal {
+			continue
+		}
+		internalType, ok := known[gvk.GroupKind().WithVersion(runtime.APIVersionInternal)]
+		if !ok || internalType == externalType {
+			continue
+		}
+		// all the types known to a scheme are registered from runtime.Objects
+		external := reflect.New(externalType).Interface().(runtime.Object)
+		if unversioned, _ := scheme.IsUnversioned(external); unversioned {
+			continue
+		}
+		internal := reflect.New(internalType).Interface()
+
+		result := ConversionResult{
+			Kind:                gvk,
+			ExternalType:        externalType,
+			InternalType:        internalType,
+			MissingToInternal:   !scheme.HasConversionFunc(external, internal),
+			MissingFromInternal: !scheme.HasConversionFunc(internal, external),
+			MissingDefaulter:    !scheme.HasDefaulterFunc(external),
+		}
+		if result.MissingConversion() || result.MissingDefaulter {
+			results = append(results, result)
+		}
+	}
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Kind.String() < results[j].Kind.String()
+	})
+	return results
+}
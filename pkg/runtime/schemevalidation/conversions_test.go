@@ -0,0 +1,71 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package schemevalidation
+
+import (
+	"reflect"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/conversion"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	runtimetesting "k8s.io/apimachinery/pkg/runtime/testing"
+)
+
+func TestCheckConversions(t *testing.T) {
+	internalGV := schema.GroupVersion{Group: "test.group", Version: runtime.APIVersionInternal}
+	externalGV := schema.GroupVersion{Group: "test.group", Version: "v1"}
+	otherGV := schema.GroupVersion{Group: "other.group", Version: "v1"}
+
+	scheme := runtime.NewScheme()
+	scheme.AddKnownTypeWithName(internalGV.WithKind("Simple"), &runtimetesting.InternalSimple{})
+	scheme.AddKnownTypeWithName(externalGV.WithKind("Simple"), &runtimetesting.ExternalSimple{})
+	scheme.AddKnownTypeWithName(internalGV.WithKind("TestType1"), &runtimetesting.TestType1{})
+	scheme.AddKnownTypeWithName(externalGV.WithKind("TestType1"), &runtimetesting.ExternalTestType1{})
+	scheme.AddKnownTypes(internalGV, &runtimetesting.ExternalInternalSame{})
+	scheme.AddKnownTypes(externalGV, &runtimetesting.ExternalInternalSame{})
+	scheme.AddKnownTypeWithName(otherGV.WithKind("Simple"), &runtimetesting.ExternalSimple{})
+	scheme.AddKnownTypeWithName(externalGV.WithKind("Unregistered"), &runtimetesting.ExternalComplex{})
+
+	noop := func(a, b interface{}, scope conversion.Scope) error { return nil }
+	if err := scheme.AddConversionFunc((*runtimetesting.ExternalSimple)(nil), (*runtimetesting.InternalSimple)(nil), noop); err != nil {
+		t.Fatal(err)
+	}
+	if err := scheme.AddGeneratedConversionFunc((*runtimetesting.InternalSimple)(nil), (*runtimetesting.ExternalSimple)(nil), noop); err != nil {
+		t.Fatal(err)
+	}
+	if err := scheme.AddIgnoredConversionType(&runtimetesting.TestType1{}, &runtimetesting.ExternalTestType1{}); err != nil {
+		t.Fatal(err)
+	}
+	scheme.AddTypeDefaultingFunc(&runtimetesting.ExternalSimple{}, func(interface{}) {})
+
+	expected := []ConversionResult{
+		{
+			Kind:              externalGV.WithKind("TestType1"),
+			ExternalType:      reflect.TypeOf(runtimetesting.ExternalTestType1{}),
+			InternalType:      reflect.TypeOf(runtimetesting.TestType1{}),
+			MissingToInternal: true,
+			MissingDefaulter:  true,
+		},
+	}
+	if actual := CheckConversions(scheme); !reflect.DeepEqual(expected, actual) {
+		t.Errorf("expected %v, got %v", expected, actual)
+	}
+	if e, a := "test.group/v1, Kind=TestType1: missing conversion from testing.ExternalTestType1 to testing.TestType1, defaulting function for testing.ExternalTestType1", expected[0].String(); e != a {
+		t.Errorf("expected %q, got %q", e, a)
+	}
+}
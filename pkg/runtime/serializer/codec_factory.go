@@ -25,6 +25,7 @@ import (
 	"k8s.io/apimachinery/pkg/runtime/serializer/json"
 	"k8s.io/apimachinery/pkg/runtime/serializer/protobuf"
 	"k8s.io/apimachinery/pkg/runtime/serializer/recognizer"
+	"k8s.io/apimachinery/pkg/runtime/serializer/streaming"
 	"k8s.io/apimachinery/pkg/runtime/serializer/versioning"
 )
 
@@ -52,7 +53,7 @@ type serializerType struct {
 func newSerializersForScheme(scheme *runtime.Scheme, mf json.MetaFactory, options CodecFactoryOptions) []serializerType {
 	jsonSerializer := json.NewSerializerWithOptions(
 		mf, scheme, scheme,
-		json.SerializerOptions{Yaml: false, Pretty: false, Strict: options.Strict},
+		json.SerializerOptions{Yaml: false, Pretty: false, Strict: options.Strict, Limits: options.DecodingLimits},
 	)
 	jsonSerializerType := serializerType{
 		AcceptContentTypes: []string{runtime.ContentTypeJSON},
@@ -67,37 +68,45 @@ func newSerializersForScheme(scheme *runtime.Scheme, mf json.MetaFactory, option
 	if options.Pretty {
 		jsonSerializerType.PrettySerializer = json.NewSerializerWithOptions(
 			mf, scheme, scheme,
-			json.SerializerOptions{Yaml: false, Pretty: true, Strict: options.Strict},
+			json.SerializerOptions{Yaml: false, Pretty: true, Strict: options.Strict, Limits: options.DecodingLimits},
 		)
 	}
 
 	strictJSONSerializer := json.NewSerializerWithOptions(
 		mf, scheme, scheme,
-		json.SerializerOptions{Yaml: false, Pretty: false, Strict: true},
+		json.SerializerOptions{Yaml: false, Pretty: false, Strict: true, Limits: options.DecodingLimits},
 	)
 	jsonSerializerType.StrictSerializer = strictJSONSerializer
+	if len(options.StrictGroupKinds) > 0 {
+		applyStrictnessPolicy(&jsonSerializerType, strictJSONSerializer, options)
+	}
 
 	yamlSerializer := json.NewSerializerWithOptions(
 		mf, scheme, scheme,
-		json.SerializerOptions{Yaml: true, Pretty: false, Strict: options.Strict},
+		json.SerializerOptions{Yaml: true, Pretty: false, Strict: options.Strict, Limits: options.DecodingLimits},
 	)
 	strictYAMLSerializer := json.NewSerializerWithOptions(
 		mf, scheme, scheme,
-		json.SerializerOptions{Yaml: true, Pretty: false, Strict: true},
+		json.SerializerOptions{Yaml: true, Pretty: false, Strict: true, Limits: options.DecodingLimits},
 	)
-	protoSerializer := protobuf.NewSerializer(scheme, scheme)
+	yamlSerializerType := serializerType{
+		AcceptContentTypes: []string{runtime.ContentTypeYAML},
+		ContentType:        runtime.ContentTypeYAML,
+		FileExtensions:     []string{"yaml"},
+		EncodesAsText:      true,
+		Serializer:         yamlSerializer,
+		StrictSerializer:   strictYAMLSerializer,
+	}
+	if len(options.StrictGroupKinds) > 0 {
+		applyStrictnessPolicy(&yamlSerializerType, strictYAMLSerializer, options)
+	}
+
+	protoSerializer := protobuf.NewSerializerWithOptions(scheme, scheme, protobuf.SerializerOptions{UnstructuredAsJSON: options.UnstructuredAsJSON, Limits: options.DecodingLimits})
 	protoRawSerializer := protobuf.NewRawSerializer(scheme, scheme)
 
 	serializers := []serializerType{
 		jsonSerializerType,
-		{
-			AcceptContentTypes: []string{runtime.ContentTypeYAML},
-			ContentType:        runtime.ContentTypeYAML,
-			FileExtensions:     []string{"yaml"},
-			EncodesAsText:      true,
-			Serializer:         yamlSerializer,
-			StrictSerializer:   strictYAMLSerializer,
-		},
+		yamlSerializerType,
 		{
 			AcceptContentTypes: []string{runtime.ContentTypeProtobuf},
 			ContentType:        runtime.ContentTypeProtobuf,
@@ -117,9 +126,33 @@ func newSerializersForScheme(scheme *runtime.Scheme, mf json.MetaFactory, option
 			serializers = append(serializers, serializer)
 		}
 	}
+
+	for _, info := range options.Serializers {
+		serializers = addSerializer(serializers, info)
+	}
+
+	if len(options.KindOverrides) > 0 {
+		applyKindOverrides(serializers, options.KindOverrides)
+	}
+	if len(options.Hooks) > 0 {
+		applyCodecHooks(serializers, options.Hooks)
+	}
 	return serializers
 }
 
+// applyStrictnessPolicy replaces the non-strict serializers of s with serializers that decode
+// according to the StrictGroupKinds option.
+func applyStrictnessPolicy(s *serializerType, strictSerializer runtime.Serializer, options CodecFactoryOptions) {
+	policy := StrictnessPolicy{Default: options.Strict, GroupKinds: options.StrictGroupKinds}
+	s.Serializer = NewStrictnessPolicySerializer(s.Serializer, strictSerializer, policy)
+	if s.PrettySerializer != nil {
+		s.PrettySerializer = NewStrictnessPolicySerializer(s.PrettySerializer, strictSerializer, policy)
+	}
+	if s.StreamSerializer != nil {
+		s.StreamSerializer = NewStrictnessPolicySerializer(s.StreamSerializer, strictSerializer, policy)
+	}
+}
+
 // CodecFactory provides methods for retrieving codecs and serializers for specific
 // versions and content types.
 type CodecFactory struct {
@@ -128,6 +161,9 @@ type CodecFactory struct {
 	accepts   []runtime.SerializerInfo
 
 	legacySerializer runtime.Serializer
+
+	compressions   map[string]Compression
+	decodingLimits runtime.DecodingLimits
 }
 
 // CodecFactoryOptions holds the options for configuring CodecFactory behavior
@@ -136,6 +172,20 @@ type CodecFactoryOptions struct {
 	Strict bool
 	// Pretty includes a pretty serializer along with the non-pretty one
 	Pretty bool
+	// StrictGroupKinds overrides Strict for the decoding of individual group kinds, see WithStrictness
+	StrictGroupKinds map[schema.GroupKind]bool
+	// KindOverrides replaces the standard encoding and decoding of the given kinds, see WithKindOverride
+	KindOverrides map[schema.GroupVersionKind]KindOverride
+	// Serializers are registered in addition to the json, yaml and protobuf serializers, see WithSerializer
+	Serializers []runtime.SerializerInfo
+	// DecodingLimits bounds the resources the serializers spend decoding data, see WithDecodingLimits
+	DecodingLimits runtime.DecodingLimits
+	// Hooks are invoked around the serialization of every object, see WithCodecHooks
+	Hooks []CodecHooks
+	// Compressions holds the content encodings available through CodecFactory.WithContentEncoding, see WithCompression
+	Compressions map[string]Compression
+	// UnstructuredAsJSON configures the protobuf serializer to encode unstructured objects, see WithUnstructuredAsJSON
+	UnstructuredAsJSON bool
 }
 
 // CodecFactoryOptionsMutator takes a pointer to an options struct and then modifies it.
@@ -162,6 +212,64 @@ func DisableStrict(options *CodecFactoryOptions) {
 	options.Strict = false
 }
 
+// WithSerializer registers the serializers of info for info.MediaType, e.g. to support formats such as
+// CBOR. They replace the serializers of the factory for the same media type, if any, and are otherwise
+// negotiated after them. An empty StrictSerializer defaults to Serializer, as not all formats report
+// strict decoding errors. The serializers should implement recognizer.RecognizingDecoder to be used
+// by the universal deserializer. Kind overrides and codec hooks apply to them like to the built-in
+// serializers.
+func WithSerializer(info runtime.SerializerInfo) CodecFactoryOptionsMutator {
+	return func(options *CodecFactoryOptions) {
+		options.Serializers = append(options.Serializers, info)
+	}
+}
+
+// addSerializer adds a serializerType for info to serializers, replacing the serializerType with the
+// same content type, if any.
+func addSerializer(serializers []serializerType, info runtime.SerializerInfo) []serializerType {
+	s := serializerType{
+		AcceptContentTypes: []string{info.MediaType},
+		ContentType:        info.MediaType,
+		EncodesAsText:      info.EncodesAsText,
+		Serializer:         info.Serializer,
+		PrettySerializer:   info.PrettySerializer,
+		StrictSerializer:   info.StrictSerializer,
+	}
+	if s.StrictSerializer == nil {
+		s.StrictSerializer = info.Serializer
+	}
+	if info.StreamSerializer != nil {
+		s.AcceptStreamContentTypes = []string{info.MediaType}
+		s.StreamContentType = info.MediaType
+		s.Framer = info.StreamSerializer.Framer
+		s.StreamSerializer = info.StreamSerializer.Serializer
+	}
+	for i := range serializers {
+		if serializers[i].ContentType == info.MediaType {
+			serializers[i] = s
+			return serializers
+		}
+	}
+	return append(serializers, s)
+}
+
+// WithDecodingLimits configures the json, yaml and protobuf serializers to reject data exceeding limits
+// before decoding it, see runtime.DecodingLimits.
+func WithDecodingLimits(limits runtime.DecodingLimits) CodecFactoryOptionsMutator {
+	return func(options *CodecFactoryOptions) {
+		options.DecodingLimits = limits
+	}
+}
+
+// WithUnstructuredAsJSON configures the protobuf serializer to encode unstructured objects, which have no
+// protobuf representation, as JSON wrapped in the runtime.Unknown envelope instead of failing, see
+// protobuf.SerializerOptions.
+func WithUnstructuredAsJSON() CodecFactoryOptionsMutator {
+	return func(options *CodecFactoryOptions) {
+		options.UnstructuredAsJSON = true
+	}
+}
+
 // NewCodecFactory provides methods for retrieving serializers for the supported wire formats
 // and conversion wrappers to define preferred internal and external versions. In the future,
 // as the internal version is used less, callers may instead use a defaulting serializer and
@@ -180,7 +288,13 @@ func NewCodecFactory(scheme *runtime.Scheme, mutators ...CodecFactoryOptionsMuta
 	}
 
 	serializers := newSerializersForScheme(scheme, json.DefaultMetaFactory, options)
-	return newCodecFactory(scheme, serializers)
+	f := newCodecFactory(scheme, serializers)
+	if len(options.Compressions) > 0 {
+		f.compressions = options.Compressions
+		f.decodingLimits = options.DecodingLimits
+		f.universal = newDecompressingDecoder(f.universal, options.Compressions, options.DecodingLimits)
+	}
+	return f
 }
 
 // newCodecFactory is a helper for testing that allows a different metafactory to be specified.
@@ -191,7 +305,7 @@ func newCodecFactory(scheme *runtime.Scheme, serializers []serializerType) Codec
 
 	var legacySerializer runtime.Serializer
 	for _, d := range serializers {
-		decoders = append(decoders, d.Serializer)
+		decoders = append(decoders, recognizer.DefaultRegistry.Decoder(d.ContentType, d.Serializer))
 		for _, mediaType := range d.AcceptContentTypes {
 			if _, ok := alreadyAccepted[mediaType]; ok {
 				continue
@@ -219,6 +333,13 @@ func newCodecFactory(scheme *runtime.Scheme, serializers []serializerType) Codec
 					EncodesAsText: d.EncodesAsText,
 					Framer:        d.Framer,
 				}
+				// stream serializers don't delimit objects themselves, so they serve message
+				// streams as well when their framing is left to the transport
+				info.MessageStreamSerializer = &runtime.StreamSerializerInfo{
+					Serializer:    d.StreamSerializer,
+					EncodesAsText: d.EncodesAsText,
+					Framer:        streaming.MessageFramer,
+				}
 			}
 			accepts = append(accepts, info)
 			if mediaType == runtime.ContentTypeJSON {
@@ -265,7 +386,9 @@ func (f CodecFactory) LegacyCodec(version ...schema.GroupVersion) runtime.Codec
 }
 
 // UniversalDeserializer can convert any stored data recognized by this factory into a Go object that satisfies
-// runtime.Object. It does not perform conversion. It does not perform defaulting.
+// runtime.Object. It does not perform conversion. It does not perform defaulting. The format of the data is
+// identified by the serializers themselves, or by the recognizers registered for their media types in
+// recognizer.DefaultRegistry at the time the factory was created.
 func (f CodecFactory) UniversalDeserializer() runtime.Decoder {
 	return f.universal
 }
@@ -17,8 +17,10 @@ limitations under the License.
 package serializer
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
+	"io"
 	"log"
 	"os"
 	"reflect"
@@ -26,9 +28,13 @@ import (
 	"testing"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/conversion"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	serializerjson "k8s.io/apimachinery/pkg/runtime/serializer/json"
+	"k8s.io/apimachinery/pkg/runtime/serializer/protobuf"
+	"k8s.io/apimachinery/pkg/runtime/serializer/streaming"
 	runtimetesting "k8s.io/apimachinery/pkg/runtime/testing"
 	"k8s.io/apimachinery/pkg/util/diff"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
@@ -216,6 +222,63 @@ func TestStrictOption(t *testing.T) {
 	}
 }
 
+func TestStrictGroupKindsOption(t *testing.T) {
+	s, _ := GetTestScheme()
+	duplicateKeys := func(kind string) []byte {
+		return []byte(fmt.Sprintf(`{"myKindKey":%q,"myVersionKey":"v1","myVersionKey":"v1","A":"value"}`, kind))
+	}
+
+	testCases := []struct {
+		name     string
+		options  CodecFactoryOptions
+		kind     string
+		expected bool
+	}{
+		{
+			name:     "lenient kind with strict default",
+			options:  CodecFactoryOptions{Strict: true, StrictGroupKinds: map[schema.GroupKind]bool{{Kind: "TestType3"}: false}},
+			kind:     "TestType3",
+			expected: false,
+		},
+		{
+			name:     "unlisted kind with strict default",
+			options:  CodecFactoryOptions{Strict: true, StrictGroupKinds: map[schema.GroupKind]bool{{Kind: "TestType3"}: false}},
+			kind:     "TestType1",
+			expected: true,
+		},
+		{
+			name:     "strict kind with lenient default",
+			options:  CodecFactoryOptions{StrictGroupKinds: map[schema.GroupKind]bool{{Kind: "TestType3"}: true}},
+			kind:     "TestType3",
+			expected: true,
+		},
+		{
+			name:     "strict group with lenient default",
+			options:  CodecFactoryOptions{StrictGroupKinds: map[schema.GroupKind]bool{{}: true}},
+			kind:     "TestType1",
+			expected: true,
+		},
+		{
+			name:     "lenient kind in strict group",
+			options:  CodecFactoryOptions{StrictGroupKinds: map[schema.GroupKind]bool{{}: true, {Kind: "TestType1"}: false}},
+			kind:     "TestType1",
+			expected: false,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			codec := newCodecFactory(s, newSerializersForScheme(s, testMetaFactory{}, tc.options)).LegacyCodec()
+			obj, _, err := codec.Decode(duplicateKeys(tc.kind), nil, nil)
+			if obj == nil {
+				t.Fatalf("expected an object to be decoded, got error %v", err)
+			}
+			if strict := runtime.IsStrictDecodingError(err); strict != tc.expected {
+				t.Errorf("expected strict decoding error: %t, got %v", tc.expected, err)
+			}
+		})
+	}
+}
+
 func TestConvertTypesWhenDefaultNamesMatch(t *testing.T) {
 	internalGV := schema.GroupVersion{Version: runtime.APIVersionInternal}
 	externalGV := schema.GroupVersion{Version: "v1"}
@@ -367,3 +430,306 @@ func TestDirectCodec(t *testing.T) {
 		t.Fatalf("expect %v, got %v", e, a)
 	}
 }
+
+// prefixSerializer is a serializer for a fake format, JSON prefixed with "prefix:".
+type prefixSerializer struct {
+	runtime.Serializer
+}
+
+func (s prefixSerializer) Encode(obj runtime.Object, w io.Writer) error {
+	if _, err := w.Write([]byte("prefix:")); err != nil {
+		return err
+	}
+	return s.Serializer.Encode(obj, w)
+}
+
+func (s prefixSerializer) Decode(data []byte, defaults *schema.GroupVersionKind, into runtime.Object) (runtime.Object, *schema.GroupVersionKind, error) {
+	return s.Serializer.Decode(bytes.TrimPrefix(data, []byte("prefix:")), defaults, into)
+}
+
+func (s prefixSerializer) Identifier() runtime.Identifier {
+	return "prefix"
+}
+
+func (s prefixSerializer) RecognizesData(data []byte) (ok, unknown bool, err error) {
+	return bytes.HasPrefix(data, []byte("prefix:")), false, nil
+}
+
+func TestWithSerializer(t *testing.T) {
+	gv := schema.GroupVersion{Group: "test.group", Version: "v1"}
+	scheme := runtime.NewScheme()
+	scheme.AddKnownTypeWithName(gv.WithKind("Simple"), &runtimetesting.ExternalSimple{})
+	jsonSerializer := serializerjson.NewSerializerWithOptions(serializerjson.DefaultMetaFactory, scheme, scheme, serializerjson.SerializerOptions{})
+	custom := runtime.SerializerInfo{
+		MediaType:        "application/x-prefix",
+		MediaTypeType:    "application",
+		MediaTypeSubType: "x-prefix",
+		Serializer:       prefixSerializer{jsonSerializer},
+	}
+
+	factory := NewCodecFactory(scheme, WithSerializer(custom))
+	mediaTypes := factory.SupportedMediaTypes()
+	if e, a := custom.MediaType, mediaTypes[len(mediaTypes)-1].MediaType; e != a {
+		t.Fatalf("expected %s to be negotiated last, got %s", e, a)
+	}
+	info, ok := runtime.SerializerInfoForMediaType(mediaTypes, custom.MediaType)
+	if !ok || info.StrictSerializer == nil || info.StreamSerializer != nil {
+		t.Fatalf("unexpected serializer info %#v", info)
+	}
+	codec := factory.CodecForVersions(info.Serializer, factory.UniversalDeserializer(), gv, gv)
+	data, err := runtime.Encode(codec, &runtimetesting.ExternalSimple{TestString: "value"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(data), "prefix:{") {
+		t.Errorf("expected data in the registered format, got %s", data)
+	}
+	obj, err := runtime.Decode(codec, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e, a := "value", obj.(*runtimetesting.ExternalSimple).TestString; e != a {
+		t.Errorf("expected %q, got %q", e, a)
+	}
+
+	// serializers replace those registered for the same media type
+	custom.MediaType, custom.MediaTypeSubType = runtime.ContentTypeJSON, "json"
+	factory = NewCodecFactory(scheme, WithSerializer(custom))
+	if e, a := len(mediaTypes)-1, len(factory.SupportedMediaTypes()); e != a {
+		t.Errorf("expected %d media types, got %d", e, a)
+	}
+	info, _ = runtime.SerializerInfoForMediaType(factory.SupportedMediaTypes(), runtime.ContentTypeJSON)
+	if info.Serializer.Identifier() != "prefix" {
+		t.Errorf("expected the JSON serializer to be replaced, got %s", info.Serializer.Identifier())
+	}
+}
+
+// messageConn records every write as a message and returns one message per read.
+type messageConn struct {
+	messages [][]byte
+}
+
+func (c *messageConn) Write(data []byte) (int, error) {
+	c.messages = append(c.messages, append([]byte(nil), data...))
+	return len(data), nil
+}
+
+func (c *messageConn) Read(data []byte) (int, error) {
+	if len(c.messages) == 0 {
+		return 0, io.EOF
+	}
+	n := copy(data, c.messages[0])
+	if n < len(c.messages[0]) {
+		c.messages[0] = c.messages[0][n:]
+		return n, io.ErrShortBuffer
+	}
+	c.messages = c.messages[1:]
+	return n, nil
+}
+
+func (c *messageConn) Close() error {
+	return nil
+}
+
+func TestMessageStreamSerializer(t *testing.T) {
+	gv := schema.GroupVersion{Group: "test.group", Version: "v1"}
+	scheme := runtime.NewScheme()
+	scheme.AddKnownTypes(gv, &metav1.Status{})
+	factory := NewCodecFactory(scheme)
+
+	objs := []runtime.Object{
+		&metav1.Status{TypeMeta: metav1.TypeMeta{APIVersion: gv.String(), Kind: "Status"}, Message: "first"},
+		&metav1.Status{TypeMeta: metav1.TypeMeta{APIVersion: gv.String(), Kind: "Status"}, Message: strings.Repeat("x", 2048)},
+	}
+	for _, mediaType := range []string{runtime.ContentTypeJSON, runtime.ContentTypeProtobuf} {
+		t.Run(mediaType, func(t *testing.T) {
+			info, ok := runtime.SerializerInfoForMediaType(factory.SupportedMediaTypes(), mediaType)
+			if !ok || info.MessageStreamSerializer == nil {
+				t.Fatalf("expected a message stream serializer, got %#v", info)
+			}
+			stream := info.MessageStreamSerializer
+			if stream.Serializer != info.StreamSerializer.Serializer || stream.EncodesAsText != info.StreamSerializer.EncodesAsText {
+				t.Errorf("expected the stream serializer to be used for messages, got %#v", stream)
+			}
+
+			conn := &messageConn{}
+			encoder := streaming.NewEncoder(stream.Framer.NewFrameWriter(conn), stream.Serializer)
+			for _, obj := range objs {
+				if err := encoder.Encode(obj); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if len(conn.messages) != len(objs) {
+				t.Fatalf("expected one message per object, got %d messages", len(conn.messages))
+			}
+			for i, obj := range objs {
+				// messages hold the stream serialization of a single object, without a length prefix
+				expected, err := runtime.Encode(stream.Serializer, obj)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !bytes.Equal(expected, conn.messages[i]) {
+					t.Errorf("expected message %d to be %q, got %q", i, expected, conn.messages[i])
+				}
+			}
+
+			decoder := streaming.NewDecoder(stream.Framer.NewFrameReader(conn), stream.Serializer)
+			for _, obj := range objs {
+				decoded, _, err := decoder.Decode(nil, &metav1.Status{})
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !reflect.DeepEqual(obj, decoded) {
+					t.Errorf("expected %#v, got %#v", obj, decoded)
+				}
+			}
+			if _, _, err := decoder.Decode(nil, &metav1.Status{}); err != io.EOF {
+				t.Errorf("expected EOF, got %v", err)
+			}
+		})
+	}
+}
+
+func TestUnstructuredAsJSONOption(t *testing.T) {
+	scheme := runtime.NewScheme()
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "example.com/v1",
+		"kind":       "Widget",
+		"metadata":   map[string]interface{}{"name": "foo"},
+	}}
+
+	info, ok := runtime.SerializerInfoForMediaType(NewCodecFactory(scheme).SupportedMediaTypes(), runtime.ContentTypeProtobuf)
+	if !ok {
+		t.Fatalf("no serializer for %s", runtime.ContentTypeProtobuf)
+	}
+	if _, err := runtime.Encode(info.Serializer, obj); !protobuf.IsNotMarshalable(err) {
+		t.Errorf("expected a not marshalable error without the option, got %v", err)
+	}
+
+	factory := NewCodecFactory(scheme, WithUnstructuredAsJSON())
+	info, ok = runtime.SerializerInfoForMediaType(factory.SupportedMediaTypes(), runtime.ContentTypeProtobuf)
+	if !ok {
+		t.Fatalf("no serializer for %s", runtime.ContentTypeProtobuf)
+	}
+	data, err := runtime.Encode(info.Serializer, obj)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded, _, err := factory.UniversalDeserializer().Decode(data, nil, &unstructured.Unstructured{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(obj, decoded) {
+		t.Errorf("expected %#v, got %#v", obj, decoded)
+	}
+}
+
+type countingAllocator struct {
+	runtime.Allocator
+	calls int
+}
+
+func (a *countingAllocator) Allocate(n uint64) []byte {
+	a.calls++
+	return a.Allocator.Allocate(n)
+}
+
+func TestCodecFactoryEncodeWithAllocator(t *testing.T) {
+	gv := schema.GroupVersion{Group: "test.group", Version: "v1"}
+	gvk := gv.WithKind("Simple")
+	scheme := runtime.NewScheme()
+	scheme.AddKnownTypeWithName(gvk, &runtimetesting.ExternalSimple{})
+	next := func(obj runtime.Object, w io.Writer, next runtime.Encoder) error { return next.Encode(obj, w) }
+
+	testCases := []struct {
+		name            string
+		options         []CodecFactoryOptionsMutator
+		contentEncoding string
+	}{
+		{name: "plain"},
+		{name: "hooks", options: []CodecFactoryOptionsMutator{WithCodecHooks(CodecHooks{Name: "hooks", BeforeEncode: func(obj runtime.Object) (runtime.Object, error) { return obj, nil }})}},
+		{name: "kind override", options: []CodecFactoryOptionsMutator{WithKindOverride(gvk, KindOverride{Name: "next", Encode: next})}},
+		{name: "strictness", options: []CodecFactoryOptionsMutator{WithStrictness(gvk.GroupKind(), true)}},
+		{name: "compression", options: []CodecFactoryOptionsMutator{WithCompression(GzipCompression)}, contentEncoding: "gzip"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			factory := NewCodecFactory(scheme, tc.options...)
+			if len(tc.contentEncoding) > 0 {
+				var ok bool
+				if factory, ok = factory.WithContentEncoding(tc.contentEncoding); !ok {
+					t.Fatalf("no serializers for %s", tc.contentEncoding)
+				}
+			}
+			info, ok := runtime.SerializerInfoForMediaType(factory.SupportedMediaTypes(), runtime.ContentTypeJSON)
+			if !ok {
+				t.Fatalf("no serializer for %s", runtime.ContentTypeJSON)
+			}
+			memAlloc := &countingAllocator{}
+			codec := factory.CodecForVersions(info.Serializer, nil, gv, nil)
+			if err := runtime.WithAllocator(codec, memAlloc).Encode(&runtimetesting.ExternalSimple{TestString: "value"}, io.Discard); err != nil {
+				t.Fatal(err)
+			}
+			if memAlloc.calls == 0 {
+				t.Errorf("expected the allocator to be used")
+			}
+		})
+	}
+}
+
+// sizeEstimatingSerializer estimates the size of every object as 42 bytes, recording the objects.
+type sizeEstimatingSerializer struct {
+	prefixSerializer
+	estimated *[]runtime.Object
+}
+
+func (s sizeEstimatingSerializer) EstimateSize(obj runtime.Object, _ *runtime.SizeEstimateCache) (int, error) {
+	*s.estimated = append(*s.estimated, obj.DeepCopyObject())
+	return 42, nil
+}
+
+func TestCodecFactoryEstimateSize(t *testing.T) {
+	gv := schema.GroupVersion{Group: "test.group", Version: "v1"}
+	gvk := gv.WithKind("Simple")
+	scheme := runtime.NewScheme()
+	scheme.AddKnownTypeWithName(gvk, &runtimetesting.ExternalSimple{})
+	var estimated []runtime.Object
+	estimator := sizeEstimatingSerializer{
+		prefixSerializer: prefixSerializer{serializerjson.NewSerializerWithOptions(serializerjson.DefaultMetaFactory, scheme, scheme, serializerjson.SerializerOptions{})},
+		estimated:        &estimated,
+	}
+	redact := CodecHooks{Name: "redact", BeforeEncode: func(obj runtime.Object) (runtime.Object, error) {
+		redacted := obj.DeepCopyObject().(*runtimetesting.ExternalSimple)
+		redacted.TestString = "redacted"
+		return redacted, nil
+	}}
+	factory := NewCodecFactory(scheme,
+		WithSerializer(runtime.SerializerInfo{MediaType: "application/x-prefix", Serializer: estimator}),
+		WithCodecHooks(redact),
+		WithKindOverride(gv.WithKind("Other"), KindOverride{Name: "other", Encode: func(obj runtime.Object, w io.Writer, next runtime.Encoder) error { return next.Encode(obj, w) }}),
+	)
+	info, ok := runtime.SerializerInfoForMediaType(factory.SupportedMediaTypes(), "application/x-prefix")
+	if !ok {
+		t.Fatalf("no serializer for application/x-prefix")
+	}
+
+	codec := factory.CodecForVersions(info.Serializer, nil, gv, nil)
+	size, err := runtime.EstimateSize(&runtimetesting.ExternalSimple{TestString: "value"}, codec, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if size != 42 || len(estimated) != 1 {
+		t.Fatalf("expected the size to be estimated by the serializer, got %d bytes", size)
+	}
+	expected := &runtimetesting.ExternalSimple{TestString: "redacted"}
+	expected.GetObjectKind().SetGroupVersionKind(gvk)
+	if !reflect.DeepEqual(expected, estimated[0]) {
+		t.Errorf("expected the size of %#v to be estimated, got %#v", expected, estimated[0])
+	}
+
+	estimated = nil
+	strict := NewStrictnessPolicySerializer(estimator, estimator, StrictnessPolicy{})
+	if size, err := runtime.EstimateSize(&runtimetesting.ExternalSimple{}, strict, nil); err != nil || size != 42 || len(estimated) != 1 {
+		t.Errorf("expected the size to be estimated by the encoder, got %d bytes, %v", size, err)
+	}
+}
@@ -0,0 +1,250 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package serializer
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sort"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/runtime/serializer/recognizer"
+)
+
+// Compression describes a content encoding (e.g. gzip) that serializers can compress their output with.
+type Compression struct {
+	// Encoding is the name of the encoding as used in the Content-Encoding and Accept-Encoding HTTP headers.
+	Encoding string
+	// Magic is the prefix of all data compressed with this encoding. It is used to recognize compressed
+	// data on decode, data without the prefix is decoded as is.
+	Magic []byte
+	// NewWriter returns a writer compressing the data written to it into w. Close is invoked once all
+	// data has been written.
+	NewWriter func(w io.Writer) (io.WriteCloser, error)
+	// NewReader returns a reader decompressing the data read from r.
+	NewReader func(r io.Reader) (io.ReadCloser, error)
+}
+
+// DefaultMaxDecompressedBytes is the maximum size of decompressed data when the decoding limits don't
+// set MaxBytes, so that small inputs can never expand to arbitrary amounts of memory.
+const DefaultMaxDecompressedBytes = 128 * 1024 * 1024
+
+// recognizingPrefixBytes is the number of bytes decompressed to recognize compressed data, since
+// recognizers only look at the start of the data.
+const recognizingPrefixBytes = 4 * 1024
+
+// GzipCompression compresses data with gzip at the default compression level. Other encodings, such as
+// zstd, can be supported by providing a Compression backed by a library implementing them.
+var GzipCompression = Compression{
+	Encoding: "gzip",
+	Magic:    []byte{0x1f, 0x8b},
+	NewWriter: func(w io.Writer) (io.WriteCloser, error) {
+		return gzip.NewWriter(w), nil
+	},
+	NewReader: func(r io.Reader) (io.ReadCloser, error) {
+		return gzip.NewReader(r)
+	},
+}
+
+// WithCompression makes the content encoding of c available through CodecFactory.WithContentEncoding, and
+// makes the universal deserializer of the factory decompress data compressed with c. Data decompressed to
+// more than the MaxBytes of WithDecodingLimits, or DefaultMaxDecompressedBytes if it is unset, is rejected.
+// Registering a second compression with the same encoding replaces the first one.
+func WithCompression(c Compression) CodecFactoryOptionsMutator {
+	return func(options *CodecFactoryOptions) {
+		if options.Compressions == nil {
+			options.Compressions = map[string]Compression{}
+		}
+		options.Compressions[c.Encoding] = c
+	}
+}
+
+// WithContentEncoding returns a copy of the factory whose serializers compress their output with the
+// compression registered for encoding through WithCompression, and decompress data compressed with it
+// before decoding. Data that isn't compressed is decoded as is. Data decompressed to more than the MaxBytes
+// of the decoding limits of the factory, or DefaultMaxDecompressedBytes if it is unset, is rejected. Stream
+// serializers are not available for compressed media types. If no compression is registered for encoding,
+// false is returned.
+func (f CodecFactory) WithContentEncoding(encoding string) (CodecFactory, bool) {
+	c, ok := f.compressions[encoding]
+	if !ok {
+		return CodecFactory{}, false
+	}
+	accepts := make([]runtime.SerializerInfo, 0, len(f.accepts))
+	for _, info := range f.accepts {
+		info.Serializer = newCompressingSerializer(info.Serializer, c, f.decodingLimits)
+		info.PrettySerializer = newCompressingSerializer(info.PrettySerializer, c, f.decodingLimits)
+		info.StrictSerializer = newCompressingSerializer(info.StrictSerializer, c, f.decodingLimits)
+		info.StreamSerializer = nil
+		info.MessageStreamSerializer = nil
+		accepts = append(accepts, info)
+	}
+	f.accepts = accepts
+	f.legacySerializer = newCompressingSerializer(f.legacySerializer, c, f.decodingLimits)
+	return f, true
+}
+
+// NewCompressingSerializer returns a serializer that compresses the output of serializer with c, and decodes
+// data compressed with c by decompressing it before passing it to serializer. Other data is passed to
+// serializer as is. Data decompressed to more than DefaultMaxDecompressedBytes is rejected.
+func NewCompressingSerializer(serializer runtime.Serializer, c Compression) runtime.Serializer {
+	return newCompressingSerializer(serializer, c, runtime.DecodingLimits{})
+}
+
+// newCompressingSerializer is like NewCompressingSerializer, but rejects data decompressed to more than
+// limits.MaxBytes if it is set.
+func newCompressingSerializer(serializer runtime.Serializer, c Compression, limits runtime.DecodingLimits) runtime.Serializer {
+	if serializer == nil {
+		return nil
+	}
+	s := &compressingSerializer{
+		serializerWrapper: serializerWrapper{
+			encoder:    serializer,
+			decoder:    serializer,
+			identifier: wrapperIdentifier("compressing", serializer, map[string]interface{}{"encoding": c.Encoding}),
+		},
+		compression: c,
+		limits:      limits,
+	}
+	s.encode = s.doEncodeWithAllocator
+	return s
+}
+
+type compressingSerializer struct {
+	serializerWrapper
+	compression Compression
+	limits      runtime.DecodingLimits
+}
+
+var _ runtime.Serializer = &compressingSerializer{}
+var _ runtime.EncoderWithAllocator = &compressingSerializer{}
+var _ recognizer.RecognizingDecoder = &compressingSerializer{}
+
+// doEncodeWithAllocator encodes obj with the wrapped serializer and writes the compressed result to w.
+func (s *compressingSerializer) doEncodeWithAllocator(obj runtime.Object, w io.Writer, memAlloc runtime.MemoryAllocator) error {
+	cw, err := s.compression.NewWriter(w)
+	if err != nil {
+		return err
+	}
+	if err := runtime.WithAllocator(s.encoder, memAlloc).Encode(obj, cw); err != nil {
+		cw.Close()
+		return err
+	}
+	return cw.Close()
+}
+
+// Decode decompresses data if it is compressed and decodes the result with the wrapped serializer.
+func (s *compressingSerializer) Decode(data []byte, defaults *schema.GroupVersionKind, into runtime.Object) (runtime.Object, *schema.GroupVersionKind, error) {
+	data, err := decompress(data, s.compression, s.limits)
+	if err != nil {
+		return nil, nil, err
+	}
+	return s.decoder.Decode(data, defaults, into)
+}
+
+// RecognizesData implements the RecognizingDecoder interface.
+func (s *compressingSerializer) RecognizesData(data []byte) (ok, unknown bool, err error) {
+	if _, isRecognizer := s.decoder.(recognizer.RecognizingDecoder); !isRecognizer || !isCompressed(data, s.compression) {
+		return s.serializerWrapper.RecognizesData(data)
+	}
+	prefix, err := readDecompressed(data, s.compression, recognizingPrefixBytes)
+	if err != nil {
+		return false, false, err
+	}
+	return s.serializerWrapper.RecognizesData(prefix)
+}
+
+// isCompressed returns true if data starts with the magic prefix of c.
+func isCompressed(data []byte, c Compression) bool {
+	return len(c.Magic) > 0 && bytes.HasPrefix(data, c.Magic)
+}
+
+// decompress returns data decompressed with c, or data itself if it isn't compressed with c. A decoding
+// limit error is returned once more than limits.MaxBytes, or DefaultMaxDecompressedBytes if it is unset,
+// are decompressed, so that small inputs can't expand to arbitrary amounts of memory.
+func decompress(data []byte, c Compression, limits runtime.DecodingLimits) ([]byte, error) {
+	if !isCompressed(data, c) {
+		return data, nil
+	}
+	maxBytes := limits.MaxBytes
+	if maxBytes <= 0 {
+		maxBytes = DefaultMaxDecompressedBytes
+	}
+	decompressed, err := readDecompressed(data, c, maxBytes+1)
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(decompressed)) > maxBytes {
+		return nil, runtime.NewDecodingLimitError(fmt.Sprintf("decompressed %s data exceeds %d bytes", c.Encoding, maxBytes))
+	}
+	return decompressed, nil
+}
+
+// readDecompressed decompresses at most n bytes of data with c.
+func readDecompressed(data []byte, c Compression, n int64) ([]byte, error) {
+	r, err := c.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("unable to decompress %s data: %v", c.Encoding, err)
+	}
+	defer r.Close()
+	decompressed, err := ioutil.ReadAll(io.LimitReader(r, n))
+	if err != nil {
+		return nil, fmt.Errorf("unable to decompress %s data: %v", c.Encoding, err)
+	}
+	return decompressed, nil
+}
+
+// decompressingDecoder decompresses data compressed with any of its compressions before decoding it.
+type decompressingDecoder struct {
+	decoder      runtime.Decoder
+	compressions []Compression
+	limits       runtime.DecodingLimits
+}
+
+func newDecompressingDecoder(decoder runtime.Decoder, compressions map[string]Compression, limits runtime.DecodingLimits) runtime.Decoder {
+	encodings := make([]string, 0, len(compressions))
+	for encoding := range compressions {
+		encodings = append(encodings, encoding)
+	}
+	// try the compressions in a stable order, so that data matching the magic prefix of several of them is
+	// always decompressed the same way
+	sort.Strings(encodings)
+	d := &decompressingDecoder{decoder: decoder, limits: limits}
+	for _, encoding := range encodings {
+		d.compressions = append(d.compressions, compressions[encoding])
+	}
+	return d
+}
+
+// Decode implements runtime.Decoder interface.
+func (d *decompressingDecoder) Decode(data []byte, defaults *schema.GroupVersionKind, into runtime.Object) (runtime.Object, *schema.GroupVersionKind, error) {
+	for _, c := range d.compressions {
+		if isCompressed(data, c) {
+			decompressed, err := decompress(data, c, d.limits)
+			if err != nil {
+				return nil, nil, err
+			}
+			data = decompressed
+			break
+		}
+	}
+	return d.decoder.Decode(data, defaults, into)
+}
@@ -0,0 +1,206 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package serializer
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/runtime/serializer/recognizer"
+	runtimetesting "k8s.io/apimachinery/pkg/runtime/testing"
+)
+
+func TestCompression(t *testing.T) {
+	gv := schema.GroupVersion{Group: "test.group", Version: "v1"}
+	scheme := runtime.NewScheme()
+	scheme.AddKnownTypeWithName(gv.WithKind("Simple"), &runtimetesting.ExternalSimple{})
+
+	factory := NewCodecFactory(scheme, WithCompression(GzipCompression))
+	if _, ok := factory.WithContentEncoding("br"); ok {
+		t.Errorf("expected no serializers for an unregistered encoding")
+	}
+	compressed, ok := factory.WithContentEncoding("gzip")
+	if !ok {
+		t.Fatalf("expected serializers for gzip")
+	}
+
+	obj := &runtimetesting.ExternalSimple{TestString: "value"}
+	for _, mediaType := range []string{runtime.ContentTypeJSON, runtime.ContentTypeYAML} {
+		t.Run(mediaType, func(t *testing.T) {
+			info, ok := runtime.SerializerInfoForMediaType(compressed.SupportedMediaTypes(), mediaType)
+			if !ok {
+				t.Fatalf("no serializer for %s", mediaType)
+			}
+			if info.StreamSerializer != nil || info.MessageStreamSerializer != nil {
+				t.Errorf("expected no stream serializers for compressed media types")
+			}
+			codec := compressed.CodecForVersions(info.Serializer, info.Serializer, gv, gv)
+			data, err := runtime.Encode(codec, obj)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.HasPrefix(data, GzipCompression.Magic) {
+				t.Fatalf("expected gzip compressed data, got %q", data)
+			}
+
+			for name, decoder := range map[string]runtime.Decoder{
+				"codec":     codec,
+				"universal": factory.UniversalDecoder(gv),
+			} {
+				decoded, err := runtime.Decode(decoder, data)
+				if err != nil {
+					t.Fatalf("%s: %v", name, err)
+				}
+				if e, a := obj.TestString, decoded.(*runtimetesting.ExternalSimple).TestString; e != a {
+					t.Errorf("%s: expected %q, got %q", name, e, a)
+				}
+			}
+
+			// uncompressed data is decoded as is
+			plain, ok := runtime.SerializerInfoForMediaType(factory.SupportedMediaTypes(), mediaType)
+			if !ok {
+				t.Fatalf("no serializer for %s", mediaType)
+			}
+			data, err = runtime.Encode(factory.CodecForVersions(plain.Serializer, nil, gv, nil), obj)
+			if err != nil {
+				t.Fatal(err)
+			}
+			decoded, err := runtime.Decode(codec, data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if e, a := obj.TestString, decoded.(*runtimetesting.ExternalSimple).TestString; e != a {
+				t.Errorf("expected %q, got %q", e, a)
+			}
+		})
+	}
+}
+
+func TestCompressingSerializerErrors(t *testing.T) {
+	scheme := runtime.NewScheme()
+	serializer := NewCompressingSerializer(NewCodecFactory(scheme).LegacyCodec(), GzipCompression)
+	if _, _, err := serializer.Decode([]byte{0x1f, 0x8b, 0x00}, nil, nil); err == nil {
+		t.Errorf("expected an error for corrupt compressed data")
+	}
+	if NewCompressingSerializer(nil, GzipCompression) != nil {
+		t.Errorf("expected a nil serializer to stay nil")
+	}
+}
+
+func TestDecompressionLimits(t *testing.T) {
+	gv := schema.GroupVersion{Group: "test.group", Version: "v1"}
+	scheme := runtime.NewScheme()
+	scheme.AddKnownTypeWithName(gv.WithKind("Simple"), &runtimetesting.ExternalSimple{})
+
+	// a JSON object padded with 10MiB of whitespace compresses to a few kilobytes
+	plain := append([]byte(`{"apiVersion":"test.group/v1","kind":"Simple"}`), bytes.Repeat([]byte(" "), 10*1024*1024)...)
+	data := &bytes.Buffer{}
+	w, err := GzipCompression.NewWriter(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Write(plain); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if data.Len() > 64*1024 {
+		t.Fatalf("expected the data to be highly compressible, got %d bytes", data.Len())
+	}
+
+	limits := runtime.DecodingLimits{MaxBytes: 1024 * 1024}
+	factory := NewCodecFactory(scheme, WithCompression(GzipCompression), WithDecodingLimits(limits))
+	if _, _, err := factory.UniversalDeserializer().Decode(data.Bytes(), nil, nil); !runtime.IsDecodingLimitError(err) {
+		t.Errorf("expected a decoding limit error from the universal deserializer, got %v", err)
+	}
+	compressed, _ := factory.WithContentEncoding("gzip")
+	info, _ := runtime.SerializerInfoForMediaType(compressed.SupportedMediaTypes(), runtime.ContentTypeJSON)
+	if _, _, err := info.Serializer.Decode(data.Bytes(), nil, nil); !runtime.IsDecodingLimitError(err) {
+		t.Errorf("expected a decoding limit error from the compressing serializer, got %v", err)
+	}
+
+	// the same data is decoded without limits
+	factory = NewCodecFactory(scheme, WithCompression(GzipCompression))
+	if _, _, err := factory.UniversalDeserializer().Decode(data.Bytes(), nil, nil); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+// endlessReader returns an endless stream of spaces.
+type endlessReader struct{}
+
+func (endlessReader) Read(p []byte) (int, error) {
+	for i := range p {
+		p[i] = ' '
+	}
+	return len(p), nil
+}
+
+func TestDefaultDecompressionLimit(t *testing.T) {
+	endless := Compression{
+		Encoding: "endless",
+		Magic:    []byte("endless"),
+		NewReader: func(r io.Reader) (io.ReadCloser, error) {
+			return ioutil.NopCloser(endlessReader{}), nil
+		},
+	}
+	factory := NewCodecFactory(runtime.NewScheme(), WithCompression(endless))
+	if _, _, err := factory.UniversalDeserializer().Decode([]byte("endless"), nil, nil); !runtime.IsDecodingLimitError(err) {
+		t.Errorf("expected a decoding limit error without limits, got %v", err)
+	}
+
+	// recognizing data only decompresses its start
+	compressed, _ := factory.WithContentEncoding("endless")
+	info, _ := runtime.SerializerInfoForMediaType(compressed.SupportedMediaTypes(), runtime.ContentTypeJSON)
+	ok, unknown, err := info.Serializer.(recognizer.RecognizingDecoder).RecognizesData([]byte("endless"))
+	if ok || unknown || err != nil {
+		t.Errorf("expected whitespace not to be recognized as JSON, got %t, %t, %v", ok, unknown, err)
+	}
+}
+
+func TestDecompressionOrder(t *testing.T) {
+	gv := schema.GroupVersion{Group: "test.group", Version: "v1"}
+	scheme := runtime.NewScheme()
+	scheme.AddKnownTypeWithName(gv.WithKind("Simple"), &runtimetesting.ExternalSimple{})
+	// data starting with "xy" matches both compressions, and only a decompresses it to a valid object
+	a := Compression{
+		Encoding: "a",
+		Magic:    []byte("x"),
+		NewReader: func(r io.Reader) (io.ReadCloser, error) {
+			return ioutil.NopCloser(strings.NewReader(`{"apiVersion":"test.group/v1","kind":"Simple"}`)), nil
+		},
+	}
+	b := Compression{
+		Encoding: "b",
+		Magic:    []byte("xy"),
+		NewReader: func(r io.Reader) (io.ReadCloser, error) {
+			return ioutil.NopCloser(strings.NewReader("invalid")), nil
+		},
+	}
+	for i := 0; i < 20; i++ {
+		factory := NewCodecFactory(scheme, WithCompression(b), WithCompression(a))
+		if _, _, err := factory.UniversalDeserializer().Decode([]byte("xy"), nil, nil); err != nil {
+			t.Fatalf("expected the compressions to be tried in order of their encodings, got %v", err)
+		}
+	}
+}
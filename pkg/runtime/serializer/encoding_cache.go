@@ -0,0 +1,189 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package serializer
+
+import (
+	"bytes"
+	"container/list"
+	"io"
+	"reflect"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// EncodingCache memoizes the results of encoders, so that objects encoded repeatedly, for example the
+// objects of watch events sent to many clients, are serialized once per encoder. Results are cached
+// per object pointer and encoder Identifier, and are only reused while the resourceVersion of the
+// object stays the same. Objects without a resourceVersion, objects that are not pointers, and
+// runtime.CacheableObjects, which cache their serializations themselves, are never memoized.
+//
+// Objects must not be mutated without changing their resourceVersion unless Invalidate is called,
+// otherwise stale serializations are written. The cache holds references to the objects of the
+// cached results until they are evicted.
+type EncodingCache struct {
+	lock       sync.Mutex
+	maxEntries int
+	// lru holds *encodingCacheEntry values, the most recently used first
+	lru     *list.List
+	entries map[runtime.Object]map[runtime.Identifier]*list.Element
+}
+
+type encodingCacheEntry struct {
+	obj             runtime.Object
+	identifier      runtime.Identifier
+	resourceVersion string
+	data            []byte
+}
+
+// NewEncodingCache returns an EncodingCache holding at most maxEntries results, evicting the
+// least recently used results first. maxEntries must be positive.
+func NewEncodingCache(maxEntries int) *EncodingCache {
+	if maxEntries <= 0 {
+		panic("encoding cache size must be positive")
+	}
+	return &EncodingCache{
+		maxEntries: maxEntries,
+		lru:        list.New(),
+		entries:    map[runtime.Object]map[runtime.Identifier]*list.Element{},
+	}
+}
+
+// Encoder returns an encoder memoizing the results of encoder in the cache. The returned encoder
+// has the same Identifier as encoder.
+func (c *EncodingCache) Encoder(encoder runtime.Encoder) runtime.Encoder {
+	return &memoizingEncoder{encoder: encoder, cache: c}
+}
+
+// Invalidate drops the cached results of all encoders for obj.
+func (c *EncodingCache) Invalidate(obj runtime.Object) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	for _, element := range c.entries[obj] {
+		c.lru.Remove(element)
+	}
+	delete(c.entries, obj)
+}
+
+// Clear drops all cached results.
+func (c *EncodingCache) Clear() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.lru.Init()
+	c.entries = map[runtime.Object]map[runtime.Identifier]*list.Element{}
+}
+
+// Len returns the number of cached results.
+func (c *EncodingCache) Len() int {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.lru.Len()
+}
+
+// get returns the result cached for obj and id, if it was encoded with resourceVersion.
+func (c *EncodingCache) get(obj runtime.Object, id runtime.Identifier, resourceVersion string) ([]byte, bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	element, ok := c.entries[obj][id]
+	if !ok {
+		return nil, false
+	}
+	entry := element.Value.(*encodingCacheEntry)
+	if entry.resourceVersion != resourceVersion {
+		c.remove(element)
+		return nil, false
+	}
+	c.lru.MoveToFront(element)
+	return entry.data, true
+}
+
+func (c *EncodingCache) add(obj runtime.Object, id runtime.Identifier, resourceVersion string, data []byte) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	entry := &encodingCacheEntry{obj: obj, identifier: id, resourceVersion: resourceVersion, data: data}
+	if element, ok := c.entries[obj][id]; ok {
+		element.Value = entry
+		c.lru.MoveToFront(element)
+		return
+	}
+	if c.entries[obj] == nil {
+		c.entries[obj] = map[runtime.Identifier]*list.Element{}
+	}
+	c.entries[obj][id] = c.lru.PushFront(entry)
+	for c.lru.Len() > c.maxEntries {
+		c.remove(c.lru.Back())
+	}
+}
+
+func (c *EncodingCache) remove(element *list.Element) {
+	entry := c.lru.Remove(element).(*encodingCacheEntry)
+	delete(c.entries[entry.obj], entry.identifier)
+	if len(c.entries[entry.obj]) == 0 {
+		delete(c.entries, entry.obj)
+	}
+}
+
+// memoizingEncoder encodes objects with encoder, memoizing the results in cache.
+type memoizingEncoder struct {
+	encoder runtime.Encoder
+	cache   *EncodingCache
+}
+
+var _ runtime.Encoder = &memoizingEncoder{}
+
+// Encode writes the cached serialization of obj, or encodes obj and caches the result.
+func (e *memoizingEncoder) Encode(obj runtime.Object, w io.Writer) error {
+	resourceVersion, ok := memoizable(obj)
+	if !ok {
+		return e.encoder.Encode(obj, w)
+	}
+	id := e.encoder.Identifier()
+	if data, ok := e.cache.get(obj, id, resourceVersion); ok {
+		_, err := w.Write(data)
+		return err
+	}
+	buf := &bytes.Buffer{}
+	if err := e.encoder.Encode(obj, buf); err != nil {
+		return err
+	}
+	e.cache.add(obj, id, resourceVersion, buf.Bytes())
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// Identifier implements runtime.Encoder interface.
+func (e *memoizingEncoder) Identifier() runtime.Identifier {
+	return e.encoder.Identifier()
+}
+
+// memoizable returns the resourceVersion of obj, and whether its serializations can be memoized.
+func memoizable(obj runtime.Object) (string, bool) {
+	if obj == nil || reflect.TypeOf(obj).Kind() != reflect.Ptr {
+		return "", false
+	}
+	if _, ok := obj.(runtime.CacheableObject); ok {
+		return "", false
+	}
+	var resourceVersion string
+	if accessor, err := meta.Accessor(obj); err == nil {
+		resourceVersion = accessor.GetResourceVersion()
+	} else if accessor, err := meta.ListAccessor(obj); err == nil {
+		resourceVersion = accessor.GetResourceVersion()
+	}
+	return resourceVersion, len(resourceVersion) > 0
+}
@@ -0,0 +1,120 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package serializer
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+type countingEncoder struct {
+	runtime.Encoder
+	id    runtime.Identifier
+	calls int
+}
+
+func (e *countingEncoder) Encode(obj runtime.Object, w io.Writer) error {
+	e.calls++
+	return e.Encoder.Encode(obj, w)
+}
+
+func (e *countingEncoder) Identifier() runtime.Identifier {
+	return e.id
+}
+
+func newWidget(name, resourceVersion string) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{"apiVersion": "example.com/v1", "kind": "Widget"}}
+	obj.SetName(name)
+	obj.SetResourceVersion(resourceVersion)
+	return obj
+}
+
+func TestEncodingCache(t *testing.T) {
+	cache := NewEncodingCache(2)
+	first := &countingEncoder{Encoder: unstructured.UnstructuredJSONScheme, id: "first"}
+	second := &countingEncoder{Encoder: unstructured.UnstructuredJSONScheme, id: "second"}
+	firstMemoizing, secondMemoizing := cache.Encoder(first), cache.Encoder(second)
+	if firstMemoizing.Identifier() != "first" {
+		t.Errorf("expected the identifier of the wrapped encoder, got %q", firstMemoizing.Identifier())
+	}
+
+	encode := func(e runtime.Encoder, obj runtime.Object) string {
+		buf := &bytes.Buffer{}
+		if err := e.Encode(obj, buf); err != nil {
+			t.Fatal(err)
+		}
+		return buf.String()
+	}
+
+	obj := newWidget("a", "1")
+	expected := encode(unstructured.UnstructuredJSONScheme, obj)
+	for i := 0; i < 3; i++ {
+		if actual := encode(firstMemoizing, obj); actual != expected {
+			t.Errorf("expected %s, got %s", expected, actual)
+		}
+	}
+	encode(secondMemoizing, obj)
+	if first.calls != 1 || second.calls != 1 || cache.Len() != 2 {
+		t.Errorf("expected a single encoding per encoder, got %d and %d encodings, %d entries", first.calls, second.calls, cache.Len())
+	}
+
+	// a new resourceVersion invalidates the cached results
+	obj.SetResourceVersion("2")
+	if actual, expected := encode(firstMemoizing, obj), encode(unstructured.UnstructuredJSONScheme, obj); actual != expected {
+		t.Errorf("expected %s, got %s", expected, actual)
+	}
+	if first.calls != 2 {
+		t.Errorf("expected the object to be encoded again, got %d encodings", first.calls)
+	}
+
+	cache.Invalidate(obj)
+	if cache.Len() != 0 {
+		t.Errorf("expected no entries after invalidation, got %d", cache.Len())
+	}
+	encode(firstMemoizing, obj)
+	if first.calls != 3 {
+		t.Errorf("expected the object to be encoded again, got %d encodings", first.calls)
+	}
+
+	// the least recently used results are evicted
+	other, another := newWidget("b", "1"), newWidget("c", "1")
+	encode(firstMemoizing, other)
+	encode(firstMemoizing, obj)
+	encode(firstMemoizing, another)
+	if cache.Len() != 2 {
+		t.Errorf("expected 2 entries, got %d", cache.Len())
+	}
+	calls := first.calls
+	encode(firstMemoizing, obj)
+	encode(firstMemoizing, other)
+	if first.calls != calls+1 {
+		t.Errorf("expected only the evicted object to be encoded again, got %d encodings", first.calls-calls)
+	}
+
+	// objects without a resourceVersion are not memoized
+	cache.Clear()
+	unversioned := newWidget("d", "")
+	encode(firstMemoizing, unversioned)
+	encode(firstMemoizing, unversioned)
+	if cache.Len() != 0 || first.calls != calls+3 {
+		t.Errorf("expected objects without resourceVersion not to be memoized, got %d entries", cache.Len())
+	}
+}
@@ -0,0 +1,131 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package serializer
+
+import (
+	"io"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/runtime/serializer/recognizer"
+)
+
+// CodecHooks are functions invoked by the wire format serializers of a CodecFactory around the
+// serialization of every object. Hooks see the objects in their serialized version: they run after
+// conversion on encode and before conversion on decode, so that they apply to the codecs returned by
+// CodecForVersions and friends as well as to the universal deserializer.
+type CodecHooks struct {
+	// Name identifies the hooks in the identifiers of encoders, so that objects encoded with and
+	// without the hooks are cached separately. It must be unique among the hooks of a factory.
+	Name string
+	// BeforeEncode, if set, is invoked with each object before it is encoded. The returned object
+	// is encoded instead of obj. BeforeEncode must not mutate obj, which may be shared with other
+	// encoders, but may return a modified copy of it, e.g. without managed fields. Encoding fails
+	// with the returned error, if any.
+	BeforeEncode func(obj runtime.Object) (runtime.Object, error)
+	// AfterDecode, if set, is invoked with each object decoded successfully, the data it was decoded
+	// from and its serialized kind. Decoding fails with the returned error, if any.
+	AfterDecode func(data []byte, obj runtime.Object, gvk *schema.GroupVersionKind) error
+}
+
+// WithCodecHooks registers hooks invoked around the serialization of every object. Hooks run in
+// the order they are registered on encode, and in the reverse order on decode, so that the first
+// registered hooks are the farthest from the wire. Hooks run outside kind overrides.
+func WithCodecHooks(hooks CodecHooks) CodecFactoryOptionsMutator {
+	return func(options *CodecFactoryOptions) {
+		options.Hooks = append(options.Hooks, hooks)
+	}
+}
+
+// applyCodecHooks wraps the serializers of every serializerType with hooks.
+func applyCodecHooks(serializers []serializerType, hooks []CodecHooks) {
+	for i := range serializers {
+		s := &serializers[i]
+		// the hooks registered last wrap the serializer first
+		for j := len(hooks) - 1; j >= 0; j-- {
+			s.Serializer = newHookSerializer(s.Serializer, hooks[j])
+			s.PrettySerializer = newHookSerializer(s.PrettySerializer, hooks[j])
+			s.StrictSerializer = newHookSerializer(s.StrictSerializer, hooks[j])
+			s.StreamSerializer = newHookSerializer(s.StreamSerializer, hooks[j])
+		}
+	}
+}
+
+// hookSerializer invokes CodecHooks around a serializer.
+type hookSerializer struct {
+	serializerWrapper
+	hooks CodecHooks
+}
+
+var _ runtime.Serializer = &hookSerializer{}
+var _ runtime.EncoderWithAllocator = &hookSerializer{}
+var _ runtime.SizeEstimator = &hookSerializer{}
+var _ recognizer.RecognizingDecoder = &hookSerializer{}
+
+func newHookSerializer(serializer runtime.Serializer, hooks CodecHooks) runtime.Serializer {
+	if serializer == nil {
+		return nil
+	}
+	s := &hookSerializer{
+		serializerWrapper: serializerWrapper{
+			encoder:    serializer,
+			decoder:    serializer,
+			identifier: serializer.Identifier(),
+		},
+		hooks: hooks,
+	}
+	// hooks not changing the encoded objects don't change the Identifier
+	if hooks.BeforeEncode != nil {
+		s.identifier = wrapperIdentifier("hooks", serializer, map[string]interface{}{"hooks": hooks.Name})
+		s.encode = s.doEncodeWithAllocator
+	}
+	return s
+}
+
+// doEncodeWithAllocator invokes the BeforeEncode hook and encodes the resulting object.
+func (s *hookSerializer) doEncodeWithAllocator(obj runtime.Object, w io.Writer, memAlloc runtime.MemoryAllocator) error {
+	obj, err := s.hooks.BeforeEncode(obj)
+	if err != nil {
+		return err
+	}
+	return runtime.WithAllocator(s.encoder, memAlloc).Encode(obj, w)
+}
+
+// EstimateSize implements runtime.SizeEstimator. The size of the encoding of the object returned by the
+// BeforeEncode hook is estimated with runtime.EstimateSize.
+func (s *hookSerializer) EstimateSize(obj runtime.Object, cache *runtime.SizeEstimateCache) (int, error) {
+	if s.hooks.BeforeEncode != nil {
+		var err error
+		if obj, err = s.hooks.BeforeEncode(obj); err != nil {
+			return 0, err
+		}
+	}
+	return runtime.EstimateSize(obj, s.encoder, cache)
+}
+
+// Decode decodes data and invokes the AfterDecode hook with the result. Objects decoded with strict
+// decoding errors are passed to the hook as well.
+func (s *hookSerializer) Decode(data []byte, defaults *schema.GroupVersionKind, into runtime.Object) (runtime.Object, *schema.GroupVersionKind, error) {
+	obj, gvk, err := s.decoder.Decode(data, defaults, into)
+	if s.hooks.AfterDecode == nil || obj == nil || (err != nil && !runtime.IsStrictDecodingError(err)) {
+		return obj, gvk, err
+	}
+	if hookErr := s.hooks.AfterDecode(data, obj, gvk); hookErr != nil {
+		return nil, gvk, hookErr
+	}
+	return obj, gvk, err
+}
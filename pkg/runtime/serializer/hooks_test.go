@@ -0,0 +1,97 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package serializer
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	runtimetesting "k8s.io/apimachinery/pkg/runtime/testing"
+)
+
+func TestCodecHooks(t *testing.T) {
+	gv := schema.GroupVersion{Group: "test.group", Version: "v1"}
+	scheme := runtime.NewScheme()
+	scheme.AddKnownTypeWithName(gv.WithKind("Simple"), &runtimetesting.ExternalSimple{})
+
+	var calls []string
+	redact := CodecHooks{
+		Name: "redact",
+		BeforeEncode: func(obj runtime.Object) (runtime.Object, error) {
+			calls = append(calls, "redact encode")
+			redacted := obj.DeepCopyObject().(*runtimetesting.ExternalSimple)
+			redacted.TestString = "redacted"
+			return redacted, nil
+		},
+		AfterDecode: func(data []byte, obj runtime.Object, gvk *schema.GroupVersionKind) error {
+			calls = append(calls, "redact decode "+gvk.Kind)
+			return nil
+		},
+	}
+	limit := CodecHooks{
+		Name: "limit",
+		BeforeEncode: func(obj runtime.Object) (runtime.Object, error) {
+			calls = append(calls, "limit encode")
+			return obj, nil
+		},
+		AfterDecode: func(data []byte, obj runtime.Object, gvk *schema.GroupVersionKind) error {
+			calls = append(calls, "limit decode")
+			if len(data) > 100 {
+				return errors.New("too large")
+			}
+			return nil
+		},
+	}
+	factory := NewCodecFactory(scheme, WithCodecHooks(redact), WithCodecHooks(limit))
+	info, ok := runtime.SerializerInfoForMediaType(factory.SupportedMediaTypes(), runtime.ContentTypeJSON)
+	if !ok {
+		t.Fatalf("no serializer for %s", runtime.ContentTypeJSON)
+	}
+	codec := factory.CodecForVersions(info.Serializer, factory.UniversalDeserializer(), gv, gv)
+
+	obj := &runtimetesting.ExternalSimple{TestString: "secret"}
+	data, err := runtime.Encode(codec, obj)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "secret") || obj.TestString != "secret" {
+		t.Errorf("expected a redacted copy to be encoded, got %s", data)
+	}
+	if _, err := runtime.Decode(codec, data); err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"redact encode", "limit encode", "limit decode", "redact decode Simple"}
+	if !reflect.DeepEqual(expected, calls) {
+		t.Errorf("expected %v, got %v", expected, calls)
+	}
+
+	large := []byte(`{"apiVersion":"test.group/v1","kind":"Simple","testString":"` + strings.Repeat("x", 100) + `"}`)
+	if _, err := runtime.Decode(codec, large); err == nil || err.Error() != "too large" {
+		t.Errorf("expected the hook error, got %v", err)
+	}
+
+	plain := NewCodecFactory(scheme)
+	for i, info := range factory.SupportedMediaTypes() {
+		if info.Serializer.Identifier() == plain.SupportedMediaTypes()[i].Serializer.Identifier() {
+			t.Errorf("%s: expected serializers with hooks to have a different identifier", info.MediaType)
+		}
+	}
+}
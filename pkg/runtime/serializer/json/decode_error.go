@@ -0,0 +1,189 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package json
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	kjson "sigs.k8s.io/json"
+)
+
+// DecodeError is returned by the Serializer when data can't be unmarshaled into an object, with the
+// location of the failure.
+type DecodeError struct {
+	// GroupVersionKind is the kind of the object being decoded.
+	GroupVersionKind schema.GroupVersionKind
+	// ObjectType is the Go type of the object being decoded.
+	ObjectType reflect.Type
+	// Path is the JSON pointer of the value that couldn't be decoded, or of the value being decoded
+	// when a syntax error was found. It is empty if the location is unknown or if it is the whole
+	// document.
+	Path string
+	// Offset is the offset in bytes of the failure in the decoded data, or -1 if it is unknown. YAML
+	// data is converted to JSON before being decoded, so the offsets of YAML errors are unknown.
+	Offset int64
+	// FieldType is the Go type the value at Path couldn't be decoded into, if known.
+	FieldType reflect.Type
+
+	// Err is the error returned by the unmarshaler.
+	Err error
+}
+
+func (e *DecodeError) Error() string {
+	var location []string
+	if len(e.Path) > 0 {
+		location = append(location, fmt.Sprintf("at %q", e.Path))
+	}
+	if e.Offset >= 0 {
+		location = append(location, fmt.Sprintf("offset %d", e.Offset))
+	}
+	if e.FieldType != nil {
+		location = append(location, fmt.Sprintf("into %v", e.FieldType))
+	}
+	if len(location) == 0 {
+		return fmt.Sprintf("error decoding %v: %v", e.GroupVersionKind, e.Err)
+	}
+	return fmt.Sprintf("error decoding %v %s: %v", e.GroupVersionKind, strings.Join(location, " "), e.Err)
+}
+
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// newDecodeError locates err, returned by unmarshaling the JSON data, in data.
+func newDecodeError(err error, data []byte, into interface{}, gvk schema.GroupVersionKind, yaml bool) error {
+	decodeErr := &DecodeError{
+		GroupVersionKind: gvk,
+		ObjectType:       reflect.TypeOf(into),
+		Offset:           -1,
+		Err:              err,
+	}
+	if isSyntaxError, offset := kjson.SyntaxErrorOffset(err); isSyntaxError {
+		decodeErr.Offset = offset
+	} else if offset, fieldType, ok := unmarshalTypeError(err); ok {
+		decodeErr.Offset, decodeErr.FieldType = offset, fieldType
+	}
+	if decodeErr.Offset >= 0 {
+		decodeErr.Path = jsonPointerAtOffset(data, decodeErr.Offset)
+	}
+	if yaml {
+		decodeErr.Offset = -1
+	}
+	return decodeErr
+}
+
+var reflectType = reflect.TypeOf((*reflect.Type)(nil)).Elem()
+
+// unmarshalTypeError returns the offset and target type of an UnmarshalTypeError returned by
+// encoding/json or by sigs.k8s.io/json, whose error type isn't exported.
+func unmarshalTypeError(err error) (int64, reflect.Type, bool) {
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		return typeErr.Offset, typeErr.Type, true
+	}
+	v := reflect.ValueOf(err)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct || v.Elem().Type().Name() != "UnmarshalTypeError" {
+		return 0, nil, false
+	}
+	offset, fieldType := v.Elem().FieldByName("Offset"), v.Elem().FieldByName("Type")
+	if offset.Kind() != reflect.Int64 || !fieldType.IsValid() || fieldType.Type() != reflectType {
+		return 0, nil, false
+	}
+	t, _ := fieldType.Interface().(reflect.Type)
+	return offset.Int(), t, true
+}
+
+type jsonPointerFrame struct {
+	array bool
+	// key is the key of the current member of an object, valid unless expectKey is set.
+	key       string
+	expectKey bool
+	// index is the index of the current element of an array.
+	index int
+}
+
+// jsonPointerAtOffset returns the JSON pointer of the innermost value of the JSON document data
+// containing offset, or of the last value read before a syntax error.
+func jsonPointerAtOffset(data []byte, offset int64) string {
+	var stack []*jsonPointerFrame
+	pointer := func() string {
+		var b strings.Builder
+		for _, frame := range stack {
+			switch {
+			case frame.array:
+				b.WriteString("/" + strconv.Itoa(frame.index))
+			case !frame.expectKey:
+				b.WriteString("/" + strings.NewReplacer("~", "~0", "/", "~1").Replace(frame.key))
+			}
+		}
+		return b.String()
+	}
+	completeValue := func() {
+		if len(stack) == 0 {
+			return
+		}
+		if top := stack[len(stack)-1]; top.array {
+			top.index++
+		} else {
+			top.expectKey = true
+		}
+	}
+
+	d := json.NewDecoder(bytes.NewReader(data))
+	for {
+		token, err := d.Token()
+		if err != nil {
+			return pointer()
+		}
+		if len(stack) > 0 {
+			if top := stack[len(stack)-1]; !top.array && top.expectKey {
+				if key, ok := token.(string); ok {
+					top.key, top.expectKey = key, false
+					if d.InputOffset() >= offset {
+						return pointer()
+					}
+					continue
+				}
+			}
+		}
+		switch token {
+		case json.Delim('{'), json.Delim('['):
+			if d.InputOffset() >= offset {
+				return pointer()
+			}
+			stack = append(stack, &jsonPointerFrame{array: token == json.Delim('['), expectKey: true})
+		case json.Delim('}'), json.Delim(']'):
+			stack = stack[:len(stack)-1]
+			if d.InputOffset() >= offset {
+				return pointer()
+			}
+			completeValue()
+		default:
+			if d.InputOffset() >= offset {
+				return pointer()
+			}
+			completeValue()
+		}
+	}
+}
@@ -17,6 +17,7 @@ limitations under the License.
 package json
 
 import (
+	"bytes"
 	"encoding/json"
 	"io"
 	"strconv"
@@ -36,7 +37,7 @@ import (
 // is not nil, the object has the group, version, and kind fields set.
 // Deprecated: use NewSerializerWithOptions instead.
 func NewSerializer(meta MetaFactory, creater runtime.ObjectCreater, typer runtime.ObjectTyper, pretty bool) *Serializer {
-	return NewSerializerWithOptions(meta, creater, typer, SerializerOptions{false, pretty, false})
+	return NewSerializerWithOptions(meta, creater, typer, SerializerOptions{Yaml: false, Pretty: pretty, Strict: false})
 }
 
 // NewYAMLSerializer creates a YAML serializer that handles encoding versioned objects into the proper YAML form. If typer
@@ -44,7 +45,7 @@ func NewSerializer(meta MetaFactory, creater runtime.ObjectCreater, typer runtim
 // matches JSON, and will error if constructs are used that do not serialize to JSON.
 // Deprecated: use NewSerializerWithOptions instead.
 func NewYAMLSerializer(meta MetaFactory, creater runtime.ObjectCreater, typer runtime.ObjectTyper) *Serializer {
-	return NewSerializerWithOptions(meta, creater, typer, SerializerOptions{true, false, false})
+	return NewSerializerWithOptions(meta, creater, typer, SerializerOptions{Yaml: true, Pretty: false, Strict: false})
 }
 
 // NewSerializerWithOptions creates a JSON/YAML serializer that handles encoding versioned objects into the proper JSON/YAML
@@ -68,6 +69,9 @@ func identifier(options SerializerOptions) runtime.Identifier {
 		"pretty": strconv.FormatBool(options.Pretty),
 		"strict": strconv.FormatBool(options.Strict),
 	}
+	if options.Deterministic {
+		result["deterministic"] = "true"
+	}
 	identifier, err := json.Marshal(result)
 	if err != nil {
 		klog.Fatalf("Failed marshaling identifier for json Serializer: %v", err)
@@ -93,6 +97,16 @@ type SerializerOptions struct {
 	// Strict: configures the Serializer to return strictDecodingError's when duplicate fields are present decoding JSON or YAML.
 	// Note that enabling this option is not as performant as the non-strict variant, and should not be used in fast paths.
 	Strict bool
+	// Limits: configures the Serializer to reject data exceeding the limits before decoding it, with an error for
+	// which runtime.IsDecodingLimitError returns true. MaxDocuments and MaxExpandedBytes only apply to YAML.
+	Limits runtime.DecodingLimits
+
+	// Deterministic: configures a JSON enabled Serializer(`Yaml: false`) to sort the keys of every JSON object it writes,
+	// including the objects written by custom marshalers, like the raw content of runtime.RawExtension and
+	// runtime.Unknown, so that encoding equal objects always produces the same bytes. The keys of maps are always
+	// sorted otherwise, and YAML output is always sorted. Note that this requires decoding the output again, and
+	// should not be used in fast paths.
+	Deterministic bool
 }
 
 // Serializer handles encoding versioned objects into the proper JSON form
@@ -107,6 +121,7 @@ type Serializer struct {
 
 // Serializer implements Serializer
 var _ runtime.Serializer = &Serializer{}
+var _ runtime.EncoderWithAllocator = &Serializer{}
 var _ recognizer.RecognizingDecoder = &Serializer{}
 
 // gvkWithDefaults returns group kind and version defaulting from provided default
@@ -132,15 +147,28 @@ func gvkWithDefaults(actual, defaultGVK schema.GroupVersionKind) schema.GroupVer
 // If into is nil or data's gvk different from into's gvk, it will generate a new Object with ObjectCreater.New(gvk)
 // On success or most errors, the method will return the calculated schema kind.
 // The gvk calculate priority will be originalData > default gvk > into
+// Errors unmarshaling the data into the object are returned as *DecodeError, locating the failure in data.
 func (s *Serializer) Decode(originalData []byte, gvk *schema.GroupVersionKind, into runtime.Object) (runtime.Object, *schema.GroupVersionKind, error) {
 	data := originalData
+	if err := s.options.Limits.CheckSize(len(data)); err != nil {
+		return nil, nil, err
+	}
 	if s.options.Yaml {
+		if err := checkYAMLDocuments(data, s.options.Limits.MaxDocuments); err != nil {
+			return nil, nil, err
+		}
+		if err := checkYAMLLimits(data, s.options.Limits); err != nil {
+			return nil, nil, err
+		}
 		altered, err := yaml.YAMLToJSON(data)
 		if err != nil {
 			return nil, nil, err
 		}
 		data = altered
 	}
+	if err := checkJSONDepth(data, s.options.Limits.MaxDepth); err != nil {
+		return nil, nil, err
+	}
 
 	actual, err := s.meta.Interpret(data)
 	if err != nil {
@@ -165,7 +193,7 @@ func (s *Serializer) Decode(originalData []byte, gvk *schema.GroupVersionKind, i
 		case runtime.IsNotRegisteredError(err), isUnstructured:
 			strictErrs, err := s.unmarshal(into, data, originalData)
 			if err != nil {
-				return nil, actual, err
+				return nil, actual, newDecodeError(err, data, into, *actual, s.options.Yaml)
 			} else if len(strictErrs) > 0 {
 				return into, actual, runtime.NewStrictDecodingError(strictErrs)
 			}
@@ -192,7 +220,7 @@ func (s *Serializer) Decode(originalData []byte, gvk *schema.GroupVersionKind, i
 
 	strictErrs, err := s.unmarshal(obj, data, originalData)
 	if err != nil {
-		return nil, actual, err
+		return nil, actual, newDecodeError(err, data, obj, *actual, s.options.Yaml)
 	} else if len(strictErrs) > 0 {
 		return obj, actual, runtime.NewStrictDecodingError(strictErrs)
 	}
@@ -207,6 +235,17 @@ func (s *Serializer) Encode(obj runtime.Object, w io.Writer) error {
 	return s.doEncode(obj, w)
 }
 
+// EncodeWithAllocator writes an object to the provided writer.
+// In addition, it allows for providing a memory allocator for efficient memory usage during object serialization.
+// The encoded output is assembled in memory obtained from memAlloc and written to w with a single call.
+func (s *Serializer) EncodeWithAllocator(obj runtime.Object, w io.Writer, memAlloc runtime.MemoryAllocator) error {
+	encode := func(obj runtime.Object, w io.Writer) error { return s.doEncodeWithAllocator(obj, w, memAlloc) }
+	if co, ok := obj.(runtime.CacheableObject); ok {
+		return co.CacheEncode(s.Identifier(), encode, w)
+	}
+	return encode(obj, w)
+}
+
 func (s *Serializer) doEncode(obj runtime.Object, w io.Writer) error {
 	if s.options.Yaml {
 		json, err := json.Marshal(obj)
@@ -221,6 +260,18 @@ func (s *Serializer) doEncode(obj runtime.Object, w io.Writer) error {
 		return err
 	}
 
+	if s.options.Deterministic {
+		data, err := json.Marshal(obj)
+		if err != nil {
+			return err
+		}
+		if data, err = sortJSONKeys(data, s.options.Pretty); err != nil {
+			return err
+		}
+		_, err = w.Write(data)
+		return err
+	}
+
 	if s.options.Pretty {
 		data, err := json.MarshalIndent(obj, "", "  ")
 		if err != nil {
@@ -233,6 +284,89 @@ func (s *Serializer) doEncode(obj runtime.Object, w io.Writer) error {
 	return encoder.Encode(obj)
 }
 
+func (s *Serializer) doEncodeWithAllocator(obj runtime.Object, w io.Writer, memAlloc runtime.MemoryAllocator) error {
+	if memAlloc == nil {
+		klog.Error("a mandatory memory allocator wasn't provided, this might have a negative impact on performance, check invocations of EncodeWithAllocator method, falling back on runtime.SimpleAllocator")
+		memAlloc = &runtime.SimpleAllocator{}
+	}
+
+	buf := &allocatorBuffer{memAlloc: memAlloc, buf: memAlloc.Allocate(0)}
+	encoder := json.NewEncoder(buf)
+	if s.options.Pretty && !s.options.Yaml {
+		encoder.SetIndent("", "  ")
+	}
+	if err := encoder.Encode(obj); err != nil {
+		return err
+	}
+	data := buf.buf
+
+	if s.options.Yaml {
+		yamlData, err := yaml.JSONToYAML(data)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(yamlData)
+		return err
+	}
+
+	if s.options.Deterministic {
+		sorted, err := sortJSONKeys(data, s.options.Pretty)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(sorted)
+		return err
+	}
+
+	if s.options.Pretty {
+		// match the output of json.MarshalIndent, which has no trailing newline
+		data = data[:len(data)-1]
+	}
+	_, err := w.Write(data)
+	return err
+}
+
+// sortJSONKeys re-encodes the JSON document data with the keys of all its objects sorted, like the output of
+// json.Encoder if pretty is false and json.MarshalIndent otherwise. Numbers are preserved as written.
+func sortJSONKeys(data []byte, pretty bool) ([]byte, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var document interface{}
+	if err := decoder.Decode(&document); err != nil {
+		return nil, err
+	}
+	buf := &bytes.Buffer{}
+	encoder := json.NewEncoder(buf)
+	if pretty {
+		encoder.SetIndent("", "  ")
+	}
+	if err := encoder.Encode(document); err != nil {
+		return nil, err
+	}
+	sorted := buf.Bytes()
+	if pretty {
+		sorted = sorted[:len(sorted)-1]
+	}
+	return sorted, nil
+}
+
+// allocatorBuffer is an io.Writer that accumulates data in memory obtained from a runtime.MemoryAllocator.
+type allocatorBuffer struct {
+	memAlloc runtime.MemoryAllocator
+	buf      []byte
+}
+
+func (b *allocatorBuffer) Write(p []byte) (int, error) {
+	if len(b.buf)+len(p) > cap(b.buf) {
+		grown := b.memAlloc.Allocate(uint64(2*cap(b.buf) + len(p)))
+		// the allocator may return the memory backing b.buf, in which case this is a no-op
+		copy(grown, b.buf)
+		b.buf = grown[:len(b.buf)]
+	}
+	b.buf = append(b.buf, p...)
+	return len(p), nil
+}
+
 // IsStrict indicates whether the serializer
 // uses strict decoding or not
 func (s *Serializer) IsStrict() bool {
@@ -249,13 +383,9 @@ func (s *Serializer) unmarshal(into runtime.Object, data, originalData []byte) (
 	}
 
 	if s.options.Yaml {
-		// In strict mode pass the original data through the YAMLToJSONStrict converter.
-		// This is done to catch duplicate fields in YAML that would have been dropped in the original YAMLToJSON conversion.
-		// TODO: rework YAMLToJSONStrict to return warnings about duplicate fields without terminating so we don't have to do this twice.
-		_, err := yaml.YAMLToJSONStrict(originalData)
-		if err != nil {
-			strictErrs = append(strictErrs, err)
-		}
+		// In strict mode look for duplicate fields in the original data, since they were dropped
+		// by the conversion to JSON. All of them are reported, with their path.
+		strictErrs = append(strictErrs, yamlDuplicateFieldErrors(originalData)...)
 	}
 
 	var strictJSONErrs []error
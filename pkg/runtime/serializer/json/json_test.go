@@ -17,7 +17,10 @@ limitations under the License.
 package json_test
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
+	"io"
 	"reflect"
 	"strings"
 	"testing"
@@ -333,7 +336,7 @@ func TestDecode(t *testing.T) {
 			typer:       &mockTyper{gvk: &schema.GroupVersionKind{Kind: "Test", Group: "other", Version: "blah"}},
 			expectedGVK: &schema.GroupVersionKind{Kind: "Test", Group: "other", Version: "blah"},
 			errFn: func(err error) bool {
-				return strings.Contains(err.Error(), `"value" already set in map`)
+				return strings.Contains(err.Error(), `duplicate field "value"`)
 			},
 			yaml:   true,
 			strict: true,
@@ -357,7 +360,42 @@ func TestDecode(t *testing.T) {
 			typer:       &mockTyper{gvk: &schema.GroupVersionKind{Kind: "Test", Group: "other", Version: "blah"}},
 			expectedGVK: &schema.GroupVersionKind{},
 			errFn: func(err error) bool {
-				return strings.Contains(err.Error(), `"value" already set in map`)
+				return strings.Contains(err.Error(), `duplicate field "value"`)
+			},
+			yaml:   true,
+			strict: true,
+		},
+		// All strictness violations should be reported at once by the strict YAML deserializer, with their paths.
+		{
+			data: []byte("value: 1\n" +
+				"value: 2\n" +
+				"unknown: 1\n" +
+				"spec:\n" +
+				"  A: 1\n" +
+				"  A: 2\n" +
+				"  Z: 1\n" +
+				"interface:\n" +
+				"- name: a\n" +
+				"  name: b\n"),
+			into:        &testDecodable{},
+			typer:       &mockTyper{gvk: &schema.GroupVersionKind{Kind: "Test", Group: "other", Version: "blah"}},
+			expectedGVK: &schema.GroupVersionKind{Kind: "Test", Group: "other", Version: "blah"},
+			errFn: func(err error) bool {
+				strictErr, ok := runtime.AsStrictDecodingError(err)
+				if !ok {
+					return false
+				}
+				var messages []string
+				for _, err := range strictErr.Errors() {
+					messages = append(messages, err.Error())
+				}
+				return reflect.DeepEqual([]string{
+					`duplicate field "value"`,
+					`duplicate field "spec.A"`,
+					`duplicate field "interface[0].name"`,
+					`unknown field "spec.Z"`,
+					`unknown field "unknown"`,
+				}, messages)
 			},
 			yaml:   true,
 			strict: true,
@@ -567,6 +605,68 @@ func TestDecode(t *testing.T) {
 	}
 }
 
+func TestDecodeErrors(t *testing.T) {
+	gvk := schema.GroupVersionKind{Kind: "Test", Group: "other", Version: "blah"}
+	typer := &mockTyper{err: runtime.NewNotRegisteredErrForKind("mock", gvk)}
+	testCases := []struct {
+		name      string
+		data      string
+		yaml      bool
+		path      string
+		offset    int64
+		fieldType reflect.Type
+	}{
+		{
+			name:      "field",
+			data:      `{"kind":"Test","apiVersion":"other/blah","value":"1"}`,
+			path:      "/value",
+			offset:    52,
+			fieldType: reflect.TypeOf(0),
+		},
+		{
+			name:      "nested field",
+			data:      `{"kind":"Test","apiVersion":"other/blah","spec":{"A":1,"B":[2]}}`,
+			path:      "/spec/B",
+			offset:    60,
+			fieldType: reflect.TypeOf(0),
+		},
+		{
+			name:      "array item",
+			data:      `{"kind":"Test","apiVersion":"other/blah","interface":{"a/b":[1,{"c":1e1000}]}}`,
+			path:      "/interface/a~1b/1/c",
+			offset:    75,
+			fieldType: reflect.TypeOf(float64(0)),
+		},
+		{
+			name:      "yaml",
+			data:      "kind: Test\napiVersion: other/blah\nspec:\n  A: true\n",
+			yaml:      true,
+			path:      "/spec/A",
+			offset:    -1,
+			fieldType: reflect.TypeOf(0),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := json.NewSerializerWithOptions(json.DefaultMetaFactory, nil, typer, json.SerializerOptions{Yaml: tc.yaml})
+			_, _, err := s.Decode([]byte(tc.data), nil, &testDecodable{})
+			var decodeErr *json.DecodeError
+			if !errors.As(err, &decodeErr) {
+				t.Fatalf("expected a decode error, got %v", err)
+			}
+			if decodeErr.GroupVersionKind != gvk || decodeErr.ObjectType != reflect.TypeOf(&testDecodable{}) {
+				t.Errorf("unexpected object %v %v", decodeErr.GroupVersionKind, decodeErr.ObjectType)
+			}
+			if decodeErr.Path != tc.path || decodeErr.Offset != tc.offset || decodeErr.FieldType != tc.fieldType {
+				t.Errorf("expected %q at %d into %v, got %q at %d into %v", tc.path, tc.offset, tc.fieldType, decodeErr.Path, decodeErr.Offset, decodeErr.FieldType)
+			}
+			if !strings.Contains(err.Error(), tc.path) || !strings.Contains(err.Error(), decodeErr.Err.Error()) {
+				t.Errorf("expected the error to mention the path and the unmarshaling error, got %v", err)
+			}
+		})
+	}
+}
+
 func TestCacheableObject(t *testing.T) {
 	gvk := schema.GroupVersionKind{Group: "group", Version: "version", Kind: "MockCacheableObject"}
 	creater := &mockCreater{obj: &runtimetesting.MockCacheableObject{}}
@@ -603,3 +703,235 @@ func (t *mockTyper) ObjectKinds(obj runtime.Object) ([]schema.GroupVersionKind,
 func (t *mockTyper) Recognizes(_ schema.GroupVersionKind) bool {
 	return false
 }
+
+func TestEncodeWithAllocator(t *testing.T) {
+	obj := &testDecodable{
+		TypeMeta: metav1.TypeMeta{APIVersion: "other/blah", Kind: "Test"},
+		Other:    "<html>",
+		Value:    1,
+		Spec:     DecodableSpec{A: 1, B: 2},
+		Interface: map[string]interface{}{
+			"nested": []interface{}{"a", int64(1)},
+		},
+	}
+	testCases := map[string]json.SerializerOptions{
+		"json":   {},
+		"pretty": {Pretty: true},
+		"yaml":   {Yaml: true},
+
+		"deterministic":        {Deterministic: true},
+		"deterministic pretty": {Deterministic: true, Pretty: true},
+	}
+	for name, options := range testCases {
+		t.Run(name, func(t *testing.T) {
+			s := json.NewSerializerWithOptions(json.DefaultMetaFactory, nil, nil, options)
+			expected := &bytes.Buffer{}
+			if err := s.Encode(obj, expected); err != nil {
+				t.Fatal(err)
+			}
+
+			allocator := &runtime.Allocator{}
+			for _, memAlloc := range []runtime.MemoryAllocator{&runtime.SimpleAllocator{}, allocator, allocator} {
+				actual := &bytes.Buffer{}
+				if err := s.EncodeWithAllocator(obj, actual, memAlloc); err != nil {
+					t.Fatal(err)
+				}
+				if expected.String() != actual.String() {
+					t.Errorf("%T: unexpected output:\n%s", memAlloc, diff.StringDiff(expected.String(), actual.String()))
+				}
+			}
+		})
+	}
+}
+
+func TestDeterministicEncoding(t *testing.T) {
+	obj := &runtimetesting.ObjectTestExternal{
+		TypeMeta: runtime.TypeMeta{APIVersion: "v1", Kind: "Test"},
+		Items: []runtime.RawExtension{
+			{Raw: []byte(`{"kind":"Item","apiVersion":"v1","spec":{"z":1.50,"a":[{"y":"<>","b":null}]}}`)},
+		},
+	}
+	testCases := map[string]struct {
+		options  json.SerializerOptions
+		expected string
+	}{
+		"json": {
+			options:  json.SerializerOptions{Deterministic: true},
+			expected: `{"apiVersion":"v1","items":[{"apiVersion":"v1","kind":"Item","spec":{"a":[{"b":null,"y":"\u003c\u003e"}],"z":1.50}}],"kind":"Test"}` + "\n",
+		},
+		"pretty": {
+			options:  json.SerializerOptions{Deterministic: true, Pretty: true},
+			expected: "{\n  \"apiVersion\": \"v1\",\n  \"items\": [\n    {\n      \"apiVersion\": \"v1\",\n      \"kind\": \"Item\",\n      \"spec\": {\n        \"a\": [\n          {\n            \"b\": null,\n            \"y\": \"\\u003c\\u003e\"\n          }\n        ],\n        \"z\": 1.50\n      }\n    }\n  ],\n  \"kind\": \"Test\"\n}",
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			s := json.NewSerializerWithOptions(json.DefaultMetaFactory, nil, nil, tc.options)
+			actual := &bytes.Buffer{}
+			if err := s.Encode(obj, actual); err != nil {
+				t.Fatal(err)
+			}
+			if actual.String() != tc.expected {
+				t.Errorf("unexpected output:\n%s", diff.StringDiff(tc.expected, actual.String()))
+			}
+		})
+	}
+
+	if json.NewSerializerWithOptions(json.DefaultMetaFactory, nil, nil, json.SerializerOptions{Deterministic: true}).Identifier() ==
+		json.NewSerializerWithOptions(json.DefaultMetaFactory, nil, nil, json.SerializerOptions{}).Identifier() {
+		t.Errorf("expected a distinct identifier for deterministic serializers")
+	}
+	expected := `{"name":"json","pretty":"false","strict":"false","yaml":"false"}`
+	if id := json.NewSerializerWithOptions(json.DefaultMetaFactory, nil, nil, json.SerializerOptions{}).Identifier(); string(id) != expected {
+		t.Errorf("expected the identifier of non-deterministic serializers to be unchanged, got %s", id)
+	}
+}
+
+func TestCacheableObjectWithAllocator(t *testing.T) {
+	gvk := schema.GroupVersionKind{Group: "group", Version: "version", Kind: "MockCacheableObject"}
+	creater := &mockCreater{obj: &runtimetesting.MockCacheableObject{}}
+	typer := &mockTyper{gvk: &gvk}
+	serializer := json.NewSerializer(json.DefaultMetaFactory, creater, typer, false)
+
+	runtimetesting.CacheableObjectTest(t, encoderWithAllocator{serializer, &runtime.Allocator{}})
+}
+
+type encoderWithAllocator struct {
+	runtime.EncoderWithAllocator
+	memAlloc runtime.MemoryAllocator
+}
+
+func (e encoderWithAllocator) Encode(obj runtime.Object, w io.Writer) error {
+	return e.EncodeWithAllocator(obj, w, e.memAlloc)
+}
+
+type testDecodableList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []testDecodable `json:"items"`
+}
+
+func (l *testDecodableList) DeepCopyObject() runtime.Object {
+	if l == nil {
+		return nil
+	}
+	out := *l
+	out.Items = append([]testDecodable(nil), l.Items...)
+	return &out
+}
+
+func TestEncodeListStream(t *testing.T) {
+	list := &testDecodableList{
+		TypeMeta: metav1.TypeMeta{APIVersion: "other/blah", Kind: "TestList"},
+		ListMeta: metav1.ListMeta{ResourceVersion: "10", Continue: "<next>"},
+	}
+	var items []runtime.Object
+	for i := 0; i < 3; i++ {
+		item := testDecodable{Other: fmt.Sprintf("<item %d>", i), Value: i, Spec: DecodableSpec{A: i}}
+		list.Items = append(list.Items, item)
+		items = append(items, &list.Items[i])
+	}
+	s := json.NewSerializerWithOptions(json.DefaultMetaFactory, nil, nil, json.SerializerOptions{})
+
+	empty := list.DeepCopyObject().(*testDecodableList)
+	empty.Items = []testDecodable{}
+	testCases := map[string]struct {
+		items    json.ItemsProvider
+		expected *testDecodableList
+		writes   int
+	}{
+		"slice": {items: json.SliceItems(items), expected: list, writes: len(items) + 2},
+		"channel": {items: func() json.ItemsProvider {
+			ch := make(chan runtime.Object, len(items))
+			for _, item := range items {
+				ch <- item
+			}
+			close(ch)
+			return json.ChannelItems(ch)
+		}(), expected: list, writes: len(items) + 2},
+		"no items": {items: json.SliceItems(nil), expected: empty, writes: 2},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			expected := &bytes.Buffer{}
+			if err := s.Encode(tc.expected, expected); err != nil {
+				t.Fatal(err)
+			}
+			actual := &countingWriter{}
+			if err := s.EncodeListStream(list, tc.items, actual); err != nil {
+				t.Fatal(err)
+			}
+			if expected.String() != actual.String() {
+				t.Errorf("unexpected output:\n%s", diff.StringDiff(expected.String(), actual.String()))
+			}
+			if actual.writes != tc.writes {
+				t.Errorf("expected %d writes, got %d", tc.writes, actual.writes)
+			}
+		})
+	}
+
+	failing := func() (runtime.Object, error) { return nil, fmt.Errorf("source failed") }
+	if err := s.EncodeListStream(list, failing, &bytes.Buffer{}); err == nil || err.Error() != "source failed" {
+		t.Errorf("expected the source error, got %v", err)
+	}
+	yaml := json.NewSerializerWithOptions(json.DefaultMetaFactory, nil, nil, json.SerializerOptions{Yaml: true})
+	if err := yaml.EncodeListStream(list, json.SliceItems(items), &bytes.Buffer{}); err == nil {
+		t.Errorf("expected an error for YAML")
+	}
+}
+
+type countingWriter struct {
+	bytes.Buffer
+	writes int
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	w.writes++
+	return w.Buffer.Write(p)
+}
+
+func TestDecodingLimits(t *testing.T) {
+	scheme := runtime.NewScheme()
+	limits := runtime.DecodingLimits{MaxBytes: 200, MaxExpandedBytes: 400, MaxDocuments: 1, MaxDepth: 3}
+	jsonSerializer := json.NewSerializerWithOptions(json.DefaultMetaFactory, scheme, scheme, json.SerializerOptions{Limits: limits})
+	yamlSerializer := json.NewSerializerWithOptions(json.DefaultMetaFactory, scheme, scheme, json.SerializerOptions{Yaml: true, Limits: limits})
+
+	testCases := []struct {
+		name       string
+		serializer runtime.Serializer
+		data       string
+		exceeded   bool
+		invalid    bool
+	}{
+		{name: "json within limits", serializer: jsonSerializer, data: `{"apiVersion":"v1","kind":"Widget","spec":{"a":["[[[{{{"]}}`},
+		{name: "json too large", serializer: jsonSerializer, data: `{"apiVersion":"v1","kind":"Widget","spec":"` + strings.Repeat("x", 200) + `"}`, exceeded: true},
+		{name: "json too deep", serializer: jsonSerializer, data: `{"apiVersion":"v1","kind":"Widget","spec":{"a":[{}]}}`, exceeded: true},
+		{name: "yaml within limits", serializer: yamlSerializer, data: "# comment\n---\napiVersion: v1\nkind: Widget\nspec:\n  a: [b]\n---\n# trailing\n"},
+		{name: "yaml too deep", serializer: yamlSerializer, data: "apiVersion: v1\nkind: Widget\nspec:\n  a:\n  - b: c\n", exceeded: true},
+		{name: "yaml too many documents", serializer: yamlSerializer, data: "apiVersion: v1\nkind: Widget\n---\napiVersion: v1\nkind: Widget\n", exceeded: true},
+		{name: "yaml aliases within limits", serializer: yamlSerializer, data: "apiVersion: v1\nkind: Widget\na: &a [x, x]\nb: [*a, *a]\n"},
+		{name: "yaml too deep through aliases", serializer: yamlSerializer, data: "apiVersion: v1\nkind: Widget\na: &a [[x]]\nspec: {b: *a}\n", exceeded: true},
+		{name: "malformed yaml", serializer: yamlSerializer, data: "#\n-\n{\n", invalid: true},
+		{name: "malformed yaml flow sequence", serializer: yamlSerializer, data: "- 0: [:!00 \xef", invalid: true},
+		{name: "malformed yaml flow mapping", serializer: yamlSerializer, data: "0: [:!00 \xef", invalid: true},
+		{name: "yaml aliases expanding too much", serializer: yamlSerializer, data: "apiVersion: v1\nkind: Widget\na: &a xxxxxxxxxxxxxxxxxxxxxxxxxxxxxx\nb: [" + strings.Repeat("*a, ", 15) + "*a]\n", exceeded: true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, _, err := tc.serializer.Decode([]byte(tc.data), nil, &unstructured.Unstructured{})
+			if tc.invalid {
+				if err == nil || runtime.IsDecodingLimitError(err) {
+					t.Errorf("expected a parsing error, got %v", err)
+				}
+				return
+			}
+			if exceeded := runtime.IsDecodingLimitError(err); exceeded != tc.exceeded {
+				t.Errorf("expected a decoding limit error: %t, got %v", tc.exceeded, err)
+			}
+			if !tc.exceeded && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
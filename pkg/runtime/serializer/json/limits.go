@@ -0,0 +1,169 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package json
+
+import (
+	"bytes"
+	"fmt"
+
+	yamlv2 "gopkg.in/yaml.v2"
+
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// checkJSONDepth returns a decoding limit error if objects and arrays are nested deeper than
+// maxDepth in data. It doesn't validate data, which is left to the decoder.
+func checkJSONDepth(data []byte, maxDepth int) error {
+	if maxDepth <= 0 {
+		return nil
+	}
+	depth := 0
+	inString, escaped := false, false
+	for _, c := range data {
+		if inString {
+			switch {
+			case escaped:
+				escaped = false
+			case c == '\\':
+				escaped = true
+			case c == '"':
+				inString = false
+			}
+			continue
+		}
+		switch c {
+		case '"':
+			inString = true
+		case '{', '[':
+			depth++
+			if depth > maxDepth {
+				return runtime.NewDecodingLimitError(fmt.Sprintf("objects and arrays are nested deeper than %d levels", maxDepth))
+			}
+		case '}', ']':
+			depth--
+		}
+	}
+	return nil
+}
+
+// checkYAMLDocuments returns a decoding limit error if data holds more than maxDocuments YAML
+// documents. Documents holding nothing but whitespace and comments are not counted.
+func checkYAMLDocuments(data []byte, maxDocuments int) error {
+	if maxDocuments <= 0 {
+		return nil
+	}
+	documents, inDocument := 0, false
+	for _, line := range bytes.Split(data, []byte("\n")) {
+		if bytes.HasPrefix(line, []byte("---")) && (len(line) == 3 || line[3] == ' ' || line[3] == '\t' || line[3] == '\r') {
+			inDocument = false
+			continue
+		}
+		if trimmed := bytes.TrimSpace(line); inDocument || len(trimmed) == 0 || trimmed[0] == '#' {
+			continue
+		}
+		inDocument = true
+		documents++
+		if documents > maxDocuments {
+			return runtime.NewDecodingLimitError(fmt.Sprintf("data holds more than %d YAML documents", maxDocuments))
+		}
+	}
+	return nil
+}
+
+// checkYAMLLimits returns a decoding limit error if the objects and arrays of the first YAML document
+// in data are nested deeper than limits.MaxDepth, or if the document is longer than
+// limits.MaxExpandedBytes once its aliases are expanded, measured as the length of its JSON encoding.
+// The document is parsed as by the conversion to JSON, which rejects documents with excessive
+// aliasing, so that the limits are checked before the JSON is built. Data that isn't valid YAML is
+// left for the conversion to report.
+func checkYAMLLimits(data []byte, limits runtime.DecodingLimits) error {
+	if limits.MaxDepth <= 0 && limits.MaxExpandedBytes <= 0 {
+		return nil
+	}
+	var document interface{}
+	if err := yamlv2.Unmarshal(data, &document); err != nil {
+		return nil
+	}
+	m := yamlMeasurer{maxDepth: limits.MaxDepth, maxBytes: limits.MaxExpandedBytes}
+	m.measure(document, 0)
+	if m.tooDeep {
+		return runtime.NewDecodingLimitError(fmt.Sprintf("objects and arrays are nested deeper than %d levels", limits.MaxDepth))
+	}
+	if m.tooLong {
+		return runtime.NewDecodingLimitError(fmt.Sprintf("data is longer than %d bytes once YAML aliases are expanded", limits.MaxExpandedBytes))
+	}
+	return nil
+}
+
+// yamlMeasurer measures the depth of decoded YAML values and the length of their JSON encoding,
+// ignoring escaping, until a limit is exceeded.
+type yamlMeasurer struct {
+	maxDepth int
+	maxBytes int64
+
+	bytes            int64
+	tooDeep, tooLong bool
+}
+
+func (m *yamlMeasurer) exceeded() bool {
+	return m.tooDeep || m.tooLong
+}
+
+func (m *yamlMeasurer) add(n int) {
+	m.bytes += int64(n)
+	if m.maxBytes > 0 && m.bytes > m.maxBytes {
+		m.tooLong = true
+	}
+}
+
+func (m *yamlMeasurer) measure(value interface{}, depth int) {
+	switch v := value.(type) {
+	case map[interface{}]interface{}:
+		m.measureCollection(depth)
+		for key, item := range v {
+			if m.exceeded() {
+				return
+			}
+			// keys are quoted and followed by a colon and a comma
+			m.add(len(fmt.Sprint(key)) + 4)
+			m.measure(item, depth+1)
+		}
+	case []interface{}:
+		m.measureCollection(depth)
+		for _, item := range v {
+			if m.exceeded() {
+				return
+			}
+			// items are followed by a comma
+			m.add(1)
+			m.measure(item, depth+1)
+		}
+	case string:
+		m.add(len(v) + 2)
+	case nil:
+		m.add(len("null"))
+	default:
+		m.add(len(fmt.Sprint(v)))
+	}
+}
+
+func (m *yamlMeasurer) measureCollection(depth int) {
+	if m.maxDepth > 0 && depth+1 > m.maxDepth {
+		m.tooDeep = true
+	}
+	m.add(2)
+}
@@ -0,0 +1,126 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package json
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// ItemsProvider returns the items of a list one at a time. It returns a nil object once all items
+// have been returned.
+type ItemsProvider func() (runtime.Object, error)
+
+// SliceItems returns an ItemsProvider returning items in order.
+func SliceItems(items []runtime.Object) ItemsProvider {
+	return func() (runtime.Object, error) {
+		if len(items) == 0 {
+			return nil, nil
+		}
+		item := items[0]
+		items = items[1:]
+		return item, nil
+	}
+}
+
+// ChannelItems returns an ItemsProvider returning the items received from ch until it is closed.
+func ChannelItems(ch <-chan runtime.Object) ItemsProvider {
+	return func() (runtime.Object, error) {
+		return <-ch, nil
+	}
+}
+
+// EncodeListStream writes list to w as JSON with the items returned by items, encoding and writing
+// each item as it is returned rather than assembling the whole list in memory. The items of list
+// itself, if any, are ignored. The output is the same as that of Encode on list holding the items,
+// provided that the items field is serialized last as for all built-in list types. Writing stops at
+// the first error returned by items or w. The Pretty option is ignored and YAML is not supported.
+func (s *Serializer) EncodeListStream(list runtime.Object, items ItemsProvider, w io.Writer) error {
+	if s.options.Yaml {
+		return fmt.Errorf("streaming list encoding is not supported for YAML")
+	}
+	data, err := json.Marshal(list)
+	if err != nil {
+		return err
+	}
+	envelope, err := listEnvelope(data)
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(envelope); err != nil {
+		return err
+	}
+	for first := true; ; first = false {
+		item, err := items()
+		if err != nil {
+			return err
+		}
+		if item == nil {
+			break
+		}
+		data, err := json.Marshal(item)
+		if err != nil {
+			return err
+		}
+		if !first {
+			data = append([]byte{','}, data...)
+		}
+		if _, err := w.Write(data); err != nil {
+			return err
+		}
+	}
+	_, err = w.Write([]byte("]}\n"))
+	return err
+}
+
+// listEnvelope returns the JSON object in data without its items field and the closing brace,
+// followed by the start of an items array.
+func listEnvelope(data []byte) ([]byte, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	if token, err := decoder.Token(); err != nil || token != json.Delim('{') {
+		return nil, fmt.Errorf("lists must be encoded as JSON objects")
+	}
+	envelope := bytes.NewBufferString("{")
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+		var value json.RawMessage
+		if err := decoder.Decode(&value); err != nil {
+			return nil, err
+		}
+		key := token.(string)
+		if key == "items" {
+			continue
+		}
+		encodedKey, err := json.Marshal(key)
+		if err != nil {
+			return nil, err
+		}
+		envelope.Write(encodedKey)
+		envelope.WriteByte(':')
+		envelope.Write(value)
+		envelope.WriteByte(',')
+	}
+	envelope.WriteString(`"items":[`)
+	return envelope.Bytes(), nil
+}
@@ -0,0 +1,69 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package json
+
+import (
+	"fmt"
+	"strconv"
+
+	yamlv2 "gopkg.in/yaml.v2"
+)
+
+// yamlDuplicateFieldErrors returns an error for every duplicate field in the YAML document data,
+// formatted like the strict JSON decoding errors with the path of the field, e.g.
+// `duplicate field "spec.containers[0].name"`. Duplicates are otherwise dropped by the conversion
+// of YAML to JSON before the JSON strict checks can see them.
+func yamlDuplicateFieldErrors(data []byte) []error {
+	var document yamlv2.MapSlice
+	if err := yamlv2.Unmarshal(data, &document); err != nil {
+		// documents that aren't mappings can't have fields, and syntax errors are reported when
+		// converting to JSON
+		return nil
+	}
+	var errs []error
+	seen := map[string]bool{}
+	walkYAMLDuplicates(document, "", func(path string) {
+		if seen[path] {
+			return
+		}
+		seen[path] = true
+		errs = append(errs, fmt.Errorf("duplicate field %q", path))
+	})
+	return errs
+}
+
+func walkYAMLDuplicates(value interface{}, path string, duplicate func(path string)) {
+	switch typed := value.(type) {
+	case yamlv2.MapSlice:
+		keys := map[string]bool{}
+		for _, item := range typed {
+			key := fmt.Sprint(item.Key)
+			if len(path) > 0 {
+				key = path + "." + key
+			}
+			if keys[key] {
+				duplicate(key)
+			}
+			keys[key] = true
+			walkYAMLDuplicates(item.Value, key, duplicate)
+		}
+	case []interface{}:
+		for i, item := range typed {
+			walkYAMLDuplicates(item, path+"["+strconv.Itoa(i)+"]", duplicate)
+		}
+	}
+}
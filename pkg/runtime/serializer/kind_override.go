@@ -0,0 +1,182 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package serializer
+
+import (
+	"io"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/runtime/serializer/recognizer"
+)
+
+// KindOverride replaces the standard encoding and/or decoding of a single GroupVersionKind.
+// Overrides are applied by the wire format serializers, after conversion on encode and before
+// conversion on decode, and are passed the standard serializer for the media type so that they
+// can delegate to it, e.g. after redacting fields of an object.
+type KindOverride struct {
+	// Name identifies the override in the identifiers of encoders, so that objects encoded with
+	// different overrides of the same kind are cached separately. Overrides with different Encode
+	// functions must have different names.
+	Name string
+	// MediaTypes restricts the override to the listed media types (e.g. application/json).
+	// The override applies to all media types if MediaTypes is empty.
+	MediaTypes []string
+	// Encode, if set, is invoked instead of next to encode objects of the overridden kind.
+	// Encode must not mutate obj, which may be shared with other encoders.
+	Encode func(obj runtime.Object, w io.Writer, next runtime.Encoder) error
+	// Decode, if set, is invoked instead of next to decode data whose serialized kind (after
+	// applying defaults) is the overridden kind. The arguments and results are those of
+	// runtime.Decoder.
+	Decode func(data []byte, defaults *schema.GroupVersionKind, into runtime.Object, next runtime.Decoder) (runtime.Object, *schema.GroupVersionKind, error)
+}
+
+// WithKindOverride registers override for the provided kind. Registering a second override for
+// the same kind replaces the first one.
+func WithKindOverride(gvk schema.GroupVersionKind, override KindOverride) CodecFactoryOptionsMutator {
+	return func(options *CodecFactoryOptions) {
+		if options.KindOverrides == nil {
+			options.KindOverrides = map[schema.GroupVersionKind]KindOverride{}
+		}
+		options.KindOverrides[gvk] = override
+	}
+}
+
+// applyKindOverrides wraps the serializers of every serializerType with the overrides registered
+// for its content type.
+func applyKindOverrides(serializers []serializerType, overrides map[schema.GroupVersionKind]KindOverride) {
+	for i := range serializers {
+		s := &serializers[i]
+		applicable := map[schema.GroupVersionKind]KindOverride{}
+		for gvk, override := range overrides {
+			if appliesToMediaType(override, s.ContentType) {
+				applicable[gvk] = override
+			}
+		}
+		if len(applicable) == 0 {
+			continue
+		}
+		s.Serializer = newKindOverrideSerializer(s.Serializer, applicable)
+		s.PrettySerializer = newKindOverrideSerializer(s.PrettySerializer, applicable)
+		s.StrictSerializer = newKindOverrideSerializer(s.StrictSerializer, applicable)
+		s.StreamSerializer = newKindOverrideSerializer(s.StreamSerializer, applicable)
+	}
+}
+
+func appliesToMediaType(override KindOverride, mediaType string) bool {
+	if len(override.MediaTypes) == 0 {
+		return true
+	}
+	for _, t := range override.MediaTypes {
+		if t == mediaType {
+			return true
+		}
+	}
+	return false
+}
+
+// kindOverrideSerializer applies KindOverrides on top of a standard serializer.
+type kindOverrideSerializer struct {
+	serializerWrapper
+	overrides map[schema.GroupVersionKind]KindOverride
+	// decodeOverrides is true if any override replaces decoding, which requires the serialized
+	// kind to be determined before decoding.
+	decodeOverrides bool
+}
+
+var _ runtime.Serializer = &kindOverrideSerializer{}
+var _ runtime.EncoderWithAllocator = &kindOverrideSerializer{}
+var _ runtime.SizeEstimator = &kindOverrideSerializer{}
+var _ recognizer.RecognizingDecoder = &kindOverrideSerializer{}
+
+func newKindOverrideSerializer(serializer runtime.Serializer, overrides map[schema.GroupVersionKind]KindOverride) runtime.Serializer {
+	if serializer == nil {
+		return nil
+	}
+	// encoders overriding the same kinds with overrides of the same names are assumed to encode
+	// identically
+	kinds := make(map[string]string, len(overrides))
+	for gvk, override := range overrides {
+		if override.Encode != nil {
+			kinds[gvk.String()] = override.Name
+		}
+	}
+	s := &kindOverrideSerializer{
+		serializerWrapper: serializerWrapper{
+			encoder:    serializer,
+			decoder:    serializer,
+			identifier: wrapperIdentifier("kindOverride", serializer, map[string]interface{}{"kinds": kinds}),
+		},
+		overrides: overrides,
+	}
+	s.encode = s.doEncodeWithAllocator
+	for _, override := range overrides {
+		if override.Decode != nil {
+			s.decodeOverrides = true
+		}
+	}
+	return s
+}
+
+// doEncodeWithAllocator invokes the Encode function registered for the kind of obj, or the standard
+// serializer, passing memAlloc to the standard serializer, including when it is invoked by an Encode
+// function.
+func (s *kindOverrideSerializer) doEncodeWithAllocator(obj runtime.Object, w io.Writer, memAlloc runtime.MemoryAllocator) error {
+	next := runtime.WithAllocator(s.encoder, memAlloc)
+	if override, ok := s.overrides[obj.GetObjectKind().GroupVersionKind()]; ok && override.Encode != nil {
+		return override.Encode(obj, w, next)
+	}
+	return next.Encode(obj, w)
+}
+
+// EstimateSize implements runtime.SizeEstimator. The size of the encoding of overridden kinds is estimated
+// with runtime.EstimateSize from the output of their Encode function, the size of the encoding of other
+// kinds is estimated with the standard serializer.
+func (s *kindOverrideSerializer) EstimateSize(obj runtime.Object, cache *runtime.SizeEstimateCache) (int, error) {
+	if override, ok := s.overrides[obj.GetObjectKind().GroupVersionKind()]; ok && override.Encode != nil {
+		return runtime.EstimateSize(obj, kindOverrideEncoder{s}, cache)
+	}
+	return runtime.EstimateSize(obj, s.encoder, cache)
+}
+
+// kindOverrideEncoder encodes like a kindOverrideSerializer, without estimating sizes itself.
+type kindOverrideEncoder struct {
+	serializer *kindOverrideSerializer
+}
+
+func (e kindOverrideEncoder) Encode(obj runtime.Object, w io.Writer) error {
+	return e.serializer.doEncodeWithAllocator(obj, w, nil)
+}
+
+func (e kindOverrideEncoder) Identifier() runtime.Identifier {
+	return e.serializer.Identifier()
+}
+
+// Decode invokes the Decode function registered for the serialized kind of data, or the standard serializer.
+func (s *kindOverrideSerializer) Decode(data []byte, defaults *schema.GroupVersionKind, into runtime.Object) (runtime.Object, *schema.GroupVersionKind, error) {
+	if !s.decodeOverrides {
+		return s.decoder.Decode(data, defaults, into)
+	}
+	// decoding into runtime.Unknown only extracts the type information from the data
+	_, actual, err := s.decoder.Decode(data, defaults, &runtime.Unknown{})
+	if err == nil && actual != nil {
+		if override, ok := s.overrides[*actual]; ok && override.Decode != nil {
+			return override.Decode(data, defaults, into, s.decoder)
+		}
+	}
+	return s.decoder.Decode(data, defaults, into)
+}
@@ -0,0 +1,118 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package serializer
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	runtimetesting "k8s.io/apimachinery/pkg/runtime/testing"
+)
+
+func TestKindOverride(t *testing.T) {
+	gv := schema.GroupVersion{Group: "test.group", Version: "v1"}
+	simpleGVK := gv.WithKind("Simple")
+	otherGVK := gv.WithKind("Other")
+	scheme := runtime.NewScheme()
+	scheme.AddKnownTypeWithName(simpleGVK, &runtimetesting.ExternalSimple{})
+	scheme.AddKnownTypeWithName(otherGVK, &runtimetesting.InternalSimple{})
+
+	var decoded []string
+	redact := KindOverride{
+		Name:       "redact",
+		MediaTypes: []string{runtime.ContentTypeJSON},
+		Encode: func(obj runtime.Object, w io.Writer, next runtime.Encoder) error {
+			redacted := obj.DeepCopyObject().(*runtimetesting.ExternalSimple)
+			redacted.TestString = "redacted"
+			return next.Encode(redacted, w)
+		},
+		Decode: func(data []byte, defaults *schema.GroupVersionKind, into runtime.Object, next runtime.Decoder) (runtime.Object, *schema.GroupVersionKind, error) {
+			decoded = append(decoded, string(data))
+			return next.Decode(data, defaults, into)
+		},
+	}
+	factory := NewCodecFactory(scheme, WithKindOverride(simpleGVK, redact))
+
+	testCases := []struct {
+		name      string
+		mediaType string
+		obj       runtime.Object
+		gvk       schema.GroupVersionKind
+		redacted  bool
+	}{
+		{name: "overridden kind", mediaType: runtime.ContentTypeJSON, obj: &runtimetesting.ExternalSimple{TestString: "secret"}, gvk: simpleGVK, redacted: true},
+		{name: "other kind", mediaType: runtime.ContentTypeJSON, obj: &runtimetesting.InternalSimple{TestString: "secret"}, gvk: otherGVK},
+		{name: "other media type", mediaType: runtime.ContentTypeYAML, obj: &runtimetesting.ExternalSimple{TestString: "secret"}, gvk: simpleGVK},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			decoded = nil
+			info, ok := runtime.SerializerInfoForMediaType(factory.SupportedMediaTypes(), tc.mediaType)
+			if !ok {
+				t.Fatalf("no serializer for %s", tc.mediaType)
+			}
+			codec := factory.CodecForVersions(info.Serializer, factory.UniversalDeserializer(), gv, gv)
+			data, err := runtime.Encode(codec, tc.obj)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if redacted := !strings.Contains(string(data), "secret"); redacted != tc.redacted {
+				t.Errorf("expected redacted=%t, got %s", tc.redacted, data)
+			}
+			if !strings.Contains(fmt.Sprintf("%#v", tc.obj), "secret") {
+				t.Errorf("the encoded object was mutated")
+			}
+
+			out, err := runtime.Decode(codec, data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if out.GetObjectKind().GroupVersionKind() != tc.gvk {
+				t.Errorf("unexpected kind %v", out.GetObjectKind().GroupVersionKind())
+			}
+			if overridden := len(decoded) > 0; overridden != tc.redacted {
+				t.Errorf("expected decode override to be called: %t, got %v", tc.redacted, decoded)
+			}
+		})
+	}
+}
+
+func TestKindOverrideIdentifier(t *testing.T) {
+	scheme := runtime.NewScheme()
+	gvk := schema.GroupVersionKind{Group: "test.group", Version: "v1", Kind: "Simple"}
+	override := KindOverride{Name: "next", Encode: func(obj runtime.Object, w io.Writer, next runtime.Encoder) error { return next.Encode(obj, w) }}
+	other := KindOverride{Name: "other", Encode: func(obj runtime.Object, w io.Writer, next runtime.Encoder) error { return next.Encode(obj, w) }}
+
+	plain := NewCodecFactory(scheme)
+	overridden := NewCodecFactory(scheme, WithKindOverride(gvk, override))
+	otherOverridden := NewCodecFactory(scheme, WithKindOverride(gvk, other))
+	for i, info := range overridden.SupportedMediaTypes() {
+		if info.Serializer.Identifier() == plain.SupportedMediaTypes()[i].Serializer.Identifier() {
+			t.Errorf("%s: expected overridden serializer to have a different identifier", info.MediaType)
+		}
+		if info.Serializer.Identifier() == otherOverridden.SupportedMediaTypes()[i].Serializer.Identifier() {
+			t.Errorf("%s: expected serializers with different overrides to have different identifiers", info.MediaType)
+		}
+		if info.Serializer.Identifier() != NewCodecFactory(scheme, WithKindOverride(gvk, override)).SupportedMediaTypes()[i].Serializer.Identifier() {
+			t.Errorf("%s: expected serializers with the same overrides to have the same identifier", info.MediaType)
+		}
+	}
+}
@@ -0,0 +1,192 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package protobuf
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/gogo/protobuf/proto"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+var errInvalidMessage = errors.New("invalid protobuf message")
+
+// ListIterator reads the items of a list encoded by Serializer one at a time, without decoding the
+// whole list. Lists are expected to follow the conventions of the Kubernetes API, with their metadata
+// in field 1 and their items in field 2, like metav1.List, whose items are runtime.RawExtensions, and
+// the typed lists of API groups.
+//
+// The items returned by the iterator reference the encoded list, which must not be modified while
+// they are in use.
+type ListIterator struct {
+	typeMeta runtime.TypeMeta
+	listMeta []byte
+
+	// remaining holds the part of the list message that wasn't iterated over yet.
+	remaining []byte
+	item      []byte
+	err       error
+}
+
+// NewListIterator returns an iterator over the items of the list encoded in data by Serializer.
+// Iteration starts before the first item, call Next to advance to it.
+func NewListIterator(data []byte) (*ListIterator, error) {
+	if !bytes.HasPrefix(data, protoEncodingPrefix) {
+		return nil, fmt.Errorf("provided data does not appear to be a protobuf message, expected prefix %v", protoEncodingPrefix)
+	}
+	it := &ListIterator{}
+	// the envelope is a runtime.Unknown message
+	data = data[len(protoEncodingPrefix):]
+	for len(data) > 0 {
+		field, value, rest, err := nextField(data)
+		if err != nil {
+			return nil, err
+		}
+		data = rest
+		switch field {
+		case 1:
+			if err := it.typeMeta.Unmarshal(value); err != nil {
+				return nil, err
+			}
+		case 2:
+			it.remaining = value
+		case 3:
+			if len(value) > 0 {
+				return nil, fmt.Errorf("unsupported content encoding %q", value)
+			}
+		}
+	}
+	// read the list metadata, which normally precedes the items
+	if field, value, _, err := nextField(it.remaining); err == nil && field == 1 {
+		it.listMeta = value
+	}
+	return it, nil
+}
+
+// TypeMeta returns the apiVersion and kind of the list.
+func (it *ListIterator) TypeMeta() runtime.TypeMeta {
+	return it.typeMeta
+}
+
+// ListMeta decodes the metadata of the list.
+func (it *ListIterator) ListMeta() (metav1.ListMeta, error) {
+	var listMeta metav1.ListMeta
+	err := listMeta.Unmarshal(it.listMeta)
+	return listMeta, err
+}
+
+// ItemKind returns the group, version and kind of the items of typed lists, derived from the kind of
+// the list by trimming its "List" suffix. It returns an empty kind for metav1.List, whose items
+// identify their own kind.
+func (it *ListIterator) ItemKind() schema.GroupVersionKind {
+	var kind string
+	if it.typeMeta.Kind != "List" && strings.HasSuffix(it.typeMeta.Kind, "List") {
+		kind = strings.TrimSuffix(it.typeMeta.Kind, "List")
+	}
+	return schema.FromAPIVersionAndKind(it.typeMeta.APIVersion, kind)
+}
+
+// Next advances the iterator to the next item, and returns false when there are no more items or an
+// error happened, which Err returns.
+func (it *ListIterator) Next() bool {
+	it.item = nil
+	for it.err == nil && len(it.remaining) > 0 {
+		field, value, rest, err := nextField(it.remaining)
+		if err != nil {
+			it.err = err
+			return false
+		}
+		it.remaining = rest
+		if field == 2 {
+			it.item = value
+			return true
+		}
+	}
+	return false
+}
+
+// Item returns the encoded message of the current item, a runtime.RawExtension for metav1.List and
+// the item itself for typed lists.
+func (it *ListIterator) Item() []byte {
+	return it.item
+}
+
+// DecodeItem decodes the current item into into. If into is a runtime.Object, its group, version and
+// kind are set to ItemKind.
+func (it *ListIterator) DecodeItem(into proto.Message) error {
+	if it.item == nil {
+		return fmt.Errorf("the iterator is not positioned on an item")
+	}
+	if err := proto.Unmarshal(it.item, into); err != nil {
+		return err
+	}
+	if obj, ok := into.(runtime.Object); ok {
+		if gvk := it.ItemKind(); len(gvk.Kind) > 0 {
+			obj.GetObjectKind().SetGroupVersionKind(gvk)
+		}
+	}
+	return nil
+}
+
+// Err returns the error that stopped the iteration, if any.
+func (it *ListIterator) Err() error {
+	return it.err
+}
+
+// nextField reads the field at the start of the protobuf message data, and returns its number, its
+// content if it is length-delimited, and the rest of the message.
+func nextField(data []byte) (field uint64, value []byte, rest []byte, err error) {
+	tag, n := binary.Uvarint(data)
+	if n <= 0 {
+		return 0, nil, nil, errInvalidMessage
+	}
+	data = data[n:]
+	var length uint64
+	switch tag & 0x7 {
+	case 0: // varint
+		_, n := binary.Uvarint(data)
+		if n <= 0 {
+			return 0, nil, nil, errInvalidMessage
+		}
+		return tag >> 3, nil, data[n:], nil
+	case 1: // 64 bits
+		length = 8
+	case 2: // length-delimited
+		if length, n = binary.Uvarint(data); n <= 0 {
+			return 0, nil, nil, errInvalidMessage
+		}
+		data = data[n:]
+	case 5: // 32 bits
+		length = 4
+	default:
+		return 0, nil, nil, errInvalidMessage
+	}
+	if length > uint64(len(data)) {
+		return 0, nil, nil, errInvalidMessage
+	}
+	if tag&0x7 != 2 {
+		return tag >> 3, nil, data[length:], nil
+	}
+	return tag >> 3, data[:length], data[length:], nil
+}
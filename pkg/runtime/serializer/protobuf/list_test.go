@@ -0,0 +1,124 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package protobuf
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestListIterator(t *testing.T) {
+	gvk := metav1.SchemeGroupVersion.WithKind("PartialObjectMetadataList")
+	list := &metav1.PartialObjectMetadataList{TypeMeta: metav1.TypeMeta{APIVersion: gvk.GroupVersion().String(), Kind: gvk.Kind}, ListMeta: metav1.ListMeta{ResourceVersion: "10", Continue: "next"}}
+	for _, name := range []string{"a", "b", "c"} {
+		list.Items = append(list.Items, metav1.PartialObjectMetadata{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{"name": name}}})
+	}
+	data := &bytes.Buffer{}
+	if err := NewSerializer(nil, &mockTyper{gvk: &gvk}).Encode(list, data); err != nil {
+		t.Fatal(err)
+	}
+
+	it, err := NewListIterator(data.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e, a := (runtime.TypeMeta{APIVersion: "meta.k8s.io/v1", Kind: "PartialObjectMetadataList"}), it.TypeMeta(); e != a {
+		t.Errorf("expected %#v, got %#v", e, a)
+	}
+	if listMeta, err := it.ListMeta(); err != nil || !reflect.DeepEqual(list.ListMeta, listMeta) {
+		t.Errorf("unexpected list metadata %#v: %v", listMeta, err)
+	}
+	itemGVK := metav1.SchemeGroupVersion.WithKind("PartialObjectMetadata")
+	if e, a := itemGVK, it.ItemKind(); e != a {
+		t.Errorf("expected %v, got %v", e, a)
+	}
+	var items []metav1.PartialObjectMetadata
+	for it.Next() {
+		item := metav1.PartialObjectMetadata{}
+		if err := it.DecodeItem(&item); err != nil {
+			t.Fatal(err)
+		}
+		items = append(items, item)
+	}
+	if err := it.Err(); err != nil {
+		t.Fatal(err)
+	}
+	for i := range list.Items {
+		list.Items[i].SetGroupVersionKind(itemGVK)
+	}
+	if !reflect.DeepEqual(list.Items, items) {
+		t.Errorf("expected %#v, got %#v", list.Items, items)
+	}
+
+	if _, err := NewListIterator(data.Bytes()[:data.Len()-3]); err == nil {
+		t.Errorf("expected an error for truncated data")
+	}
+	// corrupt the length of the last item
+	corrupted := append([]byte{}, data.Bytes()...)
+	it, err = NewListIterator(corrupted)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < len(list.Items)-1; i++ {
+		if !it.Next() {
+			t.Fatalf("expected item %d, got %v", i, it.Err())
+		}
+	}
+	it.remaining[1] = 0x7f
+	if it.Next() || it.Err() == nil {
+		t.Errorf("expected an error for an invalid item")
+	}
+	if _, err := NewListIterator([]byte("{}")); err == nil {
+		t.Errorf("expected an error for data without the protobuf prefix")
+	}
+}
+
+func TestListIteratorRawExtensions(t *testing.T) {
+	gvk := schema.GroupVersionKind{Version: "v1", Kind: "List"}
+	list := &metav1.List{TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "List"}, Items: []runtime.RawExtension{{Raw: []byte("first")}, {Raw: []byte("second")}}}
+	data := &bytes.Buffer{}
+	if err := NewSerializer(nil, &mockTyper{gvk: &gvk}).Encode(list, data); err != nil {
+		t.Fatal(err)
+	}
+
+	it, err := NewListIterator(data.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if kind := it.ItemKind(); kind.Kind != "" {
+		t.Errorf("expected no item kind, got %v", kind)
+	}
+	var items []runtime.RawExtension
+	for it.Next() {
+		item := runtime.RawExtension{}
+		if err := it.DecodeItem(&item); err != nil {
+			t.Fatal(err)
+		}
+		items = append(items, item)
+	}
+	if err := it.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(list.Items, items) {
+		t.Errorf("expected %#v, got %#v", list.Items, items)
+	}
+}
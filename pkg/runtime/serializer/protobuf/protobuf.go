@@ -26,10 +26,12 @@ import (
 	"github.com/gogo/protobuf/proto"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/runtime/serializer/recognizer"
 	"k8s.io/apimachinery/pkg/util/framer"
+	"k8s.io/klog/v2"
 )
 
 var (
@@ -71,32 +73,61 @@ func IsNotMarshalable(err error) bool {
 // is passed, the encoded object will have group, version, and kind fields set. If typer is nil, the objects will be written
 // as-is (any type info passed with the object will be used).
 func NewSerializer(creater runtime.ObjectCreater, typer runtime.ObjectTyper) *Serializer {
+	return NewSerializerWithOptions(creater, typer, SerializerOptions{})
+}
+
+// NewSerializerWithOptions creates a Protobuf serializer like NewSerializer, configured with options.
+func NewSerializerWithOptions(creater runtime.ObjectCreater, typer runtime.ObjectTyper, options SerializerOptions) *Serializer {
 	return &Serializer{
 		prefix:  protoEncodingPrefix,
 		creater: creater,
 		typer:   typer,
+		options: options,
 	}
 }
 
+// SerializerOptions holds the options which are used to configure a protobuf serializer.
+type SerializerOptions struct {
+	// UnstructuredAsJSON: configures the Serializer to encode unstructured objects, which have no protobuf
+	// representation, as JSON wrapped in the runtime.Unknown envelope, with the content type of the envelope set
+	// to runtime.ContentTypeJSON to tell it apart from protobuf content. Without it, encoding unstructured objects
+	// fails with an error for which IsNotMarshalable returns true. Such content is decoded regardless of this option.
+	UnstructuredAsJSON bool
+
+	// Limits: configures the Serializer to reject data exceeding the limits before decoding it, with an error for
+	// which runtime.IsDecodingLimitError returns true. Only MaxBytes applies to protobuf.
+	Limits runtime.DecodingLimits
+}
+
 // Serializer handles encoding versioned objects into the proper wire form
 type Serializer struct {
 	prefix  []byte
 	creater runtime.ObjectCreater
 	typer   runtime.ObjectTyper
+	options SerializerOptions
 }
 
 var _ runtime.Serializer = &Serializer{}
+var _ runtime.EncoderWithAllocator = &Serializer{}
+var _ runtime.SizeEstimator = &Serializer{}
 var _ recognizer.RecognizingDecoder = &Serializer{}
 
-const serializerIdentifier runtime.Identifier = "protobuf"
+const (
+	serializerIdentifier                   runtime.Identifier = "protobuf"
+	unstructuredAsJSONSerializerIdentifier runtime.Identifier = "protobuf-unstructured-json"
+)
 
 // Decode attempts to convert the provided data into a protobuf message, extract the stored schema kind, apply the provided default
 // gvk, and then load that data into an object matching the desired schema kind or the provided into. If into is *runtime.Unknown,
 // the raw data will be extracted and no decoding will be performed. If into is not registered with the typer, then the object will
 // be straight decoded using normal protobuf unmarshalling (the MarshalTo interface). If into is provided and the original data is
 // not fully qualified with kind/version/group, the type of the into will be used to alter the returned gvk. On success or most
-// errors, the method will return the calculated schema kind.
+// errors, the method will return the calculated schema kind. Unstructured objects encoded as JSON can only be decoded into
+// unstructured objects, or with a nil into.
 func (s *Serializer) Decode(originalData []byte, gvk *schema.GroupVersionKind, into runtime.Object) (runtime.Object, *schema.GroupVersionKind, error) {
+	if err := s.options.Limits.CheckSize(len(originalData)); err != nil {
+		return nil, nil, err
+	}
 	prefixLen := len(s.prefix)
 	switch {
 	case len(originalData) == 0:
@@ -126,6 +157,13 @@ func (s *Serializer) Decode(originalData []byte, gvk *schema.GroupVersionKind, i
 		return intoUnknown, &actual, nil
 	}
 
+	if unk.ContentType == runtime.ContentTypeJSON {
+		if _, isUnstructured := into.(runtime.Unstructured); into != nil && !isUnstructured {
+			return nil, &actual, fmt.Errorf("unable to decode JSON content of %v into %T, only unstructured objects are supported", actual, into)
+		}
+		return unstructured.UnstructuredJSONScheme.Decode(unk.Raw, &actual, into)
+	}
+
 	if into != nil {
 		types, _, err := s.typer.ObjectKinds(into)
 		switch {
@@ -161,22 +199,36 @@ func (s *Serializer) Decode(originalData []byte, gvk *schema.GroupVersionKind, i
 	return unmarshalToObject(s.typer, s.creater, &actual, into, unk.Raw)
 }
 
+// EncodeWithAllocator writes an object to the provided writer.
+// In addition, it allows for providing a memory allocator for efficient memory usage during object serialization.
+func (s *Serializer) EncodeWithAllocator(obj runtime.Object, w io.Writer, memAlloc runtime.MemoryAllocator) error {
+	return s.encode(obj, w, memAlloc)
+}
+
 // Encode serializes the provided object to the given writer.
 func (s *Serializer) Encode(obj runtime.Object, w io.Writer) error {
+	return s.encode(obj, w, &runtime.SimpleAllocator{})
+}
+
+func (s *Serializer) encode(obj runtime.Object, w io.Writer, memAlloc runtime.MemoryAllocator) error {
 	if co, ok := obj.(runtime.CacheableObject); ok {
-		return co.CacheEncode(s.Identifier(), s.doEncode, w)
+		return co.CacheEncode(s.Identifier(), func(obj runtime.Object, w io.Writer) error { return s.doEncode(obj, w, memAlloc) }, w)
 	}
-	return s.doEncode(obj, w)
+	return s.doEncode(obj, w, memAlloc)
 }
 
-func (s *Serializer) doEncode(obj runtime.Object, w io.Writer) error {
+func (s *Serializer) doEncode(obj runtime.Object, w io.Writer, memAlloc runtime.MemoryAllocator) error {
+	if memAlloc == nil {
+		klog.Error("a mandatory memory allocator wasn't provided, this might have a negative impact on performance, check invocations of EncodeWithAllocator method, falling back on runtime.SimpleAllocator")
+		memAlloc = &runtime.SimpleAllocator{}
+	}
 	prefixSize := uint64(len(s.prefix))
 
 	var unk runtime.Unknown
 	switch t := obj.(type) {
 	case *runtime.Unknown:
 		estimatedSize := prefixSize + uint64(t.Size())
-		data := make([]byte, estimatedSize)
+		data := memAlloc.Allocate(estimatedSize)
 		i, err := t.MarshalTo(data[prefixSize:])
 		if err != nil {
 			return err
@@ -200,7 +252,7 @@ func (s *Serializer) doEncode(obj runtime.Object, w io.Writer) error {
 		// the more efficient Size and MarshalToSizedBuffer methods
 		encodedSize := uint64(t.Size())
 		estimatedSize := prefixSize + estimateUnknownSize(&unk, encodedSize)
-		data := make([]byte, estimatedSize)
+		data := memAlloc.Allocate(estimatedSize)
 
 		i, err := unk.NestedMarshalTo(data[prefixSize:], t, encodedSize)
 		if err != nil {
@@ -219,19 +271,19 @@ func (s *Serializer) doEncode(obj runtime.Object, w io.Writer) error {
 			return err
 		}
 		unk.Raw = data
+		return s.writeUnknown(&unk, w, memAlloc)
 
-		estimatedSize := prefixSize + uint64(unk.Size())
-		data = make([]byte, estimatedSize)
-
-		i, err := unk.MarshalTo(data[prefixSize:])
+	case runtime.Unstructured:
+		if !s.options.UnstructuredAsJSON {
+			return errNotMarshalable{reflect.TypeOf(obj)}
+		}
+		data, err := runtime.Encode(unstructured.UnstructuredJSONScheme, t)
 		if err != nil {
 			return err
 		}
-
-		copy(data, s.prefix)
-
-		_, err = w.Write(data[:prefixSize+uint64(i)])
-		return err
+		unk.Raw = data
+		unk.ContentType = runtime.ContentTypeJSON
+		return s.writeUnknown(&unk, w, memAlloc)
 
 	default:
 		// TODO: marshal with a different content type and serializer (JSON for third party objects)
@@ -239,8 +291,55 @@ func (s *Serializer) doEncode(obj runtime.Object, w io.Writer) error {
 	}
 }
 
+// writeUnknown writes unk, preceded by the prefix, to w in a single call.
+func (s *Serializer) writeUnknown(unk *runtime.Unknown, w io.Writer, memAlloc runtime.MemoryAllocator) error {
+	prefixSize := uint64(len(s.prefix))
+	data := memAlloc.Allocate(prefixSize + uint64(unk.Size()))
+
+	i, err := unk.MarshalTo(data[prefixSize:])
+	if err != nil {
+		return err
+	}
+
+	copy(data, s.prefix)
+
+	_, err = w.Write(data[:prefixSize+uint64(i)])
+	return err
+}
+
+// EstimateSize implements runtime.SizeEstimator. The size of objects implementing the Size method is
+// computed without encoding them, other objects are encoded to measure their size.
+func (s *Serializer) EstimateSize(obj runtime.Object, _ *runtime.SizeEstimateCache) (int, error) {
+	if co, ok := obj.(runtime.CacheableObject); ok {
+		obj = co.GetObject()
+	}
+	switch t := obj.(type) {
+	case *runtime.Unknown:
+		return len(s.prefix) + t.Size(), nil
+	case bufferedMarshaller:
+		kind := obj.GetObjectKind().GroupVersionKind()
+		unk := runtime.Unknown{
+			TypeMeta: runtime.TypeMeta{
+				Kind:       kind.Kind,
+				APIVersion: kind.GroupVersion().String(),
+			},
+		}
+		// the object is encoded in the Raw field, behind a 1 byte tag and the varint of its size
+		size := t.Size()
+		return len(s.prefix) + unk.Size() + 1 + varintSize(uint64(size)) + size, nil
+	}
+	w := &countingWriter{}
+	if err := s.doEncode(obj, w, &runtime.SimpleAllocator{}); err != nil {
+		return 0, err
+	}
+	return w.n, nil
+}
+
 // Identifier implements runtime.Encoder interface.
 func (s *Serializer) Identifier() runtime.Identifier {
+	if s.options.UnstructuredAsJSON {
+		return unstructuredAsJSONSerializerIdentifier
+	}
 	return serializerIdentifier
 }
 
@@ -288,6 +387,26 @@ func estimateUnknownSize(unk *runtime.Unknown, byteSize uint64) uint64 {
 	return size
 }
 
+// varintSize returns the number of bytes of the protobuf varint encoding of x.
+func varintSize(x uint64) int {
+	size := 1
+	for x >= 0x80 {
+		x >>= 7
+		size++
+	}
+	return size
+}
+
+// countingWriter counts the bytes written to it and discards them.
+type countingWriter struct {
+	n int
+}
+
+func (w *countingWriter) Write(data []byte) (int, error) {
+	w.n += len(data)
+	return len(data), nil
+}
+
 // NewRawSerializer creates a Protobuf serializer that handles encoding versioned objects into the proper wire form. If typer
 // is not nil, the object has the group, version, and kind fields set. This serializer does not provide type information for the
 // encoded object, and thus is not self describing (callers must know what type is being described in order to decode).
@@ -308,6 +427,8 @@ type RawSerializer struct {
 }
 
 var _ runtime.Serializer = &RawSerializer{}
+var _ runtime.EncoderWithAllocator = &RawSerializer{}
+var _ runtime.SizeEstimator = &RawSerializer{}
 
 const rawSerializerIdentifier runtime.Identifier = "raw-protobuf"
 
@@ -395,19 +516,33 @@ func unmarshalToObject(typer runtime.ObjectTyper, creater runtime.ObjectCreater,
 
 // Encode serializes the provided object to the given writer. Overrides is ignored.
 func (s *RawSerializer) Encode(obj runtime.Object, w io.Writer) error {
+	return s.encode(obj, w, &runtime.SimpleAllocator{})
+}
+
+// EncodeWithAllocator writes an object to the provided writer.
+// In addition, it allows for providing a memory allocator for efficient memory usage during object serialization.
+func (s *RawSerializer) EncodeWithAllocator(obj runtime.Object, w io.Writer, memAlloc runtime.MemoryAllocator) error {
+	return s.encode(obj, w, memAlloc)
+}
+
+func (s *RawSerializer) encode(obj runtime.Object, w io.Writer, memAlloc runtime.MemoryAllocator) error {
 	if co, ok := obj.(runtime.CacheableObject); ok {
-		return co.CacheEncode(s.Identifier(), s.doEncode, w)
+		return co.CacheEncode(s.Identifier(), func(obj runtime.Object, w io.Writer) error { return s.doEncode(obj, w, memAlloc) }, w)
 	}
-	return s.doEncode(obj, w)
+	return s.doEncode(obj, w, memAlloc)
 }
 
-func (s *RawSerializer) doEncode(obj runtime.Object, w io.Writer) error {
+func (s *RawSerializer) doEncode(obj runtime.Object, w io.Writer, memAlloc runtime.MemoryAllocator) error {
+	if memAlloc == nil {
+		klog.Error("a mandatory memory allocator wasn't provided, this might have a negative impact on performance, check invocations of EncodeWithAllocator method, falling back on runtime.SimpleAllocator")
+		memAlloc = &runtime.SimpleAllocator{}
+	}
 	switch t := obj.(type) {
 	case bufferedReverseMarshaller:
 		// this path performs a single allocation during write but requires the caller to implement
 		// the more efficient Size and MarshalToSizedBuffer methods
 		encodedSize := uint64(t.Size())
-		data := make([]byte, encodedSize)
+		data := memAlloc.Allocate(encodedSize)
 
 		n, err := t.MarshalToSizedBuffer(data)
 		if err != nil {
@@ -420,7 +555,7 @@ func (s *RawSerializer) doEncode(obj runtime.Object, w io.Writer) error {
 		// this path performs a single allocation during write but requires the caller to implement
 		// the more efficient Size and MarshalTo methods
 		encodedSize := uint64(t.Size())
-		data := make([]byte, encodedSize)
+		data := memAlloc.Allocate(encodedSize)
 
 		n, err := t.MarshalTo(data)
 		if err != nil {
@@ -443,11 +578,62 @@ func (s *RawSerializer) doEncode(obj runtime.Object, w io.Writer) error {
 	}
 }
 
+// EstimateSize implements runtime.SizeEstimator. The size of objects implementing the Size method is
+// computed without encoding them, other objects are encoded to measure their size.
+func (s *RawSerializer) EstimateSize(obj runtime.Object, _ *runtime.SizeEstimateCache) (int, error) {
+	if co, ok := obj.(runtime.CacheableObject); ok {
+		obj = co.GetObject()
+	}
+	if sizer, ok := obj.(proto.Sizer); ok {
+		if _, ok := obj.(proto.Marshaler); ok {
+			return sizer.Size(), nil
+		}
+	}
+	w := &countingWriter{}
+	if err := s.doEncode(obj, w, &runtime.SimpleAllocator{}); err != nil {
+		return 0, err
+	}
+	return w.n, nil
+}
+
 // Identifier implements runtime.Encoder interface.
 func (s *RawSerializer) Identifier() runtime.Identifier {
 	return rawSerializerIdentifier
 }
 
+// ProtoMessage returns the protobuf message RawSerializer encodes for obj, so that it can be embedded as a field
+// of other protobuf messages, such as gRPC requests and responses, instead of being wrapped in a runtime.Unknown
+// and then in a bytes field. Cacheable objects are unwrapped. Like the output of Encode, the message does not
+// identify the type of obj.
+func (s *RawSerializer) ProtoMessage(obj runtime.Object) (proto.Message, error) {
+	if co, ok := obj.(runtime.CacheableObject); ok {
+		obj = co.GetObject()
+	}
+	pb, ok := obj.(proto.Message)
+	if !ok {
+		return nil, errNotMarshalable{reflect.TypeOf(obj)}
+	}
+	return pb, nil
+}
+
+// ObjectFromProtoMessage returns the object held by a protobuf message embedded in another protobuf message, such
+// as the messages returned by ProtoMessage once decoded. If the serializer has a typer, the group, version and kind
+// of the object are set from it, since embedded messages carry no type information.
+func (s *RawSerializer) ObjectFromProtoMessage(msg proto.Message) (runtime.Object, error) {
+	obj, ok := msg.(runtime.Object)
+	if !ok {
+		return nil, fmt.Errorf("the protobuf message %T is not a runtime.Object", msg)
+	}
+	if s.typer != nil {
+		types, _, err := s.typer.ObjectKinds(obj)
+		if err != nil {
+			return nil, err
+		}
+		obj.GetObjectKind().SetGroupVersionKind(types[0])
+	}
+	return obj, nil
+}
+
 // LengthDelimitedFramer is exported variable of type lengthDelimitedFramer
 var LengthDelimitedFramer = lengthDelimitedFramer{}
 
@@ -17,8 +17,15 @@ limitations under the License.
 package protobuf
 
 import (
+	"bytes"
+	"io"
+	"reflect"
 	"testing"
 
+	"github.com/gogo/protobuf/proto"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	runtimetesting "k8s.io/apimachinery/pkg/runtime/testing"
@@ -36,9 +43,184 @@ func TestCacheableObject(t *testing.T) {
 
 	for _, encoder := range encoders {
 		runtimetesting.CacheableObjectTest(t, encoder)
+		runtimetesting.CacheableObjectTest(t, encoderWithAllocator{encoder.(runtime.EncoderWithAllocator), &runtime.Allocator{}})
+	}
+}
+
+func TestEncodeWithAllocator(t *testing.T) {
+	obj := &metav1.Status{
+		TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "Status"},
+		Status:   metav1.StatusFailure,
+		Message:  "test",
+		Details:  &metav1.StatusDetails{Name: "foo", Causes: []metav1.StatusCause{{Message: "bar"}}},
+	}
+	encoders := []runtime.EncoderWithAllocator{
+		NewSerializer(nil, nil),
+		NewRawSerializer(nil, nil),
+	}
+	for _, encoder := range encoders {
+		expected := &bytes.Buffer{}
+		if err := encoder.Encode(obj, expected); err != nil {
+			t.Fatal(err)
+		}
+		allocator := &runtime.Allocator{}
+		for _, memAlloc := range []runtime.MemoryAllocator{&runtime.SimpleAllocator{}, allocator, allocator} {
+			actual := &bytes.Buffer{}
+			if err := encoder.EncodeWithAllocator(obj, actual, memAlloc); err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(expected.Bytes(), actual.Bytes()) {
+				t.Errorf("%T with %T: unexpected output %v, expected %v", encoder, memAlloc, actual.Bytes(), expected.Bytes())
+			}
+		}
 	}
 }
 
+func TestEstimateSize(t *testing.T) {
+	gvk := schema.GroupVersionKind{Version: "v1", Kind: "Status"}
+	objs := []runtime.Object{
+		&metav1.Status{TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "Status"}, Message: "test"},
+		&metav1.Status{TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "Status"}, Message: string(bytes.Repeat([]byte("x"), 300))},
+		&runtime.Unknown{TypeMeta: runtime.TypeMeta{APIVersion: "v1", Kind: "Widget"}, Raw: []byte("data")},
+		&runtimetesting.ExternalSimple{TestString: "not marshalable"},
+	}
+	serializers := []runtime.Serializer{
+		NewSerializer(nil, &mockTyper{gvk: &gvk}),
+		NewRawSerializer(nil, &mockTyper{gvk: &gvk}),
+	}
+	for _, s := range serializers {
+		for _, obj := range objs {
+			data := &bytes.Buffer{}
+			encodeErr := s.Encode(obj, data)
+			size, err := runtime.EstimateSize(obj, s, nil)
+			if encodeErr != nil {
+				if err == nil {
+					t.Errorf("%T: expected the encoding error of %T, got %d", s, obj, size)
+				}
+				continue
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if data.Len() != size {
+				t.Errorf("%T: expected the size of %T to be %d, got %d", s, obj, data.Len(), size)
+			}
+		}
+	}
+}
+
+func TestRawSerializerProtoMessage(t *testing.T) {
+	gvk := schema.GroupVersionKind{Version: "v1", Kind: "Status"}
+	s := NewRawSerializer(nil, &mockTyper{gvk: &gvk})
+	obj := &metav1.Status{Status: metav1.StatusFailure, Message: "test", Code: 500}
+
+	msg, err := s.ProtoMessage(obj)
+	if err != nil {
+		t.Fatal(err)
+	}
+	embedded, err := proto.Marshal(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	encoded := &bytes.Buffer{}
+	if err := s.Encode(obj, encoded); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(embedded, encoded.Bytes()) {
+		t.Errorf("expected the message to be encoded as %v, got %v", encoded.Bytes(), embedded)
+	}
+
+	decoded := &metav1.Status{}
+	if err := proto.Unmarshal(embedded, decoded); err != nil {
+		t.Fatal(err)
+	}
+	restored, err := s.ObjectFromProtoMessage(decoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := obj.DeepCopy()
+	expected.SetGroupVersionKind(gvk)
+	if !reflect.DeepEqual(expected, restored) {
+		t.Errorf("expected %#v, got %#v", expected, restored)
+	}
+
+	if _, err := s.ProtoMessage(&unstructured.Unstructured{}); !IsNotMarshalable(err) {
+		t.Errorf("expected a not marshalable error, got %v", err)
+	}
+	if _, err := s.ObjectFromProtoMessage(&runtime.TypeMeta{}); err == nil {
+		t.Errorf("expected an error for a message that is not an object")
+	}
+}
+
+func TestUnstructuredAsJSON(t *testing.T) {
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "example.com/v1",
+		"kind":       "Widget",
+		"metadata":   map[string]interface{}{"name": "foo"},
+		"spec":       map[string]interface{}{"replicas": int64(3)},
+	}}
+	list := &unstructured.UnstructuredList{
+		Object: map[string]interface{}{"apiVersion": "example.com/v1", "kind": "WidgetList"},
+		Items:  []unstructured.Unstructured{*obj},
+	}
+
+	if err := NewSerializer(nil, nil).Encode(obj, &bytes.Buffer{}); !IsNotMarshalable(err) {
+		t.Errorf("expected a not marshalable error without the option, got %v", err)
+	}
+
+	s := NewSerializerWithOptions(nil, &mockTyper{}, SerializerOptions{UnstructuredAsJSON: true})
+	if s.Identifier() == NewSerializer(nil, nil).Identifier() {
+		t.Errorf("expected a distinct identifier, got %q", s.Identifier())
+	}
+	for _, original := range []runtime.Object{obj, list} {
+		data := &bytes.Buffer{}
+		if err := s.EncodeWithAllocator(original, data, &runtime.Allocator{}); err != nil {
+			t.Fatal(err)
+		}
+		if ok, _, _ := s.RecognizesData(data.Bytes()); !ok {
+			t.Fatalf("expected the protobuf prefix, got %q", data.Bytes())
+		}
+
+		unk := &runtime.Unknown{}
+		if _, _, err := s.Decode(data.Bytes(), nil, unk); err != nil {
+			t.Fatal(err)
+		}
+		if unk.ContentType != runtime.ContentTypeJSON || unk.Kind != original.GetObjectKind().GroupVersionKind().Kind {
+			t.Errorf("unexpected envelope %#v", unk)
+		}
+
+		for _, into := range []runtime.Object{nil, reflect.New(reflect.TypeOf(original).Elem()).Interface().(runtime.Object)} {
+			decoded, gvk, err := NewSerializer(nil, &mockTyper{}).Decode(data.Bytes(), nil, into)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if e, a := original.GetObjectKind().GroupVersionKind(), *gvk; e != a {
+				t.Errorf("expected %v, got %v", e, a)
+			}
+			if !reflect.DeepEqual(original, decoded) {
+				t.Errorf("expected %#v, got %#v", original, decoded)
+			}
+		}
+	}
+
+	data := &bytes.Buffer{}
+	if err := s.Encode(obj, data); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := s.Decode(data.Bytes(), nil, &metav1.Status{}); err == nil {
+		t.Errorf("expected an error decoding JSON content into a typed object")
+	}
+}
+
+type encoderWithAllocator struct {
+	runtime.EncoderWithAllocator
+	memAlloc runtime.MemoryAllocator
+}
+
+func (e encoderWithAllocator) Encode(obj runtime.Object, w io.Writer) error {
+	return e.EncodeWithAllocator(obj, w, e.memAlloc)
+}
+
 type mockCreater struct {
 	apiVersion string
 	kind       string
@@ -66,3 +248,20 @@ func (t *mockTyper) ObjectKinds(obj runtime.Object) ([]schema.GroupVersionKind,
 func (t *mockTyper) Recognizes(_ schema.GroupVersionKind) bool {
 	return false
 }
+
+func TestDecodingLimits(t *testing.T) {
+	obj := &runtime.Unknown{TypeMeta: runtime.TypeMeta{APIVersion: "v1", Kind: "Widget"}, Raw: bytes.Repeat([]byte("x"), 100)}
+	data := &bytes.Buffer{}
+	if err := NewSerializer(nil, &mockTyper{}).Encode(obj, data); err != nil {
+		t.Fatal(err)
+	}
+
+	s := NewSerializerWithOptions(nil, &mockTyper{}, SerializerOptions{Limits: runtime.DecodingLimits{MaxBytes: int64(data.Len() - 1)}})
+	if _, _, err := s.Decode(data.Bytes(), nil, &runtime.Unknown{}); !runtime.IsDecodingLimitError(err) {
+		t.Errorf("expected a decoding limit error, got %v", err)
+	}
+	s = NewSerializerWithOptions(nil, &mockTyper{}, SerializerOptions{Limits: runtime.DecodingLimits{MaxBytes: int64(data.Len())}})
+	if _, _, err := s.Decode(data.Bytes(), nil, &runtime.Unknown{}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
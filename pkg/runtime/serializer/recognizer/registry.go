@@ -0,0 +1,107 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package recognizer
+
+import (
+	"bytes"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// RecognizeFunc reports whether the provided data belongs to a serialization format. It has the
+// semantics of RecognizingDecoder.RecognizesData.
+type RecognizeFunc func(peek []byte) (ok, unknown bool, err error)
+
+// PrefixRecognizer returns a RecognizeFunc that recognizes data starting with any of the
+// provided magic byte sequences.
+func PrefixRecognizer(prefixes ...[]byte) RecognizeFunc {
+	return func(peek []byte) (bool, bool, error) {
+		for _, prefix := range prefixes {
+			if bytes.HasPrefix(peek, prefix) {
+				return true, false, nil
+			}
+		}
+		return false, false, nil
+	}
+}
+
+// Registry holds recognizers for serialization formats, indexed by media type. It allows
+// serializers that do not implement RecognizingDecoder, or whose built-in detection is not
+// sufficient, to contribute content sniffing to decoders that try multiple formats.
+//
+// A Registry is safe for concurrent use.
+type Registry struct {
+	lock        sync.RWMutex
+	recognizers map[string]RecognizeFunc
+}
+
+// NewRegistry creates an empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{recognizers: map[string]RecognizeFunc{}}
+}
+
+// DefaultRegistry is the Registry consulted by serializer.NewCodecFactory. Serializers that are
+// compiled in as plugins should register their recognizers from an init function, since the
+// registry is only read when a codec factory is constructed.
+var DefaultRegistry = NewRegistry()
+
+// Register sets the recognizer for mediaType, replacing any previously registered one.
+func (r *Registry) Register(mediaType string, fn RecognizeFunc) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.recognizers[mediaType] = fn
+}
+
+// Unregister removes the recognizer for mediaType, if any.
+func (r *Registry) Unregister(mediaType string) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	delete(r.recognizers, mediaType)
+}
+
+// Recognizer returns the recognizer registered for mediaType.
+func (r *Registry) Recognizer(mediaType string) (RecognizeFunc, bool) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	fn, ok := r.recognizers[mediaType]
+	return fn, ok
+}
+
+// Decoder returns d, made recognizing with the recognizer registered for mediaType if there is
+// one. A registered recognizer takes precedence over the decoder's own RecognizesData method.
+func (r *Registry) Decoder(mediaType string, d runtime.Decoder) runtime.Decoder {
+	fn, ok := r.Recognizer(mediaType)
+	if !ok {
+		return d
+	}
+	return WithRecognizer(d, fn)
+}
+
+// WithRecognizer returns a RecognizingDecoder that decodes with d and identifies data with fn.
+func WithRecognizer(d runtime.Decoder, fn RecognizeFunc) RecognizingDecoder {
+	return recognizingDecoder{Decoder: d, recognize: fn}
+}
+
+type recognizingDecoder struct {
+	runtime.Decoder
+	recognize RecognizeFunc
+}
+
+func (d recognizingDecoder) RecognizesData(peek []byte) (bool, bool, error) {
+	return d.recognize(peek)
+}
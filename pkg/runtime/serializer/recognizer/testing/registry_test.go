@@ -0,0 +1,73 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package testing
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/runtime/serializer/recognizer"
+)
+
+// namedDecoder accepts any data and returns an object of type A, recording its name.
+type namedDecoder struct {
+	name    string
+	decoded *[]string
+}
+
+func (d namedDecoder) Decode(data []byte, gvk *schema.GroupVersionKind, into runtime.Object) (runtime.Object, *schema.GroupVersionKind, error) {
+	*d.decoded = append(*d.decoded, d.name)
+	return A{}, &schema.GroupVersionKind{Version: "v1", Kind: "A"}, nil
+}
+
+func TestRegistry(t *testing.T) {
+	var decoded []string
+	greedy := namedDecoder{name: "greedy", decoded: &decoded}
+	magic := namedDecoder{name: "magic", decoded: &decoded}
+
+	registry := recognizer.NewRegistry()
+	registry.Register("application/x-magic", recognizer.PrefixRecognizer([]byte{0xca, 0xfe}))
+	if _, ok := registry.Recognizer("application/x-magic"); !ok {
+		t.Fatalf("expected recognizer to be registered")
+	}
+
+	d := recognizer.NewDecoder(
+		registry.Decoder("application/x-greedy", greedy),
+		registry.Decoder("application/x-magic", magic),
+	)
+	for _, tc := range []struct {
+		data     []byte
+		expected string
+	}{
+		{data: []byte{0xca, 0xfe, 0x00}, expected: "magic"},
+		{data: []byte("anything else"), expected: "greedy"},
+	} {
+		decoded = nil
+		if _, _, err := d.Decode(tc.data, nil, nil); err != nil {
+			t.Fatal(err)
+		}
+		if len(decoded) != 1 || decoded[0] != tc.expected {
+			t.Errorf("%q: expected to be decoded by %s, got %v", tc.data, tc.expected, decoded)
+		}
+	}
+
+	registry.Unregister("application/x-magic")
+	if _, ok := registry.Decoder("application/x-magic", magic).(recognizer.RecognizingDecoder); ok {
+		t.Errorf("expected unregistered media type to return the decoder unchanged")
+	}
+}
@@ -0,0 +1,43 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package streaming
+
+import (
+	"io"
+
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// MessageFramer is a runtime.Framer for transports that delimit messages themselves, like websockets,
+// which carry one object per message without delimiters or length prefixes. Its writers pass every
+// Write to the underlying writer as a message, so every object must be written with a single Write, as
+// done by the encoders returned by NewEncoder. Its readers pass reads through, so the underlying reader
+// must return one message per Read, returning io.ErrShortBuffer until the rest of a message that
+// doesn't fit in the buffer is read, as expected by NewDecoder.
+var MessageFramer runtime.Framer = messageFramer{}
+
+type messageFramer struct{}
+
+// NewFrameReader implements runtime.Framer.
+func (messageFramer) NewFrameReader(r io.ReadCloser) io.ReadCloser {
+	return r
+}
+
+// NewFrameWriter implements runtime.Framer.
+func (messageFramer) NewFrameWriter(w io.Writer) io.Writer {
+	return w
+}
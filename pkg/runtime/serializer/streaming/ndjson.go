@@ -0,0 +1,182 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package streaming
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+const (
+	// defaultMaxFrameSize matches the maximum object size of the decoders returned by NewDecoder.
+	defaultMaxFrameSize = 16 * 1024 * 1024
+	// maxPooledFrameSize is the capacity above which frame buffers are not returned to the pool.
+	maxPooledFrameSize = 1024 * 1024
+)
+
+var (
+	readerPool = sync.Pool{New: func() interface{} { return bufio.NewReaderSize(nil, 4096) }}
+	bufferPool = sync.Pool{New: func() interface{} { return new([]byte) }}
+)
+
+var errNewlineInFrame = errors.New("newline-delimited JSON frames can't contain newlines")
+
+// NewNDJSONFramer returns a runtime.Framer reading and writing newline-delimited JSON, one
+// compact JSON document per line, as used by watch streams served over plain HTTP chunked
+// encoding. Frames longer than maxFrameSize, or 16MiB if maxFrameSize is not positive, are
+// skipped by the readers, which return ErrObjectTooLarge for them. Readers return frames
+// that don't fit in the buffer passed to Read over several calls, returning io.ErrShortBuffer
+// until the frame is complete, as expected by NewDecoder.
+func NewNDJSONFramer(maxFrameSize int) runtime.Framer {
+	if maxFrameSize <= 0 {
+		maxFrameSize = defaultMaxFrameSize
+	}
+	return ndjsonFramer{maxFrameSize: maxFrameSize}
+}
+
+type ndjsonFramer struct {
+	maxFrameSize int
+}
+
+// NewFrameWriter implements runtime.Framer. The writer adds a newline to the frames that don't
+// end with one, and rejects frames containing other newlines.
+func (f ndjsonFramer) NewFrameWriter(w io.Writer) io.Writer {
+	return &ndjsonFrameWriter{w: w}
+}
+
+// NewFrameReader implements runtime.Framer. Empty lines are skipped, and the last frame of the
+// stream doesn't need to be terminated by a newline.
+func (f ndjsonFramer) NewFrameReader(r io.ReadCloser) io.ReadCloser {
+	br := readerPool.Get().(*bufio.Reader)
+	br.Reset(r)
+	return &ndjsonFrameReader{
+		r:            r,
+		br:           br,
+		frame:        bufferPool.Get().(*[]byte),
+		maxFrameSize: f.maxFrameSize,
+	}
+}
+
+type ndjsonFrameWriter struct {
+	w   io.Writer
+	buf []byte
+}
+
+// Write writes data as a single line to the nested writer.
+func (w *ndjsonFrameWriter) Write(data []byte) (int, error) {
+	line := bytes.TrimSuffix(data, []byte("\n"))
+	if bytes.IndexByte(line, '\n') >= 0 {
+		return 0, errNewlineInFrame
+	}
+	if len(line) == len(data) {
+		// write the frame and its newline at once, to keep them in the same chunk
+		w.buf = append(append(w.buf[:0], data...), '\n')
+		if _, err := w.w.Write(w.buf); err != nil {
+			return 0, err
+		}
+		return len(data), nil
+	}
+	return w.w.Write(data)
+}
+
+type ndjsonFrameReader struct {
+	r            io.ReadCloser
+	br           *bufio.Reader
+	maxFrameSize int
+
+	// frame holds the last frame read, remaining the part of it not returned yet.
+	frame     *[]byte
+	remaining []byte
+}
+
+// Read reads the next frame into data, without its newline.
+func (r *ndjsonFrameReader) Read(data []byte) (int, error) {
+	if len(r.remaining) == 0 {
+		if r.br == nil {
+			return 0, io.EOF
+		}
+		frame, err := r.readFrame()
+		if err != nil {
+			return 0, err
+		}
+		r.remaining = frame
+	}
+	n := copy(data, r.remaining)
+	r.remaining = r.remaining[n:]
+	if len(r.remaining) > 0 {
+		return n, io.ErrShortBuffer
+	}
+	return n, nil
+}
+
+// readFrame returns the next non-empty line, or ErrObjectTooLarge after discarding a line longer
+// than the maximum frame size.
+func (r *ndjsonFrameReader) readFrame() ([]byte, error) {
+	for {
+		frame := (*r.frame)[:0]
+		tooLarge := false
+		var err error
+		for {
+			var chunk []byte
+			chunk, err = r.br.ReadSlice('\n')
+			// allow for the line terminator before checking the size of the frame
+			if !tooLarge && len(frame)+len(chunk) > r.maxFrameSize+2 {
+				tooLarge, frame = true, frame[:0]
+			}
+			if !tooLarge {
+				frame = append(frame, chunk...)
+			}
+			if err != bufio.ErrBufferFull {
+				break
+			}
+		}
+		*r.frame = frame
+		if err == io.EOF && len(frame) == 0 && !tooLarge {
+			return nil, io.EOF
+		}
+		if err != nil && err != io.EOF {
+			return nil, err
+		}
+
+		frame = bytes.TrimSuffix(bytes.TrimSuffix(frame, []byte("\n")), []byte("\r"))
+		switch {
+		case tooLarge || len(frame) > r.maxFrameSize:
+			return nil, ErrObjectTooLarge
+		case len(bytes.TrimSpace(frame)) > 0:
+			return frame, nil
+		}
+	}
+}
+
+// Close closes the nested reader and releases the buffers of the frame reader.
+func (r *ndjsonFrameReader) Close() error {
+	if r.br != nil {
+		r.br.Reset(nil)
+		readerPool.Put(r.br)
+		r.br = nil
+		if cap(*r.frame) <= maxPooledFrameSize {
+			bufferPool.Put(r.frame)
+		}
+		r.frame, r.remaining = nil, nil
+	}
+	return r.r.Close()
+}
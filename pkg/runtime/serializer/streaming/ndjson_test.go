@@ -0,0 +1,111 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package streaming
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+func TestNDJSONFramer(t *testing.T) {
+	framer := NewNDJSONFramer(32)
+	frames := []string{`{"kind":"A"}`, `{"kind":"B"}` + "\n", `{"kind":"C","spec":{"a":1}}`}
+
+	out := &bytes.Buffer{}
+	w := framer.NewFrameWriter(out)
+	for _, frame := range frames {
+		if n, err := w.Write([]byte(frame)); err != nil || n != len(frame) {
+			t.Fatalf("unexpected %d %v", n, err)
+		}
+	}
+	if _, err := w.Write([]byte("{\n}")); err == nil {
+		t.Errorf("expected an error for a frame containing a newline")
+	}
+	expected := `{"kind":"A"}` + "\n" + `{"kind":"B"}` + "\n" + `{"kind":"C","spec":{"a":1}}` + "\n"
+	if out.String() != expected {
+		t.Fatalf("expected %q, got %q", expected, out.String())
+	}
+
+	// blank lines and carriage returns are ignored, and the last line doesn't need a newline
+	input := "\n" + `{"kind":"A"}` + "\r\n\r\n" + `{"kind":"` + strings.Repeat("x", 32) + `"}` + "\n" + `{"kind":"C","spec":{"a":1}}`
+	r := framer.NewFrameReader(ioutil.NopCloser(strings.NewReader(input)))
+	buf := make([]byte, 64)
+	if n, err := r.Read(buf); err != nil || string(buf[:n]) != `{"kind":"A"}` {
+		t.Fatalf("unexpected %v %q", err, buf[:n])
+	}
+	if _, err := r.Read(buf); err != ErrObjectTooLarge {
+		t.Fatalf("expected a too large error, got %v", err)
+	}
+	// a short buffer returns the frame over several reads
+	if n, err := r.Read(buf[:10]); err != io.ErrShortBuffer || string(buf[:n]) != `{"kind":"C` {
+		t.Fatalf("unexpected %v %q", err, buf[:n])
+	}
+	if n, err := r.Read(buf); err != nil || string(buf[:n]) != `","spec":{"a":1}}` {
+		t.Fatalf("unexpected %v %q", err, buf[:n])
+	}
+	if _, err := r.Read(buf); err != io.EOF {
+		t.Fatalf("expected EOF, got %v", err)
+	}
+	if err := r.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.Read(buf); err != io.EOF {
+		t.Fatalf("expected EOF after close, got %v", err)
+	}
+}
+
+func TestNDJSONDecoder(t *testing.T) {
+	frames := [][]byte{
+		bytes.Repeat([]byte("a"), 1025),
+		bytes.Repeat([]byte("b"), 1024*5),
+		bytes.Repeat([]byte("c"), 1024*1024+1),
+		bytes.Repeat([]byte("d"), 10*1024),
+	}
+	framer := NewNDJSONFramer(1024 * 1024)
+	pr, pw := io.Pipe()
+	fw := framer.NewFrameWriter(pw)
+	go func() {
+		for i := range frames {
+			fw.Write(frames[i])
+		}
+		pw.Close()
+	}()
+
+	d := &fakeDecoder{}
+	dec := NewDecoder(framer.NewFrameReader(pr), d)
+	if _, _, err := dec.Decode(nil, nil); err != nil || !bytes.Equal(d.got, frames[0]) {
+		t.Fatalf("unexpected %v %v", err, len(d.got))
+	}
+	if _, _, err := dec.Decode(nil, nil); err != nil || !bytes.Equal(d.got, frames[1]) {
+		t.Fatalf("unexpected %v %v", err, len(d.got))
+	}
+	if _, _, err := dec.Decode(nil, nil); err != ErrObjectTooLarge {
+		t.Fatalf("unexpected %v %v", err, len(d.got))
+	}
+	if _, _, err := dec.Decode(nil, nil); err != nil || !bytes.Equal(d.got, frames[3]) {
+		t.Fatalf("unexpected %v %v", err, len(d.got))
+	}
+	if _, _, err := dec.Decode(nil, nil); err != io.EOF {
+		t.Fatalf("unexpected %v %v", err, len(d.got))
+	}
+	if err := dec.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,98 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package serializer
+
+import (
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/runtime/serializer/recognizer"
+)
+
+// WithStrictness configures whether strict decoding errors are reported for the provided group kind,
+// regardless of the Strict option. An empty Kind applies to every kind of the group, and is overridden by
+// settings for specific kinds of that group.
+//
+// Configuring strictness for any group kind makes the json and yaml serializers decode every object
+// strictly, and discard the strict decoding errors for lenient kinds. This is not as performant as
+// non-strict decoding, see json.SerializerOptions.
+func WithStrictness(gk schema.GroupKind, strict bool) CodecFactoryOptionsMutator {
+	return func(options *CodecFactoryOptions) {
+		if options.StrictGroupKinds == nil {
+			options.StrictGroupKinds = map[schema.GroupKind]bool{}
+		}
+		options.StrictGroupKinds[gk] = strict
+	}
+}
+
+// StrictnessPolicy decides whether strict decoding errors are reported for objects of a group kind.
+type StrictnessPolicy struct {
+	// Default applies to group kinds that are not listed in GroupKinds.
+	Default bool
+	// GroupKinds holds the strictness of individual group kinds. An entry with an empty Kind applies
+	// to all kinds of the group that are not listed separately.
+	GroupKinds map[schema.GroupKind]bool
+}
+
+// IsStrict returns whether strict decoding errors should be reported for gk.
+func (p StrictnessPolicy) IsStrict(gk schema.GroupKind) bool {
+	if strict, ok := p.GroupKinds[gk]; ok {
+		return strict
+	}
+	if strict, ok := p.GroupKinds[schema.GroupKind{Group: gk.Group}]; ok {
+		return strict
+	}
+	return p.Default
+}
+
+// NewStrictnessPolicySerializer returns a serializer that encodes with encoder and decodes with the
+// provided strict decoder, reporting strict decoding errors only for group kinds that are strict
+// according to policy. For other group kinds, the object decoded despite the strict decoding errors
+// is returned.
+func NewStrictnessPolicySerializer(encoder runtime.Encoder, strictDecoder runtime.Decoder, policy StrictnessPolicy) runtime.Serializer {
+	return &strictnessPolicySerializer{
+		serializerWrapper: serializerWrapper{
+			encoder:    encoder,
+			decoder:    strictDecoder,
+			identifier: encoder.Identifier(),
+		},
+		policy: policy,
+	}
+}
+
+type strictnessPolicySerializer struct {
+	serializerWrapper
+	policy StrictnessPolicy
+}
+
+var _ runtime.EncoderWithAllocator = &strictnessPolicySerializer{}
+var _ runtime.SizeEstimator = &strictnessPolicySerializer{}
+var _ recognizer.RecognizingDecoder = &strictnessPolicySerializer{}
+
+// EstimateSize implements runtime.SizeEstimator, estimating the size of the encoding by the encoder with
+// runtime.EstimateSize.
+func (s *strictnessPolicySerializer) EstimateSize(obj runtime.Object, cache *runtime.SizeEstimateCache) (int, error) {
+	return runtime.EstimateSize(obj, s.encoder, cache)
+}
+
+// Decode decodes data strictly, and discards strict decoding errors for lenient group kinds.
+func (s *strictnessPolicySerializer) Decode(data []byte, defaults *schema.GroupVersionKind, into runtime.Object) (runtime.Object, *schema.GroupVersionKind, error) {
+	obj, gvk, err := s.decoder.Decode(data, defaults, into)
+	if err != nil && obj != nil && gvk != nil && runtime.IsStrictDecodingError(err) && !s.policy.IsStrict(gvk.GroupKind()) {
+		return obj, gvk, nil
+	}
+	return obj, gvk, err
+}
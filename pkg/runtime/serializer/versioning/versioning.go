@@ -18,6 +18,7 @@ package versioning
 
 import (
 	"encoding/json"
+	"fmt"
 	"io"
 	"reflect"
 	"sync"
@@ -89,6 +90,9 @@ type codec struct {
 	originalSchemeName string
 }
 
+var _ runtime.EncoderWithAllocator = &codec{}
+var _ runtime.SizeEstimator = &codec{}
+
 var identifiersMap sync.Map
 
 type codecIdentifier struct {
@@ -191,10 +195,47 @@ func (c *codec) Encode(obj runtime.Object, w io.Writer) error {
 	return c.doEncode(obj, w)
 }
 
+// EncodeWithAllocator encodes the provided object like Encode. In addition, it passes memAlloc to the
+// underlying encoder if the encoder implements runtime.EncoderWithAllocator, so that callers can reuse
+// buffers across calls, for example with allocators from runtime.AllocatorPool.
+func (c *codec) EncodeWithAllocator(obj runtime.Object, w io.Writer, memAlloc runtime.MemoryAllocator) error {
+	encode := func(obj runtime.Object, w io.Writer) error { return c.doEncodeWithAllocator(obj, w, memAlloc) }
+	if co, ok := obj.(runtime.CacheableObject); ok {
+		return co.CacheEncode(c.Identifier(), encode, w)
+	}
+	return encode(obj, w)
+}
+
 func (c *codec) doEncode(obj runtime.Object, w io.Writer) error {
+	return c.doEncodeWithAllocator(obj, w, nil)
+}
+
+func (c *codec) doEncodeWithAllocator(obj runtime.Object, w io.Writer, memAlloc runtime.MemoryAllocator) error {
+	encoder := runtime.WithAllocator(c.encoder, memAlloc)
+	return c.convertForEncoding(obj, func(obj runtime.Object) error { return encoder.Encode(obj, w) })
+}
+
+// EstimateSize implements runtime.SizeEstimator. The object is converted as by Encode, and the size of
+// the encoding of the result by the underlying encoder is estimated with runtime.EstimateSize.
+func (c *codec) EstimateSize(obj runtime.Object, cache *runtime.SizeEstimateCache) (int, error) {
+	if co, ok := obj.(runtime.CacheableObject); ok {
+		obj = co.GetObject()
+	}
+	size := 0
+	err := c.convertForEncoding(obj, func(obj runtime.Object) error {
+		var err error
+		size, err = runtime.EstimateSize(obj, c.encoder, cache)
+		return err
+	})
+	return size, err
+}
+
+// convertForEncoding invokes encode with obj converted to the encode version, with its group, version
+// and kind set to the encoded ones until encode returns.
+func (c *codec) convertForEncoding(obj runtime.Object, encode func(obj runtime.Object) error) error {
 	switch obj := obj.(type) {
 	case *runtime.Unknown:
-		return c.encoder.Encode(obj, w)
+		return encode(obj)
 	case runtime.Unstructured:
 		// An unstructured list can contain objects of multiple group version kinds. don't short-circuit just
 		// because the top-level type matches our desired destination type. actually send the object to the converter
@@ -203,14 +244,14 @@ func (c *codec) doEncode(obj runtime.Object, w io.Writer) error {
 			// avoid conversion roundtrip if GVK is the right one already or is empty (yes, this is a hack, but the old behaviour we rely on in kubectl)
 			objGVK := obj.GetObjectKind().GroupVersionKind()
 			if len(objGVK.Version) == 0 {
-				return c.encoder.Encode(obj, w)
+				return encode(obj)
 			}
 			targetGVK, ok := c.encodeVersion.KindForGroupVersionKinds([]schema.GroupVersionKind{objGVK})
 			if !ok {
 				return runtime.NewNotRegisteredGVKErrForTarget(c.originalSchemeName, objGVK, c.encodeVersion)
 			}
 			if targetGVK == objGVK {
-				return c.encoder.Encode(obj, w)
+				return encode(obj)
 			}
 		}
 	}
@@ -232,7 +273,7 @@ func (c *codec) doEncode(obj runtime.Object, w io.Writer) error {
 			}
 		}
 		objectKind.SetGroupVersionKind(gvks[0])
-		return c.encoder.Encode(obj, w)
+		return encode(obj)
 	}
 
 	// Perform a conversion if necessary
@@ -248,10 +289,135 @@ func (c *codec) doEncode(obj runtime.Object, w io.Writer) error {
 	}
 
 	// Conversion is responsible for setting the proper group, version, and kind onto the outgoing object
-	return c.encoder.Encode(out, w)
+	return encode(out)
 }
 
 // Identifier implements runtime.Encoder interface.
 func (c *codec) Identifier() runtime.Identifier {
 	return c.identifier
 }
+
+// MultiVersionEncoder is implemented by the codecs returned by NewCodec and NewDefaultingCodecForScheme.
+type MultiVersionEncoder interface {
+	// EncodeVersions writes obj converted to versions[i] to writers[i], for every version. It is
+	// equivalent to encoding obj with a codec per version, but obj is converted at most once to
+	// the decode version of the codec, if any, and the versions the type of obj isn't registered
+	// for are converted from that object, once per resulting group, version and kind.
+	EncodeVersions(obj runtime.Object, versions []runtime.GroupVersioner, writers []io.Writer) error
+}
+
+var _ MultiVersionEncoder = &codec{}
+
+// EncodeVersions implements MultiVersionEncoder. Cacheable objects are not cached, the object
+// they hold is encoded instead.
+func (c *codec) EncodeVersions(obj runtime.Object, versions []runtime.GroupVersioner, writers []io.Writer) error {
+	if len(versions) != len(writers) {
+		return fmt.Errorf("got %d versions but %d writers", len(versions), len(writers))
+	}
+	if co, ok := obj.(runtime.CacheableObject); ok {
+		obj = co.GetObject()
+	}
+
+	// objects the codec doesn't convert, or converts item by item, are encoded independently
+	encodeEach := func() error {
+		for i, version := range versions {
+			versioned := *c
+			versioned.encodeVersion = version
+			if err := versioned.doEncode(obj, writers[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	switch obj.(type) {
+	case *runtime.Unknown, runtime.Unstructured:
+		return encodeEach()
+	}
+	gvks, isUnversioned, err := c.typer.ObjectKinds(obj)
+	if err != nil {
+		return err
+	}
+	if isUnversioned {
+		return encodeEach()
+	}
+
+	objectKind := obj.GetObjectKind()
+	old := objectKind.GroupVersionKind()
+	// restore the old GVK after encoding
+	defer objectKind.SetGroupVersionKind(old)
+
+	// hub is obj converted to the decode version, which the versions the type of obj isn't
+	// registered for are converted from
+	var hub runtime.Object
+	convertFrom := func(targetGVK schema.GroupVersionKind) (runtime.Object, error) {
+		if containsGVK(gvks, targetGVK) {
+			return obj, nil
+		}
+		if hub != nil {
+			return hub, nil
+		}
+		hub = obj
+		if c.decodeVersion == nil {
+			return hub, nil
+		}
+		if hubGVK, ok := c.decodeVersion.KindForGroupVersionKinds(gvks); ok && !containsGVK(gvks, hubGVK) {
+			converted, err := c.convertor.ConvertToVersion(obj, c.decodeVersion)
+			switch {
+			case runtime.IsNotRegisteredError(err):
+				// the kind has no representation in the decode version, convert from obj directly
+			case err != nil:
+				return nil, err
+			default:
+				hub = converted
+			}
+		}
+		return hub, nil
+	}
+
+	converted := make(map[schema.GroupVersionKind]runtime.Object, len(versions))
+	for i, version := range versions {
+		if version == nil {
+			versioned := *c
+			versioned.encodeVersion = nil
+			if err := versioned.doEncode(obj, writers[i]); err != nil {
+				return err
+			}
+			continue
+		}
+		targetGVK, ok := version.KindForGroupVersionKinds(gvks)
+		if !ok {
+			return runtime.NewNotRegisteredGVKErrForTarget(c.originalSchemeName, gvks[0], version)
+		}
+		out, ok := converted[targetGVK]
+		if !ok {
+			in, err := convertFrom(targetGVK)
+			if err != nil {
+				return err
+			}
+			if out, err = c.convertor.ConvertToVersion(in, version); err != nil {
+				return err
+			}
+			if e, ok := out.(runtime.NestedObjectEncoder); ok {
+				if err := e.EncodeNestedObjects(runtime.WithVersionEncoder{Version: version, Encoder: c.encoder, ObjectTyper: c.typer}); err != nil {
+					return err
+				}
+			}
+			converted[targetGVK] = out
+		}
+		// unsafe conversions may return the same object for several versions
+		out.GetObjectKind().SetGroupVersionKind(targetGVK)
+		if err := c.encoder.Encode(out, writers[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func containsGVK(gvks []schema.GroupVersionKind, gvk schema.GroupVersionKind) bool {
+	for _, candidate := range gvks {
+		if candidate == gvk {
+			return true
+		}
+	}
+	return false
+}
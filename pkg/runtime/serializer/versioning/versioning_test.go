@@ -17,14 +17,18 @@ limitations under the License.
 package versioning
 
 import (
+	"bytes"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"reflect"
+	"sort"
 	"testing"
 
+	"k8s.io/apimachinery/pkg/conversion"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	serializerjson "k8s.io/apimachinery/pkg/runtime/serializer/json"
 	runtimetesting "k8s.io/apimachinery/pkg/runtime/testing"
 	"k8s.io/apimachinery/pkg/util/diff"
 )
@@ -385,3 +389,186 @@ func BenchmarkIdentifier(b *testing.B) {
 		}
 	}
 }
+
+type mockAllocatingSerializer struct {
+	mockSerializer
+	memAlloc runtime.MemoryAllocator
+}
+
+func (s *mockAllocatingSerializer) EncodeWithAllocator(obj runtime.Object, w io.Writer, memAlloc runtime.MemoryAllocator) error {
+	s.memAlloc = memAlloc
+	return s.Encode(obj, w)
+}
+
+func TestCodecEncodeWithAllocator(t *testing.T) {
+	gvk := schema.GroupVersionKind{Group: "group", Version: "version", Kind: "Kind"}
+	serializer := &mockAllocatingSerializer{}
+	c := NewCodec(
+		serializer, nil,
+		&checkConvertor{obj: &testDecodable{}, groupVersion: gvk.GroupVersion()}, nil,
+		&mockTyper{gvks: []schema.GroupVersionKind{gvk}}, nil,
+		gvk.GroupVersion(), nil,
+		"TestCodecEncodeWithAllocator")
+
+	memAlloc := &runtime.Allocator{}
+	if err := runtime.WithAllocator(c, memAlloc).Encode(&testDecodable{}, ioutil.Discard); err != nil {
+		t.Fatal(err)
+	}
+	if serializer.memAlloc != memAlloc {
+		t.Errorf("expected the allocator to be passed to the serializer, got %#v", serializer.memAlloc)
+	}
+
+	serializer.memAlloc = nil
+	if err := c.Encode(&testDecodable{}, ioutil.Discard); err != nil {
+		t.Fatal(err)
+	}
+	if serializer.memAlloc != nil {
+		t.Errorf("expected no allocator to be passed by Encode, got %#v", serializer.memAlloc)
+	}
+}
+
+type countingConvertor struct {
+	runtime.ObjectConvertor
+	conversions int
+}
+
+func (c *countingConvertor) ConvertToVersion(in runtime.Object, outVersion runtime.GroupVersioner) (runtime.Object, error) {
+	c.conversions++
+	return c.ObjectConvertor.ConvertToVersion(in, outVersion)
+}
+
+type testItemsInternal struct {
+	runtime.TypeMeta `json:",inline"`
+	Items            []string `json:"items"`
+}
+
+type testItemsV1 testItemsInternal
+type testItemsV2 testItemsInternal
+type testItemsV3 testItemsInternal
+
+func (t *testItemsInternal) DeepCopyObject() runtime.Object {
+	return &testItemsInternal{t.TypeMeta, append([]string(nil), t.Items...)}
+}
+func (t *testItemsV1) DeepCopyObject() runtime.Object {
+	return &testItemsV1{t.TypeMeta, append([]string(nil), t.Items...)}
+}
+func (t *testItemsV2) DeepCopyObject() runtime.Object {
+	return &testItemsV2{t.TypeMeta, append([]string(nil), t.Items...)}
+}
+func (t *testItemsV3) DeepCopyObject() runtime.Object {
+	return &testItemsV3{t.TypeMeta, append([]string(nil), t.Items...)}
+}
+
+// newMultiVersionScheme returns a scheme with the kind Items registered with distinct types in an
+// internal version and in the returned versions, which convert only to and from the internal one.
+func newMultiVersionScheme(t testing.TB) (*runtime.Scheme, []runtime.GroupVersioner) {
+	scheme := runtime.NewScheme()
+	internalGV := schema.GroupVersion{Group: "test.group", Version: runtime.APIVersionInternal}
+	scheme.AddKnownTypeWithName(internalGV.WithKind("Items"), &testItemsInternal{})
+	var versions []runtime.GroupVersioner
+	for version, obj := range map[string]runtime.Object{"v1": &testItemsV1{}, "v2": &testItemsV2{}, "v3": &testItemsV3{}} {
+		gv := schema.GroupVersion{Group: "test.group", Version: version}
+		scheme.AddKnownTypeWithName(gv.WithKind("Items"), obj)
+		versions = append(versions, gv)
+
+		toInternal := func(in, out interface{}, s conversion.Scope) error {
+			items := reflect.ValueOf(in).Elem().FieldByName("Items").Interface().([]string)
+			out.(*testItemsInternal).Items = append([]string(nil), items...)
+			return nil
+		}
+		fromInternal := func(in, out interface{}, s conversion.Scope) error {
+			items := append([]string(nil), in.(*testItemsInternal).Items...)
+			reflect.ValueOf(out).Elem().FieldByName("Items").Set(reflect.ValueOf(items))
+			return nil
+		}
+		if err := scheme.AddConversionFunc(obj, (*testItemsInternal)(nil), toInternal); err != nil {
+			t.Fatal(err)
+		}
+		if err := scheme.AddConversionFunc((*testItemsInternal)(nil), obj, fromInternal); err != nil {
+			t.Fatal(err)
+		}
+	}
+	sort.Slice(versions, func(i, j int) bool { return versions[i].Identifier() < versions[j].Identifier() })
+	return scheme, versions
+}
+
+func TestEncodeVersions(t *testing.T) {
+	scheme, versions := newMultiVersionScheme(t)
+	serializer := serializerjson.NewSerializerWithOptions(serializerjson.DefaultMetaFactory, scheme, scheme, serializerjson.SerializerOptions{})
+	internalGV := schema.GroupVersion{Group: "test.group", Version: runtime.APIVersionInternal}
+	versions = append(versions, versions[0], nil)
+
+	for _, tc := range []struct {
+		obj         runtime.Object
+		conversions int
+	}{
+		// one conversion per distinct version, and one to the internal version for the external object
+		{obj: &testItemsInternal{Items: []string{"a", "b"}}, conversions: 3},
+		{obj: &testItemsV2{TypeMeta: runtime.TypeMeta{APIVersion: "test.group/v2", Kind: "Items"}, Items: []string{"a"}}, conversions: 4},
+	} {
+		convertor := &countingConvertor{ObjectConvertor: runtime.UnsafeObjectConvertor(scheme)}
+		c := NewCodec(serializer, serializer, convertor, scheme, scheme, scheme, nil, internalGV, "TestEncodeVersions")
+		original := tc.obj.DeepCopyObject()
+
+		writers := make([]io.Writer, len(versions))
+		for i := range writers {
+			writers[i] = &bytes.Buffer{}
+		}
+		if err := c.(MultiVersionEncoder).EncodeVersions(tc.obj, versions, writers); err != nil {
+			t.Fatal(err)
+		}
+		if convertor.conversions != tc.conversions {
+			t.Errorf("%T: expected %d conversions, got %d", tc.obj, tc.conversions, convertor.conversions)
+		}
+		if !reflect.DeepEqual(original, tc.obj) {
+			t.Errorf("expected the object to be unchanged, got %#v", tc.obj)
+		}
+		for i, version := range versions {
+			expected := &bytes.Buffer{}
+			if err := NewDefaultingCodecForScheme(scheme, serializer, serializer, version, internalGV).Encode(tc.obj, expected); err != nil {
+				t.Fatal(err)
+			}
+			if e, a := expected.String(), writers[i].(*bytes.Buffer).String(); e != a {
+				t.Errorf("%T to %v: expected %s, got %s", tc.obj, version, e, a)
+			}
+		}
+	}
+
+	c := NewDefaultingCodecForScheme(scheme, serializer, serializer, nil, internalGV)
+	if err := c.(MultiVersionEncoder).EncodeVersions(&testItemsInternal{}, versions, nil); err == nil {
+		t.Errorf("expected an error for mismatched versions and writers")
+	}
+}
+
+func BenchmarkEncodeVersions(b *testing.B) {
+	scheme, versions := newMultiVersionScheme(b)
+	serializer := serializerjson.NewSerializerWithOptions(serializerjson.DefaultMetaFactory, scheme, scheme, serializerjson.SerializerOptions{})
+	internalGV := schema.GroupVersion{Group: "test.group", Version: runtime.APIVersionInternal}
+	obj := &testItemsV1{TypeMeta: runtime.TypeMeta{APIVersion: "test.group/v1", Kind: "Items"}}
+	for i := 0; i < 100; i++ {
+		obj.Items = append(obj.Items, fmt.Sprintf("item-%d", i))
+	}
+	writers := []io.Writer{ioutil.Discard, ioutil.Discard, ioutil.Discard}
+
+	b.Run("EncodeVersions", func(b *testing.B) {
+		c := NewDefaultingCodecForScheme(scheme, serializer, serializer, nil, internalGV).(MultiVersionEncoder)
+		for i := 0; i < b.N; i++ {
+			if err := c.EncodeVersions(obj, versions, writers); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("Encode", func(b *testing.B) {
+		var codecs []runtime.Codec
+		for _, version := range versions {
+			codecs = append(codecs, NewDefaultingCodecForScheme(scheme, serializer, serializer, version, internalGV))
+		}
+		for i := 0; i < b.N; i++ {
+			for j, c := range codecs {
+				if err := c.Encode(obj, writers[j]); err != nil {
+					b.Fatal(err)
+				}
+			}
+		}
+	})
+}
@@ -0,0 +1,89 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package serializer
+
+import (
+	"encoding/json"
+	"io"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/serializer/recognizer"
+	"k8s.io/klog/v2"
+)
+
+// serializerWrapper implements the encoding and recognizing of data shared by the serializers of
+// this package that wrap another serializer. It is embedded by the wrapping serializers, which
+// implement decoding themselves.
+type serializerWrapper struct {
+	// encoder and decoder are wrapped, and are usually the same serializer.
+	encoder runtime.Encoder
+	decoder runtime.Decoder
+	// identifier is the Identifier of the wrapping serializer.
+	identifier runtime.Identifier
+	// encode encodes obj on behalf of the wrapping serializer, passing memAlloc, which may be nil,
+	// to the wrapped encoder. Encodings are then cached in runtime.CacheableObjects with the
+	// identifier of the wrapping serializer. If encode is nil, objects are encoded with the wrapped
+	// encoder, which must have the same identifier.
+	encode func(obj runtime.Object, w io.Writer, memAlloc runtime.MemoryAllocator) error
+}
+
+// wrapperIdentifier computes the Identifier of a serializer wrapping serializer from its name and
+// the fields distinguishing its encodings.
+func wrapperIdentifier(name string, serializer runtime.Encoder, fields map[string]interface{}) runtime.Identifier {
+	result := map[string]interface{}{
+		"name":       name,
+		"serializer": string(serializer.Identifier()),
+	}
+	for key, value := range fields {
+		result[key] = value
+	}
+	identifier, err := json.Marshal(result)
+	if err != nil {
+		klog.Fatalf("Failed marshaling identifier for %s serializer: %v", name, err)
+	}
+	return runtime.Identifier(identifier)
+}
+
+// Encode implements runtime.Encoder interface.
+func (s *serializerWrapper) Encode(obj runtime.Object, w io.Writer) error {
+	return s.EncodeWithAllocator(obj, w, nil)
+}
+
+// EncodeWithAllocator works like Encode, passing memAlloc to the wrapped encoder.
+func (s *serializerWrapper) EncodeWithAllocator(obj runtime.Object, w io.Writer, memAlloc runtime.MemoryAllocator) error {
+	if s.encode == nil {
+		return runtime.WithAllocator(s.encoder, memAlloc).Encode(obj, w)
+	}
+	encode := func(obj runtime.Object, w io.Writer) error { return s.encode(obj, w, memAlloc) }
+	if co, ok := obj.(runtime.CacheableObject); ok {
+		return co.CacheEncode(s.identifier, encode, w)
+	}
+	return encode(obj, w)
+}
+
+// Identifier implements runtime.Encoder interface.
+func (s *serializerWrapper) Identifier() runtime.Identifier {
+	return s.identifier
+}
+
+// RecognizesData implements the RecognizingDecoder interface, recognizing data with the wrapped decoder.
+func (s *serializerWrapper) RecognizesData(data []byte) (ok, unknown bool, err error) {
+	if r, ok := s.decoder.(recognizer.RecognizingDecoder); ok {
+		return r.RecognizesData(data)
+	}
+	return false, true, nil
+}
@@ -0,0 +1,228 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package yaml
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	jsonserializer "k8s.io/apimachinery/pkg/runtime/serializer/json"
+	"k8s.io/apimachinery/pkg/runtime/serializer/streaming"
+	"k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/klog/v2"
+)
+
+// Framer frames streams of YAML documents separated by `---` lines. Unlike json.YAMLFramer, its
+// frame readers skip documents that hold nothing but whitespace and comments, as manifests often do.
+var Framer runtime.Framer = framer{}
+
+type framer struct{}
+
+// NewFrameWriter implements stream framing for this serializer
+func (framer) NewFrameWriter(w io.Writer) io.Writer {
+	return jsonserializer.YAMLFramer.NewFrameWriter(w)
+}
+
+// NewFrameReader implements stream framing for this serializer
+func (framer) NewFrameReader(r io.ReadCloser) io.ReadCloser {
+	return &documentReader{r: r, reader: yaml.NewYAMLReader(bufio.NewReader(r))}
+}
+
+// documentReader returns a non-empty YAML document per Read, or io.ErrShortBuffer if the
+// document doesn't fit in the buffer to assist the caller in framing the document.
+type documentReader struct {
+	r         io.ReadCloser
+	reader    *yaml.YAMLReader
+	remaining []byte
+}
+
+func (d *documentReader) Read(data []byte) (n int, err error) {
+	for len(d.remaining) == 0 {
+		document, err := d.reader.Read()
+		if err != nil {
+			return 0, err
+		}
+		if !isEmptyDocument(document) {
+			d.remaining = document
+		}
+	}
+	if len(d.remaining) <= len(data) {
+		n = copy(data, d.remaining)
+		d.remaining = nil
+		return n, nil
+	}
+	n = copy(data, d.remaining)
+	d.remaining = d.remaining[n:]
+	return n, io.ErrShortBuffer
+}
+
+func (d *documentReader) Close() error {
+	return d.r.Close()
+}
+
+// isEmptyDocument returns true if document holds only whitespace and comments.
+func isEmptyDocument(document []byte) bool {
+	for _, line := range bytes.Split(document, []byte("\n")) {
+		line = bytes.TrimSpace(line)
+		if len(line) > 0 && line[0] != '#' {
+			return false
+		}
+	}
+	return true
+}
+
+// MultiDocumentSerializer encodes and decodes streams of YAML documents separated by `---` lines,
+// such as manifests holding several objects.
+type MultiDocumentSerializer struct {
+	serializer runtime.Serializer
+	limits     runtime.DecodingLimits
+	identifier runtime.Identifier
+}
+
+var _ runtime.Serializer = &MultiDocumentSerializer{}
+
+// NewMultiDocumentSerializer returns a MultiDocumentSerializer encoding and decoding the individual
+// documents with serializer, which must handle single YAML documents, e.g. json.NewYAMLSerializer.
+func NewMultiDocumentSerializer(serializer runtime.Serializer) *MultiDocumentSerializer {
+	return &MultiDocumentSerializer{
+		serializer: serializer,
+		identifier: multiDocumentIdentifier(serializer),
+	}
+}
+
+// NewMultiDocumentSerializerWithLimits returns a MultiDocumentSerializer like NewMultiDocumentSerializer,
+// whose decoders fail with a decoding limit error once more than limits.MaxDocuments documents have
+// been decoded. The other limits apply to individual documents, and are enforced by serializer.
+func NewMultiDocumentSerializerWithLimits(serializer runtime.Serializer, limits runtime.DecodingLimits) *MultiDocumentSerializer {
+	s := NewMultiDocumentSerializer(serializer)
+	s.limits = limits
+	return s
+}
+
+func multiDocumentIdentifier(serializer runtime.Serializer) runtime.Identifier {
+	result := map[string]string{
+		"name":       "multiDocumentYAML",
+		"serializer": string(serializer.Identifier()),
+	}
+	identifier, err := json.Marshal(result)
+	if err != nil {
+		klog.Fatalf("Failed marshaling identifier for MultiDocumentSerializer: %v", err)
+	}
+	return runtime.Identifier(identifier)
+}
+
+// Encode writes each item of obj as a separate document if obj is a list, and obj as a single
+// document otherwise.
+func (s *MultiDocumentSerializer) Encode(obj runtime.Object, w io.Writer) error {
+	if !meta.IsListType(obj) {
+		return s.EncodeList([]runtime.Object{obj}, w)
+	}
+	items, err := meta.ExtractList(obj)
+	if err != nil {
+		return err
+	}
+	return s.EncodeList(items, w)
+}
+
+// EncodeList writes objs to w as a stream of documents, each preceded by a `---` line.
+func (s *MultiDocumentSerializer) EncodeList(objs []runtime.Object, w io.Writer) error {
+	fw := Framer.NewFrameWriter(w)
+	buf := &bytes.Buffer{}
+	for _, obj := range objs {
+		buf.Reset()
+		if err := s.serializer.Encode(obj, buf); err != nil {
+			return err
+		}
+		if _, err := fw.Write(buf.Bytes()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Identifier implements runtime.Encoder interface.
+func (s *MultiDocumentSerializer) Identifier() runtime.Identifier {
+	return s.identifier
+}
+
+// Decode decodes data holding a single non-empty document. Use DecodeAll or NewDecoder to decode
+// data holding several documents.
+func (s *MultiDocumentSerializer) Decode(data []byte, defaults *schema.GroupVersionKind, into runtime.Object) (runtime.Object, *schema.GroupVersionKind, error) {
+	d := s.NewDecoder(io.NopCloser(bytes.NewReader(data)))
+	obj, gvk, err := d.Decode(defaults, into)
+	if err == io.EOF {
+		return nil, nil, fmt.Errorf("no YAML documents found")
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	if _, _, err := d.Decode(defaults, nil); err != io.EOF {
+		return nil, nil, fmt.Errorf("expected a single YAML document, found several")
+	}
+	return obj, gvk, nil
+}
+
+// DecodeAll decodes the documents of data in order.
+func (s *MultiDocumentSerializer) DecodeAll(data []byte, defaults *schema.GroupVersionKind) ([]runtime.Object, error) {
+	d := s.NewDecoder(io.NopCloser(bytes.NewReader(data)))
+	var objs []runtime.Object
+	for {
+		obj, _, err := d.Decode(defaults, nil)
+		if err == io.EOF {
+			return objs, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("unable to decode YAML document %d: %v", len(objs)+1, err)
+		}
+		objs = append(objs, obj)
+	}
+}
+
+// NewDecoder returns a decoder decoding the documents read from r one Decode call at a time,
+// returning io.EOF once all documents have been decoded.
+func (s *MultiDocumentSerializer) NewDecoder(r io.ReadCloser) streaming.Decoder {
+	d := streaming.NewDecoder(Framer.NewFrameReader(r), s.serializer)
+	if s.limits.MaxDocuments <= 0 {
+		return d
+	}
+	return &limitedDecoder{Decoder: d, maxDocuments: s.limits.MaxDocuments}
+}
+
+// limitedDecoder fails once more than maxDocuments documents have been decoded.
+type limitedDecoder struct {
+	streaming.Decoder
+	maxDocuments int
+	documents    int
+}
+
+func (d *limitedDecoder) Decode(defaults *schema.GroupVersionKind, into runtime.Object) (runtime.Object, *schema.GroupVersionKind, error) {
+	if d.documents >= d.maxDocuments {
+		// only fail if there is another document
+		if _, _, err := d.Decoder.Decode(defaults, &runtime.Unknown{}); err == io.EOF {
+			return nil, nil, err
+		}
+		return nil, nil, runtime.NewDecodingLimitError(fmt.Sprintf("the stream holds more than %d YAML documents", d.maxDocuments))
+	}
+	d.documents++
+	return d.Decoder.Decode(defaults, into)
+}
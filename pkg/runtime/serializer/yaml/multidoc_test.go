@@ -0,0 +1,145 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package yaml
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"reflect"
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	jsonserializer "k8s.io/apimachinery/pkg/runtime/serializer/json"
+	runtimetesting "k8s.io/apimachinery/pkg/runtime/testing"
+)
+
+func newMultiDocumentTestSerializer() *MultiDocumentSerializer {
+	gv := schema.GroupVersion{Group: "test.group", Version: "v1"}
+	scheme := runtime.NewScheme()
+	scheme.AddKnownTypeWithName(gv.WithKind("Simple"), &runtimetesting.ExternalSimple{})
+	return NewMultiDocumentSerializer(jsonserializer.NewYAMLSerializer(jsonserializer.DefaultMetaFactory, scheme, scheme))
+}
+
+func simple(value string) *runtimetesting.ExternalSimple {
+	return &runtimetesting.ExternalSimple{
+		TypeMeta:   runtime.TypeMeta{APIVersion: "test.group/v1", Kind: "Simple"},
+		TestString: value,
+	}
+}
+
+func TestMultiDocumentSerializerRoundTrip(t *testing.T) {
+	s := newMultiDocumentTestSerializer()
+	objs := []runtime.Object{simple("a"), simple("b")}
+	buf := &bytes.Buffer{}
+	if err := s.EncodeList(objs, buf); err != nil {
+		t.Fatal(err)
+	}
+	expected := "---\napiVersion: test.group/v1\nkind: Simple\ntestString: a\n---\napiVersion: test.group/v1\nkind: Simple\ntestString: b\n"
+	if buf.String() != expected {
+		t.Errorf("expected %q, got %q", expected, buf.String())
+	}
+
+	decoded, err := s.DecodeAll(buf.Bytes(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(objs, decoded) {
+		t.Errorf("expected %#v, got %#v", objs, decoded)
+	}
+
+	// lists are encoded as one document per item
+	list := &metav1.List{Items: []runtime.RawExtension{{Object: simple("a")}, {Object: simple("b")}}}
+	listBuf := &bytes.Buffer{}
+	if err := s.Encode(list, listBuf); err != nil {
+		t.Fatal(err)
+	}
+	if listBuf.String() != expected {
+		t.Errorf("expected %q, got %q", expected, listBuf.String())
+	}
+}
+
+func TestMultiDocumentSerializerDecode(t *testing.T) {
+	s := newMultiDocumentTestSerializer()
+	data := `# leading comment
+---
+apiVersion: test.group/v1
+kind: Simple
+testString: a
+---
+# only a comment
+---
+
+--- # trailing comment
+apiVersion: test.group/v1
+kind: Simple
+testString: b
+`
+	d := s.NewDecoder(ioutil.NopCloser(strings.NewReader(data)))
+	var values []string
+	for {
+		obj, _, err := d.Decode(nil, nil)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		values = append(values, obj.(*runtimetesting.ExternalSimple).TestString)
+	}
+	if e, a := []string{"a", "b"}, values; !reflect.DeepEqual(e, a) {
+		t.Errorf("expected %v, got %v", e, a)
+	}
+
+	if _, _, err := s.Decode([]byte(data), nil, nil); err == nil || !strings.Contains(err.Error(), "single YAML document") {
+		t.Errorf("expected an error for several documents, got %v", err)
+	}
+	if _, _, err := s.Decode([]byte("# nothing\n---\n"), nil, nil); err == nil {
+		t.Errorf("expected an error for no documents")
+	}
+	obj, _, err := s.Decode([]byte("---\napiVersion: test.group/v1\nkind: Simple\ntestString: c\n"), nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e, a := simple("c"), obj; !reflect.DeepEqual(e, a) {
+		t.Errorf("expected %#v, got %#v", e, a)
+	}
+	if _, err := s.DecodeAll([]byte("apiVersion: test.group/v1\nkind: Simple\n---\nkind: [\n"), nil); err == nil || !strings.Contains(err.Error(), "document 2") {
+		t.Errorf("expected an error for the invalid document, got %v", err)
+	}
+}
+
+func TestMultiDocumentSerializerLimits(t *testing.T) {
+	s := NewMultiDocumentSerializerWithLimits(newMultiDocumentTestSerializer().serializer, runtime.DecodingLimits{MaxDocuments: 2})
+	buf := &bytes.Buffer{}
+	if err := s.EncodeList([]runtime.Object{simple("a"), simple("b")}, buf); err != nil {
+		t.Fatal(err)
+	}
+	if objs, err := s.DecodeAll(buf.Bytes(), nil); err != nil || len(objs) != 2 {
+		t.Errorf("expected 2 objects, got %v, %v", objs, err)
+	}
+	if err := s.EncodeList([]runtime.Object{simple("c")}, buf); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.DecodeAll(buf.Bytes(), nil); !strings.Contains(fmt.Sprint(err), "more than 2 YAML documents") {
+		t.Errorf("expected a decoding limit error, got %v", err)
+	}
+}
@@ -0,0 +1,147 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package runtime
+
+import (
+	"math"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// SizeEstimator is an optional interface for encoders that can compute the size of the encoding of
+// an object cheaply, without encoding it.
+type SizeEstimator interface {
+	// EstimateSize returns the number of bytes Encode would write for obj, or an approximation of it.
+	// Estimators delegating to other encoders pass cache, which may be nil, to EstimateSize.
+	EstimateSize(obj Object, cache *SizeEstimateCache) (int, error)
+}
+
+// sizeOverheadKey identifies the encoding of a type by an encoder.
+type sizeOverheadKey struct {
+	encoder Identifier
+	t       reflect.Type
+}
+
+// SizeEstimateCache caches, per encoder identifier and type, the average number of bytes an encoder
+// writes for every value of an object in addition to the content of its strings and byte slices, as
+// measured by EstimateSize. It is safe for concurrent use. The zero value is an empty cache.
+type SizeEstimateCache struct {
+	overheads sync.Map
+}
+
+// NewSizeEstimateCache returns an empty SizeEstimateCache.
+func NewSizeEstimateCache() *SizeEstimateCache {
+	return &SizeEstimateCache{}
+}
+
+// EstimateSize returns an approximation of the number of bytes encoder writes to encode obj, e.g.
+// to enforce size quotas before performing an encode. Encoders implementing SizeEstimator are asked
+// for the size directly, like the codecs of CodecFactory do for the objects they pass to the wire
+// format serializers after conversion. Other encoders encode obj to measure its size.
+//
+// If cache is not nil, the average size of the encoding of the values of the first object of every
+// type, like field names, numbers and delimiters, besides the content of its strings and byte slices,
+// is cached per encoder identifier and type, and the size of the following objects of the type is
+// estimated from their content and number of values without encoding them. The estimate is therefore
+// only exact for the first object of a type, and is less precise for encodings that don't write
+// strings and byte slices as is, e.g. base64 encoded, and for objects whose values differ a lot from
+// those of the first object. Since they aren't encoded, errors encoding the following objects aren't
+// reported either.
+func EstimateSize(obj Object, encoder Encoder, cache *SizeEstimateCache) (int, error) {
+	if co, ok := obj.(CacheableObject); ok {
+		obj = co.GetObject()
+	}
+	if estimator, ok := encoder.(SizeEstimator); ok {
+		return estimator.EstimateSize(obj, cache)
+	}
+	if cache == nil {
+		w := &countingWriter{}
+		if err := encoder.Encode(obj, w); err != nil {
+			return 0, err
+		}
+		return w.n, nil
+	}
+
+	key := sizeOverheadKey{encoder: encoder.Identifier(), t: reflect.TypeOf(obj)}
+	content, values := contentSize(reflect.ValueOf(obj))
+	if overhead, ok := cache.overheads.Load(key); ok {
+		size := content + int(math.Ceil(float64(values)*overhead.(float64)))
+		if size < 0 {
+			return 0, nil
+		}
+		return size, nil
+	}
+	w := &countingWriter{}
+	if err := encoder.Encode(obj, w); err != nil {
+		return 0, err
+	}
+	cache.overheads.Store(key, float64(w.n-content)/float64(values))
+	return w.n, nil
+}
+
+// contentSize returns the total length of the strings and byte slices of v, and the number of values
+// of v, counting v, every struct field, collection item and map key. Empty fields omitted from the JSON
+// encoding are skipped. The number of values is always positive.
+func contentSize(v reflect.Value) (content, values int) {
+	values = 1
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if !v.IsNil() {
+			return contentSize(v.Elem())
+		}
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < v.NumField(); i++ {
+			field := t.Field(i)
+			if field.PkgPath != "" || (v.Field(i).IsZero() && strings.Contains(field.Tag.Get("json"), ",omitempty")) {
+				// unexported fields and empty optional fields aren't encoded
+				continue
+			}
+			c, n := contentSize(v.Field(i))
+			content, values = content+c, values+n
+		}
+	case reflect.Slice, reflect.Array:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return v.Len(), values
+		}
+		for i := 0; i < v.Len(); i++ {
+			c, n := contentSize(v.Index(i))
+			content, values = content+c, values+n
+		}
+	case reflect.Map:
+		iter := v.MapRange()
+		for iter.Next() {
+			kc, kn := contentSize(iter.Key())
+			vc, vn := contentSize(iter.Value())
+			content, values = content+kc+vc, values+kn+vn
+		}
+	case reflect.String:
+		return v.Len(), values
+	}
+	return content, values
+}
+
+// countingWriter counts the bytes written to it and discards them.
+type countingWriter struct {
+	n int
+}
+
+func (w *countingWriter) Write(data []byte) (int, error) {
+	w.n += len(data)
+	return len(data), nil
+}
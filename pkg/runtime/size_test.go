@@ -0,0 +1,98 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package runtime_test
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/runtime/serializer/json"
+	runtimetesting "k8s.io/apimachinery/pkg/runtime/testing"
+)
+
+type sizeEstimatingEncoder struct {
+	size int
+	err  error
+}
+
+func (e sizeEstimatingEncoder) Encode(obj runtime.Object, w io.Writer) error {
+	if e.err != nil {
+		return e.err
+	}
+	_, err := w.Write(make([]byte, e.size))
+	return err
+}
+
+func (e sizeEstimatingEncoder) Identifier() runtime.Identifier {
+	return "sizeEstimatingEncoder"
+}
+
+type sizeEstimator struct {
+	sizeEstimatingEncoder
+}
+
+func (e sizeEstimator) EstimateSize(obj runtime.Object, _ *runtime.SizeEstimateCache) (int, error) {
+	return e.size, e.err
+}
+
+func TestEstimateSize(t *testing.T) {
+	scheme := runtime.NewScheme()
+	scheme.AddKnownTypeWithName(schema.GroupVersionKind{Group: "test.group", Version: "v1", Kind: "Simple"}, &runtimetesting.ExternalSimple{})
+	serializer := json.NewSerializerWithOptions(json.DefaultMetaFactory, scheme, scheme, json.SerializerOptions{})
+
+	cache := runtime.NewSizeEstimateCache()
+	for i, obj := range []*runtimetesting.ExternalSimple{
+		{TestString: "value"},
+		{TestString: strings.Repeat("x", 1000)},
+		{TestString: ""},
+	} {
+		data, err := runtime.Encode(serializer, obj)
+		if err != nil {
+			t.Fatal(err)
+		}
+		size, err := runtime.EstimateSize(obj, serializer, cache)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// the first object of the type is encoded, the size of the others is estimated
+		if i == 0 && size != len(data) {
+			t.Errorf("expected the size of the first object to be %d, got %d", len(data), size)
+		}
+		if size < len(data)*9/10 || size > len(data)*11/10 {
+			t.Errorf("expected the size of %#v to approximate %d, got %d", obj, len(data), size)
+		}
+		// without a cache, objects are encoded to measure their size
+		if size, err := runtime.EstimateSize(obj, serializer, nil); err != nil || size != len(data) {
+			t.Errorf("expected the size of %#v to be %d, got %d (%v)", obj, len(data), size, err)
+		}
+	}
+
+	if size, err := runtime.EstimateSize(&runtimetesting.ExternalSimple{}, sizeEstimator{sizeEstimatingEncoder{size: 42}}, runtime.NewSizeEstimateCache()); err != nil || size != 42 {
+		t.Errorf("expected the size of the estimator, got %d (%v)", size, err)
+	}
+	encodeErr := errors.New("encoding failed")
+	if _, err := runtime.EstimateSize(&runtimetesting.ExternalSimple{}, sizeEstimatingEncoder{err: encodeErr}, runtime.NewSizeEstimateCache()); err != encodeErr {
+		t.Errorf("expected %v, got %v", encodeErr, err)
+	}
+	if size, err := runtime.EstimateSize(&runtimetesting.ExternalSimple{}, sizeEstimatingEncoder{size: 10}, runtime.NewSizeEstimateCache()); err != nil || size != 10 {
+		t.Errorf("expected the encoded size, got %d (%v)", size, err)
+	}
+}
@@ -333,3 +333,186 @@ func TestSetExtractListRoundTrip(t *testing.T) {
 		}
 	}
 }
+
+func TestListItemsWithAlloc(t *testing.T) {
+	list := &testapigroup.CarpList{
+		Items: []testapigroup.Carp{
+			{ObjectMeta: metav1.ObjectMeta{Name: "1"}},
+			{ObjectMeta: metav1.ObjectMeta{Name: "2"}},
+		},
+	}
+
+	extracted, err := meta.ExtractListWithAlloc(list)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var each []runtime.Object
+	if err := meta.EachListItemWithAlloc(list, func(obj runtime.Object) error {
+		each = append(each, obj)
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, items := range map[string][]runtime.Object{"extract": extracted, "each": each} {
+		if e, a := len(list.Items), len(items); e != a {
+			t.Fatalf("%s: expected %d items, got %d", name, e, a)
+		}
+		for i := range list.Items {
+			if items[i] == &list.Items[i] {
+				t.Errorf("%s: item[%d] references the list", name, i)
+			}
+			if !reflect.DeepEqual(items[i], &list.Items[i]) {
+				t.Errorf("%s: item[%d]: expected %#v, got %#v", name, i, &list.Items[i], items[i])
+			}
+		}
+	}
+
+	unstructuredList := &unstructured.UnstructuredList{Items: []unstructured.Unstructured{{Object: map[string]interface{}{"kind": "Carp"}}}}
+	if err := meta.EachListItemWithAlloc(unstructuredList, func(obj runtime.Object) error {
+		if obj == &unstructuredList.Items[0] {
+			t.Errorf("item references the list")
+		}
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestTypedListItems(t *testing.T) {
+	list := &testapigroup.CarpList{
+		Items: []testapigroup.Carp{
+			{ObjectMeta: metav1.ObjectMeta{Name: "1"}},
+			{ObjectMeta: metav1.ObjectMeta{Name: "2"}},
+		},
+	}
+
+	extracted, err := meta.ExtractListT[*testapigroup.Carp](list)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(extracted) != 2 || extracted[0] != &list.Items[0] || extracted[1] != &list.Items[1] {
+		t.Errorf("unexpected items %#v", extracted)
+	}
+	copied, err := meta.ExtractListWithAllocT[*testapigroup.Carp](list)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(copied) != 2 || copied[0] == &list.Items[0] || copied[1].Name != "2" {
+		t.Errorf("unexpected items %#v", copied)
+	}
+
+	var names []string
+	for _, each := range []func(runtime.Object, func(*testapigroup.Carp) error) error{meta.EachListItemT[*testapigroup.Carp], meta.EachListItemWithAllocT[*testapigroup.Carp]} {
+		if err := each(list, func(carp *testapigroup.Carp) error {
+			names = append(names, carp.Name)
+			return nil
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if e, a := []string{"1", "2", "1", "2"}, names; !reflect.DeepEqual(e, a) {
+		t.Errorf("expected %v, got %v", e, a)
+	}
+
+	mixed := &List{Items: []runtime.Object{&testapigroup.Carp{}, &v1.Carp{}}}
+	if _, err := meta.ExtractListT[*testapigroup.Carp](mixed); err == nil {
+		t.Errorf("expected an error for items of another type")
+	}
+	calls := 0
+	err = meta.EachListItemT(mixed, func(*testapigroup.Carp) error {
+		calls++
+		return nil
+	})
+	if err == nil || calls != 1 {
+		t.Errorf("expected the loop to stop at the item of another type, got %d calls and %v", calls, err)
+	}
+}
+
+func TestSetListSharesItems(t *testing.T) {
+	newList := func() *testapigroup.CarpList {
+		return &testapigroup.CarpList{
+			Items: []testapigroup.Carp{
+				{ObjectMeta: metav1.ObjectMeta{Name: "1"}},
+				{ObjectMeta: metav1.ObjectMeta{Name: "2"}},
+				{ObjectMeta: metav1.ObjectMeta{Name: "3"}},
+			},
+		}
+	}
+
+	testCases := []struct {
+		name   string
+		filter func([]runtime.Object) []runtime.Object
+		names  []string
+		shared bool
+	}{
+		{name: "unchanged", filter: func(objs []runtime.Object) []runtime.Object { return objs }, names: []string{"1", "2", "3"}, shared: true},
+		{name: "resliced", filter: func(objs []runtime.Object) []runtime.Object { return objs[1:] }, names: []string{"2", "3"}, shared: true},
+		{name: "reordered", filter: func(objs []runtime.Object) []runtime.Object { return []runtime.Object{objs[2], objs[0]} }, names: []string{"3", "1"}},
+		{name: "gap", filter: func(objs []runtime.Object) []runtime.Object { return []runtime.Object{objs[0], objs[2]} }, names: []string{"1", "3"}},
+		{name: "new item", filter: func(objs []runtime.Object) []runtime.Object {
+			return []runtime.Object{objs[0], &testapigroup.Carp{ObjectMeta: metav1.ObjectMeta{Name: "4"}}}
+		}, names: []string{"1", "4"}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			list := newList()
+			objects, err := meta.ExtractList(list)
+			if err != nil {
+				t.Fatal(err)
+			}
+			objects = tc.filter(objects)
+			if err := meta.SetList(list, objects); err != nil {
+				t.Fatal(err)
+			}
+			var names []string
+			for _, item := range list.Items {
+				names = append(names, item.Name)
+			}
+			if !reflect.DeepEqual(tc.names, names) {
+				t.Errorf("expected %v, got %v", tc.names, names)
+			}
+			if shared := objects[0] == &list.Items[0]; shared != tc.shared {
+				t.Errorf("expected shared=%t", tc.shared)
+			}
+		})
+	}
+}
+
+func BenchmarkExtractList(b *testing.B) {
+	list := &testapigroup.CarpList{Items: make([]testapigroup.Carp, 1000)}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := meta.ExtractList(list); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkSetList(b *testing.B) {
+	list := &testapigroup.CarpList{Items: make([]testapigroup.Carp, 1000)}
+	objects, err := meta.ExtractList(list)
+	if err != nil {
+		b.Fatal(err)
+	}
+	copied := make([]runtime.Object, len(objects))
+	for i := range objects {
+		copied[i] = objects[i].DeepCopyObject()
+	}
+	b.Run("shared", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if err := meta.SetList(list, objects); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("copied", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if err := meta.SetList(list, copied); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
@@ -206,19 +206,26 @@ func TestGarbageCollection(t *testing.T) {
 
 func BenchmarkExpiringCacheContention(b *testing.B) {
 	b.Run("evict_probablility=100%", func(b *testing.B) {
-		benchmarkExpiringCacheContention(b, 1)
+		benchmarkExpiringCacheContention(b, 1, NewExpiring())
 	})
 	b.Run("evict_probablility=10%", func(b *testing.B) {
-		benchmarkExpiringCacheContention(b, 0.1)
+		benchmarkExpiringCacheContention(b, 0.1, NewExpiring())
 	})
 	b.Run("evict_probablility=1%", func(b *testing.B) {
-		benchmarkExpiringCacheContention(b, 0.01)
+		benchmarkExpiringCacheContention(b, 0.01, NewExpiring())
 	})
 }
 
-func benchmarkExpiringCacheContention(b *testing.B, prob float64) {
+// expiringCache is implemented by Expiring and ShardedExpiring.
+type expiringCache interface {
+	Get(key interface{}) (interface{}, bool)
+	Set(key interface{}, val interface{}, ttl time.Duration)
+	Delete(key interface{})
+	Len() int
+}
+
+func benchmarkExpiringCacheContention(b *testing.B, prob float64, cache expiringCache) {
 	const numKeys = 1 << 16
-	cache := NewExpiring()
 
 	keys := []string{}
 	for i := 0; i < numKeys; i++ {
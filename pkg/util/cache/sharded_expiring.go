@@ -0,0 +1,130 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"fmt"
+	"hash/maphash"
+	"time"
+
+	"k8s.io/utils/clock"
+)
+
+// NewShardedExpiring returns an initialized sharded expiring cache with the
+// given number of shards. Less than one shard is treated as one shard.
+func NewShardedExpiring(shards int) *ShardedExpiring {
+	return NewShardedExpiringWithClock(shards, clock.RealClock{})
+}
+
+// NewShardedExpiringWithClock is like NewShardedExpiring but allows passing in
+// a custom clock for testing. The clock is shared by all shards.
+func NewShardedExpiringWithClock(shards int, clock clock.Clock) *ShardedExpiring {
+	if shards < 1 {
+		shards = 1
+	}
+	c := &ShardedExpiring{
+		seed:   maphash.MakeSeed(),
+		shards: make([]*Expiring, shards),
+	}
+	for i := range c.shards {
+		c.shards[i] = NewExpiringWithClock(clock)
+	}
+	return c
+}
+
+// ShardedExpiring is an expiring cache whose entries are partitioned into
+// shards by the hash of their key. Each shard is an Expiring cache with its own
+// lock, so that concurrent operations on keys of different shards do not
+// contend. Garbage collection of expired entries happens per shard during calls
+// to Set() on that shard.
+//
+// Keys are hashed according to their dynamic type: strings and integers are
+// hashed directly, other keys are hashed from their fmt %v representation,
+// which is more expensive.
+type ShardedExpiring struct {
+	seed   maphash.Seed
+	shards []*Expiring
+}
+
+// Get looks up an entry in the cache.
+func (c *ShardedExpiring) Get(key interface{}) (val interface{}, ok bool) {
+	return c.shard(key).Get(key)
+}
+
+// Set sets a key/value/expiry entry in the map, overwriting any previous entry
+// with the same key. See Expiring.Set for details.
+func (c *ShardedExpiring) Set(key interface{}, val interface{}, ttl time.Duration) {
+	c.shard(key).Set(key, val, ttl)
+}
+
+// Delete deletes an entry in the map.
+func (c *ShardedExpiring) Delete(key interface{}) {
+	c.shard(key).Delete(key)
+}
+
+// Len returns the number of items in the cache. Shards are counted one after
+// the other, so the result is not a consistent snapshot under concurrent
+// modification.
+func (c *ShardedExpiring) Len() int {
+	n := 0
+	for _, shard := range c.shards {
+		n += shard.Len()
+	}
+	return n
+}
+
+func (c *ShardedExpiring) shard(key interface{}) *Expiring {
+	if len(c.shards) == 1 {
+		return c.shards[0]
+	}
+	return c.shards[c.hash(key)%uint64(len(c.shards))]
+}
+
+// hash returns the same value for equal keys.
+func (c *ShardedExpiring) hash(key interface{}) uint64 {
+	var i uint64
+	switch k := key.(type) {
+	case string:
+		return c.hashString(k)
+	case int:
+		i = uint64(k)
+	case int32:
+		i = uint64(k)
+	case int64:
+		i = uint64(k)
+	case uint:
+		i = uint64(k)
+	case uint32:
+		i = uint64(k)
+	case uint64:
+		i = k
+	default:
+		return c.hashString(fmt.Sprintf("%T/%v", key, key))
+	}
+	// Mix the bits of the integer so that sequential keys spread across shards.
+	i ^= i >> 33
+	i *= 0xff51afd7ed558ccd
+	i ^= i >> 33
+	return i
+}
+
+func (c *ShardedExpiring) hashString(s string) uint64 {
+	var h maphash.Hash
+	h.SetSeed(c.seed)
+	h.WriteString(s)
+	return h.Sum64()
+}
@@ -0,0 +1,112 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	testingclock "k8s.io/utils/clock/testing"
+)
+
+func TestShardedExpiringCache(t *testing.T) {
+	type structKey struct {
+		namespace, name string
+	}
+	fc := &testingclock.FakeClock{}
+	cache := NewShardedExpiringWithClock(8, fc)
+
+	keys := []interface{}{"foo", 1, int64(1), uint32(7), structKey{"ns", "name"}, &structKey{}}
+	for i, key := range keys {
+		cache.Set(key, i, time.Duration(i+1)*time.Second)
+	}
+	for i, key := range keys {
+		if result, ok := cache.Get(key); !ok || result != i {
+			t.Errorf("%#v: expected %d, true, got %#v, %v", key, i, result, ok)
+		}
+	}
+	if result, ok := cache.Get(structKey{"ns", "name"}); !ok || result != 4 {
+		t.Errorf("expected equal struct keys to find the entry, got %#v, %v", result, ok)
+	}
+	if cache.Len() != len(keys) {
+		t.Errorf("unexpected cache size: got=%d, want=%d", cache.Len(), len(keys))
+	}
+
+	cache.Delete("foo")
+	if result, ok := cache.Get("foo"); ok || result != nil {
+		t.Errorf("Expected null, false, got %#v, %v", result, ok)
+	}
+
+	// All shards share the clock.
+	fc.Step(3 * time.Second)
+	for i, key := range keys {
+		if _, ok := cache.Get(key); ok != (i >= 3) {
+			t.Errorf("%#v: expected found=%v after 3s", key, i >= 3)
+		}
+	}
+}
+
+func TestShardedExpiringDistribution(t *testing.T) {
+	cache := NewShardedExpiring(4)
+	for i := 0; i < 1000; i++ {
+		cache.Set(fmt.Sprintf("key-%d", i), i, time.Hour)
+		cache.Set(i, i, time.Hour)
+	}
+	for i, shard := range cache.shards {
+		if shard.Len() == 0 {
+			t.Errorf("shard %d is empty", i)
+		}
+	}
+	if cache.Len() != 2000 {
+		t.Errorf("unexpected cache size: got=%d, want=2000", cache.Len())
+	}
+
+	if single := NewShardedExpiring(0); len(single.shards) != 1 {
+		t.Errorf("expected a single shard, got %d", len(single.shards))
+	}
+}
+
+func TestShardedExpiringConcurrentAccess(t *testing.T) {
+	cache := NewShardedExpiring(16)
+	var wg sync.WaitGroup
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 1000; j++ {
+				key := (i*1000 + j) % 500
+				if _, ok := cache.Get(key); !ok {
+					cache.Set(key, j, time.Hour)
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+	if cache.Len() != 500 {
+		t.Errorf("unexpected cache size: got=%d, want=500", cache.Len())
+	}
+}
+
+func BenchmarkShardedExpiringCacheContention(b *testing.B) {
+	for _, shards := range []int{1, 16, 64} {
+		b.Run(fmt.Sprintf("shards=%d/evict_probablility=10%%", shards), func(b *testing.B) {
+			benchmarkExpiringCacheContention(b, 0.1, NewShardedExpiring(shards))
+		})
+	}
+}
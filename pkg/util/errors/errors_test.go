@@ -17,6 +17,7 @@ limitations under the License.
 package errors
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"reflect"
@@ -528,3 +529,62 @@ func TestErrConfigurationInvalidWithErrorsIsShortCircuitsOnFirstMatch(t *testing
 		t.Errorf("expected exactly one error to get accessed, got %d", numAccessed)
 	}
 }
+
+type typedError struct{ msg string }
+
+func (e *typedError) Error() string { return e.msg }
+
+func TestTree(t *testing.T) {
+	if Tree(nil) != nil {
+		t.Errorf("expected no tree for nil")
+	}
+
+	err := NewAggregate([]error{
+		fmt.Errorf("failed to sync pod: %w", &typedError{"connection refused"}),
+		errors.New("quota exceeded"),
+		errors.New("quota exceeded"),
+		NewAggregate([]error{errors.New("a"), errors.New("b")}),
+		errors.New("quota exceeded"),
+	})
+	expected := `5 errors
+  - failed to sync pod
+    - connection refused [*errors.typedError]
+  - quota exceeded (x3)
+  - 2 errors
+    - a
+    - b`
+	if e, a := expected, Tree(err).String(); e != a {
+		t.Errorf("expected\n%s\ngot\n%s", e, a)
+	}
+
+	// identical nested trees are deduplicated as well.
+	for i, test := range []struct {
+		err      error
+		expected string
+	}{
+		{
+			err:      NewAggregate([]error{fmt.Errorf("x: %w", errors.New("y")), fmt.Errorf("x: %w", errors.New("y"))}),
+			expected: "2 errors\n  - x (x2)\n    - y",
+		},
+		{
+			err:      NewAggregate([]error{errors.New("y"), &typedError{"y"}}),
+			expected: "2 errors\n  - y\n  - y [*errors.typedError]",
+		},
+		{
+			err:      fmt.Errorf("custom message %w here", errors.New("inner")),
+			expected: "custom message inner here\n  - inner",
+		},
+	} {
+		if e, a := test.expected, Tree(test.err).String(); e != a {
+			t.Errorf("%d: expected\n%s\ngot\n%s", i, e, a)
+		}
+	}
+
+	data, jsonErr := json.Marshal(Tree(NewAggregate([]error{errors.New("a"), errors.New("a")})))
+	if jsonErr != nil {
+		t.Fatal(jsonErr)
+	}
+	if e, a := `{"type":"errors.aggregate","count":1,"children":[{"message":"a","type":"*errors.errorString","count":2}]}`, string(data); e != a {
+		t.Errorf("expected %s, got %s", e, a)
+	}
+}
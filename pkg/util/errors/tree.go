@@ -0,0 +1,140 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package errors
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrorNode is a node of the tree of an error built by Tree, suitable for rendering as text
+// with String or for serializing as JSON.
+type ErrorNode struct {
+	// Message is the message of the error, without the messages of the errors it wraps for
+	// errors whose message ends with them, such as errors created by fmt.Errorf with %w.
+	// Aggregates have no message of their own.
+	Message string `json:"message,omitempty"`
+	// Type is the Go type of the error, indicating its origin.
+	Type string `json:"type"`
+	// Count is the number of identical errors represented by the node, at least 1.
+	Count int `json:"count"`
+	// Children are the errors of an aggregate, or the errors wrapped by an error.
+	Children []*ErrorNode `json:"children,omitempty"`
+}
+
+// Tree returns the tree of err, whose children are the errors of aggregates and the errors
+// wrapped by other errors, as returned by Unwrap. Identical sibling errors are represented by a
+// single node counting them. Tree returns nil if err is nil.
+func Tree(err error) *ErrorNode {
+	if err == nil {
+		return nil
+	}
+	node := &ErrorNode{Type: fmt.Sprintf("%T", err), Count: 1}
+
+	var children []error
+	if agg, ok := err.(Aggregate); ok {
+		children = agg.Errors()
+	} else {
+		node.Message = err.Error()
+		switch wrapper := err.(type) {
+		case interface{ Unwrap() []error }:
+			children = wrapper.Unwrap()
+		default:
+			if wrapped := errors.Unwrap(err); wrapped != nil {
+				children = []error{wrapped}
+				// the message of errors like fmt.Errorf("...: %w", err) repeats the wrapped one.
+				if suffix := wrapped.Error(); len(suffix) < len(node.Message) && strings.HasSuffix(node.Message, suffix) {
+					node.Message = strings.TrimRight(strings.TrimSuffix(node.Message, suffix), ": ")
+				}
+			}
+		}
+	}
+
+	index := map[string]*ErrorNode{}
+	for _, child := range children {
+		childNode := Tree(child)
+		if childNode == nil {
+			continue
+		}
+		key := childNode.key()
+		if existing, ok := index[key]; ok {
+			existing.Count += childNode.Count
+			continue
+		}
+		index[key] = childNode
+		node.Children = append(node.Children, childNode)
+	}
+	return node
+}
+
+// key identifies identical trees.
+func (n *ErrorNode) key() string {
+	var b strings.Builder
+	b.WriteString(n.Type + "\x00" + n.Message)
+	for _, child := range n.Children {
+		fmt.Fprintf(&b, "\x00%d\x00%s", child.Count, child.key())
+	}
+	return b.String()
+}
+
+// plainErrorTypes are the types of errors that only carry messages, whose type is not rendered.
+var plainErrorTypes = map[string]bool{
+	"*errors.errorString": true,
+	"*fmt.wrapError":      true,
+	"*fmt.wrapErrors":     true,
+	"errors.aggregate":    true,
+}
+
+// String renders the tree as indented text, with a line per node. Nodes representing
+// several errors are suffixed with their count, errors of types carrying more than a
+// message with their type, and aggregates are summarized with the number of errors
+// they contain.
+func (n *ErrorNode) String() string {
+	if n == nil {
+		return ""
+	}
+	var b strings.Builder
+	n.render(&b, "")
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+func (n *ErrorNode) render(b *strings.Builder, indent string) {
+	b.WriteString(indent)
+	if len(indent) > 0 {
+		b.WriteString("- ")
+	}
+	if len(n.Message) > 0 {
+		b.WriteString(n.Message)
+	} else {
+		total := 0
+		for _, child := range n.Children {
+			total += child.Count
+		}
+		fmt.Fprintf(b, "%d errors", total)
+	}
+	if !plainErrorTypes[n.Type] {
+		fmt.Fprintf(b, " [%s]", n.Type)
+	}
+	if n.Count > 1 {
+		fmt.Fprintf(b, " (x%d)", n.Count)
+	}
+	b.WriteString("\n")
+	for _, child := range n.Children {
+		child.render(b, indent+"  ")
+	}
+}
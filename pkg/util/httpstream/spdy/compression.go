@@ -0,0 +1,129 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spdy
+
+import (
+	"compress/flate"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/util/httpstream"
+)
+
+const (
+	// HeaderStreamCompression is sent with upgrade requests to offer compression of stream
+	// data, echoed by servers accepting it, and sent with the headers of streams to indicate
+	// whether the data of the stream is compressed.
+	HeaderStreamCompression = "X-Stream-Compression"
+	// StreamCompressionDeflate compresses the data of streams in both directions with DEFLATE.
+	StreamCompressionDeflate = "deflate"
+	// StreamCompressionNone disables compression of a stream of a connection that negotiated
+	// compression, e.g. because its payload is already compressed.
+	StreamCompressionNone = "none"
+)
+
+// CompressionOptions configures the compression of the data of streams. Compression is only
+// used if both sides of a connection enable it, and applies to the streams created by the
+// client without a HeaderStreamCompression header of StreamCompressionNone.
+type CompressionOptions struct {
+	// Level is the compress/flate level used to compress written data, trading CPU for
+	// compression ratio, from flate.BestSpeed to flate.BestCompression, or flate.HuffmanOnly.
+	// If zero, flate.DefaultCompression is used.
+	Level int
+}
+
+func (o *CompressionOptions) level() int {
+	if o.Level == 0 {
+		return flate.DefaultCompression
+	}
+	return o.Level
+}
+
+func (o *CompressionOptions) validate() error {
+	if o == nil {
+		return nil
+	}
+	if l := o.level(); l != flate.HuffmanOnly && (l < flate.DefaultCompression || l > flate.BestCompression) {
+		return fmt.Errorf("invalid compression level %d", o.Level)
+	}
+	return nil
+}
+
+// compressionOffered returns true if header offers or accepts StreamCompressionDeflate.
+func compressionOffered(header http.Header) bool {
+	for _, value := range header.Values(HeaderStreamCompression) {
+		for _, v := range strings.Split(value, ",") {
+			if strings.EqualFold(strings.TrimSpace(v), StreamCompressionDeflate) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// compressedStream compresses the data written to a stream, and decompresses the data read
+// from it. Writes are flushed so that interactive data is not delayed.
+type compressedStream struct {
+	httpstream.Stream
+
+	writeLock sync.Mutex
+	writer    *flate.Writer
+
+	readOnce sync.Once
+	reader   io.ReadCloser
+}
+
+func newCompressedStream(s httpstream.Stream, opts *CompressionOptions) (*compressedStream, error) {
+	writer, err := flate.NewWriter(s, opts.level())
+	if err != nil {
+		return nil, err
+	}
+	return &compressedStream{Stream: s, writer: writer}, nil
+}
+
+func (s *compressedStream) Read(p []byte) (int, error) {
+	s.readOnce.Do(func() {
+		s.reader = flate.NewReader(s.Stream)
+	})
+	return s.reader.Read(p)
+}
+
+func (s *compressedStream) Write(p []byte) (int, error) {
+	s.writeLock.Lock()
+	defer s.writeLock.Unlock()
+	if _, err := s.writer.Write(p); err != nil {
+		return 0, err
+	}
+	if err := s.writer.Flush(); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Close writes the end of the compressed data and closes the stream for writing.
+func (s *compressedStream) Close() error {
+	s.writeLock.Lock()
+	err := s.writer.Close()
+	s.writeLock.Unlock()
+	if closeErr := s.Stream.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
@@ -0,0 +1,174 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spdy
+
+import (
+	"bytes"
+	"compress/flate"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/util/httpstream"
+)
+
+// compressionEchoServer echoes the data of two streams, reporting their compression header.
+func compressionEchoServer(t *testing.T, compression *CompressionOptions, headers chan<- string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		streamCh := make(chan httpstream.Stream)
+		upgrader, err := NewResponseUpgraderWithCompression(0, compression)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		conn := upgrader.UpgradeResponse(w, req, func(s httpstream.Stream, replySent <-chan struct{}) error {
+			streamCh <- s
+			return nil
+		})
+		if conn == nil {
+			t.Error("unexpected nil connection")
+			return
+		}
+		defer conn.Close()
+
+		for i := 0; i < 2; i++ {
+			stream := <-streamCh
+			headers <- stream.Headers().Get(HeaderStreamCompression)
+			go func() {
+				io.Copy(stream, stream)
+				stream.Close()
+			}()
+		}
+		<-conn.CloseChan()
+	}))
+}
+
+func testCompressedEcho(t *testing.T, serverCompression *CompressionOptions, expectedHeaders []string) {
+	headers := make(chan string, 2)
+	server := compressionEchoServer(t, serverCompression, headers)
+	defer server.Close()
+
+	req, err := http.NewRequest("GET", server.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rt := NewRoundTripperWithConfig(RoundTripperConfig{
+		Compression: &CompressionOptions{Level: flate.BestSpeed},
+		Proxier:     func(*http.Request) (*url.URL, error) { return nil, nil },
+	})
+	resp, err := rt.RoundTrip(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn, err := rt.NewConnection(resp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	payload := []byte(strings.Repeat("some highly compressible log output\n", 1000))
+	for i, value := range []string{"", StreamCompressionNone} {
+		streamHeaders := http.Header{}
+		if len(value) > 0 {
+			streamHeaders.Set(HeaderStreamCompression, value)
+		}
+		stream, err := conn.CreateStream(streamHeaders)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if e, a := expectedHeaders[i], <-headers; e != a {
+			t.Errorf("stream %d: expected compression header %q, got %q", i, e, a)
+		}
+		if _, err := stream.Write(payload); err != nil {
+			t.Fatal(err)
+		}
+		if err := stream.Close(); err != nil {
+			t.Fatal(err)
+		}
+		echoed, err := ioutil.ReadAll(stream)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(payload, echoed) {
+			t.Errorf("stream %d: expected the payload to be echoed, got %d bytes", i, len(echoed))
+		}
+	}
+}
+
+func TestCompressionNegotiated(t *testing.T) {
+	testCompressedEcho(t, &CompressionOptions{}, []string{StreamCompressionDeflate, StreamCompressionNone})
+}
+
+func TestCompressionDeclined(t *testing.T) {
+	testCompressedEcho(t, nil, []string{"", StreamCompressionNone})
+}
+
+type bufferStream struct {
+	httpstream.Stream
+	buf bytes.Buffer
+}
+
+func (s *bufferStream) Read(p []byte) (int, error)  { return s.buf.Read(p) }
+func (s *bufferStream) Write(p []byte) (int, error) { return s.buf.Write(p) }
+func (s *bufferStream) Close() error                { return nil }
+
+func TestCompressedStream(t *testing.T) {
+	payload := []byte(strings.Repeat("a", 100000))
+	underlying := &bufferStream{}
+	stream, err := newCompressedStream(underlying, &CompressionOptions{Level: flate.BestCompression})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n, err := stream.Write(payload[:10]); err != nil || n != 10 {
+		t.Fatalf("unexpected write result %d %v", n, err)
+	}
+	// writes are flushed, so the data can be read before the stream is closed.
+	partial := make([]byte, 10)
+	if _, err := io.ReadFull(flate.NewReader(bytes.NewReader(underlying.buf.Bytes())), partial); err != nil {
+		t.Fatalf("expected flushed data to be readable: %v", err)
+	}
+	if _, err := stream.Write(payload[10:]); err != nil {
+		t.Fatal(err)
+	}
+	if err := stream.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if underlying.buf.Len() > len(payload)/100 {
+		t.Errorf("expected the payload to be compressed, got %d bytes", underlying.buf.Len())
+	}
+	data, err := ioutil.ReadAll(stream)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(payload, data) {
+		t.Errorf("expected the payload to be decompressed, got %d bytes", len(data))
+	}
+
+	if _, err := newCompressedStream(underlying, &CompressionOptions{Level: 10}); err == nil {
+		t.Errorf("expected an error for an invalid level")
+	}
+	if err := (&CompressionOptions{Level: 10}).validate(); err == nil {
+		t.Errorf("expected an error validating an invalid level")
+	}
+	if _, err := NewResponseUpgraderWithCompression(0, &CompressionOptions{Level: 10}); err == nil {
+		t.Errorf("expected an error creating an upgrader with an invalid level")
+	}
+}
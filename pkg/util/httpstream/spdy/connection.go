@@ -35,6 +35,8 @@ type connection struct {
 	streamLock       sync.Mutex
 	newStreamHandler httpstream.NewStreamHandler
 	ping             func() (time.Duration, error)
+	// compression is used for streams requesting it, if not nil.
+	compression *CompressionOptions
 }
 
 // NewClientConnection creates a new SPDY client connection.
@@ -81,15 +83,63 @@ func NewServerConnectionWithPings(conn net.Conn, newStreamHandler httpstream.New
 	return newConnection(spdyConn, newStreamHandler, pingPeriod, spdyConn.Ping), nil
 }
 
+// NewClientConnectionWithCompression creates a new SPDY client connection
+// compressing the data of its streams, which must have been negotiated with
+// the server by sending and receiving a HeaderStreamCompression header while
+// upgrading the connection. Streams created with a HeaderStreamCompression
+// header of StreamCompressionNone are not compressed.
+//
+// If pingPeriod is non-zero, a background goroutine will send periodic Ping
+// frames to the server.
+func NewClientConnectionWithCompression(conn net.Conn, pingPeriod time.Duration, compression *CompressionOptions) (httpstream.Connection, error) {
+	if err := compression.validate(); err != nil {
+		defer conn.Close()
+		return nil, err
+	}
+	spdyConn, err := spdystream.NewConnection(conn, false)
+	if err != nil {
+		defer conn.Close()
+		return nil, err
+	}
+
+	return newConnectionWithCompression(spdyConn, httpstream.NoOpNewStreamHandler, pingPeriod, spdyConn.Ping, compression), nil
+}
+
+// NewServerConnectionWithCompression creates a new SPDY server connection
+// decompressing and compressing the data of the streams created by the client
+// with a HeaderStreamCompression header of StreamCompressionDeflate.
+// newStreamHandler will be invoked with the decompressing stream.
+//
+// If pingPeriod is non-zero, a background goroutine will send periodic Ping
+// frames to the client.
+func NewServerConnectionWithCompression(conn net.Conn, newStreamHandler httpstream.NewStreamHandler, pingPeriod time.Duration, compression *CompressionOptions) (httpstream.Connection, error) {
+	if err := compression.validate(); err != nil {
+		defer conn.Close()
+		return nil, err
+	}
+	spdyConn, err := spdystream.NewConnection(conn, true)
+	if err != nil {
+		defer conn.Close()
+		return nil, err
+	}
+
+	return newConnectionWithCompression(spdyConn, newStreamHandler, pingPeriod, spdyConn.Ping, compression), nil
+}
+
 // newConnection returns a new connection wrapping conn. newStreamHandler
 // will be invoked when the server receives a newly created stream from the
 // client.
 func newConnection(conn *spdystream.Connection, newStreamHandler httpstream.NewStreamHandler, pingPeriod time.Duration, pingFn func() (time.Duration, error)) httpstream.Connection {
+	return newConnectionWithCompression(conn, newStreamHandler, pingPeriod, pingFn, nil)
+}
+
+func newConnectionWithCompression(conn *spdystream.Connection, newStreamHandler httpstream.NewStreamHandler, pingPeriod time.Duration, pingFn func() (time.Duration, error), compression *CompressionOptions) httpstream.Connection {
 	c := &connection{
 		conn:             conn,
 		newStreamHandler: newStreamHandler,
 		ping:             pingFn,
 		streams:          make(map[uint32]httpstream.Stream),
+		compression:      compression,
 	}
 	go conn.Serve(c.newSpdyStream)
 	if pingPeriod > 0 && pingFn != nil {
@@ -134,14 +184,26 @@ func (c *connection) RemoveStreams(streams ...httpstream.Stream) {
 // CreateStream creates a new stream with the specified headers and registers
 // it with the connection.
 func (c *connection) CreateStream(headers http.Header) (httpstream.Stream, error) {
-	stream, err := c.conn.CreateStream(headers, nil, false)
+	compress := c.compression != nil && headers.Get(HeaderStreamCompression) != StreamCompressionNone
+	if compress {
+		headers = headers.Clone()
+		headers.Set(HeaderStreamCompression, StreamCompressionDeflate)
+	}
+	spdyStream, err := c.conn.CreateStream(headers, nil, false)
 	if err != nil {
 		return nil, err
 	}
-	if err = stream.WaitTimeout(createStreamResponseTimeout); err != nil {
+	if err = spdyStream.WaitTimeout(createStreamResponseTimeout); err != nil {
 		return nil, err
 	}
 
+	var stream httpstream.Stream = spdyStream
+	if compress {
+		if stream, err = newCompressedStream(spdyStream, c.compression); err != nil {
+			spdyStream.Reset()
+			return nil, err
+		}
+	}
 	c.registerStream(stream)
 	return stream, nil
 }
@@ -164,7 +226,18 @@ func (c *connection) CloseChan() <-chan bool {
 // It calls connection's newStreamHandler, giving it the opportunity to accept or reject
 // the stream. If newStreamHandler returns an error, the stream is rejected. If not, the
 // stream is accepted and registered with the connection.
-func (c *connection) newSpdyStream(stream *spdystream.Stream) {
+func (c *connection) newSpdyStream(spdyStream *spdystream.Stream) {
+	var stream httpstream.Stream = spdyStream
+	if c.compression != nil && spdyStream.Headers().Get(HeaderStreamCompression) == StreamCompressionDeflate {
+		compressed, err := newCompressedStream(spdyStream, c.compression)
+		if err != nil {
+			klog.Warningf("Stream rejected: %v", err)
+			spdyStream.Reset()
+			return
+		}
+		stream = compressed
+	}
+
 	replySent := make(chan struct{})
 	err := c.newStreamHandler(stream, replySent)
 	rejectStream := (err != nil)
@@ -175,7 +248,7 @@ func (c *connection) newSpdyStream(stream *spdystream.Stream) {
 	}
 
 	c.registerStream(stream)
-	stream.SendReply(http.Header{}, rejectStream)
+	spdyStream.SendReply(http.Header{}, rejectStream)
 	close(replySent)
 }
 
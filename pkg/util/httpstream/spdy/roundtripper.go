@@ -76,6 +76,8 @@ type SpdyRoundTripper struct {
 	// pingPeriod is a period for sending Ping frames over established
 	// connections.
 	pingPeriod time.Duration
+	// compression is offered to the server if not nil.
+	compression *CompressionOptions
 }
 
 var _ utilnet.TLSClientConfigHolder = &SpdyRoundTripper{}
@@ -115,6 +117,7 @@ func NewRoundTripperWithConfig(cfg RoundTripperConfig) *SpdyRoundTripper {
 		requireSameHostRedirects: cfg.RequireSameHostRedirects,
 		proxier:                  cfg.Proxier,
 		pingPeriod:               cfg.PingPeriod,
+		compression:              cfg.Compression,
 	}
 }
 
@@ -127,6 +130,9 @@ type RoundTripperConfig struct {
 	// PingPeriod is a period for sending SPDY Pings on the connection.
 	// Optional.
 	PingPeriod time.Duration
+	// Compression, if specified, is offered to the server to compress the data
+	// of streams. It is only used if the server accepts it. Optional.
+	Compression *CompressionOptions
 
 	FollowRedirects          bool
 	RequireSameHostRedirects bool
@@ -358,6 +364,9 @@ func (s *SpdyRoundTripper) RoundTrip(req *http.Request) (*http.Response, error)
 	header := utilnet.CloneHeader(req.Header)
 	header.Add(httpstream.HeaderConnection, httpstream.HeaderUpgrade)
 	header.Add(httpstream.HeaderUpgrade, HeaderSpdy31)
+	if s.compression != nil {
+		header.Set(HeaderStreamCompression, StreamCompressionDeflate)
+	}
 
 	var (
 		conn        net.Conn
@@ -421,6 +430,9 @@ func (s *SpdyRoundTripper) NewConnection(resp *http.Response) (httpstream.Connec
 		return nil, fmt.Errorf("unable to upgrade connection: %s", responseError)
 	}
 
+	if s.compression != nil && compressionOffered(resp.Header) {
+		return NewClientConnectionWithCompression(s.conn, s.pingPeriod, s.compression)
+	}
 	return NewClientConnectionWithPings(s.conn, s.pingPeriod)
 }
 
@@ -35,7 +35,8 @@ const HeaderSpdy31 = "SPDY/3.1"
 // responseUpgrader knows how to upgrade HTTP responses. It
 // implements the httpstream.ResponseUpgrader interface.
 type responseUpgrader struct {
-	pingPeriod time.Duration
+	pingPeriod  time.Duration
+	compression *CompressionOptions
 }
 
 // connWrapper is used to wrap a hijacked connection and its bufio.Reader. All
@@ -80,6 +81,20 @@ func NewResponseUpgraderWithPings(pingPeriod time.Duration) httpstream.ResponseU
 	return responseUpgrader{pingPeriod: pingPeriod}
 }
 
+// NewResponseUpgraderWithCompression returns a new httpstream.ResponseUpgrader
+// that accepts offers of clients to compress the data of streams. If compression
+// is nil, offers are declined. An error is returned if the compression level is
+// invalid.
+//
+// If pingPeriod is non-zero, for each incoming connection a background
+// goroutine will send periodic Ping frames to the client.
+func NewResponseUpgraderWithCompression(pingPeriod time.Duration, compression *CompressionOptions) (httpstream.ResponseUpgrader, error) {
+	if err := compression.validate(); err != nil {
+		return nil, err
+	}
+	return responseUpgrader{pingPeriod: pingPeriod, compression: compression}, nil
+}
+
 // UpgradeResponse upgrades an HTTP response to one that supports multiplexed
 // streams. newStreamHandler will be called synchronously whenever the
 // other end of the upgraded connection creates a new stream.
@@ -101,6 +116,11 @@ func (u responseUpgrader) UpgradeResponse(w http.ResponseWriter, req *http.Reque
 
 	w.Header().Add(httpstream.HeaderConnection, httpstream.HeaderUpgrade)
 	w.Header().Add(httpstream.HeaderUpgrade, HeaderSpdy31)
+	var compression *CompressionOptions
+	if u.compression != nil && compressionOffered(req.Header) {
+		compression = u.compression
+		w.Header().Set(HeaderStreamCompression, StreamCompressionDeflate)
+	}
 	w.WriteHeader(http.StatusSwitchingProtocols)
 
 	conn, bufrw, err := hijacker.Hijack()
@@ -110,7 +130,7 @@ func (u responseUpgrader) UpgradeResponse(w http.ResponseWriter, req *http.Reque
 	}
 
 	connWithBuf := &connWrapper{Conn: conn, bufReader: bufrw.Reader}
-	spdyConn, err := NewServerConnectionWithPings(connWithBuf, newStreamHandler, u.pingPeriod)
+	spdyConn, err := NewServerConnectionWithCompression(connWithBuf, newStreamHandler, u.pingPeriod, compression)
 	if err != nil {
 		runtime.HandleError(fmt.Errorf("unable to upgrade: error creating SPDY server connection: %v", err))
 		return nil
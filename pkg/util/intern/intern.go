@@ -0,0 +1,91 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package intern deduplicates identical strings, so that caches holding the metadata of
+// many objects share a single copy of recurring label keys and values.
+package intern // import "k8s.io/apimachinery/pkg/util/intern"
+
+import "sync"
+
+// Interner returns a canonical instance for each distinct string it is given. Strings are
+// retained until Reset is called, so an Interner should only be used for values that are
+// expected to recur, like label keys and values. It is safe for concurrent use, and a nil
+// *Interner returns strings unchanged, so that interning can be made optional.
+type Interner struct {
+	lock    sync.RWMutex
+	strings map[string]string
+}
+
+// New returns an empty Interner.
+func New() *Interner {
+	return &Interner{strings: map[string]string{}}
+}
+
+// Intern returns the canonical instance of s.
+func (i *Interner) Intern(s string) string {
+	if i == nil || len(s) == 0 {
+		return s
+	}
+	i.lock.RLock()
+	canonical, ok := i.strings[s]
+	i.lock.RUnlock()
+	if ok {
+		return canonical
+	}
+
+	i.lock.Lock()
+	defer i.lock.Unlock()
+	if canonical, ok := i.strings[s]; ok {
+		return canonical
+	}
+	if i.strings == nil {
+		i.strings = map[string]string{}
+	}
+	i.strings[s] = s
+	return s
+}
+
+// InternAll replaces each element of items with its canonical instance and returns items.
+func (i *Interner) InternAll(items []string) []string {
+	if i == nil {
+		return items
+	}
+	for j := range items {
+		items[j] = i.Intern(items[j])
+	}
+	return items
+}
+
+// Len returns the number of distinct strings retained by the Interner.
+func (i *Interner) Len() int {
+	if i == nil {
+		return 0
+	}
+	i.lock.RLock()
+	defer i.lock.RUnlock()
+	return len(i.strings)
+}
+
+// Reset releases all strings retained by the Interner. Strings interned before and after a
+// Reset are no longer deduplicated against each other.
+func (i *Interner) Reset() {
+	if i == nil {
+		return
+	}
+	i.lock.Lock()
+	defer i.lock.Unlock()
+	i.strings = map[string]string{}
+}
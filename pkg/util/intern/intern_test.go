@@ -0,0 +1,94 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package intern
+
+import (
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"unsafe"
+)
+
+func dataPointer(s string) uintptr {
+	return (*reflect.StringHeader)(unsafe.Pointer(&s)).Data
+}
+
+func TestIntern(t *testing.T) {
+	interner := New()
+	first := strings.Repeat("app", 2)
+	second := strings.Repeat("app", 2)
+	if dataPointer(first) == dataPointer(second) {
+		t.Fatalf("expected distinct strings")
+	}
+
+	if interned := interner.Intern(first); dataPointer(interned) != dataPointer(first) {
+		t.Errorf("expected the first string to become canonical")
+	}
+	if interned := interner.Intern(second); interned != second || dataPointer(interned) != dataPointer(first) {
+		t.Errorf("expected the canonical instance for an equal string")
+	}
+	if interner.Len() != 1 {
+		t.Errorf("expected 1 string, got %d", interner.Len())
+	}
+
+	items := interner.InternAll([]string{strings.Repeat("app", 2), "web"})
+	if dataPointer(items[0]) != dataPointer(first) || items[1] != "web" || interner.Len() != 2 {
+		t.Errorf("unexpected interned items %v", items)
+	}
+
+	interner.Reset()
+	if interner.Len() != 0 {
+		t.Errorf("expected no strings after reset, got %d", interner.Len())
+	}
+	if interned := interner.Intern(second); dataPointer(interned) != dataPointer(second) {
+		t.Errorf("expected strings interned after a reset to become canonical")
+	}
+}
+
+func TestNilAndZeroInterner(t *testing.T) {
+	var nilInterner *Interner
+	s := strings.Repeat("a", 3)
+	if interned := nilInterner.Intern(s); dataPointer(interned) != dataPointer(s) || nilInterner.Len() != 0 {
+		t.Errorf("expected a nil interner to return strings unchanged")
+	}
+	nilInterner.Reset()
+
+	var zero Interner
+	if zero.Intern(s) != s || zero.Len() != 1 {
+		t.Errorf("expected the zero interner to be usable")
+	}
+}
+
+func TestInternConcurrently(t *testing.T) {
+	interner := New()
+	var wg sync.WaitGroup
+	results := make([]string, 10)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i] = interner.Intern(strings.Repeat("x", 4))
+		}(i)
+	}
+	wg.Wait()
+	for _, result := range results {
+		if dataPointer(result) != dataPointer(results[0]) {
+			t.Errorf("expected all goroutines to get the canonical instance")
+		}
+	}
+}
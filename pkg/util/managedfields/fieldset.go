@@ -0,0 +1,229 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package managedfields
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+
+	"sigs.k8s.io/structured-merge-diff/v4/fieldpath"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// FieldPath is the path to a field, as a sequence of the path elements used as keys in FieldsV1:
+// "f:<name>" for struct fields and map keys, "k:<keys>" for list items identified by their keys,
+// "v:<value>" for set items and "i:<index>" for list items identified by their index.
+type FieldPath []string
+
+// String returns the path in a human readable form, e.g. `.spec.containers[name="nginx"].image`.
+// Invalid paths are returned in their serialized form.
+func (p FieldPath) String() string {
+	path, err := p.toPath()
+	if err != nil {
+		return fmt.Sprintf("%q", []string(p))
+	}
+	return path.String()
+}
+
+func (p FieldPath) toPath() (fieldpath.Path, error) {
+	path := make(fieldpath.Path, 0, len(p))
+	for _, element := range p {
+		pe, err := fieldpath.DeserializePathElement(element)
+		if err != nil {
+			return nil, fmt.Errorf("invalid path element %q: %w", element, err)
+		}
+		path = append(path, pe)
+	}
+	return path, nil
+}
+
+func fromPath(path fieldpath.Path) (FieldPath, error) {
+	p := make(FieldPath, 0, len(path))
+	for _, pe := range path {
+		element, err := fieldpath.SerializePathElement(pe)
+		if err != nil {
+			return nil, err
+		}
+		p = append(p, element)
+	}
+	return p, nil
+}
+
+// FieldSet is a set of field paths, like the fields recorded for a manager in the managed fields of an
+// object. It allows to query and combine managed fields without handling their serialized form.
+// The zero value is an empty set. FieldSets are not safe for concurrent modification.
+type FieldSet struct {
+	set *fieldpath.Set
+}
+
+// NewFieldSet returns a FieldSet containing paths.
+func NewFieldSet(paths ...FieldPath) (*FieldSet, error) {
+	s := &FieldSet{set: fieldpath.NewSet()}
+	for _, p := range paths {
+		if err := s.Insert(p); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// FieldSetFromFieldsV1 parses the fields of a managed fields entry into a FieldSet.
+func FieldSetFromFieldsV1(fields metav1.FieldsV1) (*FieldSet, error) {
+	set := &fieldpath.Set{}
+	if len(fields.Raw) > 0 {
+		if err := set.FromJSON(bytes.NewReader(fields.Raw)); err != nil {
+			return nil, fmt.Errorf("error parsing fields: %w", err)
+		}
+	}
+	return &FieldSet{set: set}, nil
+}
+
+// ManagedFieldSet returns the fields of obj managed by manager for subresource, combined across all
+// operations. Subresource is empty for the fields of the main resource. The returned set is empty
+// if manager does not manage any fields of obj.
+func ManagedFieldSet(obj metav1.Object, manager string, subresource string) (*FieldSet, error) {
+	result := &FieldSet{set: fieldpath.NewSet()}
+	for _, entry := range obj.GetManagedFields() {
+		if entry.Manager != manager || entry.Subresource != subresource || entry.FieldsV1 == nil {
+			continue
+		}
+		s, err := FieldSetFromFieldsV1(*entry.FieldsV1)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing fields of manager %q: %w", manager, err)
+		}
+		result = result.Union(s)
+	}
+	return result, nil
+}
+
+// ToFieldsV1 serializes the set in the form used by managed fields entries.
+func (s *FieldSet) ToFieldsV1() (metav1.FieldsV1, error) {
+	data, err := s.fieldSet().ToJSON()
+	if err != nil {
+		return metav1.FieldsV1{}, err
+	}
+	return metav1.FieldsV1{Raw: data}, nil
+}
+
+func (s *FieldSet) fieldSet() *fieldpath.Set {
+	if s == nil || s.set == nil {
+		return fieldpath.NewSet()
+	}
+	return s.set
+}
+
+// Insert adds path to the set.
+func (s *FieldSet) Insert(path FieldPath) error {
+	p, err := path.toPath()
+	if err != nil {
+		return err
+	}
+	if s.set == nil {
+		s.set = fieldpath.NewSet()
+	}
+	s.set.Insert(p)
+	return nil
+}
+
+// Has returns true if path is a member of the set. Invalid paths are never members.
+func (s *FieldSet) Has(path FieldPath) bool {
+	p, err := path.toPath()
+	if err != nil {
+		return false
+	}
+	return s.fieldSet().Has(p)
+}
+
+// Child returns the paths of the set that begin with element, with element removed. This allows to
+// traverse the set, e.g. s.Child("f:metadata") holds the paths below metadata.
+func (s *FieldSet) Child(element string) (*FieldSet, error) {
+	pe, err := fieldpath.DeserializePathElement(element)
+	if err != nil {
+		return nil, fmt.Errorf("invalid path element %q: %w", element, err)
+	}
+	return &FieldSet{set: s.fieldSet().WithPrefix(pe)}, nil
+}
+
+// Union returns a set containing the paths of both s and other.
+func (s *FieldSet) Union(other *FieldSet) *FieldSet {
+	return &FieldSet{set: s.fieldSet().Union(other.fieldSet())}
+}
+
+// Intersection returns a set containing the paths that are members of both s and other, e.g. the
+// fields owned by one manager that are also set by another.
+func (s *FieldSet) Intersection(other *FieldSet) *FieldSet {
+	return &FieldSet{set: s.fieldSet().Intersection(other.fieldSet())}
+}
+
+// Difference returns a set containing the paths of s that are not members of other. Paths below a
+// member of other remain in the result, see RecursiveDifference.
+func (s *FieldSet) Difference(other *FieldSet) *FieldSet {
+	return &FieldSet{set: s.fieldSet().Difference(other.fieldSet())}
+}
+
+// RecursiveDifference returns a set containing the paths of s that are neither members of other,
+// nor below a member of other.
+func (s *FieldSet) RecursiveDifference(other *FieldSet) *FieldSet {
+	return &FieldSet{set: s.fieldSet().RecursiveDifference(other.fieldSet())}
+}
+
+// Leaves returns a set containing only the paths of s that have no children in s.
+func (s *FieldSet) Leaves() *FieldSet {
+	return &FieldSet{set: s.fieldSet().Leaves()}
+}
+
+// Empty returns true if the set has no members.
+func (s *FieldSet) Empty() bool {
+	return s.fieldSet().Empty()
+}
+
+// Len returns the number of paths in the set.
+func (s *FieldSet) Len() int {
+	return s.fieldSet().Size()
+}
+
+// Equal returns true if s and other have the same members.
+func (s *FieldSet) Equal(other *FieldSet) bool {
+	return s.fieldSet().Equals(other.fieldSet())
+}
+
+// Paths returns the members of the set in sorted order.
+func (s *FieldSet) Paths() []FieldPath {
+	var paths []fieldpath.Path
+	s.fieldSet().Iterate(func(p fieldpath.Path) {
+		paths = append(paths, p.Copy())
+	})
+	sort.Slice(paths, func(i, j int) bool { return paths[i].Compare(paths[j]) < 0 })
+
+	result := make([]FieldPath, 0, len(paths))
+	for _, p := range paths {
+		fp, err := fromPath(p)
+		if err != nil {
+			// paths of a set consist of path elements that were deserialized before
+			panic(fmt.Sprintf("unable to serialize path %v: %v", p, err))
+		}
+		result = append(result, fp)
+	}
+	return result
+}
+
+// String returns the members of the set in human readable form, one per line.
+func (s *FieldSet) String() string {
+	return s.fieldSet().String()
+}
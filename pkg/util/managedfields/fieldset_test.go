@@ -0,0 +1,120 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package managedfields
+
+import (
+	"reflect"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestFieldSet(t *testing.T) {
+	applier := &metav1.FieldsV1{Raw: []byte(`{"f:metadata":{"f:labels":{".":{},"f:app":{}}},"f:spec":{"f:containers":{"k:{\"name\":\"nginx\"}":{".":{},"f:image":{},"f:name":{}}}}}`)}
+	updater := &metav1.FieldsV1{Raw: []byte(`{"f:metadata":{"f:labels":{"f:app":{},"f:tier":{}}},"f:spec":{"f:replicas":{}}}`)}
+	obj := &metav1.ObjectMeta{ManagedFields: []metav1.ManagedFieldsEntry{
+		{Manager: "a", Operation: metav1.ManagedFieldsOperationApply, FieldsV1: applier},
+		{Manager: "b", Operation: metav1.ManagedFieldsOperationUpdate, FieldsV1: updater},
+		{Manager: "b", Operation: metav1.ManagedFieldsOperationUpdate, Subresource: "status", FieldsV1: &metav1.FieldsV1{Raw: []byte(`{"f:status":{}}`)}},
+	}}
+
+	a, err := ManagedFieldSet(obj, "a", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := ManagedFieldSet(obj, "b", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	shared := a.Intersection(b)
+	if e, a := []FieldPath{{"f:metadata", "f:labels", "f:app"}}, shared.Paths(); !reflect.DeepEqual(e, a) {
+		t.Errorf("expected shared fields %v, got %v", e, a)
+	}
+	if e, a := `.metadata.labels.app`, shared.Paths()[0].String(); e != a {
+		t.Errorf("expected %q, got %q", e, a)
+	}
+
+	onlyA := a.Difference(b)
+	if onlyA.Has(FieldPath{"f:metadata", "f:labels", "f:app"}) || !onlyA.Has(FieldPath{"f:spec", "f:containers", `k:{"name":"nginx"}`, "f:image"}) {
+		t.Errorf("unexpected difference %v", onlyA)
+	}
+	if e, a := 4, onlyA.Len(); e != a {
+		t.Errorf("expected %d fields, got %d: %v", e, a, onlyA)
+	}
+
+	containers, err := a.Child("f:spec")
+	if err != nil {
+		t.Fatal(err)
+	}
+	containers, err = containers.Child("f:containers")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e, a := []FieldPath{{`k:{"name":"nginx"}`}, {`k:{"name":"nginx"}`, "f:image"}, {`k:{"name":"nginx"}`, "f:name"}}, containers.Paths(); !reflect.DeepEqual(e, a) {
+		t.Errorf("expected %v, got %v", e, a)
+	}
+
+	status, err := ManagedFieldSet(obj, "b", "status")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !status.Has(FieldPath{"f:status"}) || status.Len() != 1 {
+		t.Errorf("expected only the status fields, got %v", status)
+	}
+	if none, err := ManagedFieldSet(obj, "c", ""); err != nil || !none.Empty() {
+		t.Errorf("expected no fields for an unknown manager, got %v, %v", none, err)
+	}
+}
+
+func TestFieldSetRoundTrip(t *testing.T) {
+	s, err := NewFieldSet(
+		FieldPath{"f:metadata", "f:annotations", "f:example.com/key"},
+		FieldPath{"f:spec", "f:ports", `k:{"port":80,"protocol":"TCP"}`},
+		FieldPath{"f:spec", "f:finalizers", `v:"foo"`},
+		FieldPath{"f:spec", "f:args", "i:0"},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fields, err := s.ToFieldsV1()
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := FieldSetFromFieldsV1(fields)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !s.Equal(parsed) {
+		t.Errorf("expected round trip to preserve the set, got\n%v\nexpected\n%v", parsed, s)
+	}
+
+	if _, err := NewFieldSet(FieldPath{"x:invalid"}); err == nil {
+		t.Errorf("expected invalid path elements to be rejected")
+	}
+	if _, err := FieldSetFromFieldsV1(metav1.FieldsV1{Raw: []byte(`{"f:a":`)}); err == nil {
+		t.Errorf("expected invalid fields to be rejected")
+	}
+
+	var zero FieldSet
+	if !zero.Empty() || zero.Has(FieldPath{"f:a"}) || !zero.Union(s).Equal(s) {
+		t.Errorf("expected the zero value to be an empty set")
+	}
+	if err := zero.Insert(FieldPath{"f:a"}); err != nil || !zero.Has(FieldPath{"f:a"}) {
+		t.Errorf("expected insert into the zero value to succeed, got %v", err)
+	}
+}
@@ -130,18 +130,86 @@ func SetOldTransportDefaults(t *http.Transport) *http.Transport {
 // SetTransportDefaults applies the defaults from http.DefaultTransport
 // for the Proxy, Dial, and TLSHandshakeTimeout fields if unset
 func SetTransportDefaults(t *http.Transport) *http.Transport {
+	return SetTransportDefaultsWithOptions(t, transportOptionsFromEnvironment())
+}
+
+// TransportOptions controls the defaults applied by SetTransportDefaultsWithOptions.
+// Zero values select the defaults of SetTransportDefaults, without the overrides
+// read from environment variables.
+type TransportOptions struct {
+	// DisableHTTP2 prevents the transport from being configured for HTTP/2.
+	DisableHTTP2 bool
+	// HTTP2ReadIdleTimeout is the timeout after which a health check is performed
+	// on HTTP/2 connections that received no frames. Defaults to 30 seconds if zero,
+	// the health check is disabled if negative.
+	HTTP2ReadIdleTimeout time.Duration
+	// HTTP2PingTimeout is the timeout after which HTTP/2 connections are closed if
+	// no response to a health check is received. Defaults to 15 seconds if zero.
+	HTTP2PingTimeout time.Duration
+	// MaxIdleConns is set on transports without a limit of idle connections if non-zero.
+	MaxIdleConns int
+	// MaxIdleConnsPerHost is set on transports without a limit of idle connections
+	// per host if non-zero.
+	MaxIdleConnsPerHost int
+	// IdleConnTimeout is set on transports without an idle connection timeout. Defaults
+	// to the timeout of http.DefaultTransport if zero.
+	IdleConnTimeout time.Duration
+	// TLSHandshakeTimeout is set on transports without a TLS handshake timeout. Defaults
+	// to the timeout of http.DefaultTransport if zero.
+	TLSHandshakeTimeout time.Duration
+	// Proxy is set on transports without a proxy function, or using http.ProxyFromEnvironment.
+	// Defaults to http.ProxyFromEnvironment with support for CIDRs in NO_PROXY if nil.
+	Proxy func(*http.Request) (*url.URL, error)
+}
+
+// SetTransportDefaultsWithOptions applies the defaults of opts to the unset fields
+// of t, and configures t for HTTP/2 unless disabled by opts or by the NextProtos of
+// its TLS configuration. Unlike SetTransportDefaults, it ignores environment variables.
+func SetTransportDefaultsWithOptions(t *http.Transport, opts TransportOptions) *http.Transport {
+	if opts.Proxy != nil && (t.Proxy == nil || isDefault(t.Proxy)) {
+		t.Proxy = opts.Proxy
+	}
+	if t.TLSHandshakeTimeout == 0 {
+		t.TLSHandshakeTimeout = opts.TLSHandshakeTimeout
+	}
+	if t.IdleConnTimeout == 0 {
+		t.IdleConnTimeout = opts.IdleConnTimeout
+	}
+	if t.MaxIdleConns == 0 {
+		t.MaxIdleConns = opts.MaxIdleConns
+	}
+	if t.MaxIdleConnsPerHost == 0 {
+		t.MaxIdleConnsPerHost = opts.MaxIdleConnsPerHost
+	}
 	t = SetOldTransportDefaults(t)
+
 	// Allow clients to disable http2 if needed.
-	if s := os.Getenv("DISABLE_HTTP2"); len(s) > 0 {
+	if opts.DisableHTTP2 {
 		klog.Info("HTTP2 has been explicitly disabled")
 	} else if allowsHTTP2(t) {
-		if err := configureHTTP2Transport(t); err != nil {
+		if err := configureHTTP2Transport(t, opts.HTTP2ReadIdleTimeout, opts.HTTP2PingTimeout); err != nil {
 			klog.Warningf("Transport failed http2 configuration: %v", err)
 		}
 	}
 	return t
 }
 
+// transportOptionsFromEnvironment returns the options of SetTransportDefaults, read from
+// the DISABLE_HTTP2, HTTP2_READ_IDLE_TIMEOUT_SECONDS and HTTP2_PING_TIMEOUT_SECONDS
+// environment variables.
+func transportOptionsFromEnvironment() TransportOptions {
+	opts := TransportOptions{
+		DisableHTTP2:         len(os.Getenv("DISABLE_HTTP2")) > 0,
+		HTTP2ReadIdleTimeout: time.Duration(readIdleTimeoutSeconds()) * time.Second,
+		HTTP2PingTimeout:     time.Duration(pingTimeoutSeconds()) * time.Second,
+	}
+	if opts.HTTP2ReadIdleTimeout == 0 {
+		// a read idle timeout of zero disables the health check.
+		opts.HTTP2ReadIdleTimeout = -1
+	}
+	return opts
+}
+
 func readIdleTimeoutSeconds() int {
 	ret := 30
 	// User can set the readIdleTimeout to 0 to disable the HTTP/2
@@ -172,7 +240,7 @@ func pingTimeoutSeconds() int {
 	return ret
 }
 
-func configureHTTP2Transport(t *http.Transport) error {
+func configureHTTP2Transport(t *http.Transport, readIdleTimeout, pingTimeout time.Duration) error {
 	t2, err := http2.ConfigureTransports(t)
 	if err != nil {
 		return err
@@ -185,8 +253,18 @@ func configureHTTP2Transport(t *http.Transport) error {
 	// by default, which caused
 	// https://github.com/kubernetes/client-go/issues/374 and
 	// https://github.com/kubernetes/kubernetes/issues/87615.
-	t2.ReadIdleTimeout = time.Duration(readIdleTimeoutSeconds()) * time.Second
-	t2.PingTimeout = time.Duration(pingTimeoutSeconds()) * time.Second
+	switch {
+	case readIdleTimeout < 0:
+		t2.ReadIdleTimeout = 0
+	case readIdleTimeout == 0:
+		t2.ReadIdleTimeout = 30 * time.Second
+	default:
+		t2.ReadIdleTimeout = readIdleTimeout
+	}
+	t2.PingTimeout = pingTimeout
+	if t2.PingTimeout <= 0 {
+		t2.PingTimeout = 15 * time.Second
+	}
 	return nil
 }
 
@@ -34,6 +34,7 @@ import (
 	"reflect"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -1110,6 +1111,52 @@ func TestPingTimeoutSeconds(t *testing.T) {
 	reset()
 }
 
+func TestSetTransportDefaultsWithOptions(t *testing.T) {
+	reset := setEnv("DISABLE_HTTP2", "true")
+	defer reset()
+
+	proxyURL, _ := url.Parse("http://proxy.example.com")
+	proxy := http.ProxyURL(proxyURL)
+	tr := SetTransportDefaultsWithOptions(&http.Transport{MaxIdleConnsPerHost: 5}, TransportOptions{
+		MaxIdleConns:        50,
+		MaxIdleConnsPerHost: 10,
+		TLSHandshakeTimeout: 3 * time.Second,
+		Proxy:               proxy,
+	})
+	if tr.MaxIdleConns != 50 || tr.MaxIdleConnsPerHost != 5 || tr.TLSHandshakeTimeout != 3*time.Second || tr.IdleConnTimeout != defaultTransport.IdleConnTimeout {
+		t.Errorf("unexpected transport defaults %#v", tr)
+	}
+	if u, err := tr.Proxy(&http.Request{URL: &url.URL{Scheme: "https", Host: "example.com"}}); err != nil || u.String() != proxyURL.String() {
+		t.Errorf("expected the proxy function of the options, got %v %v", u, err)
+	}
+	if tr.DialContext == nil {
+		t.Errorf("expected a default dialer")
+	}
+	if _, ok := tr.TLSNextProto["h2"]; !ok {
+		t.Errorf("expected HTTP/2 to be configured regardless of DISABLE_HTTP2")
+	}
+
+	tr = SetTransportDefaultsWithOptions(&http.Transport{}, TransportOptions{DisableHTTP2: true})
+	if _, ok := tr.TLSNextProto["h2"]; ok {
+		t.Errorf("expected HTTP/2 not to be configured")
+	}
+	if tr.Proxy == nil || tr.TLSHandshakeTimeout != defaultTransport.TLSHandshakeTimeout {
+		t.Errorf("expected the defaults of SetTransportDefaults, got %#v", tr)
+	}
+
+	if tr := SetTransportDefaults(&http.Transport{}); tr.TLSNextProto != nil {
+		t.Errorf("expected SetTransportDefaults to respect DISABLE_HTTP2")
+	}
+}
+
+func TestTransportOptionsFromEnvironment(t *testing.T) {
+	reset := setEnv("HTTP2_READ_IDLE_TIMEOUT_SECONDS", "0")
+	defer reset()
+	if opts := transportOptionsFromEnvironment(); opts.HTTP2ReadIdleTimeout >= 0 || opts.HTTP2PingTimeout != 15*time.Second {
+		t.Errorf("expected the health check to be disabled, got %#v", opts)
+	}
+}
+
 func Benchmark_ParseQuotedString(b *testing.B) {
 	str := `"The quick brown" fox jumps over the lazy dog`
 	b.ReportAllocs()
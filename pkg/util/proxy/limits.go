@@ -0,0 +1,110 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proxy
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ConnectionLimiter limits the number of upgraded connections proxied concurrently, in total
+// and per client, so that a single client cannot exhaust the file descriptors of the proxy.
+// The zero value imposes no limits. A ConnectionLimiter is safe for concurrent use and may be
+// shared between handlers to enforce limits across them.
+type ConnectionLimiter struct {
+	// MaxConnections is the maximum number of concurrent connections. Connections beyond the
+	// limit are rejected with a 503 Service Unavailable error. No limit is imposed if zero.
+	MaxConnections int
+	// MaxConnectionsPerClient is the maximum number of concurrent connections of a client.
+	// Connections beyond the limit are rejected with a 429 Too Many Requests error. No limit
+	// is imposed if zero.
+	MaxConnectionsPerClient int
+	// ClientKeyFunc returns the identity of the client of a request, e.g. the name of the
+	// authenticated user. If nil or if it returns an empty key, clients are identified by the
+	// IP of the remote address of the request. Forwarding headers are not trusted.
+	ClientKeyFunc func(req *http.Request) string
+	// RetryAfterSeconds is suggested to rejected clients. If zero, a delay of one second is suggested.
+	RetryAfterSeconds int
+
+	lock      sync.Mutex
+	total     int
+	perClient map[string]int
+}
+
+// Acquire reserves a connection for the client of req, returning a function releasing it
+// once the connection is closed. It returns an API status error if a limit is exceeded.
+func (l *ConnectionLimiter) Acquire(req *http.Request) (release func(), err error) {
+	key := l.clientKey(req)
+	retryAfter := l.RetryAfterSeconds
+	if retryAfter <= 0 {
+		retryAfter = 1
+	}
+
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	if l.MaxConnections > 0 && l.total >= l.MaxConnections {
+		err := errors.NewServiceUnavailable(fmt.Sprintf("the proxy is serving the maximum of %d connections", l.MaxConnections))
+		err.ErrStatus.Details = &metav1.StatusDetails{RetryAfterSeconds: int32(retryAfter)}
+		return nil, err
+	}
+	if l.MaxConnectionsPerClient > 0 && l.perClient[key] >= l.MaxConnectionsPerClient {
+		return nil, errors.NewTooManyRequests(fmt.Sprintf("client %q has reached the maximum of %d concurrent connections", key, l.MaxConnectionsPerClient), retryAfter)
+	}
+	if l.perClient == nil {
+		l.perClient = map[string]int{}
+	}
+	l.total++
+	l.perClient[key]++
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			l.lock.Lock()
+			defer l.lock.Unlock()
+			l.total--
+			if l.perClient[key]--; l.perClient[key] <= 0 {
+				delete(l.perClient, key)
+			}
+		})
+	}, nil
+}
+
+// Connections returns the number of connections currently reserved, in total and by the
+// client of req.
+func (l *ConnectionLimiter) Connections(req *http.Request) (total, client int) {
+	key := l.clientKey(req)
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	return l.total, l.perClient[key]
+}
+
+func (l *ConnectionLimiter) clientKey(req *http.Request) string {
+	if l.ClientKeyFunc != nil {
+		if key := l.ClientKeyFunc(req); len(key) > 0 {
+			return key
+		}
+	}
+	if host, _, err := net.SplitHostPort(req.RemoteAddr); err == nil {
+		return host
+	}
+	return req.RemoteAddr
+}
@@ -0,0 +1,115 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proxy
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+)
+
+func newLimitedRequest(remoteAddr, user string) *http.Request {
+	req := httptest.NewRequest("GET", "http://example.com/exec", nil)
+	req.RemoteAddr = remoteAddr
+	req.Header.Set("X-User", user)
+	return req
+}
+
+func TestConnectionLimiter(t *testing.T) {
+	l := &ConnectionLimiter{MaxConnections: 3, MaxConnectionsPerClient: 2}
+
+	a1, err := l.Acquire(newLimitedRequest("10.0.0.1:1000", ""))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := l.Acquire(newLimitedRequest("10.0.0.1:1001", "")); err != nil {
+		t.Fatal(err)
+	}
+	_, err = l.Acquire(newLimitedRequest("10.0.0.1:1002", ""))
+	if !errors.IsTooManyRequests(err) {
+		t.Fatalf("expected a too many requests error for the third connection of a client, got %v", err)
+	}
+	if delay, ok := errors.SuggestsClientDelay(err); !ok || delay != 1 {
+		t.Errorf("expected a suggested delay of 1s, got %d %v", delay, ok)
+	}
+
+	if _, err := l.Acquire(newLimitedRequest("10.0.0.2:1000", "")); err != nil {
+		t.Fatal(err)
+	}
+	_, err = l.Acquire(newLimitedRequest("10.0.0.3:1000", ""))
+	if !errors.IsServiceUnavailable(err) {
+		t.Fatalf("expected a service unavailable error beyond the total limit, got %v", err)
+	}
+
+	// releasing is idempotent.
+	a1()
+	a1()
+	if total, client := l.Connections(newLimitedRequest("10.0.0.1:1003", "")); total != 2 || client != 1 {
+		t.Errorf("expected 2 connections, 1 of the client, got %d and %d", total, client)
+	}
+	if _, err := l.Acquire(newLimitedRequest("10.0.0.1:1003", "")); err != nil {
+		t.Errorf("expected a connection to be available after release, got %v", err)
+	}
+}
+
+func TestConnectionLimiterClientKeyFunc(t *testing.T) {
+	l := &ConnectionLimiter{
+		MaxConnectionsPerClient: 1,
+		ClientKeyFunc:           func(req *http.Request) string { return req.Header.Get("X-User") },
+	}
+	if _, err := l.Acquire(newLimitedRequest("10.0.0.1:1000", "alice")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := l.Acquire(newLimitedRequest("10.0.0.2:1000", "alice")); !errors.IsTooManyRequests(err) {
+		t.Errorf("expected connections of a user to be limited across IPs, got %v", err)
+	}
+	if _, err := l.Acquire(newLimitedRequest("10.0.0.1:1001", "bob")); err != nil {
+		t.Errorf("expected users to be limited separately, got %v", err)
+	}
+	// requests without identity fall back to the IP.
+	if _, err := l.Acquire(newLimitedRequest("10.0.0.1:1002", "")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := l.Acquire(newLimitedRequest("10.0.0.1:1003", "")); !errors.IsTooManyRequests(err) {
+		t.Errorf("expected anonymous connections to be limited by IP, got %v", err)
+	}
+}
+
+func TestUpgradeAwareHandlerConnectionLimit(t *testing.T) {
+	limiter := &ConnectionLimiter{MaxConnections: 1}
+	release, err := limiter.Acquire(newLimitedRequest("10.0.0.1:1000", ""))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer release()
+
+	responder := &fakeResponder{t: t}
+	backendURL, _ := url.Parse("http://127.0.0.1:1")
+	handler := NewUpgradeAwareHandler(backendURL, nil, false, true, responder)
+	handler.ConnectionLimiter = limiter
+
+	req := newLimitedRequest("10.0.0.2:1000", "")
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Upgrade", "SPDY/3.1")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	if !errors.IsServiceUnavailable(responder.err) {
+		t.Errorf("expected the upgrade to be rejected as unavailable, got %v", responder.err)
+	}
+}
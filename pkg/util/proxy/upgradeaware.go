@@ -88,6 +88,9 @@ type UpgradeAwareHandler struct {
 	MaxBytesPerSec int64
 	// Responder is passed errors that occur while setting up proxying.
 	Responder ErrorResponder
+	// ConnectionLimiter, if specified, limits the number of concurrently proxied upgraded connections.
+	// Upgrade requests exceeding the limits are passed to the Responder as API status errors.
+	ConnectionLimiter *ConnectionLimiter
 }
 
 const defaultFlushInterval = 200 * time.Millisecond
@@ -290,6 +293,16 @@ func (h *UpgradeAwareHandler) tryUpgrade(w http.ResponseWriter, req *http.Reques
 		return false
 	}
 
+	if h.ConnectionLimiter != nil {
+		release, err := h.ConnectionLimiter.Acquire(req)
+		if err != nil {
+			klog.V(4).Infof("Rejecting upgrade request: %v", err)
+			h.Responder.Error(w, req, err)
+			return true
+		}
+		defer release()
+	}
+
 	var (
 		backendConn net.Conn
 		rawResponse []byte
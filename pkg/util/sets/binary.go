@@ -0,0 +1,143 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sets
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/gob"
+	"fmt"
+	"reflect"
+)
+
+// GobEncode encodes the set for encoding/gob as a list of its items, sorted as by List. T must be
+// a type gob can encode.
+func (s Set[T]) GobEncode() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(s.List()); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// GobDecode decodes a set encoded by GobEncode, rejecting duplicate items.
+func (s *Set[T]) GobDecode(data []byte) error {
+	var items []T
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&items); err != nil {
+		return err
+	}
+	set, err := fromUniqueItems(items)
+	if err != nil {
+		return err
+	}
+	if set == nil {
+		set = New[T]()
+	}
+	*s = set
+	return nil
+}
+
+// MarshalBinary encodes the set in a compact binary form, for sets of strings and integers. The
+// encoding starts with the number of items, followed by each item, sorted as by List, prefixed
+// with its length. Lengths and counts are unsigned varints, strings are stored as is and integers
+// as varints.
+func (s Set[T]) MarshalBinary() ([]byte, error) {
+	items := s.List()
+	kind := reflect.TypeOf(items).Elem().Kind()
+	if !binaryKind(kind) {
+		return nil, fmt.Errorf("binary encoding of sets of %s is not supported", kind)
+	}
+	var buf bytes.Buffer
+	var scratch, prefix [binary.MaxVarintLen64]byte
+	buf.Write(prefix[:binary.PutUvarint(prefix[:], uint64(len(items)))])
+	for _, item := range items {
+		v := reflect.ValueOf(item)
+		var payload []byte
+		switch {
+		case kind == reflect.String:
+			payload = []byte(v.String())
+		case v.CanInt():
+			payload = scratch[:binary.PutVarint(scratch[:], v.Int())]
+		default:
+			payload = scratch[:binary.PutUvarint(scratch[:], v.Uint())]
+		}
+		buf.Write(prefix[:binary.PutUvarint(prefix[:], uint64(len(payload)))])
+		buf.Write(payload)
+	}
+	return buf.Bytes(), nil
+}
+
+// UnmarshalBinary decodes a set encoded by MarshalBinary, rejecting duplicate items and integers
+// that overflow T.
+func (s *Set[T]) UnmarshalBinary(data []byte) error {
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	if !binaryKind(t.Kind()) {
+		return fmt.Errorf("binary encoding of sets of %s is not supported", t.Kind())
+	}
+	count, n := binary.Uvarint(data)
+	if n <= 0 || count > uint64(len(data)) {
+		return fmt.Errorf("invalid set encoding: bad item count")
+	}
+	data = data[n:]
+	set := make(Set[T], count)
+	for i := uint64(0); i < count; i++ {
+		size, n := binary.Uvarint(data)
+		if n <= 0 || size > uint64(len(data)-n) {
+			return fmt.Errorf("invalid set encoding: bad length of item %d", i)
+		}
+		payload := data[n : n+int(size)]
+		data = data[n+int(size):]
+
+		var item T
+		v := reflect.ValueOf(&item).Elem()
+		switch {
+		case t.Kind() == reflect.String:
+			v.SetString(string(payload))
+		case v.CanInt():
+			x, m := binary.Varint(payload)
+			if m != len(payload) || v.OverflowInt(x) {
+				return fmt.Errorf("invalid set encoding: bad item %d", i)
+			}
+			v.SetInt(x)
+		default:
+			x, m := binary.Uvarint(payload)
+			if m != len(payload) || v.OverflowUint(x) {
+				return fmt.Errorf("invalid set encoding: bad item %d", i)
+			}
+			v.SetUint(x)
+		}
+		if set.Has(item) {
+			return fmt.Errorf("duplicate item %v in set", item)
+		}
+		set[item] = Empty{}
+	}
+	if len(data) > 0 {
+		return fmt.Errorf("invalid set encoding: %d trailing bytes", len(data))
+	}
+	*s = set
+	return nil
+}
+
+func binaryKind(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return true
+	}
+	return false
+}
@@ -14,23 +14,23 @@ See the License for the specific language governing permissions and
 limitations under the License.
 */
 
-// Code generated by set-gen. DO NOT EDIT.
-
 package sets
 
-import (
-	"reflect"
-	"sort"
-)
+import "reflect"
 
 // sets.Byte is a set of bytes, implemented via map[byte]struct{} for minimal memory consumption.
+// It is a thin wrapper around Set[byte], to which it can be converted, and back, with a type
+// conversion: Set[byte](s) and Byte(set).
 type Byte map[byte]Empty
 
+// castByte returns s as a Set[byte], sharing its content.
+func castByte(s Byte) Set[byte] {
+	return Set[byte](s)
+}
+
 // NewByte creates a Byte from a list of values.
 func NewByte(items ...byte) Byte {
-	ss := Byte{}
-	ss.Insert(items...)
-	return ss
+	return Byte(New(items...))
 }
 
 // ByteKeySet creates a Byte from a keys of a map[byte](? extends interface{}).
@@ -47,44 +47,27 @@ func ByteKeySet(theMap interface{}) Byte {
 
 // Insert adds items to the set.
 func (s Byte) Insert(items ...byte) Byte {
-	for _, item := range items {
-		s[item] = Empty{}
-	}
-	return s
+	return Byte(castByte(s).Insert(items...))
 }
 
 // Delete removes all items from the set.
 func (s Byte) Delete(items ...byte) Byte {
-	for _, item := range items {
-		delete(s, item)
-	}
-	return s
+	return Byte(castByte(s).Delete(items...))
 }
 
 // Has returns true if and only if item is contained in the set.
 func (s Byte) Has(item byte) bool {
-	_, contained := s[item]
-	return contained
+	return castByte(s).Has(item)
 }
 
 // HasAll returns true if and only if all items are contained in the set.
 func (s Byte) HasAll(items ...byte) bool {
-	for _, item := range items {
-		if !s.Has(item) {
-			return false
-		}
-	}
-	return true
+	return castByte(s).HasAll(items...)
 }
 
 // HasAny returns true if any items are contained in the set.
 func (s Byte) HasAny(items ...byte) bool {
-	for _, item := range items {
-		if s.Has(item) {
-			return true
-		}
-	}
-	return false
+	return castByte(s).HasAny(items...)
 }
 
 // Difference returns a set of objects that are not in s2
@@ -94,13 +77,17 @@ func (s Byte) HasAny(items ...byte) bool {
 // s1.Difference(s2) = {a3}
 // s2.Difference(s1) = {a4, a5}
 func (s Byte) Difference(s2 Byte) Byte {
-	result := NewByte()
-	for key := range s {
-		if !s2.Has(key) {
-			result.Insert(key)
-		}
-	}
-	return result
+	return Byte(castByte(s).Difference(castByte(s2)))
+}
+
+// SymmetricDifference returns a set of elements which are in either of the sets, but not in their intersection.
+// For example:
+// s1 = {a1, a2, a3}
+// s2 = {a1, a2, a4, a5}
+// s1.SymmetricDifference(s2) = {a3, a4, a5}
+// s2.SymmetricDifference(s1) = {a3, a4, a5}
+func (s1 Byte) SymmetricDifference(s2 Byte) Byte {
+	return Byte(castByte(s1).SymmetricDifference(castByte(s2)))
 }
 
 // Union returns a new set which includes items in either s1 or s2.
@@ -110,14 +97,7 @@ func (s Byte) Difference(s2 Byte) Byte {
 // s1.Union(s2) = {a1, a2, a3, a4}
 // s2.Union(s1) = {a1, a2, a3, a4}
 func (s1 Byte) Union(s2 Byte) Byte {
-	result := NewByte()
-	for key := range s1 {
-		result.Insert(key)
-	}
-	for key := range s2 {
-		result.Insert(key)
-	}
-	return result
+	return Byte(castByte(s1).Union(castByte(s2)))
 }
 
 // Intersection returns a new set which includes the item in BOTH s1 and s2
@@ -126,80 +106,42 @@ func (s1 Byte) Union(s2 Byte) Byte {
 // s2 = {a2, a3}
 // s1.Intersection(s2) = {a2}
 func (s1 Byte) Intersection(s2 Byte) Byte {
-	var walk, other Byte
-	result := NewByte()
-	if s1.Len() < s2.Len() {
-		walk = s1
-		other = s2
-	} else {
-		walk = s2
-		other = s1
-	}
-	for key := range walk {
-		if other.Has(key) {
-			result.Insert(key)
-		}
-	}
-	return result
+	return Byte(castByte(s1).Intersection(castByte(s2)))
 }
 
 // IsSuperset returns true if and only if s1 is a superset of s2.
 func (s1 Byte) IsSuperset(s2 Byte) bool {
-	for item := range s2 {
-		if !s1.Has(item) {
-			return false
-		}
-	}
-	return true
+	return castByte(s1).IsSuperset(castByte(s2))
 }
 
 // Equal returns true if and only if s1 is equal (as a set) to s2.
 // Two sets are equal if their membership is identical.
 // (In practice, this means same elements, order doesn't matter)
 func (s1 Byte) Equal(s2 Byte) bool {
-	return len(s1) == len(s2) && s1.IsSuperset(s2)
+	return castByte(s1).Equal(castByte(s2))
 }
 
-type sortableSliceOfByte []byte
-
-func (s sortableSliceOfByte) Len() int           { return len(s) }
-func (s sortableSliceOfByte) Less(i, j int) bool { return lessByte(s[i], s[j]) }
-func (s sortableSliceOfByte) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
-
 // List returns the contents as a sorted byte slice.
 func (s Byte) List() []byte {
-	res := make(sortableSliceOfByte, 0, len(s))
-	for key := range s {
-		res = append(res, key)
-	}
-	sort.Sort(res)
-	return []byte(res)
+	return SortedList(castByte(s))
 }
 
 // UnsortedList returns the slice with contents in random order.
 func (s Byte) UnsortedList() []byte {
-	res := make([]byte, 0, len(s))
-	for key := range s {
-		res = append(res, key)
-	}
-	return res
+	return castByte(s).UnsortedList()
 }
 
 // Returns a single element from the set.
 func (s Byte) PopAny() (byte, bool) {
-	for key := range s {
-		s.Delete(key)
-		return key, true
-	}
-	var zeroValue byte
-	return zeroValue, false
+	return castByte(s).PopAny()
+}
+
+// Pop removes item from the set, returning true if and only if it was contained in the set.
+func (s Byte) Pop(item byte) bool {
+	return castByte(s).Pop(item)
 }
 
 // Len returns the size of the set.
 func (s Byte) Len() int {
 	return len(s)
 }
-
-func lessByte(lhs, rhs byte) bool {
-	return lhs < rhs
-}
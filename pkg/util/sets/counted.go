@@ -0,0 +1,85 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sets
+
+// Counted is a multiset of comparable items, counting how many times each item was inserted.
+// Items are contained in the set until they have been removed as many times as they were
+// inserted.
+type Counted[T comparable] map[T]int
+
+// NewCounted creates a Counted from a list of values, counting duplicates.
+func NewCounted[T comparable](items ...T) Counted[T] {
+	c := make(Counted[T], len(items))
+	c.Insert(items...)
+	return c
+}
+
+// Insert increments the counts of items.
+func (c Counted[T]) Insert(items ...T) {
+	for _, item := range items {
+		c[item]++
+	}
+}
+
+// Remove decrements the counts of items that are contained in the set, removing those whose
+// count drops to zero. It returns the items that were removed from the set.
+func (c Counted[T]) Remove(items ...T) []T {
+	var removed []T
+	for _, item := range items {
+		count, contained := c[item]
+		if !contained {
+			continue
+		}
+		if count <= 1 {
+			delete(c, item)
+			removed = append(removed, item)
+			continue
+		}
+		c[item] = count - 1
+	}
+	return removed
+}
+
+// Count returns the number of times item was inserted and not removed.
+func (c Counted[T]) Count(item T) int {
+	return c[item]
+}
+
+// Has returns true if and only if item is contained in the set.
+func (c Counted[T]) Has(item T) bool {
+	_, contained := c[item]
+	return contained
+}
+
+// Len returns the number of distinct items in the set.
+func (c Counted[T]) Len() int {
+	return len(c)
+}
+
+// Total returns the sum of the counts of all items.
+func (c Counted[T]) Total() int {
+	total := 0
+	for _, count := range c {
+		total += count
+	}
+	return total
+}
+
+// Set returns the distinct items of the set.
+func (c Counted[T]) Set() Set[T] {
+	return KeySet(c)
+}
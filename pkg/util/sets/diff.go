@@ -0,0 +1,85 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sets
+
+// Diff is the membership difference between an old and a new set, as computed by NewDiff.
+type Diff[T comparable] struct {
+	added     Set[T]
+	removed   Set[T]
+	unchanged Set[T]
+}
+
+// NewDiff compares old and new, walking new once, and old once only if items were removed.
+func NewDiff[T comparable](old, new Set[T]) Diff[T] {
+	return newDiff(old, new, true)
+}
+
+// newDiff computes the diff of old and new, leaving unchanged nil if withUnchanged is false.
+func newDiff[T comparable](old, new Set[T], withUnchanged bool) Diff[T] {
+	d := Diff[T]{added: New[T](), removed: New[T]()}
+	if withUnchanged {
+		d.unchanged = New[T]()
+	}
+	kept := 0
+	for key := range new {
+		if !old.Has(key) {
+			d.added[key] = Empty{}
+			continue
+		}
+		kept++
+		if withUnchanged {
+			d.unchanged[key] = Empty{}
+		}
+	}
+	// every item of old is in new unless fewer of them were found there
+	if kept < len(old) {
+		for key := range old {
+			if !new.Has(key) {
+				d.removed[key] = Empty{}
+			}
+		}
+	}
+	return d
+}
+
+// Added returns the items of the new set that are not in the old set.
+func (d Diff[T]) Added() Set[T] {
+	return d.added
+}
+
+// Removed returns the items of the old set that are not in the new set.
+func (d Diff[T]) Removed() Set[T] {
+	return d.removed
+}
+
+// Unchanged returns the items that are in both sets.
+func (d Diff[T]) Unchanged() Set[T] {
+	return d.unchanged
+}
+
+// Empty returns true if and only if the old and new sets are equal.
+func (d Diff[T]) Empty() bool {
+	return len(d.added) == 0 && len(d.removed) == 0
+}
+
+// DiffOf returns the items that were added to and removed from old to get new. It is equivalent to
+// new.Difference(old) and old.Difference(new), and can be used by reconcilers to compute what to
+// create and what to delete. Use NewDiff to also get the unchanged items.
+func DiffOf[T comparable](old, new Set[T]) (added, removed Set[T]) {
+	d := newDiff(old, new, false)
+	return d.added, d.removed
+}
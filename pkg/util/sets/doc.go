@@ -14,7 +14,6 @@ See the License for the specific language governing permissions and
 limitations under the License.
 */
 
-// Code generated by set-gen. DO NOT EDIT.
-
-// Package sets has auto-generated set types.
+// Package sets has a generic Set type, and String, Int, Int32, Int64, Float64 and Byte set
+// types that are thin wrappers around it.
 package sets
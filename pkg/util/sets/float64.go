@@ -0,0 +1,148 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sets
+
+import "reflect"
+
+// sets.Float64 is a set of float64s, implemented via map[float64]struct{} for minimal memory consumption.
+// It is a thin wrapper around Set[float64], to which it can be converted, and back, with a type
+// conversion: Set[float64](s) and Float64(set). As NaN is not equal to itself, NaN items can be
+// inserted but are never found.
+type Float64 map[float64]Empty
+
+// castFloat64 returns s as a Set[float64], sharing its content.
+func castFloat64(s Float64) Set[float64] {
+	return Set[float64](s)
+}
+
+// NewFloat64 creates a Float64 from a list of values.
+func NewFloat64(items ...float64) Float64 {
+	return Float64(New(items...))
+}
+
+// Float64KeySet creates a Float64 from a keys of a map[float64](? extends interface{}).
+// If the value passed in is not actually a map, this will panic.
+func Float64KeySet(theMap interface{}) Float64 {
+	v := reflect.ValueOf(theMap)
+	ret := Float64{}
+
+	for _, keyValue := range v.MapKeys() {
+		ret.Insert(keyValue.Interface().(float64))
+	}
+	return ret
+}
+
+// Insert adds items to the set.
+func (s Float64) Insert(items ...float64) Float64 {
+	return Float64(castFloat64(s).Insert(items...))
+}
+
+// Delete removes all items from the set.
+func (s Float64) Delete(items ...float64) Float64 {
+	return Float64(castFloat64(s).Delete(items...))
+}
+
+// Has returns true if and only if item is contained in the set.
+func (s Float64) Has(item float64) bool {
+	return castFloat64(s).Has(item)
+}
+
+// HasAll returns true if and only if all items are contained in the set.
+func (s Float64) HasAll(items ...float64) bool {
+	return castFloat64(s).HasAll(items...)
+}
+
+// HasAny returns true if any items are contained in the set.
+func (s Float64) HasAny(items ...float64) bool {
+	return castFloat64(s).HasAny(items...)
+}
+
+// Difference returns a set of objects that are not in s2
+// For example:
+// s1 = {a1, a2, a3}
+// s2 = {a1, a2, a4, a5}
+// s1.Difference(s2) = {a3}
+// s2.Difference(s1) = {a4, a5}
+func (s Float64) Difference(s2 Float64) Float64 {
+	return Float64(castFloat64(s).Difference(castFloat64(s2)))
+}
+
+// SymmetricDifference returns a set of elements which are in either of the sets, but not in their intersection.
+// For example:
+// s1 = {a1, a2, a3}
+// s2 = {a1, a2, a4, a5}
+// s1.SymmetricDifference(s2) = {a3, a4, a5}
+// s2.SymmetricDifference(s1) = {a3, a4, a5}
+func (s1 Float64) SymmetricDifference(s2 Float64) Float64 {
+	return Float64(castFloat64(s1).SymmetricDifference(castFloat64(s2)))
+}
+
+// Union returns a new set which includes items in either s1 or s2.
+// For example:
+// s1 = {a1, a2}
+// s2 = {a3, a4}
+// s1.Union(s2) = {a1, a2, a3, a4}
+// s2.Union(s1) = {a1, a2, a3, a4}
+func (s1 Float64) Union(s2 Float64) Float64 {
+	return Float64(castFloat64(s1).Union(castFloat64(s2)))
+}
+
+// Intersection returns a new set which includes the item in BOTH s1 and s2
+// For example:
+// s1 = {a1, a2}
+// s2 = {a2, a3}
+// s1.Intersection(s2) = {a2}
+func (s1 Float64) Intersection(s2 Float64) Float64 {
+	return Float64(castFloat64(s1).Intersection(castFloat64(s2)))
+}
+
+// IsSuperset returns true if and only if s1 is a superset of s2.
+func (s1 Float64) IsSuperset(s2 Float64) bool {
+	return castFloat64(s1).IsSuperset(castFloat64(s2))
+}
+
+// Equal returns true if and only if s1 is equal (as a set) to s2.
+// Two sets are equal if their membership is identical.
+// (In practice, this means same elements, order doesn't matter)
+func (s1 Float64) Equal(s2 Float64) bool {
+	return castFloat64(s1).Equal(castFloat64(s2))
+}
+
+// List returns the contents as a sorted float64 slice.
+func (s Float64) List() []float64 {
+	return SortedList(castFloat64(s))
+}
+
+// UnsortedList returns the slice with contents in random order.
+func (s Float64) UnsortedList() []float64 {
+	return castFloat64(s).UnsortedList()
+}
+
+// Returns a single element from the set.
+func (s Float64) PopAny() (float64, bool) {
+	return castFloat64(s).PopAny()
+}
+
+// Pop removes item from the set, returning true if and only if it was contained in the set.
+func (s Float64) Pop(item float64) bool {
+	return castFloat64(s).Pop(item)
+}
+
+// Len returns the size of the set.
+func (s Float64) Len() int {
+	return len(s)
+}
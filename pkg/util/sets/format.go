@@ -0,0 +1,62 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sets
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// String returns the items of the set sorted as by List, separated by commas and enclosed in
+// braces, e.g. "{a, b, c}". Items of types without a natural order are sorted by their rendering.
+func (s Set[T]) String() string {
+	return s.render("%v")
+}
+
+// Format implements fmt.Formatter so that the set renders as by String for every verb, each item
+// being formatted with the verb and flags given, e.g. %+v for struct items or %q for strings.
+func (s Set[T]) Format(f fmt.State, verb rune) {
+	format := []byte{'%'}
+	for _, flag := range "+-# 0" {
+		if f.Flag(int(flag)) {
+			format = append(format, byte(flag))
+		}
+	}
+	if width, ok := f.Width(); ok {
+		format = strconv.AppendInt(format, int64(width), 10)
+	}
+	if precision, ok := f.Precision(); ok {
+		format = append(format, '.')
+		format = strconv.AppendInt(format, int64(precision), 10)
+	}
+	format = append(format, string(verb)...)
+	fmt.Fprint(f, s.render(string(format)))
+}
+
+func (s Set[T]) render(format string) string {
+	items := s.List()
+	rendered := make([]string, len(items))
+	for i, item := range items {
+		rendered[i] = fmt.Sprintf(format, item)
+	}
+	if lessFuncFor(items) == nil {
+		sort.Strings(rendered)
+	}
+	return "{" + strings.Join(rendered, ", ") + "}"
+}
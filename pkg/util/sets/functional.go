@@ -0,0 +1,121 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sets
+
+// Range calls fn for each item of s, in no particular order, until fn returns false.
+func (s Set[T]) Range(fn func(T) bool) {
+	for key := range s {
+		if !fn(key) {
+			return
+		}
+	}
+}
+
+// MatchAny returns true if and only if pred returns true for an item of s, such as a key with a
+// given prefix. It stops at the first match.
+func (s Set[T]) MatchAny(pred func(T) bool) bool {
+	for key := range s {
+		if pred(key) {
+			return true
+		}
+	}
+	return false
+}
+
+// MatchAll returns true if and only if pred returns true for all items of s. It stops at the first
+// mismatch.
+func (s Set[T]) MatchAll(pred func(T) bool) bool {
+	for key := range s {
+		if !pred(key) {
+			return false
+		}
+	}
+	return true
+}
+
+// Filter returns a new set with the items of s for which keep returns true.
+func (s Set[T]) Filter(keep func(T) bool) Set[T] {
+	result := New[T]()
+	for key := range s {
+		if keep(key) {
+			result[key] = Empty{}
+		}
+	}
+	return result
+}
+
+// Map returns a new set with the results of applying f to the items of s. Items mapped to the
+// same value are merged, so the result can be smaller than s.
+func Map[T, U comparable](s Set[T], f func(T) U) Set[U] {
+	result := make(Set[U], len(s))
+	for key := range s {
+		result[f(key)] = Empty{}
+	}
+	return result
+}
+
+// Reduce combines the items of s into a single value, starting from initial and calling f with
+// the accumulated value and each item. Items are visited in no particular order, so f should
+// give the same result regardless of the order, as for sums or counts.
+func Reduce[T comparable, A any](s Set[T], initial A, f func(A, T) A) A {
+	acc := initial
+	for key := range s {
+		acc = f(acc, key)
+	}
+	return acc
+}
+
+// GroupBy partitions items into sets keyed by the result of keyFn, such as objects by namespace,
+// in a single pass. Duplicate items are merged and keys with no items are absent.
+func GroupBy[T, K comparable](items []T, keyFn func(T) K) map[K]Set[T] {
+	groups := map[K]Set[T]{}
+	for _, item := range items {
+		key := keyFn(item)
+		group, ok := groups[key]
+		if !ok {
+			group = New[T]()
+			groups[key] = group
+		}
+		group[item] = Empty{}
+	}
+	return groups
+}
+
+// EqualFunc returns true if and only if a and b are equal as sets, comparing items with eq: every
+// item of a is equal to an item of b and vice versa, regardless of order and duplicates. It works
+// with any item type, such as structs that are not comparable or items compared loosely, and takes
+// O(len(a)*len(b)) time.
+func EqualFunc[T any](a, b []T, eq func(T, T) bool) bool {
+	return containsAllFunc(a, b, eq) && containsAllFunc(b, a, eq)
+}
+
+// containsAllFunc returns true if every item of items is equal to an item of container.
+func containsAllFunc[T any](container, items []T, eq func(T, T) bool) bool {
+	for _, item := range items {
+		found := false
+		for _, candidate := range container {
+			if eq(candidate, item) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
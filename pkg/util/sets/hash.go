@@ -0,0 +1,71 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sets
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+)
+
+// Hash returns a fingerprint of the items of s which does not depend on their order, so that
+// callers can detect membership changes without keeping a copy of the set. Equal sets have equal
+// hashes; different sets have different hashes with high probability. Strings, numbers and
+// booleans are hashed by value, so their hashes are stable across processes; other items are
+// hashed through their %#v rendering.
+func (s Set[T]) Hash() uint64 {
+	h := mix64(uint64(len(s)))
+	for key := range s {
+		h += mix64(hashItem(key))
+	}
+	return h
+}
+
+func hashItem[T comparable](item T) uint64 {
+	v := reflect.ValueOf(item)
+	switch v.Kind() {
+	case reflect.String:
+		return HashString(v.String())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return uint64(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Uint()
+	case reflect.Float32, reflect.Float64:
+		if f := v.Float(); f != 0 {
+			return math.Float64bits(f)
+		}
+		// -0 and +0 are the same item.
+		return 0
+	case reflect.Bool:
+		if v.Bool() {
+			return 1
+		}
+		return 0
+	}
+	return HashString(fmt.Sprintf("%#v", item))
+}
+
+// mix64 is the SplitMix64 finalizer. It spreads the bits of item hashes before they are summed, so
+// that similar items, such as consecutive integers, don't cancel out.
+func mix64(h uint64) uint64 {
+	h ^= h >> 30
+	h *= 0xbf58476d1ce4e5b9
+	h ^= h >> 27
+	h *= 0x94d049bb133111eb
+	h ^= h >> 31
+	return h
+}
@@ -0,0 +1,177 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sets
+
+// Hashed is a set of items of any type, including types that are not comparable, such as structs
+// containing slices. Items are grouped by their hash, and items with colliding hashes are told
+// apart with the equality function, so items that are equal must have the same hash. Sets
+// combined with each other should use the same functions; results use those of the receiver.
+type Hashed[T any] struct {
+	hash    func(T) uint64
+	equal   func(a, b T) bool
+	buckets map[uint64][]T
+	len     int
+}
+
+// NewHashed creates a Hashed set using hash and equal from a list of values.
+func NewHashed[T any](hash func(T) uint64, equal func(a, b T) bool, items ...T) *Hashed[T] {
+	s := &Hashed[T]{hash: hash, equal: equal, buckets: make(map[uint64][]T, len(items))}
+	s.Insert(items...)
+	return s
+}
+
+// Insert adds items to the set and returns it.
+func (s *Hashed[T]) Insert(items ...T) *Hashed[T] {
+	for _, item := range items {
+		h := s.hash(item)
+		if s.indexIn(h, item) >= 0 {
+			continue
+		}
+		s.buckets[h] = append(s.buckets[h], item)
+		s.len++
+	}
+	return s
+}
+
+// Delete removes all items from the set and returns it.
+func (s *Hashed[T]) Delete(items ...T) *Hashed[T] {
+	for _, item := range items {
+		h := s.hash(item)
+		i := s.indexIn(h, item)
+		if i < 0 {
+			continue
+		}
+		bucket := s.buckets[h]
+		if len(bucket) == 1 {
+			delete(s.buckets, h)
+		} else {
+			bucket[i] = bucket[len(bucket)-1]
+			var zeroValue T
+			bucket[len(bucket)-1] = zeroValue
+			s.buckets[h] = bucket[:len(bucket)-1]
+		}
+		s.len--
+	}
+	return s
+}
+
+// Has returns true if and only if item is contained in the set.
+func (s *Hashed[T]) Has(item T) bool {
+	return s.indexIn(s.hash(item), item) >= 0
+}
+
+// HasAll returns true if and only if all items are contained in the set.
+func (s *Hashed[T]) HasAll(items ...T) bool {
+	for _, item := range items {
+		if !s.Has(item) {
+			return false
+		}
+	}
+	return true
+}
+
+// HasAny returns true if any items are contained in the set.
+func (s *Hashed[T]) HasAny(items ...T) bool {
+	for _, item := range items {
+		if s.Has(item) {
+			return true
+		}
+	}
+	return false
+}
+
+// Difference returns a set of objects that are not in s2.
+func (s *Hashed[T]) Difference(s2 *Hashed[T]) *Hashed[T] {
+	result := s.empty()
+	s.each(func(item T) {
+		if !s2.Has(item) {
+			result.Insert(item)
+		}
+	})
+	return result
+}
+
+// SymmetricDifference returns a set of elements which are in either of the sets, but not in their intersection.
+func (s1 *Hashed[T]) SymmetricDifference(s2 *Hashed[T]) *Hashed[T] {
+	return s1.Difference(s2).Union(s2.Difference(s1))
+}
+
+// Union returns a new set which includes items in either s1 or s2.
+func (s1 *Hashed[T]) Union(s2 *Hashed[T]) *Hashed[T] {
+	result := s1.empty()
+	s1.each(func(item T) { result.Insert(item) })
+	s2.each(func(item T) { result.Insert(item) })
+	return result
+}
+
+// Intersection returns a new set which includes the items in BOTH s1 and s2.
+func (s1 *Hashed[T]) Intersection(s2 *Hashed[T]) *Hashed[T] {
+	result := s1.empty()
+	s1.each(func(item T) {
+		if s2.Has(item) {
+			result.Insert(item)
+		}
+	})
+	return result
+}
+
+// IsSuperset returns true if and only if s1 is a superset of s2.
+func (s1 *Hashed[T]) IsSuperset(s2 *Hashed[T]) bool {
+	superset := true
+	s2.each(func(item T) {
+		superset = superset && s1.Has(item)
+	})
+	return superset
+}
+
+// Equal returns true if and only if s1 is equal (as a set) to s2.
+func (s1 *Hashed[T]) Equal(s2 *Hashed[T]) bool {
+	return s1.Len() == s2.Len() && s1.IsSuperset(s2)
+}
+
+// UnsortedList returns the slice with contents in random order.
+func (s *Hashed[T]) UnsortedList() []T {
+	res := make([]T, 0, s.len)
+	s.each(func(item T) { res = append(res, item) })
+	return res
+}
+
+// Len returns the size of the set.
+func (s *Hashed[T]) Len() int {
+	return s.len
+}
+
+func (s *Hashed[T]) indexIn(h uint64, item T) int {
+	for i, candidate := range s.buckets[h] {
+		if s.equal(candidate, item) {
+			return i
+		}
+	}
+	return -1
+}
+
+func (s *Hashed[T]) each(f func(T)) {
+	for _, bucket := range s.buckets {
+		for _, item := range bucket {
+			f(item)
+		}
+	}
+}
+
+func (s *Hashed[T]) empty() *Hashed[T] {
+	return NewHashed(s.hash, s.equal)
+}
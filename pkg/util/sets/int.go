@@ -14,23 +14,23 @@ See the License for the specific language governing permissions and
 limitations under the License.
 */
 
-// Code generated by set-gen. DO NOT EDIT.
-
 package sets
 
-import (
-	"reflect"
-	"sort"
-)
+import "reflect"
 
 // sets.Int is a set of ints, implemented via map[int]struct{} for minimal memory consumption.
+// It is a thin wrapper around Set[int], to which it can be converted, and back, with a type
+// conversion: Set[int](s) and Int(set).
 type Int map[int]Empty
 
+// castInt returns s as a Set[int], sharing its content.
+func castInt(s Int) Set[int] {
+	return Set[int](s)
+}
+
 // NewInt creates a Int from a list of values.
 func NewInt(items ...int) Int {
-	ss := Int{}
-	ss.Insert(items...)
-	return ss
+	return Int(New(items...))
 }
 
 // IntKeySet creates a Int from a keys of a map[int](? extends interface{}).
@@ -47,44 +47,27 @@ func IntKeySet(theMap interface{}) Int {
 
 // Insert adds items to the set.
 func (s Int) Insert(items ...int) Int {
-	for _, item := range items {
-		s[item] = Empty{}
-	}
-	return s
+	return Int(castInt(s).Insert(items...))
 }
 
 // Delete removes all items from the set.
 func (s Int) Delete(items ...int) Int {
-	for _, item := range items {
-		delete(s, item)
-	}
-	return s
+	return Int(castInt(s).Delete(items...))
 }
 
 // Has returns true if and only if item is contained in the set.
 func (s Int) Has(item int) bool {
-	_, contained := s[item]
-	return contained
+	return castInt(s).Has(item)
 }
 
 // HasAll returns true if and only if all items are contained in the set.
 func (s Int) HasAll(items ...int) bool {
-	for _, item := range items {
-		if !s.Has(item) {
-			return false
-		}
-	}
-	return true
+	return castInt(s).HasAll(items...)
 }
 
 // HasAny returns true if any items are contained in the set.
 func (s Int) HasAny(items ...int) bool {
-	for _, item := range items {
-		if s.Has(item) {
-			return true
-		}
-	}
-	return false
+	return castInt(s).HasAny(items...)
 }
 
 // Difference returns a set of objects that are not in s2
@@ -94,13 +77,17 @@ func (s Int) HasAny(items ...int) bool {
 // s1.Difference(s2) = {a3}
 // s2.Difference(s1) = {a4, a5}
 func (s Int) Difference(s2 Int) Int {
-	result := NewInt()
-	for key := range s {
-		if !s2.Has(key) {
-			result.Insert(key)
-		}
-	}
-	return result
+	return Int(castInt(s).Difference(castInt(s2)))
+}
+
+// SymmetricDifference returns a set of elements which are in either of the sets, but not in their intersection.
+// For example:
+// s1 = {a1, a2, a3}
+// s2 = {a1, a2, a4, a5}
+// s1.SymmetricDifference(s2) = {a3, a4, a5}
+// s2.SymmetricDifference(s1) = {a3, a4, a5}
+func (s1 Int) SymmetricDifference(s2 Int) Int {
+	return Int(castInt(s1).SymmetricDifference(castInt(s2)))
 }
 
 // Union returns a new set which includes items in either s1 or s2.
@@ -110,14 +97,7 @@ func (s Int) Difference(s2 Int) Int {
 // s1.Union(s2) = {a1, a2, a3, a4}
 // s2.Union(s1) = {a1, a2, a3, a4}
 func (s1 Int) Union(s2 Int) Int {
-	result := NewInt()
-	for key := range s1 {
-		result.Insert(key)
-	}
-	for key := range s2 {
-		result.Insert(key)
-	}
-	return result
+	return Int(castInt(s1).Union(castInt(s2)))
 }
 
 // Intersection returns a new set which includes the item in BOTH s1 and s2
@@ -126,80 +106,42 @@ func (s1 Int) Union(s2 Int) Int {
 // s2 = {a2, a3}
 // s1.Intersection(s2) = {a2}
 func (s1 Int) Intersection(s2 Int) Int {
-	var walk, other Int
-	result := NewInt()
-	if s1.Len() < s2.Len() {
-		walk = s1
-		other = s2
-	} else {
-		walk = s2
-		other = s1
-	}
-	for key := range walk {
-		if other.Has(key) {
-			result.Insert(key)
-		}
-	}
-	return result
+	return Int(castInt(s1).Intersection(castInt(s2)))
 }
 
 // IsSuperset returns true if and only if s1 is a superset of s2.
 func (s1 Int) IsSuperset(s2 Int) bool {
-	for item := range s2 {
-		if !s1.Has(item) {
-			return false
-		}
-	}
-	return true
+	return castInt(s1).IsSuperset(castInt(s2))
 }
 
 // Equal returns true if and only if s1 is equal (as a set) to s2.
 // Two sets are equal if their membership is identical.
 // (In practice, this means same elements, order doesn't matter)
 func (s1 Int) Equal(s2 Int) bool {
-	return len(s1) == len(s2) && s1.IsSuperset(s2)
+	return castInt(s1).Equal(castInt(s2))
 }
 
-type sortableSliceOfInt []int
-
-func (s sortableSliceOfInt) Len() int           { return len(s) }
-func (s sortableSliceOfInt) Less(i, j int) bool { return lessInt(s[i], s[j]) }
-func (s sortableSliceOfInt) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
-
 // List returns the contents as a sorted int slice.
 func (s Int) List() []int {
-	res := make(sortableSliceOfInt, 0, len(s))
-	for key := range s {
-		res = append(res, key)
-	}
-	sort.Sort(res)
-	return []int(res)
+	return SortedList(castInt(s))
 }
 
 // UnsortedList returns the slice with contents in random order.
 func (s Int) UnsortedList() []int {
-	res := make([]int, 0, len(s))
-	for key := range s {
-		res = append(res, key)
-	}
-	return res
+	return castInt(s).UnsortedList()
 }
 
 // Returns a single element from the set.
 func (s Int) PopAny() (int, bool) {
-	for key := range s {
-		s.Delete(key)
-		return key, true
-	}
-	var zeroValue int
-	return zeroValue, false
+	return castInt(s).PopAny()
+}
+
+// Pop removes item from the set, returning true if and only if it was contained in the set.
+func (s Int) Pop(item int) bool {
+	return castInt(s).Pop(item)
 }
 
 // Len returns the size of the set.
 func (s Int) Len() int {
 	return len(s)
 }
-
-func lessInt(lhs, rhs int) bool {
-	return lhs < rhs
-}
@@ -14,23 +14,23 @@ See the License for the specific language governing permissions and
 limitations under the License.
 */
 
-// Code generated by set-gen. DO NOT EDIT.
-
 package sets
 
-import (
-	"reflect"
-	"sort"
-)
+import "reflect"
 
 // sets.Int32 is a set of int32s, implemented via map[int32]struct{} for minimal memory consumption.
+// It is a thin wrapper around Set[int32], to which it can be converted, and back, with a type
+// conversion: Set[int32](s) and Int32(set).
 type Int32 map[int32]Empty
 
+// castInt32 returns s as a Set[int32], sharing its content.
+func castInt32(s Int32) Set[int32] {
+	return Set[int32](s)
+}
+
 // NewInt32 creates a Int32 from a list of values.
 func NewInt32(items ...int32) Int32 {
-	ss := Int32{}
-	ss.Insert(items...)
-	return ss
+	return Int32(New(items...))
 }
 
 // Int32KeySet creates a Int32 from a keys of a map[int32](? extends interface{}).
@@ -47,44 +47,27 @@ func Int32KeySet(theMap interface{}) Int32 {
 
 // Insert adds items to the set.
 func (s Int32) Insert(items ...int32) Int32 {
-	for _, item := range items {
-		s[item] = Empty{}
-	}
-	return s
+	return Int32(castInt32(s).Insert(items...))
 }
 
 // Delete removes all items from the set.
 func (s Int32) Delete(items ...int32) Int32 {
-	for _, item := range items {
-		delete(s, item)
-	}
-	return s
+	return Int32(castInt32(s).Delete(items...))
 }
 
 // Has returns true if and only if item is contained in the set.
 func (s Int32) Has(item int32) bool {
-	_, contained := s[item]
-	return contained
+	return castInt32(s).Has(item)
 }
 
 // HasAll returns true if and only if all items are contained in the set.
 func (s Int32) HasAll(items ...int32) bool {
-	for _, item := range items {
-		if !s.Has(item) {
-			return false
-		}
-	}
-	return true
+	return castInt32(s).HasAll(items...)
 }
 
 // HasAny returns true if any items are contained in the set.
 func (s Int32) HasAny(items ...int32) bool {
-	for _, item := range items {
-		if s.Has(item) {
-			return true
-		}
-	}
-	return false
+	return castInt32(s).HasAny(items...)
 }
 
 // Difference returns a set of objects that are not in s2
@@ -94,13 +77,17 @@ func (s Int32) HasAny(items ...int32) bool {
 // s1.Difference(s2) = {a3}
 // s2.Difference(s1) = {a4, a5}
 func (s Int32) Difference(s2 Int32) Int32 {
-	result := NewInt32()
-	for key := range s {
-		if !s2.Has(key) {
-			result.Insert(key)
-		}
-	}
-	return result
+	return Int32(castInt32(s).Difference(castInt32(s2)))
+}
+
+// SymmetricDifference returns a set of elements which are in either of the sets, but not in their intersection.
+// For example:
+// s1 = {a1, a2, a3}
+// s2 = {a1, a2, a4, a5}
+// s1.SymmetricDifference(s2) = {a3, a4, a5}
+// s2.SymmetricDifference(s1) = {a3, a4, a5}
+func (s1 Int32) SymmetricDifference(s2 Int32) Int32 {
+	return Int32(castInt32(s1).SymmetricDifference(castInt32(s2)))
 }
 
 // Union returns a new set which includes items in either s1 or s2.
@@ -110,14 +97,7 @@ func (s Int32) Difference(s2 Int32) Int32 {
 // s1.Union(s2) = {a1, a2, a3, a4}
 // s2.Union(s1) = {a1, a2, a3, a4}
 func (s1 Int32) Union(s2 Int32) Int32 {
-	result := NewInt32()
-	for key := range s1 {
-		result.Insert(key)
-	}
-	for key := range s2 {
-		result.Insert(key)
-	}
-	return result
+	return Int32(castInt32(s1).Union(castInt32(s2)))
 }
 
 // Intersection returns a new set which includes the item in BOTH s1 and s2
@@ -126,80 +106,42 @@ func (s1 Int32) Union(s2 Int32) Int32 {
 // s2 = {a2, a3}
 // s1.Intersection(s2) = {a2}
 func (s1 Int32) Intersection(s2 Int32) Int32 {
-	var walk, other Int32
-	result := NewInt32()
-	if s1.Len() < s2.Len() {
-		walk = s1
-		other = s2
-	} else {
-		walk = s2
-		other = s1
-	}
-	for key := range walk {
-		if other.Has(key) {
-			result.Insert(key)
-		}
-	}
-	return result
+	return Int32(castInt32(s1).Intersection(castInt32(s2)))
 }
 
 // IsSuperset returns true if and only if s1 is a superset of s2.
 func (s1 Int32) IsSuperset(s2 Int32) bool {
-	for item := range s2 {
-		if !s1.Has(item) {
-			return false
-		}
-	}
-	return true
+	return castInt32(s1).IsSuperset(castInt32(s2))
 }
 
 // Equal returns true if and only if s1 is equal (as a set) to s2.
 // Two sets are equal if their membership is identical.
 // (In practice, this means same elements, order doesn't matter)
 func (s1 Int32) Equal(s2 Int32) bool {
-	return len(s1) == len(s2) && s1.IsSuperset(s2)
+	return castInt32(s1).Equal(castInt32(s2))
 }
 
-type sortableSliceOfInt32 []int32
-
-func (s sortableSliceOfInt32) Len() int           { return len(s) }
-func (s sortableSliceOfInt32) Less(i, j int) bool { return lessInt32(s[i], s[j]) }
-func (s sortableSliceOfInt32) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
-
 // List returns the contents as a sorted int32 slice.
 func (s Int32) List() []int32 {
-	res := make(sortableSliceOfInt32, 0, len(s))
-	for key := range s {
-		res = append(res, key)
-	}
-	sort.Sort(res)
-	return []int32(res)
+	return SortedList(castInt32(s))
 }
 
 // UnsortedList returns the slice with contents in random order.
 func (s Int32) UnsortedList() []int32 {
-	res := make([]int32, 0, len(s))
-	for key := range s {
-		res = append(res, key)
-	}
-	return res
+	return castInt32(s).UnsortedList()
 }
 
 // Returns a single element from the set.
 func (s Int32) PopAny() (int32, bool) {
-	for key := range s {
-		s.Delete(key)
-		return key, true
-	}
-	var zeroValue int32
-	return zeroValue, false
+	return castInt32(s).PopAny()
+}
+
+// Pop removes item from the set, returning true if and only if it was contained in the set.
+func (s Int32) Pop(item int32) bool {
+	return castInt32(s).Pop(item)
 }
 
 // Len returns the size of the set.
 func (s Int32) Len() int {
 	return len(s)
 }
-
-func lessInt32(lhs, rhs int32) bool {
-	return lhs < rhs
-}
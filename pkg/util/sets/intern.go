@@ -0,0 +1,29 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sets
+
+import "k8s.io/apimachinery/pkg/util/intern"
+
+// NewStringInterned creates a String from a list of values, sharing the storage of values
+// interned by interner. A nil interner stores the values as they are.
+func NewStringInterned(interner *intern.Interner, items ...string) String {
+	ss := make(String, len(items))
+	for _, item := range items {
+		ss[interner.Intern(item)] = Empty{}
+	}
+	return ss
+}
@@ -0,0 +1,37 @@
+//go:build go1.23
+// +build go1.23
+
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sets
+
+import "iter"
+
+// All returns an iterator over the items of s, in no particular order.
+func (s Set[T]) All() iter.Seq[T] {
+	return s.Range
+}
+
+// Collect creates a Set from the values of seq.
+func Collect[T comparable](seq iter.Seq[T]) Set[T] {
+	s := New[T]()
+	seq(func(item T) bool {
+		s[item] = Empty{}
+		return true
+	})
+	return s
+}
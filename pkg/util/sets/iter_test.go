@@ -0,0 +1,59 @@
+//go:build go1.23
+// +build go1.23
+
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sets
+
+import (
+	"maps"
+	"slices"
+	"testing"
+)
+
+func TestSetAll(t *testing.T) {
+	s := New(3, 1, 2)
+	items := slices.Sorted(s.All())
+	if !slices.Equal([]int{1, 2, 3}, items) {
+		t.Errorf("Expected all items, got %v", items)
+	}
+
+	count := 0
+	s.All()(func(int) bool {
+		count++
+		return false
+	})
+	if count != 1 {
+		t.Errorf("Expected iteration to stop after the first item, got %d items", count)
+	}
+
+	if items := slices.Collect(New[int]().All()); len(items) != 0 {
+		t.Errorf("Expected no items, got %v", items)
+	}
+}
+
+func TestCollect(t *testing.T) {
+	if e, a := New("a", "b"), Collect(slices.Values([]string{"a", "b", "a"})); !e.Equal(a) {
+		t.Errorf("Expected %v, got %v", e, a)
+	}
+	if e, a := New(1, 2), Collect(maps.Keys(map[int]string{1: "one", 2: "two"})); !e.Equal(a) {
+		t.Errorf("Expected %v, got %v", e, a)
+	}
+	if a := Collect(New[string]().All()); a == nil || a.Len() != 0 {
+		t.Errorf("Expected an empty set, got %#v", a)
+	}
+}
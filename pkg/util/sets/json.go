@@ -0,0 +1,100 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sets
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// MarshalJSON encodes the set as an array, sorted as by List. Items of types without a natural
+// order are sorted by their JSON encoding, so that the output is deterministic. A nil set is
+// encoded as null.
+func (s Set[T]) MarshalJSON() ([]byte, error) {
+	if s == nil {
+		return []byte("null"), nil
+	}
+	items := s.List()
+	encoded := make([]json.RawMessage, 0, len(items))
+	for _, item := range items {
+		data, err := json.Marshal(item)
+		if err != nil {
+			return nil, err
+		}
+		encoded = append(encoded, data)
+	}
+	if lessFuncFor(items) == nil {
+		sort.Slice(encoded, func(i, j int) bool {
+			return bytes.Compare(encoded[i], encoded[j]) < 0
+		})
+	}
+	return json.Marshal(encoded)
+}
+
+// UnmarshalJSON decodes the set from an array, rejecting duplicate items. null decodes to a nil
+// set.
+func (s *Set[T]) UnmarshalJSON(data []byte) error {
+	var items []T
+	if err := json.Unmarshal(data, &items); err != nil {
+		return err
+	}
+	set, err := fromUniqueItems(items)
+	if err != nil {
+		return err
+	}
+	*s = set
+	return nil
+}
+
+// MarshalYAML encodes the set as a sequence, sorted as by List, for gopkg.in/yaml. Encoders going
+// through JSON, such as sigs.k8s.io/yaml, use MarshalJSON instead.
+func (s Set[T]) MarshalYAML() (interface{}, error) {
+	if s == nil {
+		return nil, nil
+	}
+	return s.List(), nil
+}
+
+// UnmarshalYAML decodes the set from a sequence, rejecting duplicate items, for gopkg.in/yaml.
+func (s *Set[T]) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var items []T
+	if err := unmarshal(&items); err != nil {
+		return err
+	}
+	set, err := fromUniqueItems(items)
+	if err != nil {
+		return err
+	}
+	*s = set
+	return nil
+}
+
+func fromUniqueItems[T comparable](items []T) (Set[T], error) {
+	if items == nil {
+		return nil, nil
+	}
+	set := make(Set[T], len(items))
+	for _, item := range items {
+		if set.Has(item) {
+			return nil, fmt.Errorf("duplicate item %v in set", item)
+		}
+		set.Insert(item)
+	}
+	return set, nil
+}
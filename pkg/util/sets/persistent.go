@@ -0,0 +1,237 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sets
+
+import "math/bits"
+
+const (
+	persistentBits = 5
+	persistentMask = 1<<persistentBits - 1
+)
+
+// Persistent is an immutable set that shares its structure with the sets derived from it. With
+// and Without return new sets and leave the receiver unchanged, copying only the O(log n) nodes
+// on the path to each changed item, so snapshots can be derived cheaply and handed to any number
+// of concurrent readers without locking. Items are organized in a hash array mapped trie using
+// the hash function the set was created with; the zero value is not usable.
+type Persistent[T comparable] struct {
+	hash func(T) uint64
+	root *persistentNode[T]
+	len  int
+}
+
+// persistentNode is a node of the trie. entries holds an entry for each bit set in bitmap, in
+// order, each being either a child node or the items of a single hash.
+type persistentNode[T comparable] struct {
+	bitmap  uint32
+	entries []persistentEntry[T]
+}
+
+type persistentEntry[T comparable] struct {
+	child *persistentNode[T]
+	hash  uint64
+	items []T
+}
+
+// NewPersistent creates a Persistent set from a list of values, using hash to organize items.
+// HashString can be used for string-like items.
+func NewPersistent[T comparable](hash func(T) uint64, items ...T) Persistent[T] {
+	return Persistent[T]{hash: hash}.With(items...)
+}
+
+// With returns a set with the items of s and items.
+func (s Persistent[T]) With(items ...T) Persistent[T] {
+	for _, item := range items {
+		if root, added := s.root.with(s.hash(item), item, 0); added {
+			s.root = root
+			s.len++
+		}
+	}
+	return s
+}
+
+// Without returns a set with the items of s that are not in items.
+func (s Persistent[T]) Without(items ...T) Persistent[T] {
+	for _, item := range items {
+		if root, removed := s.root.without(s.hash(item), item, 0); removed {
+			s.root = root
+			s.len--
+		}
+	}
+	return s
+}
+
+// Has returns true if and only if item is contained in the set.
+func (s Persistent[T]) Has(item T) bool {
+	h := s.hash(item)
+	node := s.root
+	for shift := uint(0); node != nil; shift += persistentBits {
+		bit := uint32(1) << ((h >> shift) & persistentMask)
+		if node.bitmap&bit == 0 {
+			return false
+		}
+		e := &node.entries[node.index(bit)]
+		if e.child == nil {
+			return e.hash == h && indexOf(e.items, item) >= 0
+		}
+		node = e.child
+	}
+	return false
+}
+
+// Len returns the size of the set.
+func (s Persistent[T]) Len() int {
+	return s.len
+}
+
+// Range calls fn for each item of s, in no particular order, until fn returns false.
+func (s Persistent[T]) Range(fn func(T) bool) {
+	s.root.rangeItems(fn)
+}
+
+// Set returns the content of s as a new Set.
+func (s Persistent[T]) Set() Set[T] {
+	result := make(Set[T], s.len)
+	s.Range(func(item T) bool {
+		result[item] = Empty{}
+		return true
+	})
+	return result
+}
+
+func (n *persistentNode[T]) index(bit uint32) int {
+	return bits.OnesCount32(n.bitmap & (bit - 1))
+}
+
+// with returns a copy of n including item, or n itself if item is already included.
+func (n *persistentNode[T]) with(h uint64, item T, shift uint) (*persistentNode[T], bool) {
+	bit := uint32(1) << ((h >> shift) & persistentMask)
+	if n == nil || n.bitmap&bit == 0 {
+		entry := persistentEntry[T]{hash: h, items: []T{item}}
+		if n == nil {
+			return &persistentNode[T]{bitmap: bit, entries: []persistentEntry[T]{entry}}, true
+		}
+		i := n.index(bit)
+		entries := make([]persistentEntry[T], 0, len(n.entries)+1)
+		entries = append(entries, n.entries[:i]...)
+		entries = append(entries, entry)
+		entries = append(entries, n.entries[i:]...)
+		return &persistentNode[T]{bitmap: n.bitmap | bit, entries: entries}, true
+	}
+
+	i := n.index(bit)
+	e := n.entries[i]
+	switch {
+	case e.child != nil:
+		child, added := e.child.with(h, item, shift+persistentBits)
+		if !added {
+			return n, false
+		}
+		return n.replace(i, persistentEntry[T]{child: child}), true
+	case e.hash == h:
+		if indexOf(e.items, item) >= 0 {
+			return n, false
+		}
+		items := make([]T, 0, len(e.items)+1)
+		items = append(append(items, e.items...), item)
+		return n.replace(i, persistentEntry[T]{hash: h, items: items}), true
+	default:
+		// Move the existing items one level down, next to the new item.
+		child := &persistentNode[T]{bitmap: uint32(1) << ((e.hash >> (shift + persistentBits)) & persistentMask), entries: []persistentEntry[T]{e}}
+		child, _ = child.with(h, item, shift+persistentBits)
+		return n.replace(i, persistentEntry[T]{child: child}), true
+	}
+}
+
+// without returns a copy of n excluding item, nil if the copy would be empty, or n itself if item
+// is not included.
+func (n *persistentNode[T]) without(h uint64, item T, shift uint) (*persistentNode[T], bool) {
+	bit := uint32(1) << ((h >> shift) & persistentMask)
+	if n == nil || n.bitmap&bit == 0 {
+		return n, false
+	}
+	i := n.index(bit)
+	e := n.entries[i]
+	if e.child != nil {
+		child, removed := e.child.without(h, item, shift+persistentBits)
+		switch {
+		case !removed:
+			return n, false
+		case child == nil:
+			return n.remove(i, bit), true
+		case len(child.entries) == 1 && child.entries[0].child == nil:
+			// Collapse children left with the items of a single hash.
+			return n.replace(i, child.entries[0]), true
+		}
+		return n.replace(i, persistentEntry[T]{child: child}), true
+	}
+	j := indexOf(e.items, item)
+	if e.hash != h || j < 0 {
+		return n, false
+	}
+	if len(e.items) == 1 {
+		return n.remove(i, bit), true
+	}
+	items := make([]T, 0, len(e.items)-1)
+	items = append(append(items, e.items[:j]...), e.items[j+1:]...)
+	return n.replace(i, persistentEntry[T]{hash: h, items: items}), true
+}
+
+func (n *persistentNode[T]) replace(i int, e persistentEntry[T]) *persistentNode[T] {
+	entries := make([]persistentEntry[T], len(n.entries))
+	copy(entries, n.entries)
+	entries[i] = e
+	return &persistentNode[T]{bitmap: n.bitmap, entries: entries}
+}
+
+func (n *persistentNode[T]) remove(i int, bit uint32) *persistentNode[T] {
+	if len(n.entries) == 1 {
+		return nil
+	}
+	entries := make([]persistentEntry[T], 0, len(n.entries)-1)
+	entries = append(append(entries, n.entries[:i]...), n.entries[i+1:]...)
+	return &persistentNode[T]{bitmap: n.bitmap &^ bit, entries: entries}
+}
+
+func (n *persistentNode[T]) rangeItems(fn func(T) bool) bool {
+	if n == nil {
+		return true
+	}
+	for _, e := range n.entries {
+		if e.child != nil {
+			if !e.child.rangeItems(fn) {
+				return false
+			}
+			continue
+		}
+		for _, item := range e.items {
+			if !fn(item) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func indexOf[T comparable](items []T, item T) int {
+	for i, candidate := range items {
+		if candidate == item {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,154 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sets
+
+import "container/heap"
+
+// Prioritized is a set of comparable items, each with a priority, which can be popped in order of
+// decreasing priority. Items of equal priority are popped in the order they were inserted. The
+// zero value is an empty set ready to use. It is not safe for concurrent use.
+type Prioritized[T comparable] struct {
+	queue prioritizedQueue[T]
+	index map[T]int
+	seq   uint64
+}
+
+type prioritizedEntry[T comparable] struct {
+	item     T
+	priority int
+	seq      uint64
+}
+
+// NewPrioritized creates an empty Prioritized set.
+func NewPrioritized[T comparable]() *Prioritized[T] {
+	return &Prioritized[T]{}
+}
+
+// Insert adds item with priority to the set. If item is already contained in the set, its
+// priority is updated, keeping its original insertion order among items of equal priority.
+func (p *Prioritized[T]) Insert(item T, priority int) *Prioritized[T] {
+	if i, contained := p.index[item]; contained {
+		p.queue.entries[i].priority = priority
+		heap.Fix(&p.queue, i)
+		return p
+	}
+	if p.index == nil {
+		p.index = map[T]int{}
+		p.queue.index = p.index
+	}
+	heap.Push(&p.queue, prioritizedEntry[T]{item: item, priority: priority, seq: p.seq})
+	p.seq++
+	return p
+}
+
+// Delete removes item from the set, returning true if and only if it was contained in the set.
+func (p *Prioritized[T]) Delete(item T) bool {
+	i, contained := p.index[item]
+	if !contained {
+		return false
+	}
+	heap.Remove(&p.queue, i)
+	return true
+}
+
+// Has returns true if and only if item is contained in the set.
+func (p *Prioritized[T]) Has(item T) bool {
+	_, contained := p.index[item]
+	return contained
+}
+
+// Priority returns the priority of item, and whether it is contained in the set.
+func (p *Prioritized[T]) Priority(item T) (int, bool) {
+	i, contained := p.index[item]
+	if !contained {
+		return 0, false
+	}
+	return p.queue.entries[i].priority, true
+}
+
+// PeekHighest returns the item with the highest priority and its priority without removing it.
+// The last result is false if the set is empty.
+func (p *Prioritized[T]) PeekHighest() (T, int, bool) {
+	if len(p.queue.entries) == 0 {
+		var zeroValue T
+		return zeroValue, 0, false
+	}
+	entry := p.queue.entries[0]
+	return entry.item, entry.priority, true
+}
+
+// PopHighest removes and returns the item with the highest priority and its priority. The last
+// result is false if the set is empty.
+func (p *Prioritized[T]) PopHighest() (T, int, bool) {
+	if len(p.queue.entries) == 0 {
+		var zeroValue T
+		return zeroValue, 0, false
+	}
+	entry := heap.Pop(&p.queue).(prioritizedEntry[T])
+	return entry.item, entry.priority, true
+}
+
+// Len returns the size of the set.
+func (p *Prioritized[T]) Len() int {
+	return len(p.queue.entries)
+}
+
+// Set returns the items of the set, without their priorities.
+func (p *Prioritized[T]) Set() Set[T] {
+	s := make(Set[T], len(p.queue.entries))
+	for _, entry := range p.queue.entries {
+		s[entry.item] = Empty{}
+	}
+	return s
+}
+
+// prioritizedQueue implements heap.Interface, keeping index up to date with the position of each
+// item in entries.
+type prioritizedQueue[T comparable] struct {
+	entries []prioritizedEntry[T]
+	index   map[T]int
+}
+
+func (q *prioritizedQueue[T]) Len() int { return len(q.entries) }
+
+func (q *prioritizedQueue[T]) Less(i, j int) bool {
+	if q.entries[i].priority != q.entries[j].priority {
+		return q.entries[i].priority > q.entries[j].priority
+	}
+	return q.entries[i].seq < q.entries[j].seq
+}
+
+func (q *prioritizedQueue[T]) Swap(i, j int) {
+	q.entries[i], q.entries[j] = q.entries[j], q.entries[i]
+	q.index[q.entries[i].item] = i
+	q.index[q.entries[j].item] = j
+}
+
+func (q *prioritizedQueue[T]) Push(x interface{}) {
+	entry := x.(prioritizedEntry[T])
+	q.index[entry.item] = len(q.entries)
+	q.entries = append(q.entries, entry)
+}
+
+func (q *prioritizedQueue[T]) Pop() interface{} {
+	last := len(q.entries) - 1
+	entry := q.entries[last]
+	q.entries[last] = prioritizedEntry[T]{}
+	q.entries = q.entries[:last]
+	delete(q.index, entry.item)
+	return entry
+}
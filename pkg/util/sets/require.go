@@ -0,0 +1,70 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sets
+
+import (
+	"strconv"
+	"strings"
+)
+
+// NotSubsetError is returned by RequireSubset. It lists, sorted, the items which are not allowed
+// and the allowed items.
+type NotSubsetError struct {
+	Unexpected []string
+	Allowed    []string
+}
+
+func (e *NotSubsetError) Error() string {
+	return "unsupported values: " + quoteAll(e.Unexpected) + "; supported values: " + quoteAll(e.Allowed)
+}
+
+// NotDisjointError is returned by RequireDisjoint. It lists, sorted, the items found in both sets.
+type NotDisjointError struct {
+	Common []string
+}
+
+func (e *NotDisjointError) Error() string {
+	return "values must not be present in both sets: " + quoteAll(e.Common)
+}
+
+// RequireSubset returns a *NotSubsetError listing the items of got which are not in allowed, or
+// nil if got is a subset of allowed.
+func RequireSubset(got, allowed Set[string]) error {
+	unexpected := got.Difference(allowed)
+	if unexpected.Len() == 0 {
+		return nil
+	}
+	return &NotSubsetError{Unexpected: SortedList(unexpected), Allowed: SortedList(allowed)}
+}
+
+// RequireDisjoint returns a *NotDisjointError listing the items in both a and b, or nil if they
+// have no items in common.
+func RequireDisjoint(a, b Set[string]) error {
+	common := a.Intersection(b)
+	if common.Len() == 0 {
+		return nil
+	}
+	return &NotDisjointError{Common: SortedList(common)}
+}
+
+func quoteAll(items []string) string {
+	quoted := make([]string, len(items))
+	for i, item := range items {
+		quoted[i] = strconv.Quote(item)
+	}
+	return strings.Join(quoted, ", ")
+}
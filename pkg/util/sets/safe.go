@@ -0,0 +1,162 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sets
+
+import "sync"
+
+// Safe is a Set that is safe for concurrent use, guarded by a sync.RWMutex. Operations combining
+// it with other sets take plain Sets, which must not be modified concurrently, and return new
+// plain Sets. Use Snapshot to get a copy of its content. The zero value is an empty set ready to
+// use. A Safe must not be copied after first use.
+type Safe[T comparable] struct {
+	lock  sync.RWMutex
+	items Set[T]
+}
+
+// NewSafe creates a Safe from a list of values.
+func NewSafe[T comparable](items ...T) *Safe[T] {
+	return &Safe[T]{items: New(items...)}
+}
+
+// Insert adds items to the set and returns it.
+func (s *Safe[T]) Insert(items ...T) *Safe[T] {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.items == nil {
+		s.items = New[T]()
+	}
+	s.items.Insert(items...)
+	return s
+}
+
+// Delete removes all items from the set and returns it.
+func (s *Safe[T]) Delete(items ...T) *Safe[T] {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.items.Delete(items...)
+	return s
+}
+
+// Has returns true if and only if item is contained in the set.
+func (s *Safe[T]) Has(item T) bool {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	return s.items.Has(item)
+}
+
+// HasAll returns true if and only if all items are contained in the set.
+func (s *Safe[T]) HasAll(items ...T) bool {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	return s.items.HasAll(items...)
+}
+
+// HasAny returns true if any items are contained in the set.
+func (s *Safe[T]) HasAny(items ...T) bool {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	return s.items.HasAny(items...)
+}
+
+// Difference returns a set of objects that are not in s2.
+func (s *Safe[T]) Difference(s2 Set[T]) Set[T] {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	return s.items.Difference(s2)
+}
+
+// SymmetricDifference returns a set of elements which are in either of the sets, but not in their intersection.
+func (s *Safe[T]) SymmetricDifference(s2 Set[T]) Set[T] {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	return s.items.SymmetricDifference(s2)
+}
+
+// Union returns a new set which includes items in either s or s2.
+func (s *Safe[T]) Union(s2 Set[T]) Set[T] {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	return s.items.Union(s2)
+}
+
+// Intersection returns a new set which includes the items in BOTH s and s2.
+func (s *Safe[T]) Intersection(s2 Set[T]) Set[T] {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	return s.items.Intersection(s2)
+}
+
+// IsSuperset returns true if and only if s is a superset of s2.
+func (s *Safe[T]) IsSuperset(s2 Set[T]) bool {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	return s.items.IsSuperset(s2)
+}
+
+// Equal returns true if and only if s is equal (as a set) to s2.
+func (s *Safe[T]) Equal(s2 Set[T]) bool {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	return s.items.Equal(s2)
+}
+
+// List returns the contents as a slice, sorted as by Set.List.
+func (s *Safe[T]) List() []T {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	return s.items.List()
+}
+
+// UnsortedList returns the slice with contents in random order.
+func (s *Safe[T]) UnsortedList() []T {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	return s.items.UnsortedList()
+}
+
+// PopAny removes and returns an arbitrary item of the set, or returns false if the set is empty.
+// Concurrent callers never get the same item.
+func (s *Safe[T]) PopAny() (T, bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.items.PopAny()
+}
+
+// Pop removes item from the set, returning true if and only if it was contained in the set. When
+// called concurrently with the same item, only one caller gets true.
+func (s *Safe[T]) Pop(item T) bool {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.items.Pop(item)
+}
+
+// Len returns the size of the set.
+func (s *Safe[T]) Len() int {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	return s.items.Len()
+}
+
+// Snapshot returns a copy of the content of the set, which can be used without locking.
+func (s *Safe[T]) Snapshot() Set[T] {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	if s.items == nil {
+		return New[T]()
+	}
+	return s.items.Clone()
+}
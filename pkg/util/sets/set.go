@@ -0,0 +1,344 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sets
+
+import (
+	"reflect"
+	"sort"
+)
+
+// Set is a set of comparable items, implemented via map[T]struct{} for minimal memory consumption.
+// It has the same semantics as the generated String, Int, Int32, Int64 and Byte sets.
+type Set[T comparable] map[T]Empty
+
+// New creates a Set from a list of values.
+func New[T comparable](items ...T) Set[T] {
+	s := make(Set[T], len(items))
+	s.Insert(items...)
+	return s
+}
+
+// NewWithCapacity creates a Set with room for at least n items, or len(items) if larger, from a
+// list of values. It avoids growing the set when its eventual size is known up front.
+func NewWithCapacity[T comparable](n int, items ...T) Set[T] {
+	if n < len(items) {
+		n = len(items)
+	}
+	s := make(Set[T], n)
+	s.Insert(items...)
+	return s
+}
+
+// KeySet creates a Set from the keys of a map.
+func KeySet[T comparable, V any](theMap map[T]V) Set[T] {
+	s := make(Set[T], len(theMap))
+	for key := range theMap {
+		s[key] = Empty{}
+	}
+	return s
+}
+
+// NewFrom creates a Set from the keys returned by keyFn for items, such as the UIDs of a list of
+// objects.
+func NewFrom[T any, K comparable](items []T, keyFn func(T) K) Set[K] {
+	s := make(Set[K], len(items))
+	for _, item := range items {
+		s[keyFn(item)] = Empty{}
+	}
+	return s
+}
+
+// Insert adds items to the set and returns it.
+func (s Set[T]) Insert(items ...T) Set[T] {
+	for _, item := range items {
+		s[item] = Empty{}
+	}
+	return s
+}
+
+// Delete removes all items from the set and returns it.
+func (s Set[T]) Delete(items ...T) Set[T] {
+	for _, item := range items {
+		delete(s, item)
+	}
+	return s
+}
+
+// Has returns true if and only if item is contained in the set.
+func (s Set[T]) Has(item T) bool {
+	_, contained := s[item]
+	return contained
+}
+
+// HasAll returns true if and only if all items are contained in the set.
+func (s Set[T]) HasAll(items ...T) bool {
+	for _, item := range items {
+		if !s.Has(item) {
+			return false
+		}
+	}
+	return true
+}
+
+// HasAny returns true if any items are contained in the set.
+func (s Set[T]) HasAny(items ...T) bool {
+	for _, item := range items {
+		if s.Has(item) {
+			return true
+		}
+	}
+	return false
+}
+
+// Clone returns a new set with the same items as s. The clone of a nil set is nil.
+func (s Set[T]) Clone() Set[T] {
+	if s == nil {
+		return nil
+	}
+	result := make(Set[T], len(s))
+	for key := range s {
+		result[key] = Empty{}
+	}
+	return result
+}
+
+// DeepCopy is an alias of Clone that allows deepcopy-gen to copy fields of type Set.
+func (s Set[T]) DeepCopy() Set[T] {
+	return s.Clone()
+}
+
+// Difference returns a set of objects that are not in s2.
+// For example:
+// s1 = {a1, a2, a3}
+// s2 = {a1, a2, a4, a5}
+// s1.Difference(s2) = {a3}
+// s2.Difference(s1) = {a4, a5}
+func (s Set[T]) Difference(s2 Set[T]) Set[T] {
+	result := NewWithCapacity[T](len(s))
+	for key := range s {
+		if !s2.Has(key) {
+			result.Insert(key)
+		}
+	}
+	return result
+}
+
+// SymmetricDifference returns a set of elements which are in either of the sets, but not in their intersection.
+// For example:
+// s1 = {a1, a2, a3}
+// s2 = {a1, a2, a4, a5}
+// s1.SymmetricDifference(s2) = {a3, a4, a5}
+// s2.SymmetricDifference(s1) = {a3, a4, a5}
+func (s1 Set[T]) SymmetricDifference(s2 Set[T]) Set[T] {
+	return s1.Difference(s2).Union(s2.Difference(s1))
+}
+
+// Union returns a new set which includes items in either s1 or s2.
+// For example:
+// s1 = {a1, a2}
+// s2 = {a3, a4}
+// s1.Union(s2) = {a1, a2, a3, a4}
+// s2.Union(s1) = {a1, a2, a3, a4}
+func (s1 Set[T]) Union(s2 Set[T]) Set[T] {
+	result := NewWithCapacity[T](len(s1) + len(s2))
+	for key := range s1 {
+		result.Insert(key)
+	}
+	for key := range s2 {
+		result.Insert(key)
+	}
+	return result
+}
+
+// Intersection returns a new set which includes the items in BOTH s1 and s2.
+// For example:
+// s1 = {a1, a2}
+// s2 = {a2, a3}
+// s1.Intersection(s2) = {a2}
+func (s1 Set[T]) Intersection(s2 Set[T]) Set[T] {
+	walk, other := s1, s2
+	if s2.Len() < s1.Len() {
+		walk, other = s2, s1
+	}
+	result := NewWithCapacity[T](len(walk))
+	for key := range walk {
+		if other.Has(key) {
+			result.Insert(key)
+		}
+	}
+	return result
+}
+
+// UnionInPlace adds the items of s2 to s1. Unlike Union, it does not allocate a new set.
+func (s1 Set[T]) UnionInPlace(s2 Set[T]) {
+	for key := range s2 {
+		s1[key] = Empty{}
+	}
+}
+
+// DeleteAll removes the items of s2 from s1, leaving in s1 what s1.Difference(s2) would return.
+func (s1 Set[T]) DeleteAll(s2 Set[T]) {
+	for key := range s2 {
+		delete(s1, key)
+	}
+}
+
+// RetainAll removes the items of s1 that are not in s2, leaving in s1 what s1.Intersection(s2)
+// would return.
+func (s1 Set[T]) RetainAll(s2 Set[T]) {
+	for key := range s1 {
+		if !s2.Has(key) {
+			delete(s1, key)
+		}
+	}
+}
+
+// IsSuperset returns true if and only if s1 is a superset of s2.
+func (s1 Set[T]) IsSuperset(s2 Set[T]) bool {
+	for item := range s2 {
+		if !s1.Has(item) {
+			return false
+		}
+	}
+	return true
+}
+
+// IsSubset returns true if and only if s1 is a subset of s2.
+func (s1 Set[T]) IsSubset(s2 Set[T]) bool {
+	return s2.IsSuperset(s1)
+}
+
+// Disjoint returns true if and only if s1 and s2 have no items in common. Unlike checking the
+// length of their Intersection, it does not allocate.
+func (s1 Set[T]) Disjoint(s2 Set[T]) bool {
+	walk, other := s1, s2
+	if s2.Len() < s1.Len() {
+		walk, other = s2, s1
+	}
+	for key := range walk {
+		if other.Has(key) {
+			return false
+		}
+	}
+	return true
+}
+
+// Equal returns true if and only if s1 is equal (as a set) to s2.
+// Two sets are equal if their membership is identical.
+// (In practice, this means same elements, order doesn't matter)
+func (s1 Set[T]) Equal(s2 Set[T]) bool {
+	return len(s1) == len(s2) && s1.IsSuperset(s2)
+}
+
+// List returns the contents as a slice, sorted if T is a string, integer or floating point type.
+func (s Set[T]) List() []T {
+	res := s.UnsortedList()
+	if less := lessFuncFor(res); less != nil {
+		sort.Slice(res, less)
+	}
+	return res
+}
+
+// UnsortedList returns the slice with contents in random order.
+func (s Set[T]) UnsortedList() []T {
+	res := make([]T, 0, len(s))
+	for key := range s {
+		res = append(res, key)
+	}
+	return res
+}
+
+// PopAny removes and returns an arbitrary item of the set, or returns false if the set is empty.
+func (s Set[T]) PopAny() (T, bool) {
+	for key := range s {
+		delete(s, key)
+		return key, true
+	}
+	var zeroValue T
+	return zeroValue, false
+}
+
+// Pop removes item from the set, returning true if and only if it was contained in the set.
+func (s Set[T]) Pop(item T) bool {
+	if _, contained := s[item]; !contained {
+		return false
+	}
+	delete(s, item)
+	return true
+}
+
+// Len returns the size of the set.
+func (s Set[T]) Len() int {
+	return len(s)
+}
+
+// lessFuncFor returns a comparator for sort.Slice over items, based on the kind of their type, or
+// nil if the kind has no natural order.
+func lessFuncFor[T comparable](items []T) func(i, j int) bool {
+	v := reflect.ValueOf(items)
+	switch v.Type().Elem().Kind() {
+	case reflect.String:
+		return func(i, j int) bool { return v.Index(i).String() < v.Index(j).String() }
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return func(i, j int) bool { return v.Index(i).Int() < v.Index(j).Int() }
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return func(i, j int) bool { return v.Index(i).Uint() < v.Index(j).Uint() }
+	case reflect.Float32, reflect.Float64:
+		return func(i, j int) bool { return v.Index(i).Float() < v.Index(j).Float() }
+	}
+	return nil
+}
+
+// UnionOf returns a new set which includes the items in any of sets.
+func UnionOf[T comparable](sets ...Set[T]) Set[T] {
+	size := 0
+	for _, s := range sets {
+		if s.Len() > size {
+			size = s.Len()
+		}
+	}
+	result := make(Set[T], size)
+	for _, s := range sets {
+		result.UnionInPlace(s)
+	}
+	return result
+}
+
+// IntersectionOf returns a new set which includes the items in all of sets. The intersection of no
+// sets is empty.
+func IntersectionOf[T comparable](sets ...Set[T]) Set[T] {
+	if len(sets) == 0 {
+		return New[T]()
+	}
+	smallest := 0
+	for i, s := range sets {
+		if s.Len() < sets[smallest].Len() {
+			smallest = i
+		}
+	}
+	result := make(Set[T], sets[smallest].Len())
+	result.UnionInPlace(sets[smallest])
+	for i, s := range sets {
+		if len(result) == 0 {
+			break
+		}
+		if i != smallest {
+			result.RetainAll(s)
+		}
+	}
+	return result
+}
@@ -0,0 +1,1409 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sets
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"math/rand"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/util/sets/settest"
+	"sigs.k8s.io/yaml"
+)
+
+func TestSet(t *testing.T) {
+	s := New[string]()
+	if s.Len() != 0 {
+		t.Errorf("Expected len=0: %d", s.Len())
+	}
+	s.Insert("a", "b")
+	if s.Len() != 2 {
+		t.Errorf("Expected len=2: %d", s.Len())
+	}
+	s.Insert("c")
+	if s.Has("d") {
+		t.Errorf("Unexpected contents: %#v", s)
+	}
+	if !s.Has("a") {
+		t.Errorf("Missing contents: %#v", s)
+	}
+	s.Delete("a")
+	if s.Has("a") {
+		t.Errorf("Unexpected contents: %#v", s)
+	}
+	s.Insert("a")
+	if s.HasAll("a", "b", "d") || !s.HasAll("a", "b") {
+		t.Errorf("Unexpected HasAll results: %#v", s)
+	}
+	if !s.HasAny("d", "a") || s.HasAny("d", "e") {
+		t.Errorf("Unexpected HasAny results: %#v", s)
+	}
+	s2 := New("a", "b", "d")
+	if s.IsSuperset(s2) {
+		t.Errorf("Unexpected contents: %#v", s)
+	}
+	s2.Delete("d")
+	if !s.IsSuperset(s2) {
+		t.Errorf("Missing contents: %#v", s)
+	}
+	if s.Equal(s2) || !s.Equal(New("c", "b", "a")) {
+		t.Errorf("Unexpected Equal results: %#v", s)
+	}
+}
+
+func TestSetOperations(t *testing.T) {
+	s1 := New(1, 2, 3)
+	s2 := New(2, 3, 4, 5)
+	if e, a := New(1), s1.Difference(s2); !e.Equal(a) {
+		t.Errorf("Expected %v, got %v", e, a)
+	}
+	if e, a := New(4, 5), s2.Difference(s1); !e.Equal(a) {
+		t.Errorf("Expected %v, got %v", e, a)
+	}
+	if e, a := New(1, 2, 3, 4, 5), s1.Union(s2); !e.Equal(a) {
+		t.Errorf("Expected %v, got %v", e, a)
+	}
+	if e, a := New(2, 3), s1.Intersection(s2); !e.Equal(a) {
+		t.Errorf("Expected %v, got %v", e, a)
+	}
+	if e, a := New(1, 4, 5), s1.SymmetricDifference(s2); !e.Equal(a) {
+		t.Errorf("Expected %v, got %v", e, a)
+	}
+	if e, a := New(1, 4, 5), s2.SymmetricDifference(s1); !e.Equal(a) {
+		t.Errorf("Expected %v, got %v", e, a)
+	}
+	if e, a := New("a", "b"), KeySet(map[string]int{"a": 1, "b": 2}); !e.Equal(a) {
+		t.Errorf("Expected %v, got %v", e, a)
+	}
+}
+
+func TestSetList(t *testing.T) {
+	if e, a := []string{"a", "b", "c", "z"}, New("z", "c", "a", "b").List(); !reflect.DeepEqual(e, a) {
+		t.Errorf("Expected %v, got %v", e, a)
+	}
+	if e, a := []int64{-3, 1, 20}, New[int64](20, -3, 1).List(); !reflect.DeepEqual(e, a) {
+		t.Errorf("Expected %v, got %v", e, a)
+	}
+	if e, a := []float64{0.5, 1, 2.5}, New(2.5, 0.5, 1).List(); !reflect.DeepEqual(e, a) {
+		t.Errorf("Expected %v, got %v", e, a)
+	}
+	type key struct{ a, b int }
+	if l := New(key{1, 2}, key{3, 4}).List(); len(l) != 2 {
+		t.Errorf("Expected 2 items, got %v", l)
+	}
+}
+
+func TestDiffOf(t *testing.T) {
+	testCases := []struct {
+		name      string
+		old, new  Set[string]
+		added     Set[string]
+		removed   Set[string]
+		unchanged Set[string]
+	}{
+		{
+			name:      "empty",
+			old:       New[string](),
+			new:       New[string](),
+			added:     New[string](),
+			removed:   New[string](),
+			unchanged: New[string](),
+		},
+		{
+			name:      "nil",
+			added:     New[string](),
+			removed:   New[string](),
+			unchanged: New[string](),
+		},
+		{
+			name:      "additions",
+			old:       New("a"),
+			new:       New("a", "b", "c"),
+			added:     New("b", "c"),
+			removed:   New[string](),
+			unchanged: New("a"),
+		},
+		{
+			name:      "removals",
+			old:       New("a", "b", "c"),
+			new:       New("a"),
+			added:     New[string](),
+			removed:   New("b", "c"),
+			unchanged: New("a"),
+		},
+		{
+			name:      "mixed",
+			old:       New("a", "b", "c"),
+			new:       New("b", "c", "d"),
+			added:     New("d"),
+			removed:   New("a"),
+			unchanged: New("b", "c"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			added, removed := DiffOf(tc.old, tc.new)
+			if !added.Equal(tc.added) || !removed.Equal(tc.removed) {
+				t.Errorf("Expected added %v and removed %v, got %v and %v", tc.added, tc.removed, added, removed)
+			}
+			if !added.Equal(tc.new.Difference(tc.old)) || !removed.Equal(tc.old.Difference(tc.new)) {
+				t.Errorf("Expected DiffOf to match Difference")
+			}
+			d := NewDiff(tc.old, tc.new)
+			if !d.Unchanged().Equal(tc.unchanged) || !d.Added().Equal(tc.added) || !d.Removed().Equal(tc.removed) {
+				t.Errorf("Unexpected diff %#v", d)
+			}
+			if e, a := tc.old.Equal(tc.new), d.Empty(); e != a {
+				t.Errorf("Expected Empty to be %v", e)
+			}
+		})
+	}
+}
+
+func TestSortedList(t *testing.T) {
+	type name string
+	if e, a := []name{"a", "b", "c"}, SortedList(New[name]("c", "a", "b")); !reflect.DeepEqual(e, a) {
+		t.Errorf("Expected %v, got %v", e, a)
+	}
+	if e, a := []int{-1, 0, 7}, SortedList(New(7, -1, 0)); !reflect.DeepEqual(e, a) {
+		t.Errorf("Expected %v, got %v", e, a)
+	}
+	if a := SortedList(New[string]()); a == nil || len(a) != 0 {
+		t.Errorf("Expected an empty slice, got %#v", a)
+	}
+}
+
+func benchmarkSet(size int) Set[string] {
+	s := New[string]()
+	for i := 0; i < size; i++ {
+		s.Insert(fmt.Sprintf("item-%d", i))
+	}
+	return s
+}
+
+func BenchmarkSetList(b *testing.B) {
+	s := benchmarkSet(1000)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		s.List()
+	}
+}
+
+func BenchmarkSortedList(b *testing.B) {
+	s := benchmarkSet(1000)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		SortedList(s)
+	}
+}
+
+func TestSafe(t *testing.T) {
+	var zero Safe[string]
+	if zero.Has("a") || zero.Len() != 0 {
+		t.Errorf("Expected the zero value to be empty")
+	}
+	zero.Delete("a")
+	zero.Insert("a")
+	if !zero.Has("a") {
+		t.Errorf("Expected the zero value to be usable")
+	}
+
+	s := NewSafe("a", "b")
+	if !s.HasAll("a", "b") || s.HasAny("c") || !s.IsSuperset(New("a")) || !s.Equal(New("b", "a")) {
+		t.Errorf("Unexpected contents: %v", s.List())
+	}
+	if e, a := New("c"), s.SymmetricDifference(New("a", "b", "c")); !e.Equal(a) {
+		t.Errorf("Expected %v, got %v", e, a)
+	}
+	if e, a := New("a", "b", "c"), s.Union(New("c")); !e.Equal(a) {
+		t.Errorf("Expected %v, got %v", e, a)
+	}
+	if e, a := New("b"), s.Intersection(New("b", "c")); !e.Equal(a) {
+		t.Errorf("Expected %v, got %v", e, a)
+	}
+	if e, a := New("a"), s.Difference(New("b")); !e.Equal(a) {
+		t.Errorf("Expected %v, got %v", e, a)
+	}
+
+	snapshot := s.Snapshot()
+	snapshot.Insert("z")
+	if s.Has("z") {
+		t.Errorf("Expected the snapshot to be a copy")
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				item := fmt.Sprintf("%d-%d", i, j)
+				s.Insert(item)
+				s.Has(item)
+				s.Snapshot()
+				s.Delete(item)
+			}
+		}(i)
+	}
+	wg.Wait()
+	if e, a := []string{"a", "b"}, s.List(); !reflect.DeepEqual(e, a) {
+		t.Errorf("Expected %v, got %v", e, a)
+	}
+}
+
+func TestSetJSON(t *testing.T) {
+	type config struct {
+		Names Set[string] `json:"names"`
+		Ports Set[int]    `json:"ports,omitempty"`
+	}
+	data, err := json.Marshal(config{Names: New("b", "c", "a")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e, a := `{"names":["a","b","c"]}`, string(data); e != a {
+		t.Errorf("Expected %s, got %s", e, a)
+	}
+
+	decoded := config{}
+	if err := yaml.Unmarshal([]byte("names: [p, q]\nports: [80, 443]\n"), &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if !decoded.Names.Equal(New("p", "q")) || !decoded.Ports.Equal(New(80, 443)) {
+		t.Errorf("Unexpected decoded config %#v", decoded)
+	}
+	if data, err := yaml.Marshal(decoded); err != nil || string(data) != "names:\n- p\n- q\nports:\n- 80\n- 443\n" {
+		t.Errorf("Unexpected YAML %q: %v", data, err)
+	}
+
+	if err := json.Unmarshal([]byte(`{"names":["a","a"]}`), &decoded); err == nil {
+		t.Errorf("Expected an error for duplicate items")
+	}
+	if err := json.Unmarshal([]byte(`{"names":"a"}`), &decoded); err == nil {
+		t.Errorf("Expected an error for a non-array value")
+	}
+
+	var nilSet Set[string]
+	if data, err := json.Marshal(nilSet); err != nil || string(data) != "null" {
+		t.Errorf("Expected null, got %s: %v", data, err)
+	}
+	if err := json.Unmarshal([]byte("null"), &nilSet); err != nil || nilSet != nil {
+		t.Errorf("Expected a nil set, got %#v: %v", nilSet, err)
+	}
+
+	type key struct {
+		Name string `json:"name"`
+	}
+	if data, err := json.Marshal(New(key{"b"}, key{"a"})); err != nil || string(data) != `[{"name":"a"},{"name":"b"}]` {
+		t.Errorf("Expected items to be sorted by their encoding, got %s: %v", data, err)
+	}
+}
+
+func TestSetYAMLMarshaler(t *testing.T) {
+	if v, err := New(2, 1).MarshalYAML(); err != nil || !reflect.DeepEqual(v, []int{1, 2}) {
+		t.Errorf("Unexpected value %#v: %v", v, err)
+	}
+	s := Set[int]{}
+	unmarshal := func(data string) func(interface{}) error {
+		return func(v interface{}) error { return json.Unmarshal([]byte(data), v) }
+	}
+	if err := s.UnmarshalYAML(unmarshal("[3, 4]")); err != nil || !s.Equal(New(3, 4)) {
+		t.Errorf("Unexpected set %v: %v", s, err)
+	}
+	if err := s.UnmarshalYAML(unmarshal("[3, 3]")); err == nil {
+		t.Errorf("Expected an error for duplicate items")
+	}
+}
+
+func TestSetPop(t *testing.T) {
+	s := New(1, 2)
+	if !s.Pop(1) || s.Pop(1) || s.Has(1) {
+		t.Errorf("Unexpected contents after Pop: %v", s)
+	}
+	if item, ok := s.PopAny(); !ok || item != 2 || s.Len() != 0 {
+		t.Errorf("Unexpected PopAny result %d, %v: %v", item, ok, s)
+	}
+	if item, ok := s.PopAny(); ok || item != 0 {
+		t.Errorf("Expected PopAny to fail on an empty set, got %d", item)
+	}
+}
+
+func TestSafePopAny(t *testing.T) {
+	s := NewSafe[int]()
+	for i := 0; i < 1000; i++ {
+		s.Insert(i)
+	}
+	popped := make(chan int, 1000)
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				item, ok := s.PopAny()
+				if !ok {
+					return
+				}
+				popped <- item
+			}
+		}()
+	}
+	wg.Wait()
+	close(popped)
+	seen := New[int]()
+	for item := range popped {
+		if seen.Has(item) {
+			t.Errorf("Item %d was popped twice", item)
+		}
+		seen.Insert(item)
+	}
+	if seen.Len() != 1000 || s.Len() != 0 || s.Pop(1) {
+		t.Errorf("Expected all items to be popped once, got %d", seen.Len())
+	}
+}
+
+func TestSetClone(t *testing.T) {
+	s := New("a", "b")
+	clone := s.Clone()
+	if !clone.Equal(s) {
+		t.Errorf("Expected %v, got %v", s, clone)
+	}
+	clone.Insert("c")
+	if s.Has("c") {
+		t.Errorf("Expected the clone to be independent of the original")
+	}
+	if copied := s.DeepCopy(); !copied.Equal(s) {
+		t.Errorf("Expected %v, got %v", s, copied)
+	}
+	var nilSet Set[string]
+	if nilSet.Clone() != nil {
+		t.Errorf("Expected the clone of a nil set to be nil")
+	}
+	if New[int]().Clone() == nil {
+		t.Errorf("Expected the clone of an empty set to not be nil")
+	}
+}
+
+func BenchmarkSetClone(b *testing.B) {
+	s := benchmarkSet(1000)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		s.Clone()
+	}
+}
+
+func BenchmarkSetUnionCopy(b *testing.B) {
+	s := benchmarkSet(1000)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		s.Union(New[string]())
+	}
+}
+
+func TestSetFilter(t *testing.T) {
+	even := func(i int) bool { return i%2 == 0 }
+	if e, a := New(2, 4), New(1, 2, 3, 4).Filter(even); !e.Equal(a) {
+		t.Errorf("Expected %v, got %v", e, a)
+	}
+	if a := New[int]().Filter(even); a == nil || a.Len() != 0 {
+		t.Errorf("Expected an empty set, got %#v", a)
+	}
+	s := New(1, 2)
+	if a := s.Filter(func(int) bool { return true }); !a.Equal(s) {
+		t.Errorf("Expected %v, got %v", s, a)
+	}
+}
+
+func TestMap(t *testing.T) {
+	if e, a := New("1", "2"), Map(New(1, 2), func(i int) string { return fmt.Sprint(i) }); !e.Equal(a) {
+		t.Errorf("Expected %v, got %v", e, a)
+	}
+	if e, a := New(0, 1), Map(New(1, 2, 3), func(i int) int { return i % 2 }); !e.Equal(a) {
+		t.Errorf("Expected merged items %v, got %v", e, a)
+	}
+	s := New("a", "b")
+	if a := Map(s, func(s string) string { return s }); !a.Equal(s) {
+		t.Errorf("Expected %v, got %v", s, a)
+	}
+	if a := Map(New[int](), func(i int) int { return i }); a == nil || a.Len() != 0 {
+		t.Errorf("Expected an empty set, got %#v", a)
+	}
+}
+
+func TestReduce(t *testing.T) {
+	sum := func(acc, i int) int { return acc + i }
+	if e, a := 6, Reduce(New(1, 2, 3), 0, sum); e != a {
+		t.Errorf("Expected %d, got %d", e, a)
+	}
+	if e, a := 10, Reduce(New[int](), 10, sum); e != a {
+		t.Errorf("Expected the initial value %d for an empty set, got %d", e, a)
+	}
+	collected := Reduce(New("a", "b"), New[string](), func(acc Set[string], s string) Set[string] {
+		acc.Insert(s)
+		return acc
+	})
+	if e := New("a", "b"); !e.Equal(collected) {
+		t.Errorf("Expected %v, got %v", e, collected)
+	}
+}
+
+func TestGroupBy(t *testing.T) {
+	type object struct{ namespace, name string }
+	objects := []object{{"a", "x"}, {"b", "x"}, {"a", "y"}, {"a", "x"}}
+	groups := GroupBy(objects, func(o object) string { return o.namespace })
+	expected := map[string]Set[object]{
+		"a": New(object{"a", "x"}, object{"a", "y"}),
+		"b": New(object{"b", "x"}),
+	}
+	if !reflect.DeepEqual(expected, groups) {
+		t.Errorf("Expected %v, got %v", expected, groups)
+	}
+	if groups := GroupBy(nil, func(o object) string { return o.namespace }); groups == nil || len(groups) != 0 {
+		t.Errorf("Expected no groups, got %v", groups)
+	}
+}
+
+type hashedItem struct {
+	name  string
+	kinds []string
+}
+
+func hashedItemEqual(a, b hashedItem) bool {
+	return a.name == b.name && reflect.DeepEqual(a.kinds, b.kinds)
+}
+
+func TestHashed(t *testing.T) {
+	hash := func(i hashedItem) uint64 {
+		h := fnv.New64a()
+		h.Write([]byte(i.name))
+		for _, k := range i.kinds {
+			h.Write([]byte{0})
+			h.Write([]byte(k))
+		}
+		return h.Sum64()
+	}
+	a := hashedItem{"a", []string{"Pod"}}
+	b := hashedItem{"b", []string{"Pod", "Node"}}
+	c := hashedItem{"c", nil}
+
+	s := NewHashed(hash, hashedItemEqual, a, b, hashedItem{"a", []string{"Pod"}})
+	if s.Len() != 2 || !s.HasAll(a, b) || s.Has(c) || !s.HasAny(c, b) {
+		t.Errorf("Unexpected contents: %v", s.UnsortedList())
+	}
+	s2 := NewHashed(hash, hashedItemEqual, b, c)
+	if e, a := NewHashed(hash, hashedItemEqual, a, b, c), s.Union(s2); !e.Equal(a) {
+		t.Errorf("Expected %v, got %v", e.UnsortedList(), a.UnsortedList())
+	}
+	if e, a := NewHashed(hash, hashedItemEqual, b), s.Intersection(s2); !e.Equal(a) {
+		t.Errorf("Expected %v, got %v", e.UnsortedList(), a.UnsortedList())
+	}
+	if e, a := NewHashed(hash, hashedItemEqual, a), s.Difference(s2); !e.Equal(a) {
+		t.Errorf("Expected %v, got %v", e.UnsortedList(), a.UnsortedList())
+	}
+	if e, a := NewHashed(hash, hashedItemEqual, a, c), s.SymmetricDifference(s2); !e.Equal(a) {
+		t.Errorf("Expected %v, got %v", e.UnsortedList(), a.UnsortedList())
+	}
+	if s.IsSuperset(s2) || !s.Union(s2).IsSuperset(s2) {
+		t.Errorf("Unexpected IsSuperset results")
+	}
+	s.Delete(a, c)
+	if s.Len() != 1 || s.Has(a) || !s.Has(b) {
+		t.Errorf("Unexpected contents after Delete: %v", s.UnsortedList())
+	}
+}
+
+func TestHashedCollisions(t *testing.T) {
+	collide := func(hashedItem) uint64 { return 42 }
+	items := []hashedItem{{"a", nil}, {"b", nil}, {"c", []string{"x"}}, {"c", []string{"y"}}}
+	s := NewHashed(collide, hashedItemEqual, items...)
+	if s.Len() != 4 || !s.HasAll(items...) || s.Has(hashedItem{"d", nil}) {
+		t.Errorf("Unexpected contents: %v", s.UnsortedList())
+	}
+	s.Insert(items[0])
+	s.Delete(items[1], hashedItem{"d", nil})
+	if s.Len() != 3 || s.Has(items[1]) || !s.HasAll(items[0], items[2], items[3]) {
+		t.Errorf("Unexpected contents after Delete: %v", s.UnsortedList())
+	}
+	s.Delete(items...)
+	if s.Len() != 0 || len(s.UnsortedList()) != 0 {
+		t.Errorf("Expected an empty set, got %v", s.UnsortedList())
+	}
+}
+
+func TestCounted(t *testing.T) {
+	c := NewCounted("finalizer-a", "finalizer-a", "finalizer-b")
+	if c.Count("finalizer-a") != 2 || c.Count("finalizer-b") != 1 || c.Count("finalizer-c") != 0 {
+		t.Errorf("Unexpected counts: %v", c)
+	}
+	if c.Len() != 2 || c.Total() != 3 {
+		t.Errorf("Expected 2 items inserted 3 times, got %d and %d", c.Len(), c.Total())
+	}
+	if e, a := New("finalizer-a", "finalizer-b"), c.Set(); !e.Equal(a) {
+		t.Errorf("Expected %v, got %v", e, a)
+	}
+
+	if removed := c.Remove("finalizer-a", "finalizer-c"); len(removed) != 0 {
+		t.Errorf("Expected no items to be removed, got %v", removed)
+	}
+	if !c.Has("finalizer-a") || c.Count("finalizer-a") != 1 {
+		t.Errorf("Expected finalizer-a to be counted once, got %d", c.Count("finalizer-a"))
+	}
+	if e, a := []string{"finalizer-a", "finalizer-b"}, c.Remove("finalizer-a", "finalizer-b"); !reflect.DeepEqual(e, a) {
+		t.Errorf("Expected %v to be removed, got %v", e, a)
+	}
+	if c.Has("finalizer-a") || c.Len() != 0 || c.Set().Len() != 0 {
+		t.Errorf("Expected an empty set, got %v", c)
+	}
+}
+
+func TestSetSubsetAndDisjoint(t *testing.T) {
+	testCases := []struct {
+		s1, s2   Set[string]
+		subset   bool
+		disjoint bool
+	}{
+		{s1: New("a"), s2: New("a", "b"), subset: true},
+		{s1: New("a", "b"), s2: New("a"), subset: false},
+		{s1: New("a", "b"), s2: New("b", "a"), subset: true},
+		{s1: New("a"), s2: New("b", "c"), subset: false, disjoint: true},
+		{s1: New[string](), s2: New("a"), subset: true, disjoint: true},
+		{s1: nil, s2: nil, subset: true, disjoint: true},
+	}
+	for _, tc := range testCases {
+		if a := tc.s1.IsSubset(tc.s2); a != tc.subset {
+			t.Errorf("Expected %v.IsSubset(%v) to be %v", tc.s1, tc.s2, tc.subset)
+		}
+		if a := tc.s1.Disjoint(tc.s2); a != tc.disjoint {
+			t.Errorf("Expected %v.Disjoint(%v) to be %v", tc.s1, tc.s2, tc.disjoint)
+		}
+		if a := tc.s2.Disjoint(tc.s1); a != tc.disjoint {
+			t.Errorf("Expected %v.Disjoint(%v) to be %v", tc.s2, tc.s1, tc.disjoint)
+		}
+	}
+}
+
+func BenchmarkSetDisjoint(b *testing.B) {
+	s1, s2 := benchmarkSet(1000), New("other")
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		s1.Disjoint(s2)
+	}
+}
+
+func TestSetInPlaceOperations(t *testing.T) {
+	s := New(1, 2, 3)
+	s.UnionInPlace(New(3, 4))
+	if e := New(1, 2, 3, 4); !e.Equal(s) {
+		t.Errorf("Expected %v, got %v", e, s)
+	}
+	s.DeleteAll(New(1, 5))
+	if e := New(2, 3, 4); !e.Equal(s) {
+		t.Errorf("Expected %v, got %v", e, s)
+	}
+	s.RetainAll(New(3, 4, 6))
+	if e := New(3, 4); !e.Equal(s) {
+		t.Errorf("Expected %v, got %v", e, s)
+	}
+	s.RetainAll(nil)
+	if s.Len() != 0 {
+		t.Errorf("Expected an empty set, got %v", s)
+	}
+
+	s1, s2 := New("a", "b", "c"), New("b", "c", "d")
+	union, difference, intersection := s1.Clone(), s1.Clone(), s1.Clone()
+	union.UnionInPlace(s2)
+	difference.DeleteAll(s2)
+	intersection.RetainAll(s2)
+	if !union.Equal(s1.Union(s2)) || !difference.Equal(s1.Difference(s2)) || !intersection.Equal(s1.Intersection(s2)) {
+		t.Errorf("Expected in-place operations to match their allocating counterparts")
+	}
+}
+
+func benchmarkOperands() (Set[string], Set[string]) {
+	s1, s2 := benchmarkSet(1000), New[string]()
+	for i := 500; i < 1500; i++ {
+		s2.Insert(fmt.Sprintf("item-%d", i))
+	}
+	return s1, s2
+}
+
+func BenchmarkSetUnion(b *testing.B) {
+	s1, s2 := benchmarkOperands()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		s1 = s1.Union(s2)
+	}
+}
+
+func BenchmarkSetUnionInPlace(b *testing.B) {
+	s1, s2 := benchmarkOperands()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		s1.UnionInPlace(s2)
+	}
+}
+
+func BenchmarkSetDifference(b *testing.B) {
+	s1, s2 := benchmarkOperands()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		s1.Difference(s2)
+	}
+}
+
+func BenchmarkSetDeleteAll(b *testing.B) {
+	s1, s2 := benchmarkOperands()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		s := s1.Clone()
+		b.StartTimer()
+		s.DeleteAll(s2)
+	}
+}
+
+func BenchmarkSetIntersection(b *testing.B) {
+	s1, s2 := benchmarkOperands()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		s1.Intersection(s2)
+	}
+}
+
+func BenchmarkSetRetainAll(b *testing.B) {
+	s1, s2 := benchmarkOperands()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		s := s1.Clone()
+		b.StartTimer()
+		s.RetainAll(s2)
+	}
+}
+
+func TestUnionOf(t *testing.T) {
+	if e, a := New(1, 2, 3, 4), UnionOf(New(1, 2), New(2, 3), nil, New(4)); !e.Equal(a) {
+		t.Errorf("Expected %v, got %v", e, a)
+	}
+	if a := UnionOf[int](); a == nil || a.Len() != 0 {
+		t.Errorf("Expected an empty set, got %#v", a)
+	}
+	s := New("a")
+	u := UnionOf(s)
+	u.Insert("b")
+	if s.Has("b") {
+		t.Errorf("Expected the union to be a new set")
+	}
+}
+
+func TestIntersectionOf(t *testing.T) {
+	if e, a := New(2, 3), IntersectionOf(New(1, 2, 3, 4), New(2, 3, 5), New(0, 2, 3)); !e.Equal(a) {
+		t.Errorf("Expected %v, got %v", e, a)
+	}
+	if e, a := New(1, 2), IntersectionOf(New(1, 2)); !e.Equal(a) {
+		t.Errorf("Expected %v, got %v", e, a)
+	}
+	if a := IntersectionOf(New(1, 2), New[int]()); a.Len() != 0 {
+		t.Errorf("Expected an empty set, got %v", a)
+	}
+	if a := IntersectionOf[int](); a == nil || a.Len() != 0 {
+		t.Errorf("Expected an empty set, got %#v", a)
+	}
+	s := New("a")
+	i := IntersectionOf(s)
+	i.Insert("b")
+	if s.Has("b") {
+		t.Errorf("Expected the intersection to be a new set")
+	}
+}
+
+func TestSetUnsortedList(t *testing.T) {
+	s := New("c", "a", "b")
+	l := s.UnsortedList()
+	if len(l) != 3 || cap(l) != 3 || !New(l...).Equal(s) {
+		t.Errorf("Unexpected list %v", l)
+	}
+	if l := New[int]().UnsortedList(); l == nil || len(l) != 0 {
+		t.Errorf("Expected an empty slice, got %#v", l)
+	}
+	if l := NewSafe(1, 2).UnsortedList(); !New(l...).Equal(New(1, 2)) {
+		t.Errorf("Unexpected list %v", l)
+	}
+}
+
+func BenchmarkSetUnsortedList(b *testing.B) {
+	s := benchmarkSet(1000)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		s.UnsortedList()
+	}
+}
+
+func TestNewFrom(t *testing.T) {
+	type object struct {
+		uid       string
+		namespace string
+	}
+	objects := []object{{"1", "a"}, {"2", "a"}, {"3", "b"}}
+	if e, a := New("1", "2", "3"), NewFrom(objects, func(o object) string { return o.uid }); !e.Equal(a) {
+		t.Errorf("Expected %v, got %v", e, a)
+	}
+	if e, a := New("a", "b"), NewFrom(objects, func(o object) string { return o.namespace }); !e.Equal(a) {
+		t.Errorf("Expected %v, got %v", e, a)
+	}
+	if a := NewFrom(nil, func(o object) string { return o.uid }); a == nil || a.Len() != 0 {
+		t.Errorf("Expected an empty set, got %#v", a)
+	}
+}
+
+// safeAdapter adapts Safe to settest.Interface, whose operations take and return the set type.
+type safeAdapter struct {
+	*Safe[int]
+}
+
+func (s safeAdapter) Insert(items ...int) safeAdapter {
+	return safeAdapter{s.Safe.Insert(items...)}
+}
+
+func (s safeAdapter) Delete(items ...int) safeAdapter {
+	return safeAdapter{s.Safe.Delete(items...)}
+}
+
+func (s safeAdapter) Union(s2 safeAdapter) safeAdapter {
+	return safeAdapter{NewSafe(s.Safe.Union(s2.Snapshot()).UnsortedList()...)}
+}
+
+func (s safeAdapter) Intersection(s2 safeAdapter) safeAdapter {
+	return safeAdapter{NewSafe(s.Safe.Intersection(s2.Snapshot()).UnsortedList()...)}
+}
+
+func (s safeAdapter) Difference(s2 safeAdapter) safeAdapter {
+	return safeAdapter{NewSafe(s.Safe.Difference(s2.Snapshot()).UnsortedList()...)}
+}
+
+func (s safeAdapter) IsSuperset(s2 safeAdapter) bool {
+	return s.Safe.IsSuperset(s2.Snapshot())
+}
+
+func (s safeAdapter) Equal(s2 safeAdapter) bool {
+	return s.Safe.Equal(s2.Snapshot())
+}
+
+func TestConformance(t *testing.T) {
+	samples := []int{1, 2, 3, 5, 8, 13, 21, 34}
+	t.Run("Set", func(t *testing.T) {
+		settest.Run(t, New[int], samples)
+	})
+	t.Run("Safe", func(t *testing.T) {
+		settest.Run(t, func(items ...int) safeAdapter { return safeAdapter{NewSafe(items...)} }, samples)
+	})
+	t.Run("Hashed", func(t *testing.T) {
+		hash := func(i int) uint64 { return uint64(i % 3) }
+		equal := func(a, b int) bool { return a == b }
+		settest.Run(t, func(items ...int) *Hashed[int] { return NewHashed(hash, equal, items...) }, samples)
+	})
+}
+
+func TestSetChaining(t *testing.T) {
+	s := New[string]().Insert("a").Insert("b", "c").Delete("b")
+	if e := New("a", "c"); !e.Equal(s) {
+		t.Errorf("Expected %v, got %v", e, s)
+	}
+	original := New(1)
+	if chained := original.Insert(2); !chained.Equal(original) || !original.Has(2) {
+		t.Errorf("Expected Insert to return its receiver")
+	}
+	if safe := NewSafe[int]().Insert(1, 2).Delete(1); !safe.Equal(New(2)) {
+		t.Errorf("Unexpected contents: %v", safe.List())
+	}
+	equal := func(a, b int) bool { return a == b }
+	if hashed := NewHashed(func(i int) uint64 { return uint64(i) }, equal).Insert(1, 2).Delete(2); hashed.Len() != 1 || !hashed.Has(1) {
+		t.Errorf("Unexpected contents: %v", hashed.UnsortedList())
+	}
+}
+
+func TestSharded(t *testing.T) {
+	s := NewSharded(4, HashString[string])
+	if s.Has("a") || s.Len() != 0 || s.Snapshot().Len() != 0 {
+		t.Errorf("Expected an empty set")
+	}
+	s.Insert("a", "b", "c").Delete("b")
+	if !s.Has("a") || s.Has("b") || s.Len() != 2 {
+		t.Errorf("Unexpected contents: %v", s.Snapshot())
+	}
+	if e, a := New("a", "c"), s.Snapshot(); !e.Equal(a) {
+		t.Errorf("Expected %v, got %v", e, a)
+	}
+
+	single := NewSharded(0, func(i int) uint64 { return uint64(i) })
+	single.Insert(1, 2)
+	if len(single.shards) != 1 || single.Len() != 2 {
+		t.Errorf("Expected a single shard with 2 items")
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				item := fmt.Sprintf("%d-%d", i, j)
+				s.Insert(item)
+				if !s.Has(item) {
+					t.Errorf("Expected %s to be in the set", item)
+				}
+				s.Len()
+				s.Delete(item)
+			}
+		}(i)
+	}
+	wg.Wait()
+	if s.Len() != 2 {
+		t.Errorf("Expected concurrent changes to be undone, got %v", s.Snapshot())
+	}
+}
+
+func TestHashString(t *testing.T) {
+	type uid string
+	if HashString("abc") != HashString(uid("abc")) || HashString("abc") == HashString("abd") {
+		t.Errorf("Unexpected hashes")
+	}
+	h := fnv.New64a()
+	h.Write([]byte("abc"))
+	if e, a := h.Sum64(), HashString("abc"); e != a {
+		t.Errorf("Expected the FNV-1a hash %d, got %d", e, a)
+	}
+}
+
+func benchmarkContention(b *testing.B, insert func(string), has func(string) bool, remove func(string)) {
+	items := make([]string, 1024)
+	for i := range items {
+		items[i] = fmt.Sprintf("uid-%d", i)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		i := 0
+		for pb.Next() {
+			item := items[i%len(items)]
+			insert(item)
+			has(item)
+			remove(item)
+			i++
+		}
+	})
+}
+
+func BenchmarkSafeContention(b *testing.B) {
+	s := NewSafe[string]()
+	benchmarkContention(b, func(i string) { s.Insert(i) }, s.Has, func(i string) { s.Delete(i) })
+}
+
+func BenchmarkShardedContention(b *testing.B) {
+	for _, shards := range []int{1, 16, 64} {
+		b.Run(fmt.Sprintf("%d shards", shards), func(b *testing.B) {
+			s := NewSharded(shards, HashString[string])
+			benchmarkContention(b, func(i string) { s.Insert(i) }, s.Has, func(i string) { s.Delete(i) })
+		})
+	}
+}
+
+func TestSetRange(t *testing.T) {
+	s := New(1, 2, 3, 4)
+	visited := New[int]()
+	s.Range(func(i int) bool {
+		visited.Insert(i)
+		return true
+	})
+	if !visited.Equal(s) {
+		t.Errorf("Expected all items to be visited, got %v", visited)
+	}
+
+	calls := 0
+	s.Range(func(i int) bool {
+		calls++
+		return i%2 != 0
+	})
+	if calls == 0 || calls > 3 {
+		t.Errorf("Expected Range to stop at the first even item, got %d calls", calls)
+	}
+
+	New[int]().Range(func(int) bool {
+		t.Errorf("Expected no calls for an empty set")
+		return true
+	})
+}
+
+func TestSetMatch(t *testing.T) {
+	s := New("example.com/owner", "example.com/team", "app")
+	hasPrefix := func(prefix string) func(string) bool {
+		return func(s string) bool { return strings.HasPrefix(s, prefix) }
+	}
+	if !s.MatchAny(hasPrefix("example.com/")) || s.MatchAny(hasPrefix("other.io/")) {
+		t.Errorf("Unexpected MatchAny results")
+	}
+	if s.MatchAll(hasPrefix("example.com/")) || !s.MatchAll(func(s string) bool { return len(s) > 0 }) {
+		t.Errorf("Unexpected MatchAll results")
+	}
+	empty := New[string]()
+	if empty.MatchAny(hasPrefix("")) || !empty.MatchAll(hasPrefix("x")) {
+		t.Errorf("Unexpected results for an empty set")
+	}
+}
+
+func TestEqualFunc(t *testing.T) {
+	foldEqual := strings.EqualFold
+	testCases := []struct {
+		a, b     []string
+		expected bool
+	}{
+		{a: nil, b: nil, expected: true},
+		{a: []string{"a"}, b: nil, expected: false},
+		{a: []string{"a", "B"}, b: []string{"b", "A"}, expected: true},
+		{a: []string{"a", "a", "b"}, b: []string{"B", "A"}, expected: true},
+		{a: []string{"a", "b"}, b: []string{"a", "c"}, expected: false},
+		{a: []string{"a"}, b: []string{"a", "b"}, expected: false},
+	}
+	for _, tc := range testCases {
+		if a := EqualFunc(tc.a, tc.b, foldEqual); a != tc.expected {
+			t.Errorf("Expected EqualFunc(%v, %v) to be %v", tc.a, tc.b, tc.expected)
+		}
+	}
+	type selector struct{ keys []string }
+	eq := func(a, b selector) bool { return reflect.DeepEqual(a.keys, b.keys) }
+	if !EqualFunc([]selector{{[]string{"a"}}, {nil}}, []selector{{nil}, {[]string{"a"}}}, eq) {
+		t.Errorf("Expected non-comparable items to be compared with eq")
+	}
+}
+
+func TestPersistent(t *testing.T) {
+	empty := NewPersistent(HashString[string])
+	v1 := empty.With("a", "b")
+	v2 := v1.With("c").Without("a")
+	if empty.Len() != 0 || empty.Has("a") {
+		t.Errorf("Expected the empty set to be unchanged, got %v", empty.Set())
+	}
+	if e, a := New("a", "b"), v1.Set(); !e.Equal(a) || v1.Len() != 2 {
+		t.Errorf("Expected %v, got %v", e, a)
+	}
+	if e, a := New("b", "c"), v2.Set(); !e.Equal(a) || v2.Len() != 2 || v2.Has("a") {
+		t.Errorf("Expected %v, got %v", e, a)
+	}
+	if v3 := v2.With("b").Without("z"); v3.Len() != 2 || v3.root != v2.root {
+		t.Errorf("Expected no-op changes to share the whole set")
+	}
+	calls := 0
+	v2.Range(func(string) bool {
+		calls++
+		return false
+	})
+	if calls != 1 {
+		t.Errorf("Expected Range to stop after the first item, got %d calls", calls)
+	}
+}
+
+func TestPersistentRandomized(t *testing.T) {
+	hashes := map[string]func(int) uint64{
+		"identity": func(i int) uint64 { return uint64(i) },
+		"constant": func(int) uint64 { return 7 },
+		"high":     func(i int) uint64 { return uint64(i%4) << 62 },
+		"mixed":    func(i int) uint64 { return uint64(i%8)<<60 | uint64(i%3) },
+	}
+	for name, hash := range hashes {
+		t.Run(name, func(t *testing.T) {
+			r := rand.New(rand.NewSource(1))
+			s := NewPersistent(hash)
+			expected := New[int]()
+			var versions []Persistent[int]
+			var snapshots []Set[int]
+			for i := 0; i < 2000; i++ {
+				item := r.Intn(200)
+				if r.Intn(3) == 0 {
+					s = s.Without(item)
+					expected.Delete(item)
+				} else {
+					s = s.With(item)
+					expected.Insert(item)
+				}
+				if i%100 == 0 {
+					versions = append(versions, s)
+					snapshots = append(snapshots, expected.Clone())
+				}
+				if s.Has(item) != expected.Has(item) || s.Len() != expected.Len() {
+					t.Fatalf("Unexpected contents after changing %d: %v", item, s.Set())
+				}
+			}
+			if !s.Set().Equal(expected) {
+				t.Errorf("Expected %v, got %v", expected, s.Set())
+			}
+			for i, v := range versions {
+				if !v.Set().Equal(snapshots[i]) || v.Len() != snapshots[i].Len() {
+					t.Errorf("Expected version %d to be unchanged", i)
+				}
+			}
+			s = s.Without(expected.UnsortedList()...)
+			if s.Len() != 0 || s.root != nil {
+				t.Errorf("Expected an empty set, got %v", s.Set())
+			}
+		})
+	}
+}
+
+func BenchmarkPersistentWith(b *testing.B) {
+	s := NewPersistent(HashString[string])
+	for i := 0; i < 10000; i++ {
+		s = s.With(fmt.Sprintf("item-%d", i))
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		s.With("new-item")
+	}
+}
+
+func BenchmarkSetCloneInsert(b *testing.B) {
+	s := benchmarkSet(10000)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		s.Clone().Insert("new-item")
+	}
+}
+
+func TestSetGob(t *testing.T) {
+	type checkpoint struct {
+		Names Set[string]
+		IDs   Set[int64]
+	}
+	in := checkpoint{Names: New("b", "a", "c"), IDs: New[int64](-1, 0, 1<<40)}
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(in); err != nil {
+		t.Fatal(err)
+	}
+	var out checkpoint
+	if err := gob.NewDecoder(&buf).Decode(&out); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(in, out) {
+		t.Errorf("Expected %v, got %v", in, out)
+	}
+
+	data, err := New[string]().GobEncode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var empty Set[string]
+	if err := empty.GobDecode(data); err != nil || empty == nil || empty.Len() != 0 {
+		t.Errorf("Expected an empty set, got %v (%v)", empty, err)
+	}
+}
+
+func TestSetBinary(t *testing.T) {
+	strs := New("", "a", "bb", strings.Repeat("c", 300))
+	data, err := strs.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decodedStrs Set[string]
+	if err := decodedStrs.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	if !strs.Equal(decodedStrs) {
+		t.Errorf("Expected %v, got %v", strs, decodedStrs)
+	}
+	if again, _ := strs.Clone().MarshalBinary(); !bytes.Equal(data, again) {
+		t.Errorf("Expected a deterministic encoding")
+	}
+
+	ints := New(-300, -1, 0, 1, 1<<40)
+	data, err = ints.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decodedInts Set[int]
+	if err := decodedInts.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	if !ints.Equal(decodedInts) {
+		t.Errorf("Expected %v, got %v", ints, decodedInts)
+	}
+
+	uints := New[uint16](0, 1, 65535)
+	data, err = uints.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decodedUints Set[uint16]
+	if err := decodedUints.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	if !uints.Equal(decodedUints) {
+		t.Errorf("Expected %v, got %v", uints, decodedUints)
+	}
+	var narrow Set[uint8]
+	if err := narrow.UnmarshalBinary(data); err == nil {
+		t.Errorf("Expected an overflow error, got %v", narrow)
+	}
+
+	for _, data := range [][]byte{
+		nil,
+		{2, 1, 'a'},
+		{2, 1, 'a', 1, 'a'},
+		{1, 5, 'a'},
+		{1, 1, 'a', 0},
+	} {
+		var s Set[string]
+		if err := s.UnmarshalBinary(data); err == nil {
+			t.Errorf("Expected an error decoding %v, got %v", data, s)
+		}
+	}
+
+	if _, err := New(1.5).MarshalBinary(); err == nil {
+		t.Errorf("Expected an error encoding a set of floats")
+	}
+	var floats Set[float64]
+	if err := floats.UnmarshalBinary([]byte{0}); err == nil {
+		t.Errorf("Expected an error decoding a set of floats")
+	}
+}
+
+func TestSetFormat(t *testing.T) {
+	type point struct{ X, Y int }
+	testCases := []struct {
+		format   string
+		set      interface{}
+		expected string
+	}{
+		{"%v", New("c", "a", "b"), "{a, b, c}"},
+		{"%s", New("c", "a", "b"), "{a, b, c}"},
+		{"%q", New("b", "a"), `{"a", "b"}`},
+		{"%v", New(10, -1, 2), "{-1, 2, 10}"},
+		{"%03d", New(10, 2), "{002, 010}"},
+		{"%.1f", New(1.25, 0.5), "{0.5, 1.2}"},
+		{"%v", New(point{2, 1}, point{1, 2}), "{{1 2}, {2 1}}"},
+		{"%+v", New(point{2, 1}, point{1, 2}), "{{X:1 Y:2}, {X:2 Y:1}}"},
+		{"%v", New[string](), "{}"},
+		{"%v", Set[string](nil), "{}"},
+		{"%v", []Set[int]{New(1), New(2, 3)}, "[{1} {2, 3}]"},
+	}
+	for _, tc := range testCases {
+		if e, a := tc.expected, fmt.Sprintf(tc.format, tc.set); e != a {
+			t.Errorf("%s: Expected %v, got %v", tc.format, e, a)
+		}
+	}
+	if e, a := "{a, b}", New("b", "a").String(); e != a {
+		t.Errorf("Expected %v, got %v", e, a)
+	}
+}
+
+func TestNewWithCapacity(t *testing.T) {
+	for _, n := range []int{-1, 0, 1, 100} {
+		s := NewWithCapacity(n, "a", "b", "a")
+		if e, a := New("a", "b"), s; !e.Equal(a) {
+			t.Errorf("%d: Expected %v, got %v", n, e, a)
+		}
+	}
+	s := NewWithCapacity[int](10)
+	if s == nil || s.Len() != 0 {
+		t.Errorf("Expected an empty set, got %v", s)
+	}
+}
+
+func BenchmarkNewWithCapacity(b *testing.B) {
+	items := benchmarkSet(1000).UnsortedList()
+	b.Run("New", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			s := New[string]()
+			for _, item := range items {
+				s.Insert(item)
+			}
+		}
+	})
+	b.Run("NewWithCapacity", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			s := NewWithCapacity[string](len(items))
+			for _, item := range items {
+				s.Insert(item)
+			}
+		}
+	})
+}
+
+func TestSetHash(t *testing.T) {
+	type point struct{ X, Y int }
+	if e, a := New("a", "b", "c").Hash(), New("c", "b", "a").Hash(); e != a {
+		t.Errorf("Expected equal hashes for equal sets, got %d and %d", e, a)
+	}
+	if e, a := New(point{1, 2}).Hash(), New(point{1, 2}).Hash(); e != a {
+		t.Errorf("Expected equal hashes for equal sets, got %d and %d", e, a)
+	}
+	if e, a := New(0.0).Hash(), New(math.Copysign(0, -1)).Hash(); e != a {
+		t.Errorf("Expected equal hashes for zeros, got %d and %d", e, a)
+	}
+	if e, a := New[string]().Hash(), Set[string](nil).Hash(); e != a {
+		t.Errorf("Expected equal hashes for empty sets, got %d and %d", e, a)
+	}
+
+	hashes := map[uint64]string{}
+	for _, s := range []interface{ Hash() uint64 }{
+		New[int](), New(0), New(1), New(2), New(1, 2), New(0, 3), New(0, 1, 2, 3),
+		New(point{1, 2}), New(point{2, 1}), New(point{1, 2}, point{2, 1}),
+		New(""), New("a"), New("ab"), New("a", "b"),
+	} {
+		description := fmt.Sprintf("%v", s)
+		if other, found := hashes[s.Hash()]; found {
+			t.Errorf("Expected different hashes for %s and %s", other, description)
+		}
+		hashes[s.Hash()] = description
+	}
+
+	if New(true).Hash() == New(false).Hash() {
+		t.Errorf("Expected different hashes for {true} and {false}")
+	}
+
+	s := New("a")
+	before := s.Hash()
+	s.Insert("b")
+	if before == s.Hash() {
+		t.Errorf("Expected the hash to change after an insertion")
+	}
+	s.Delete("b")
+	if before != s.Hash() {
+		t.Errorf("Expected the hash to be restored after a deletion")
+	}
+}
+
+func TestRequireSubset(t *testing.T) {
+	allowed := New("get", "list", "watch")
+	if err := RequireSubset(New("get", "list"), allowed); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	if err := RequireSubset(nil, allowed); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	err := RequireSubset(New("get", "patch", "delete"), allowed)
+	notSubset, ok := err.(*NotSubsetError)
+	if !ok {
+		t.Fatalf("Expected a *NotSubsetError, got %#v", err)
+	}
+	if e, a := []string{"delete", "patch"}, notSubset.Unexpected; !reflect.DeepEqual(e, a) {
+		t.Errorf("Expected %v, got %v", e, a)
+	}
+	if e, a := `unsupported values: "delete", "patch"; supported values: "get", "list", "watch"`, err.Error(); e != a {
+		t.Errorf("Expected %v, got %v", e, a)
+	}
+}
+
+func TestRequireDisjoint(t *testing.T) {
+	if err := RequireDisjoint(New("a", "b"), New("c")); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	err := RequireDisjoint(New("a", "b", "c"), New("c", "b", "d"))
+	notDisjoint, ok := err.(*NotDisjointError)
+	if !ok {
+		t.Fatalf("Expected a *NotDisjointError, got %#v", err)
+	}
+	if e, a := []string{"b", "c"}, notDisjoint.Common; !reflect.DeepEqual(e, a) {
+		t.Errorf("Expected %v, got %v", e, a)
+	}
+	if e, a := `values must not be present in both sets: "b", "c"`, err.Error(); e != a {
+		t.Errorf("Expected %v, got %v", e, a)
+	}
+}
+
+func TestPrioritized(t *testing.T) {
+	var p Prioritized[string]
+	if _, _, ok := p.PopHighest(); ok || p.Len() != 0 || p.Has("a") {
+		t.Errorf("Expected an empty set")
+	}
+	p.Insert("low", 1).Insert("high", 10).Insert("first", 5).Insert("second", 5).Insert("updated", 0)
+	p.Insert("updated", 7)
+	if priority, ok := p.Priority("updated"); !ok || priority != 7 {
+		t.Errorf("Expected priority 7, got %d (%t)", priority, ok)
+	}
+	if !p.Delete("low") || p.Delete("low") || p.Has("low") {
+		t.Errorf("Expected low to be deleted once")
+	}
+	if e, a := New("high", "first", "second", "updated"), p.Set(); !e.Equal(a) {
+		t.Errorf("Expected %v, got %v", e, a)
+	}
+	if item, priority, ok := p.PeekHighest(); !ok || item != "high" || priority != 10 || p.Len() != 4 {
+		t.Errorf("Expected to peek high, got %s %d (%t)", item, priority, ok)
+	}
+
+	var popped []string
+	for {
+		item, _, ok := p.PopHighest()
+		if !ok {
+			break
+		}
+		popped = append(popped, item)
+	}
+	if e, a := []string{"high", "updated", "first", "second"}, popped; !reflect.DeepEqual(e, a) {
+		t.Errorf("Expected %v, got %v", e, a)
+	}
+}
+
+func TestPrioritizedRandomized(t *testing.T) {
+	p := NewPrioritized[int]()
+	priorities := map[int]int{}
+	for i := 0; i < 1000; i++ {
+		item := rand.Intn(100)
+		if rand.Intn(4) == 0 {
+			if e, a := priorities[item] != 0, p.Delete(item); e != a {
+				t.Fatalf("Expected Delete(%d) to return %t, got %t", item, e, a)
+			}
+			delete(priorities, item)
+			continue
+		}
+		priority := rand.Intn(50) + 1
+		p.Insert(item, priority)
+		priorities[item] = priority
+	}
+	last := math.MaxInt
+	for p.Len() > 0 {
+		item, priority, _ := p.PopHighest()
+		if priority > last || priorities[item] != priority {
+			t.Fatalf("Unexpected item %d with priority %d after %d", item, priority, last)
+		}
+		delete(priorities, item)
+		last = priority
+	}
+	if len(priorities) != 0 {
+		t.Errorf("Expected all items to be popped, left %v", priorities)
+	}
+}
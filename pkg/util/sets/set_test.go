@@ -17,8 +17,12 @@ limitations under the License.
 package sets
 
 import (
+	"math"
+	"math/rand"
 	"reflect"
 	"testing"
+
+	"k8s.io/apimachinery/pkg/util/intern"
 )
 
 func TestStringSet(t *testing.T) {
@@ -118,6 +122,42 @@ func TestStringSetDifference(t *testing.T) {
 	}
 }
 
+func TestStringSetSymmetricDifference(t *testing.T) {
+	a := NewString("1", "2", "3")
+	b := NewString("1", "2", "4", "5")
+	c := a.SymmetricDifference(b)
+	d := b.SymmetricDifference(a)
+	if !c.Equal(NewString("3", "4", "5")) {
+		t.Errorf("Unexpected contents: %#v", c.List())
+	}
+	if !d.Equal(NewString("3", "4", "5")) {
+		t.Errorf("Unexpected contents: %#v", d.List())
+	}
+	if e := NewInt(1).SymmetricDifference(NewInt(1)); e.Len() != 0 {
+		t.Errorf("Unexpected contents: %#v", e.List())
+	}
+}
+
+func TestStringSetPop(t *testing.T) {
+	s := NewString("a", "b")
+	if !s.Pop("a") || s.Has("a") {
+		t.Errorf("Expected a to be popped: %#v", s)
+	}
+	if s.Pop("a") {
+		t.Errorf("Expected a to be popped only once")
+	}
+	item, ok := s.PopAny()
+	if !ok || item != "b" || s.Len() != 0 {
+		t.Errorf("Unexpected PopAny result %q, %v: %#v", item, ok, s)
+	}
+	if _, ok := s.PopAny(); ok {
+		t.Errorf("Expected PopAny to fail on an empty set")
+	}
+	if i := NewInt(1, 2); !i.Pop(2) || i.Pop(3) || !i.Equal(NewInt(1)) {
+		t.Errorf("Unexpected contents: %#v", i)
+	}
+}
+
 func TestStringSetHasAny(t *testing.T) {
 	a := NewString("1", "2", "3")
 
@@ -268,3 +308,130 @@ func TestStringIntersection(t *testing.T) {
 		}
 	}
 }
+
+func TestNewStringInterned(t *testing.T) {
+	interner := intern.New()
+	s := NewStringInterned(interner, "a", "b", "a")
+	if !s.Equal(NewString("a", "b")) {
+		t.Errorf("unexpected set %v", s.List())
+	}
+	if interner.Len() != 2 {
+		t.Errorf("expected 2 interned strings, got %d", interner.Len())
+	}
+	if s := NewStringInterned(nil, "a"); !s.Equal(NewString("a")) {
+		t.Errorf("unexpected set %v for a nil interner", s.List())
+	}
+}
+
+// typedSet is the API shared by the String, Int, Int32, Int64, Float64 and Byte sets.
+type typedSet[T comparable, S any] interface {
+	~map[T]Empty
+	Insert(items ...T) S
+	Delete(items ...T) S
+	Has(item T) bool
+	HasAll(items ...T) bool
+	HasAny(items ...T) bool
+	Difference(s2 S) S
+	SymmetricDifference(s2 S) S
+	Union(s2 S) S
+	Intersection(s2 S) S
+	IsSuperset(s2 S) bool
+	Equal(s2 S) bool
+	List() []T
+	UnsortedList() []T
+	PopAny() (T, bool)
+	Pop(item T) bool
+	Len() int
+}
+
+// testTypedSet checks that the operations of a typed set match those of Set on random subsets of
+// samples.
+func testTypedSet[T ordered, S typedSet[T, S]](t *testing.T, newSet func(items ...T) S, samples []T) {
+	r := rand.New(rand.NewSource(1))
+	random := func() []T {
+		var items []T
+		for _, item := range samples {
+			if r.Intn(2) == 0 {
+				items = append(items, item)
+			}
+		}
+		return items
+	}
+	for i := 0; i < 50; i++ {
+		a, b := random(), random()
+		s1, s2 := newSet(a...), newSet(b...)
+		g1, g2 := New(a...), New(b...)
+		for _, c := range []struct {
+			name     string
+			actual   S
+			expected Set[T]
+		}{
+			{"Difference", s1.Difference(s2), g1.Difference(g2)},
+			{"SymmetricDifference", s1.SymmetricDifference(s2), g1.SymmetricDifference(g2)},
+			{"Union", s1.Union(s2), g1.Union(g2)},
+			{"Intersection", s1.Intersection(s2), g1.Intersection(g2)},
+		} {
+			if !Set[T](c.actual).Equal(c.expected) {
+				t.Errorf("%s: expected %v, got %v", c.name, c.expected, c.actual)
+			}
+		}
+		if s1.IsSuperset(s2) != g1.IsSuperset(g2) || s1.Equal(s2) != g1.Equal(g2) || s1.HasAny(b...) != g1.HasAny(b...) || s1.HasAll(b...) != g1.HasAll(b...) {
+			t.Errorf("Unexpected predicate results for %v and %v", a, b)
+		}
+		if e, a := SortedList(g1), s1.List(); !reflect.DeepEqual(e, a) && len(e)+len(a) > 0 {
+			t.Errorf("List: expected %v, got %v", e, a)
+		}
+		if !New(s1.UnsortedList()...).Equal(g1) || s1.Len() != g1.Len() {
+			t.Errorf("UnsortedList: expected %v, got %v", g1, s1.UnsortedList())
+		}
+		if len(b) > 0 && (!s2.Pop(b[0]) || s2.Pop(b[0]) || s2.Has(b[0])) {
+			t.Errorf("Pop: unexpected results for %v", b[0])
+		}
+		if item, ok := s1.PopAny(); ok != (len(a) > 0) || (ok && s1.Has(item)) {
+			t.Errorf("PopAny: unexpected result %v, %v", item, ok)
+		}
+		if s := newSet().Insert(a...).Delete(b...); !Set[T](s).Equal(g1.Difference(g2)) {
+			t.Errorf("Insert and Delete: expected %v, got %v", g1.Difference(g2), s)
+		}
+	}
+}
+
+func TestTypedSetsMatchSet(t *testing.T) {
+	t.Run("String", func(t *testing.T) { testTypedSet(t, NewString, []string{"a", "b", "c", "d", "e"}) })
+	t.Run("Int", func(t *testing.T) { testTypedSet(t, NewInt, []int{-2, 0, 1, 5, 8}) })
+	t.Run("Int32", func(t *testing.T) { testTypedSet(t, NewInt32, []int32{-2, 0, 1, 5, 8}) })
+	t.Run("Int64", func(t *testing.T) { testTypedSet(t, NewInt64, []int64{-2, 0, 1, 5, 8}) })
+	t.Run("Float64", func(t *testing.T) { testTypedSet(t, NewFloat64, []float64{-1.5, 0, 0.25, 1, 1e9}) })
+	t.Run("Byte", func(t *testing.T) { testTypedSet(t, NewByte, []byte{0, 1, 'a', 'z', 255}) })
+}
+
+func TestTypedSetConversions(t *testing.T) {
+	s := NewString("a", "b")
+	generic := Set[string](s)
+	generic.Insert("c")
+	if !s.Has("c") {
+		t.Errorf("Expected the conversion to share the content of the set")
+	}
+	if e, a := NewString("a", "b", "c"), String(New("a", "b", "c")); !e.Equal(a) {
+		t.Errorf("Expected %v, got %v", e, a)
+	}
+}
+
+func TestFloat64Set(t *testing.T) {
+	s := NewFloat64(2.5, -1, 0.5)
+	if e, a := []float64{-1, 0.5, 2.5}, s.List(); !reflect.DeepEqual(e, a) {
+		t.Errorf("Expected %v, got %v", e, a)
+	}
+	if !s.Has(0.5) || s.Has(0.25) {
+		t.Errorf("Unexpected contents: %v", s.List())
+	}
+	if e, a := NewFloat64(1, 2), Float64KeySet(map[float64]string{1: "a", 2: "b"}); !e.Equal(a) {
+		t.Errorf("Expected %v, got %v", e, a)
+	}
+
+	// NaN is not equal to itself, so every inserted NaN is a new item that is never found
+	nan := NewFloat64(math.NaN(), math.NaN())
+	if nan.Len() != 2 || nan.Has(math.NaN()) {
+		t.Errorf("Unexpected NaN handling: %v", nan.List())
+	}
+}
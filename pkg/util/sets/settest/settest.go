@@ -0,0 +1,224 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package settest provides a conformance suite checking that set implementations have the
+// semantics of sets.Set.
+package settest // import "k8s.io/apimachinery/pkg/util/sets/settest"
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// Interface is the set behavior checked by Run. S is the type of the operands and results of the
+// set operations, usually the set type itself. Implementations whose operations take or return
+// other types can be checked through an adapter.
+type Interface[T any, S any] interface {
+	Insert(items ...T) S
+	Delete(items ...T) S
+	Has(item T) bool
+	Len() int
+	Union(s2 S) S
+	Intersection(s2 S) S
+	Difference(s2 S) S
+	IsSuperset(s2 S) bool
+	Equal(s2 S) bool
+}
+
+// subsetCount is the number of random subsets of the samples each invariant is checked with.
+const subsetCount = 50
+
+// Run checks that the sets created by newSet behave like sets.Set, using subsets of samples as
+// operands. samples must be distinct items, and newSet must return a new set with the given
+// items. Besides checking the results of operations on known subsets, Run checks algebraic
+// invariants: commutativity, associativity and idempotence of union and intersection, absorption,
+// De Morgan's laws, and that operations don't modify their operands.
+func Run[T any, S Interface[T, S]](t *testing.T, newSet func(items ...T) S, samples []T) {
+	t.Helper()
+	suite := &suite[T, S]{newSet: newSet, samples: samples, rand: rand.New(rand.NewSource(1))}
+
+	t.Run("membership", suite.testMembership)
+	t.Run("operations", suite.testOperations)
+	t.Run("union", func(t *testing.T) {
+		suite.forSubsets(t, func(t *testing.T, a, b, c subset) {
+			sa, sb, sc := suite.build(a), suite.build(b), suite.build(c)
+			suite.expectEqual(t, "a|b = b|a", sa.Union(sb), sb.Union(sa))
+			suite.expectEqual(t, "(a|b)|c = a|(b|c)", sa.Union(sb).Union(sc), sa.Union(sb.Union(sc)))
+			suite.expectEqual(t, "a|a = a", sa.Union(sa), sa)
+			suite.expectEqual(t, "a|(a&b) = a", sa.Union(sa.Intersection(sb)), sa)
+			if !sa.Union(sb).IsSuperset(sa) {
+				t.Errorf("expected a|b >= a for a=%v, b=%v", a, b)
+			}
+		})
+	})
+	t.Run("intersection", func(t *testing.T) {
+		suite.forSubsets(t, func(t *testing.T, a, b, c subset) {
+			sa, sb, sc := suite.build(a), suite.build(b), suite.build(c)
+			suite.expectEqual(t, "a&b = b&a", sa.Intersection(sb), sb.Intersection(sa))
+			suite.expectEqual(t, "(a&b)&c = a&(b&c)", sa.Intersection(sb).Intersection(sc), sa.Intersection(sb.Intersection(sc)))
+			suite.expectEqual(t, "a&a = a", sa.Intersection(sa), sa)
+			suite.expectEqual(t, "a&(a|b) = a", sa.Intersection(sa.Union(sb)), sa)
+			suite.expectEqual(t, "a&(b|c) = (a&b)|(a&c)", sa.Intersection(sb.Union(sc)), sa.Intersection(sb).Union(sa.Intersection(sc)))
+		})
+	})
+	t.Run("difference", func(t *testing.T) {
+		suite.forSubsets(t, func(t *testing.T, a, b, c subset) {
+			sa, sb := suite.build(a), suite.build(b)
+			universe := suite.build(suite.full())
+			suite.expectEqual(t, "U-(a|b) = (U-a)&(U-b)", universe.Difference(sa.Union(sb)), universe.Difference(sa).Intersection(universe.Difference(sb)))
+			suite.expectEqual(t, "U-(a&b) = (U-a)|(U-b)", universe.Difference(sa.Intersection(sb)), universe.Difference(sa).Union(universe.Difference(sb)))
+			suite.expectEqual(t, "(a-b)|(a&b) = a", sa.Difference(sb).Union(sa.Intersection(sb)), sa)
+			suite.expectEqual(t, "(a-b)&b = {}", sa.Difference(sb).Intersection(sb), suite.newSet())
+			suite.expectEqual(t, "a-a = {}", sa.Difference(sa), suite.newSet())
+		})
+	})
+	t.Run("operands", func(t *testing.T) {
+		suite.forSubsets(t, func(t *testing.T, a, b, c subset) {
+			sa, sb := suite.build(a), suite.build(b)
+			sa.Union(sb)
+			sa.Intersection(sb)
+			sa.Difference(sb)
+			sb.Difference(sa)
+			suite.expectContents(t, "a after operations", sa, a)
+			suite.expectContents(t, "b after operations", sb, b)
+		})
+	})
+}
+
+// subset holds whether each sample is in a set.
+type subset []bool
+
+type suite[T any, S Interface[T, S]] struct {
+	newSet  func(items ...T) S
+	samples []T
+	rand    *rand.Rand
+}
+
+func (s *suite[T, S]) full() subset {
+	all := make(subset, len(s.samples))
+	for i := range all {
+		all[i] = true
+	}
+	return all
+}
+
+func (s *suite[T, S]) random() subset {
+	sub := make(subset, len(s.samples))
+	for i := range sub {
+		sub[i] = s.rand.Intn(2) == 0
+	}
+	return sub
+}
+
+func (s *suite[T, S]) items(sub subset) []T {
+	var items []T
+	for i, in := range sub {
+		if in {
+			items = append(items, s.samples[i])
+		}
+	}
+	return items
+}
+
+func (s *suite[T, S]) build(sub subset) S {
+	return s.newSet(s.items(sub)...)
+}
+
+func (s *suite[T, S]) forSubsets(t *testing.T, check func(t *testing.T, a, b, c subset)) {
+	empty := make(subset, len(s.samples))
+	check(t, empty, empty, empty)
+	check(t, s.full(), empty, s.full())
+	for i := 0; i < subsetCount; i++ {
+		check(t, s.random(), s.random(), s.random())
+	}
+}
+
+func (s *suite[T, S]) expectEqual(t *testing.T, invariant string, actual, expected S) {
+	t.Helper()
+	if !actual.Equal(expected) || !expected.Equal(actual) {
+		t.Errorf("expected %s, got %d items instead of %d", invariant, actual.Len(), expected.Len())
+	}
+}
+
+func (s *suite[T, S]) expectContents(t *testing.T, description string, actual S, expected subset) {
+	t.Helper()
+	count := 0
+	for i, in := range expected {
+		if in {
+			count++
+		}
+		if actual.Has(s.samples[i]) != in {
+			t.Errorf("%s: expected Has(%v) to be %v", description, s.samples[i], in)
+		}
+	}
+	if actual.Len() != count {
+		t.Errorf("%s: expected %d items, got %d", description, count, actual.Len())
+	}
+}
+
+func (s *suite[T, S]) testMembership(t *testing.T) {
+	set := s.newSet()
+	expected := make(subset, len(s.samples))
+	s.expectContents(t, "new set", set, expected)
+	for i := 0; i < subsetCount*len(s.samples); i++ {
+		j := s.rand.Intn(len(s.samples))
+		if s.rand.Intn(2) == 0 {
+			set.Insert(s.samples[j])
+			expected[j] = true
+		} else {
+			set.Delete(s.samples[j])
+			expected[j] = false
+		}
+	}
+	s.expectContents(t, "after random inserts and deletes", set, expected)
+
+	set = set.Insert(s.samples...).Insert(s.samples...)
+	s.expectContents(t, "after inserting all samples twice", set, s.full())
+	set = set.Delete(s.samples...)
+	s.expectContents(t, "after deleting all samples", set, make(subset, len(s.samples)))
+}
+
+func (s *suite[T, S]) testOperations(t *testing.T) {
+	s.forSubsets(t, func(t *testing.T, a, b, _ subset) {
+		union, intersection, difference := make(subset, len(a)), make(subset, len(a)), make(subset, len(a))
+		superset := true
+		for i := range a {
+			union[i] = a[i] || b[i]
+			intersection[i] = a[i] && b[i]
+			difference[i] = a[i] && !b[i]
+			superset = superset && (a[i] || !b[i])
+		}
+		sa, sb := s.build(a), s.build(b)
+		s.expectContents(t, "union", sa.Union(sb), union)
+		s.expectContents(t, "intersection", sa.Intersection(sb), intersection)
+		s.expectContents(t, "difference", sa.Difference(sb), difference)
+		if sa.IsSuperset(sb) != superset {
+			t.Errorf("expected IsSuperset to be %v for a=%v, b=%v", superset, a, b)
+		}
+		if !sa.Equal(s.build(a)) || sa.Equal(sb) != s.equalSubsets(a, b) {
+			t.Errorf("unexpected Equal results for a=%v, b=%v", a, b)
+		}
+	})
+}
+
+func (s *suite[T, S]) equalSubsets(a, b subset) bool {
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,124 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sets
+
+import (
+	"sync"
+	"unsafe"
+)
+
+// Sharded is a set that is safe for concurrent use, split into shards with their own locks to
+// reduce contention between goroutines accessing different items. Items are assigned to shards by
+// their hash.
+type Sharded[T comparable] struct {
+	hash   func(T) uint64
+	shards []shard[T]
+}
+
+type shard[T comparable] struct {
+	lock  sync.RWMutex
+	items Set[T]
+	// Pad shards to a cache line so that locking one shard doesn't slow down its neighbors.
+	_ [64 - unsafe.Sizeof(sync.RWMutex{})%64 - unsafe.Sizeof(Set[int]{})%64]byte
+}
+
+// NewSharded creates a Sharded set with shardCount shards, at least one, assigning items to
+// shards with hash. HashString can be used for string-like items.
+func NewSharded[T comparable](shardCount int, hash func(T) uint64) *Sharded[T] {
+	if shardCount < 1 {
+		shardCount = 1
+	}
+	s := &Sharded[T]{hash: hash, shards: make([]shard[T], shardCount)}
+	for i := range s.shards {
+		s.shards[i].items = New[T]()
+	}
+	return s
+}
+
+// HashString returns the 64-bit FNV-1a hash of s, for use with NewSharded.
+func HashString[S ~string](s S) uint64 {
+	const (
+		offset64 = 14695981039346656037
+		prime64  = 1099511628211
+	)
+	h := uint64(offset64)
+	for i := 0; i < len(s); i++ {
+		h ^= uint64(s[i])
+		h *= prime64
+	}
+	return h
+}
+
+func (s *Sharded[T]) shardFor(item T) *shard[T] {
+	return &s.shards[s.hash(item)%uint64(len(s.shards))]
+}
+
+// Insert adds items to the set and returns it.
+func (s *Sharded[T]) Insert(items ...T) *Sharded[T] {
+	for _, item := range items {
+		sh := s.shardFor(item)
+		sh.lock.Lock()
+		sh.items[item] = Empty{}
+		sh.lock.Unlock()
+	}
+	return s
+}
+
+// Delete removes all items from the set and returns it.
+func (s *Sharded[T]) Delete(items ...T) *Sharded[T] {
+	for _, item := range items {
+		sh := s.shardFor(item)
+		sh.lock.Lock()
+		delete(sh.items, item)
+		sh.lock.Unlock()
+	}
+	return s
+}
+
+// Has returns true if and only if item is contained in the set.
+func (s *Sharded[T]) Has(item T) bool {
+	sh := s.shardFor(item)
+	sh.lock.RLock()
+	defer sh.lock.RUnlock()
+	return sh.items.Has(item)
+}
+
+// Len returns the size of the set. Shards are counted one after the other, so the result may not
+// reflect concurrent changes.
+func (s *Sharded[T]) Len() int {
+	n := 0
+	for i := range s.shards {
+		sh := &s.shards[i]
+		sh.lock.RLock()
+		n += len(sh.items)
+		sh.lock.RUnlock()
+	}
+	return n
+}
+
+// Snapshot returns a copy of the content of the set, which can be used without locking. Shards
+// are copied one after the other, so the snapshot may include some concurrent changes only.
+func (s *Sharded[T]) Snapshot() Set[T] {
+	result := make(Set[T], s.Len())
+	for i := range s.shards {
+		sh := &s.shards[i]
+		sh.lock.RLock()
+		result.UnionInPlace(sh.items)
+		sh.lock.RUnlock()
+	}
+	return result
+}
@@ -14,23 +14,23 @@ See the License for the specific language governing permissions and
 limitations under the License.
 */
 
-// Code generated by set-gen. DO NOT EDIT.
-
 package sets
 
-import (
-	"reflect"
-	"sort"
-)
+import "reflect"
 
 // sets.String is a set of strings, implemented via map[string]struct{} for minimal memory consumption.
+// It is a thin wrapper around Set[string], to which it can be converted, and back, with a type
+// conversion: Set[string](s) and String(set).
 type String map[string]Empty
 
+// castString returns s as a Set[string], sharing its content.
+func castString(s String) Set[string] {
+	return Set[string](s)
+}
+
 // NewString creates a String from a list of values.
 func NewString(items ...string) String {
-	ss := String{}
-	ss.Insert(items...)
-	return ss
+	return String(New(items...))
 }
 
 // StringKeySet creates a String from a keys of a map[string](? extends interface{}).
@@ -47,44 +47,27 @@ func StringKeySet(theMap interface{}) String {
 
 // Insert adds items to the set.
 func (s String) Insert(items ...string) String {
-	for _, item := range items {
-		s[item] = Empty{}
-	}
-	return s
+	return String(castString(s).Insert(items...))
 }
 
 // Delete removes all items from the set.
 func (s String) Delete(items ...string) String {
-	for _, item := range items {
-		delete(s, item)
-	}
-	return s
+	return String(castString(s).Delete(items...))
 }
 
 // Has returns true if and only if item is contained in the set.
 func (s String) Has(item string) bool {
-	_, contained := s[item]
-	return contained
+	return castString(s).Has(item)
 }
 
 // HasAll returns true if and only if all items are contained in the set.
 func (s String) HasAll(items ...string) bool {
-	for _, item := range items {
-		if !s.Has(item) {
-			return false
-		}
-	}
-	return true
+	return castString(s).HasAll(items...)
 }
 
 // HasAny returns true if any items are contained in the set.
 func (s String) HasAny(items ...string) bool {
-	for _, item := range items {
-		if s.Has(item) {
-			return true
-		}
-	}
-	return false
+	return castString(s).HasAny(items...)
 }
 
 // Difference returns a set of objects that are not in s2
@@ -94,13 +77,17 @@ func (s String) HasAny(items ...string) bool {
 // s1.Difference(s2) = {a3}
 // s2.Difference(s1) = {a4, a5}
 func (s String) Difference(s2 String) String {
-	result := NewString()
-	for key := range s {
-		if !s2.Has(key) {
-			result.Insert(key)
-		}
-	}
-	return result
+	return String(castString(s).Difference(castString(s2)))
+}
+
+// SymmetricDifference returns a set of elements which are in either of the sets, but not in their intersection.
+// For example:
+// s1 = {a1, a2, a3}
+// s2 = {a1, a2, a4, a5}
+// s1.SymmetricDifference(s2) = {a3, a4, a5}
+// s2.SymmetricDifference(s1) = {a3, a4, a5}
+func (s1 String) SymmetricDifference(s2 String) String {
+	return String(castString(s1).SymmetricDifference(castString(s2)))
 }
 
 // Union returns a new set which includes items in either s1 or s2.
@@ -110,14 +97,7 @@ func (s String) Difference(s2 String) String {
 // s1.Union(s2) = {a1, a2, a3, a4}
 // s2.Union(s1) = {a1, a2, a3, a4}
 func (s1 String) Union(s2 String) String {
-	result := NewString()
-	for key := range s1 {
-		result.Insert(key)
-	}
-	for key := range s2 {
-		result.Insert(key)
-	}
-	return result
+	return String(castString(s1).Union(castString(s2)))
 }
 
 // Intersection returns a new set which includes the item in BOTH s1 and s2
@@ -126,80 +106,42 @@ func (s1 String) Union(s2 String) String {
 // s2 = {a2, a3}
 // s1.Intersection(s2) = {a2}
 func (s1 String) Intersection(s2 String) String {
-	var walk, other String
-	result := NewString()
-	if s1.Len() < s2.Len() {
-		walk = s1
-		other = s2
-	} else {
-		walk = s2
-		other = s1
-	}
-	for key := range walk {
-		if other.Has(key) {
-			result.Insert(key)
-		}
-	}
-	return result
+	return String(castString(s1).Intersection(castString(s2)))
 }
 
 // IsSuperset returns true if and only if s1 is a superset of s2.
 func (s1 String) IsSuperset(s2 String) bool {
-	for item := range s2 {
-		if !s1.Has(item) {
-			return false
-		}
-	}
-	return true
+	return castString(s1).IsSuperset(castString(s2))
 }
 
 // Equal returns true if and only if s1 is equal (as a set) to s2.
 // Two sets are equal if their membership is identical.
 // (In practice, this means same elements, order doesn't matter)
 func (s1 String) Equal(s2 String) bool {
-	return len(s1) == len(s2) && s1.IsSuperset(s2)
+	return castString(s1).Equal(castString(s2))
 }
 
-type sortableSliceOfString []string
-
-func (s sortableSliceOfString) Len() int           { return len(s) }
-func (s sortableSliceOfString) Less(i, j int) bool { return lessString(s[i], s[j]) }
-func (s sortableSliceOfString) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
-
 // List returns the contents as a sorted string slice.
 func (s String) List() []string {
-	res := make(sortableSliceOfString, 0, len(s))
-	for key := range s {
-		res = append(res, key)
-	}
-	sort.Sort(res)
-	return []string(res)
+	return SortedList(castString(s))
 }
 
 // UnsortedList returns the slice with contents in random order.
 func (s String) UnsortedList() []string {
-	res := make([]string, 0, len(s))
-	for key := range s {
-		res = append(res, key)
-	}
-	return res
+	return castString(s).UnsortedList()
 }
 
 // Returns a single element from the set.
 func (s String) PopAny() (string, bool) {
-	for key := range s {
-		s.Delete(key)
-		return key, true
-	}
-	var zeroValue string
-	return zeroValue, false
+	return castString(s).PopAny()
+}
+
+// Pop removes item from the set, returning true if and only if it was contained in the set.
+func (s String) Pop(item string) bool {
+	return castString(s).Pop(item)
 }
 
 // Len returns the size of the set.
 func (s String) Len() int {
 	return len(s)
 }
-
-func lessString(lhs, rhs string) bool {
-	return lhs < rhs
-}
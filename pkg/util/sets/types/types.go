@@ -14,21 +14,18 @@ See the License for the specific language governing permissions and
 limitations under the License.
 */
 
-// Package types just provides input types to the set generator. It also
-// contains a "go generate" block.
-// (You must first `go install k8s.io/code-generator/cmd/set-gen`)
+// Package types used to provide input types to the set generator. The typed sets are now thin
+// wrappers around the generic Set and are no longer generated.
 package types
 
-//go:generate set-gen -i k8s.io/kubernetes/pkg/util/sets/types
 //lint:file-ignore U1000 Ignore unused fields
 
 type ReferenceSetTypes struct {
-	// These types all cause files to be generated.
-	// These types should be reflected in the output of
-	// the "//pkg/util/sets:set-gen" genrule.
+	// These types used to cause files to be generated.
 	a int64
 	b int
 	c byte
 	d string
 	e int32
+	f float64
 }
@@ -0,0 +1,164 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package uuid
+
+import (
+	"crypto/rand"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/clock"
+)
+
+// SortableIDLength is the length of sortable IDs.
+const SortableIDLength = 26
+
+// sortableIDAlphabet is the lowercase Crockford base32 alphabet, which keeps the lexical order
+// of encoded IDs and makes them valid DNS-1123 labels.
+const sortableIDAlphabet = "0123456789abcdefghjkmnpqrstvwxyz"
+
+// maxSortableIDTime is the largest timestamp of sortable IDs, in milliseconds since the Unix epoch.
+const maxSortableIDTime = 1<<48 - 1
+
+// SortableIDGenerator generates k-sortable IDs in the style of ULIDs: a 48 bit timestamp in
+// milliseconds followed by 80 random bits, encoded as 26 characters of lowercase Crockford
+// base32. IDs sort lexically in the order of their timestamps. IDs generated by a generator
+// within the same millisecond increment the random bits of the previous ID, so that they are
+// unique and strictly increasing. IDs of different generators only collide if they draw the
+// same 80 random bits within the same millisecond.
+type SortableIDGenerator struct {
+	lock    sync.Mutex
+	clock   clock.PassiveClock
+	entropy io.Reader
+	// generated is true once an ID was generated, the last of which is lastTime and last.
+	generated bool
+	lastTime  uint64
+	last      [10]byte
+}
+
+// NewSortableIDGenerator returns a generator reading the time from clock and random bits from
+// entropy, defaulting to the real clock and crypto/rand if nil.
+func NewSortableIDGenerator(c clock.PassiveClock, entropy io.Reader) *SortableIDGenerator {
+	if c == nil {
+		c = clock.RealClock{}
+	}
+	if entropy == nil {
+		entropy = rand.Reader
+	}
+	return &SortableIDGenerator{clock: c, entropy: entropy}
+}
+
+var defaultSortableIDGenerator = NewSortableIDGenerator(nil, nil)
+
+// NewSortableID returns a new sortable ID from a process-wide generator. It panics if random
+// bits cannot be read.
+func NewSortableID() types.UID {
+	id, err := defaultSortableIDGenerator.Generate()
+	if err != nil {
+		panic(err)
+	}
+	return id
+}
+
+// Generate returns a new sortable ID, or an error if random bits cannot be read.
+func (g *SortableIDGenerator) Generate() (types.UID, error) {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	t := g.clock.Now()
+	ms := t.UnixMilli()
+	if ms < 0 || ms > maxSortableIDTime {
+		return "", fmt.Errorf("time %v cannot be encoded in a sortable ID", t)
+	}
+	now := uint64(ms)
+	if g.generated && now <= g.lastTime {
+		// the clock did not advance or went backwards, so the previous ID is incremented
+		// to keep IDs strictly increasing.
+		if !increment(g.last[:]) {
+			if g.lastTime == maxSortableIDTime {
+				return "", fmt.Errorf("sortable IDs exhausted")
+			}
+			g.lastTime++
+		}
+	} else {
+		if _, err := io.ReadFull(g.entropy, g.last[:]); err != nil {
+			return "", fmt.Errorf("failed to read random bits: %v", err)
+		}
+		g.lastTime = now
+		g.generated = true
+	}
+	return types.UID(encodeSortableID(g.lastTime, g.last)), nil
+}
+
+// increment adds one to the big-endian number b, returning false if it overflowed.
+func increment(b []byte) bool {
+	for i := len(b) - 1; i >= 0; i-- {
+		b[i]++
+		if b[i] != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func encodeSortableID(ms uint64, random [10]byte) string {
+	// the 128 bits are encoded as 26 groups of 5 bits, the first having 3 bits only.
+	var data [16]byte
+	for i := 0; i < 6; i++ {
+		data[i] = byte(ms >> (8 * (5 - i)))
+	}
+	copy(data[6:], random[:])
+
+	out := make([]byte, SortableIDLength)
+	bit := 128
+	for i := SortableIDLength - 1; i >= 0; i-- {
+		var value byte
+		for j := 0; j < 5 && bit > 0; j++ {
+			bit--
+			if data[bit/8]&(1<<(7-uint(bit%8))) != 0 {
+				value |= 1 << uint(j)
+			}
+		}
+		out[i] = sortableIDAlphabet[value]
+	}
+	return string(out)
+}
+
+// SortableIDTime returns the time encoded in a sortable ID, with millisecond precision. It
+// returns an error if id is not a sortable ID. Decoding is case-insensitive.
+func SortableIDTime(id types.UID) (time.Time, error) {
+	s := strings.ToLower(string(id))
+	if len(s) != SortableIDLength || s[0] > '7' {
+		return time.Time{}, fmt.Errorf("%q is not a sortable ID", id)
+	}
+	var ms uint64
+	// the first 10 characters encode the 48 bits of the timestamp, preceded by 2 zero bits.
+	for i, c := range []byte(s) {
+		value := strings.IndexByte(sortableIDAlphabet, c)
+		if value < 0 {
+			return time.Time{}, fmt.Errorf("%q is not a sortable ID", id)
+		}
+		if i < 10 {
+			ms = ms<<5 | uint64(value)
+		}
+	}
+	return time.UnixMilli(int64(ms)), nil
+}
@@ -0,0 +1,109 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package uuid
+
+import (
+	"bytes"
+	"errors"
+	"sort"
+	"testing"
+	"testing/iotest"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
+	testclock "k8s.io/utils/clock/testing"
+)
+
+func TestSortableIDGenerator(t *testing.T) {
+	start := time.UnixMilli(1650000000123)
+	fakeClock := testclock.NewFakePassiveClock(start)
+	// maximal random bits make the second ID of a millisecond overflow into the next one.
+	entropy := bytes.NewReader(append(bytes.Repeat([]byte{0xff}, 10), bytes.Repeat([]byte{0x01}, 20)...))
+	g := NewSortableIDGenerator(fakeClock, entropy)
+
+	var ids []types.UID
+	generate := func() types.UID {
+		id, err := g.Generate()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(id) != SortableIDLength {
+			t.Fatalf("unexpected length of %q", id)
+		}
+		if errs := validation.IsDNS1123Label(string(id)); len(errs) > 0 {
+			t.Errorf("expected %q to be a valid name: %v", id, errs)
+		}
+		ids = append(ids, id)
+		return id
+	}
+
+	first := generate()
+	if e, a := "01g0ntkx3vzzzzzzzzzzzzzzzz", string(first); e != a {
+		t.Errorf("expected %s, got %s", e, a)
+	}
+	overflowed := generate()
+	if ts, err := SortableIDTime(overflowed); err != nil || !ts.Equal(start.Add(time.Millisecond)) {
+		t.Errorf("expected the overflow to advance the time, got %v %v", ts, err)
+	}
+	generate()
+
+	fakeClock.SetTime(start.Add(time.Second))
+	later := generate()
+	if ts, err := SortableIDTime(later); err != nil || !ts.Equal(start.Add(time.Second)) {
+		t.Errorf("expected the time of the clock, got %v %v", ts, err)
+	}
+	// the clock going backwards preserves the order.
+	fakeClock.SetTime(start)
+	generate()
+
+	if !sort.SliceIsSorted(ids, func(i, j int) bool { return ids[i] < ids[j] }) {
+		t.Errorf("expected sorted IDs, got %v", ids)
+	}
+	for i := 1; i < len(ids); i++ {
+		if ids[i] == ids[i-1] {
+			t.Errorf("duplicate ID %s", ids[i])
+		}
+	}
+}
+
+func TestSortableIDGeneratorErrors(t *testing.T) {
+	g := NewSortableIDGenerator(testclock.NewFakePassiveClock(time.Now()), iotest.ErrReader(errors.New("no entropy")))
+	if _, err := g.Generate(); err == nil {
+		t.Errorf("expected an error without entropy")
+	}
+	g = NewSortableIDGenerator(testclock.NewFakePassiveClock(time.Unix(-1, 0)), nil)
+	if _, err := g.Generate(); err == nil {
+		t.Errorf("expected an error for times before the epoch")
+	}
+	for _, id := range []types.UID{"", "01g0nswxsv", "81g0nswxsvzzzzzzzzzzzzzzzz", "01g0nswxsvzzzzzzzzzzzzzzzu"} {
+		if _, err := SortableIDTime(id); err == nil {
+			t.Errorf("%q: expected an error", id)
+		}
+	}
+}
+
+func TestNewSortableID(t *testing.T) {
+	before := time.Now().Truncate(time.Millisecond)
+	a, b := NewSortableID(), NewSortableID()
+	if a >= b {
+		t.Errorf("expected %s < %s", a, b)
+	}
+	if ts, err := SortableIDTime(types.UID(bytes.ToUpper([]byte(a)))); err != nil || ts.Before(before) {
+		t.Errorf("unexpected time %v %v", ts, err)
+	}
+}
@@ -0,0 +1,124 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validation
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// ReservedKeyOutcome is the outcome of using a reserved label or annotation key.
+type ReservedKeyOutcome string
+
+const (
+	// ReservedKeyAllow allows the use of a key.
+	ReservedKeyAllow ReservedKeyOutcome = "Allow"
+	// ReservedKeyWarn allows the use of a key, with a warning.
+	ReservedKeyWarn ReservedKeyOutcome = "Warn"
+	// ReservedKeyDeny forbids the use of a key.
+	ReservedKeyDeny ReservedKeyOutcome = "Deny"
+)
+
+// ReservedKeyRule reserves the keys with a prefix in a domain, e.g. "kubernetes.io" for
+// "kubernetes.io/name" and "node.kubernetes.io/unreachable".
+type ReservedKeyRule struct {
+	// Domain is the reserved domain. The rule applies to keys whose prefix is the domain
+	// or one of its subdomains.
+	Domain string
+	// Outcome is the outcome of using a key of the domain.
+	Outcome ReservedKeyOutcome
+	// Exceptions are keys of the domain that are allowed, e.g. well-known keys meant to be
+	// set by users.
+	Exceptions []string
+	// Message is an optional explanation added to errors and warnings, e.g. who the domain
+	// is reserved for.
+	Message string
+}
+
+// ReservedKeyPolicy applies rules to label and annotation keys. The rule of the most specific
+// domain matching the prefix of a key applies. Keys without a matching rule are allowed.
+type ReservedKeyPolicy struct {
+	Rules []ReservedKeyRule
+}
+
+// Outcome returns the outcome of using key, and the rule deciding it if any.
+func (p *ReservedKeyPolicy) Outcome(key string) (ReservedKeyOutcome, *ReservedKeyRule) {
+	if p == nil {
+		return ReservedKeyAllow, nil
+	}
+	i := strings.Index(key, "/")
+	if i < 0 {
+		return ReservedKeyAllow, nil
+	}
+	prefix := strings.ToLower(key[:i])
+
+	var match *ReservedKeyRule
+	matchLen := 0
+	for j := range p.Rules {
+		rule := &p.Rules[j]
+		domain := strings.ToLower(strings.TrimSuffix(rule.Domain, "/"))
+		if prefix != domain && !strings.HasSuffix(prefix, "."+domain) {
+			continue
+		}
+		if match == nil || len(domain) > matchLen {
+			match, matchLen = rule, len(domain)
+		}
+	}
+	if match == nil {
+		return ReservedKeyAllow, nil
+	}
+	for _, exception := range match.Exceptions {
+		if exception == key {
+			return ReservedKeyAllow, match
+		}
+	}
+	return match.Outcome, match
+}
+
+// ValidateKeys applies the policy to the keys of the labels or annotations at fldPath. It
+// returns Forbidden errors for denied keys, and warnings for keys with a Warn outcome. Keys
+// are validated in sorted order.
+func (p *ReservedKeyPolicy) ValidateKeys(keys map[string]string, fldPath *field.Path) (field.ErrorList, []string) {
+	sorted := make([]string, 0, len(keys))
+	for k := range keys {
+		sorted = append(sorted, k)
+	}
+	sort.Strings(sorted)
+
+	var allErrs field.ErrorList
+	var warnings []string
+	for _, k := range sorted {
+		outcome, rule := p.Outcome(k)
+		if rule == nil {
+			continue
+		}
+		detail := fmt.Sprintf("the domain %q is reserved", rule.Domain)
+		if len(rule.Message) > 0 {
+			detail += ": " + rule.Message
+		}
+		switch outcome {
+		case ReservedKeyDeny:
+			allErrs = append(allErrs, field.Forbidden(fldPath.Key(k), detail))
+		case ReservedKeyWarn:
+			warnings = append(warnings, fmt.Sprintf("%s: %s", fldPath.Key(k), detail))
+		}
+	}
+	return allErrs, warnings
+}
@@ -744,3 +744,59 @@ func TestIsValidSocketAddr(t *testing.T) {
 		}
 	}
 }
+
+func TestReservedKeyPolicy(t *testing.T) {
+	policy := &ReservedKeyPolicy{Rules: []ReservedKeyRule{
+		{Domain: "kubernetes.io", Outcome: ReservedKeyDeny, Exceptions: []string{"kubernetes.io/description"}},
+		{Domain: "node.kubernetes.io", Outcome: ReservedKeyWarn, Message: "managed by the node controller"},
+		{Domain: "example.com/", Outcome: ReservedKeyDeny, Message: "reserved for the platform team"},
+	}}
+
+	testCases := []struct {
+		key     string
+		outcome ReservedKeyOutcome
+		domain  string
+	}{
+		{"app", ReservedKeyAllow, ""},
+		{"other.io/app", ReservedKeyAllow, ""},
+		{"notkubernetes.io/app", ReservedKeyAllow, ""},
+		{"kubernetes.io/name", ReservedKeyDeny, "kubernetes.io"},
+		{"kubernetes.io/description", ReservedKeyAllow, "kubernetes.io"},
+		{"apps.kubernetes.io/name", ReservedKeyDeny, "kubernetes.io"},
+		{"node.kubernetes.io/unreachable", ReservedKeyWarn, "node.kubernetes.io"},
+		{"team.Example.com/owner", ReservedKeyDeny, "example.com/"},
+	}
+	for _, tc := range testCases {
+		outcome, rule := policy.Outcome(tc.key)
+		if outcome != tc.outcome {
+			t.Errorf("%s: expected %s, got %s", tc.key, tc.outcome, outcome)
+		}
+		if domain := ""; rule != nil {
+			domain = rule.Domain
+			if domain != tc.domain {
+				t.Errorf("%s: expected the rule of %q, got %q", tc.key, tc.domain, domain)
+			}
+		} else if len(tc.domain) > 0 {
+			t.Errorf("%s: expected the rule of %q", tc.key, tc.domain)
+		}
+	}
+
+	errs, warnings := policy.ValidateKeys(map[string]string{
+		"app":                            "foo",
+		"kubernetes.io/name":             "foo",
+		"team.example.com/owner":         "foo",
+		"node.kubernetes.io/unreachable": "",
+	}, field.NewPath("metadata", "labels"))
+	if len(errs) != 2 || errs[0].Type != field.ErrorTypeForbidden || errs[0].Field != "metadata.labels[kubernetes.io/name]" ||
+		!strings.Contains(errs[1].Detail, "platform team") {
+		t.Errorf("unexpected errors %v", errs)
+	}
+	if len(warnings) != 1 || !strings.HasPrefix(warnings[0], "metadata.labels[node.kubernetes.io/unreachable]: ") {
+		t.Errorf("unexpected warnings %v", warnings)
+	}
+
+	var nilPolicy *ReservedKeyPolicy
+	if errs, warnings := nilPolicy.ValidateKeys(map[string]string{"kubernetes.io/name": ""}, field.NewPath("labels")); len(errs) > 0 || len(warnings) > 0 {
+		t.Errorf("expected a nil policy to allow all keys, got %v %v", errs, warnings)
+	}
+}
@@ -0,0 +1,253 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package wait
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/utils/clock"
+)
+
+// Schedule computes the times at which an AlignedTicker ticks.
+type Schedule interface {
+	// Next returns the first time of the schedule strictly after t.
+	Next(t time.Time) time.Time
+}
+
+type everySchedule time.Duration
+
+// Every returns a schedule of the multiples of d since the zero time, e.g. every minute
+// on :00 or every 15 minutes on :00, :15, :30 and :45. Intervals are aligned in UTC, so
+// schedules of replicas align regardless of their time zone, and daily schedules tick at
+// midnight UTC. An error is returned if d isn't positive.
+func Every(d time.Duration) (Schedule, error) {
+	if d <= 0 {
+		return nil, fmt.Errorf("invalid interval %v: must be positive", d)
+	}
+	return everySchedule(d), nil
+}
+
+func (s everySchedule) Next(t time.Time) time.Time {
+	return t.Truncate(time.Duration(s)).Add(time.Duration(s))
+}
+
+// cronSchedule is a parsed cron expression, with a bit set of the matching values of each field.
+type cronSchedule struct {
+	minute, hour, dom, month, dow uint64
+	// domStar and dowStar record whether the day fields are unrestricted. If both are
+	// restricted, days matching either field match, as in cron.
+	domStar, dowStar bool
+}
+
+var cronDescriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// ParseCron parses a standard cron expression of five fields for minutes, hours, days of
+// the month, months and days of the week, or one of the descriptors @yearly, @annually,
+// @monthly, @weekly, @daily, @midnight and @hourly. Fields may be *, values, ranges and
+// steps such as 1-5 or */15, or lists of them. Months and days of the week are numeric,
+// with Sunday being 0 or 7. Times of the schedule are in the location of the time passed
+// to Next.
+func ParseCron(expression string) (Schedule, error) {
+	expr := strings.TrimSpace(expression)
+	if descriptor, ok := cronDescriptors[expr]; ok {
+		expr = descriptor
+	}
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("invalid cron expression %q: expected 5 fields, got %d", expression, len(fields))
+	}
+
+	s := &cronSchedule{
+		domStar: fields[2] == "*" || fields[2] == "?",
+		dowStar: fields[4] == "*" || fields[4] == "?",
+	}
+	var err error
+	for _, f := range []struct {
+		bits     *uint64
+		min, max int
+	}{
+		{&s.minute, 0, 59},
+		{&s.hour, 0, 23},
+		{&s.dom, 1, 31},
+		{&s.month, 1, 12},
+		{&s.dow, 0, 7},
+	} {
+		field := fields[0]
+		fields = fields[1:]
+		if *f.bits, err = parseCronField(field, f.min, f.max); err != nil {
+			return nil, fmt.Errorf("invalid cron expression %q: %v", expression, err)
+		}
+	}
+	// Sunday is both 0 and 7.
+	if s.dow&(1<<7) != 0 {
+		s.dow |= 1
+	}
+	return s, nil
+}
+
+func parseCronField(field string, min, max int) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(field, ",") {
+		rangePart, step := part, 1
+		if i := strings.Index(part, "/"); i >= 0 {
+			var err error
+			if step, err = strconv.Atoi(part[i+1:]); err != nil || step <= 0 {
+				return 0, fmt.Errorf("invalid step in %q", part)
+			}
+			rangePart = part[:i]
+		}
+
+		start, end := min, max
+		switch {
+		case rangePart == "*" || rangePart == "?":
+		case strings.Contains(rangePart, "-"):
+			bounds := strings.SplitN(rangePart, "-", 2)
+			var err1, err2 error
+			start, err1 = strconv.Atoi(bounds[0])
+			end, err2 = strconv.Atoi(bounds[1])
+			if err1 != nil || err2 != nil {
+				return 0, fmt.Errorf("invalid range %q", rangePart)
+			}
+		default:
+			value, err := strconv.Atoi(rangePart)
+			if err != nil {
+				return 0, fmt.Errorf("invalid value %q", rangePart)
+			}
+			start = value
+			if step == 1 {
+				end = value
+			}
+		}
+		if start < min || end > max || start > end {
+			return 0, fmt.Errorf("%q is out of the range %d-%d", part, min, max)
+		}
+		for v := start; v <= end; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, nil
+}
+
+func (s *cronSchedule) Next(t time.Time) time.Time {
+	loc := t.Location()
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	// every combination of months and days occurs within a few years, so a schedule
+	// that does not match within them never matches, e.g. February 30th.
+	limit := t.AddDate(5, 0, 0)
+	for t.Before(limit) {
+		if s.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, loc)
+			continue
+		}
+		if !s.matchesDay(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc)
+			continue
+		}
+		if s.hour&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, loc)
+			continue
+		}
+		if s.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+func (s *cronSchedule) matchesDay(t time.Time) bool {
+	dom := s.dom&(1<<uint(t.Day())) != 0
+	dow := s.dow&(1<<uint(t.Weekday())) != 0
+	switch {
+	case s.domStar && s.dowStar:
+		return true
+	case s.domStar:
+		return dow
+	case s.dowStar:
+		return dom
+	}
+	return dom || dow
+}
+
+// AlignedTicker is a clock.Ticker delivering the times of a schedule, rather than times
+// at a fixed interval from its creation. This aligns periodic work, such as resyncs and
+// reports, across processes and restarts. As with time.Ticker, ticks are dropped if the
+// receiver is not ready for them. A schedule that never matches never ticks.
+type AlignedTicker struct {
+	c        chan time.Time
+	stopOnce sync.Once
+	stopCh   chan struct{}
+}
+
+var _ clock.Ticker = &AlignedTicker{}
+
+// NewAlignedTicker returns a ticker delivering the times of schedule as measured by c,
+// which may be a fake clock in tests.
+func NewAlignedTicker(c clock.Clock, schedule Schedule) *AlignedTicker {
+	t := &AlignedTicker{
+		c:      make(chan time.Time, 1),
+		stopCh: make(chan struct{}),
+	}
+	go t.run(c, schedule)
+	return t
+}
+
+func (t *AlignedTicker) run(c clock.Clock, schedule Schedule) {
+	for {
+		now := c.Now()
+		next := schedule.Next(now)
+		if next.IsZero() {
+			return
+		}
+		timer := c.NewTimer(next.Sub(now))
+		select {
+		case <-t.stopCh:
+			timer.Stop()
+			return
+		case tick := <-timer.C():
+			select {
+			case t.c <- tick:
+			default:
+			}
+		}
+	}
+}
+
+// C returns the channel delivering the ticks.
+func (t *AlignedTicker) C() <-chan time.Time {
+	return t.c
+}
+
+// Stop turns off the ticker. It does not close the channel.
+func (t *AlignedTicker) Stop() {
+	t.stopOnce.Do(func() {
+		close(t.stopCh)
+	})
+}
@@ -0,0 +1,156 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package wait
+
+import (
+	"testing"
+	"time"
+
+	testingclock "k8s.io/utils/clock/testing"
+)
+
+func mustParseTime(t *testing.T, value string) time.Time {
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return parsed
+}
+
+func TestEvery(t *testing.T) {
+	testCases := []struct {
+		interval time.Duration
+		now      string
+		expected string
+	}{
+		{time.Minute, "2022-03-04T10:11:12Z", "2022-03-04T10:12:00Z"},
+		{time.Minute, "2022-03-04T10:12:00Z", "2022-03-04T10:13:00Z"},
+		{15 * time.Minute, "2022-03-04T10:11:12Z", "2022-03-04T10:15:00Z"},
+		{time.Hour, "2022-03-04T23:59:59Z", "2022-03-05T00:00:00Z"},
+		{time.Hour, "2022-03-04T10:11:12+05:30", "2022-03-04T10:30:00+05:30"},
+	}
+	for _, tc := range testCases {
+		schedule, err := Every(tc.interval)
+		if err != nil {
+			t.Errorf("%v: unexpected error: %v", tc.interval, err)
+			continue
+		}
+		next := schedule.Next(mustParseTime(t, tc.now))
+		if expected := mustParseTime(t, tc.expected); !next.Equal(expected) {
+			t.Errorf("%v after %s: expected %v, got %v", tc.interval, tc.now, expected, next)
+		}
+	}
+
+	for _, interval := range []time.Duration{0, -time.Minute} {
+		if _, err := Every(interval); err == nil {
+			t.Errorf("%v: expected an error", interval)
+		}
+	}
+}
+
+func TestParseCron(t *testing.T) {
+	testCases := []struct {
+		expression string
+		now        string
+		expected   string
+	}{
+		{"* * * * *", "2022-03-04T10:11:12Z", "2022-03-04T10:12:00Z"},
+		{"@hourly", "2022-03-04T10:11:12Z", "2022-03-04T11:00:00Z"},
+		{"@daily", "2022-03-04T10:11:12Z", "2022-03-05T00:00:00Z"},
+		{"*/15 * * * *", "2022-03-04T10:11:12Z", "2022-03-04T10:15:00Z"},
+		{"5/20 * * * *", "2022-03-04T10:26:00Z", "2022-03-04T10:45:00Z"},
+		{"30 2 * * 1-5", "2022-03-04T10:11:12Z", "2022-03-07T02:30:00Z"},
+		{"0 0 * * 7", "2022-03-04T10:11:12Z", "2022-03-06T00:00:00Z"},
+		{"0 0 1,15 * *", "2022-03-04T10:11:12Z", "2022-03-15T00:00:00Z"},
+		// both day fields are restricted, so either matches.
+		{"0 0 13 * 5", "2022-03-05T00:00:00Z", "2022-03-11T00:00:00Z"},
+		{"0 12 29 2 *", "2022-03-04T10:11:12Z", "2024-02-29T12:00:00Z"},
+		{"0 0 * * *", "2022-03-04T10:11:12+01:00", "2022-03-05T00:00:00+01:00"},
+		{"0 0 30 2 *", "2022-03-04T10:11:12Z", "0001-01-01T00:00:00Z"},
+	}
+	for _, tc := range testCases {
+		schedule, err := ParseCron(tc.expression)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tc.expression, err)
+			continue
+		}
+		next := schedule.Next(mustParseTime(t, tc.now))
+		if expected := mustParseTime(t, tc.expected); !next.Equal(expected) {
+			t.Errorf("%s after %s: expected %v, got %v", tc.expression, tc.now, expected, next)
+		}
+	}
+
+	for _, expression := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "* * 0 * *", "*/0 * * * *", "5-1 * * * *", "a * * * *", "@often"} {
+		if _, err := ParseCron(expression); err == nil {
+			t.Errorf("%q: expected an error", expression)
+		}
+	}
+}
+
+func TestAlignedTicker(t *testing.T) {
+	start := mustParseTime(t, "2022-03-04T10:11:12Z")
+	fakeClock := testingclock.NewFakeClock(start)
+	schedule, err := Every(time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ticker := NewAlignedTicker(fakeClock, schedule)
+	defer ticker.Stop()
+
+	for _, expected := range []string{"2022-03-04T10:12:00Z", "2022-03-04T10:13:00Z"} {
+		waitForWaiters(t, fakeClock)
+		fakeClock.SetTime(mustParseTime(t, expected))
+		select {
+		case tick := <-ticker.C():
+			if !tick.Equal(mustParseTime(t, expected)) {
+				t.Errorf("expected a tick at %s, got %v", expected, tick)
+			}
+		case <-time.After(testTimeout):
+			t.Fatalf("expected a tick at %s", expected)
+		}
+	}
+
+	waitForWaiters(t, fakeClock)
+	fakeClock.Step(30 * time.Second)
+	select {
+	case tick := <-ticker.C():
+		t.Errorf("unexpected tick at %v", tick)
+	default:
+	}
+
+	ticker.Stop()
+	ticker.Stop()
+	deadline := time.Now().Add(testTimeout)
+	for fakeClock.HasWaiters() {
+		if time.Now().After(deadline) {
+			t.Fatal("expected the timer of the ticker to be stopped")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+const testTimeout = 10 * time.Second
+
+func waitForWaiters(t *testing.T, fakeClock *testingclock.FakeClock) {
+	deadline := time.Now().Add(testTimeout)
+	for !fakeClock.HasWaiters() {
+		if time.Now().After(deadline) {
+			t.Fatal("expected the ticker to wait for the clock")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
@@ -221,6 +221,37 @@ func (cf ConditionFunc) WithContext() ConditionWithContextFunc {
 	}
 }
 
+// FailedAttemptFunc observes an attempt of a condition that did not succeed.
+// attempt is the number of the attempt, starting at 1, and err is the error
+// returned by the condition, or nil if the condition was not satisfied.
+type FailedAttemptFunc func(attempt int, err error)
+
+// ObserveFailedAttempts returns a ConditionFunc that calls observe every time
+// cf returns false or an error, for instance to log or record intermediate
+// failures of a Poll or ExponentialBackoff. It does not change the outcome of
+// cf, so errors still abort the loop after being observed. Attempts are counted
+// across all invocations of the returned ConditionFunc.
+func (cf ConditionFunc) ObserveFailedAttempts(observe FailedAttemptFunc) ConditionFunc {
+	condition := cf.WithContext().ObserveFailedAttempts(observe)
+	return func() (bool, error) {
+		return condition(context.TODO())
+	}
+}
+
+// ObserveFailedAttempts returns a ConditionWithContextFunc that calls observe
+// every time cf returns false or an error. See ConditionFunc.ObserveFailedAttempts.
+func (cf ConditionWithContextFunc) ObserveFailedAttempts(observe FailedAttemptFunc) ConditionWithContextFunc {
+	attempt := 0
+	return func(ctx context.Context) (bool, error) {
+		attempt++
+		done, err := cf(ctx)
+		if err != nil || !done {
+			observe(attempt, err)
+		}
+		return done, err
+	}
+}
+
 // runConditionWithCrashProtection runs a ConditionFunc with crash protection
 func runConditionWithCrashProtection(condition ConditionFunc) (bool, error) {
 	return runConditionWithCrashProtectionWithContext(context.TODO(), condition.WithContext())
@@ -728,6 +759,16 @@ func poller(interval, timeout time.Duration) WaitWithContextFunc {
 // ExponentialBackoffWithContext works with a request context and a Backoff. It ensures that the retry wait never
 // exceeds the deadline specified by the request context.
 func ExponentialBackoffWithContext(ctx context.Context, backoff Backoff, condition ConditionFunc) error {
+	return ExponentialBackoffWithAttemptTimeout(ctx, backoff, 0, condition.WithContext())
+}
+
+// ExponentialBackoffWithAttemptTimeout works like ExponentialBackoffWithContext, but runs each attempt
+// with its own context derived from ctx, which expires after attemptTimeout. An attempt that fails
+// because its own context expired, while ctx is still active, is retried like an attempt that returned
+// false, so a single hung attempt cannot consume the whole retry budget. The condition must respect
+// the context it is passed for the timeout to have any effect. An attemptTimeout of 0 disables the
+// per-attempt timeout.
+func ExponentialBackoffWithAttemptTimeout(ctx context.Context, backoff Backoff, attemptTimeout time.Duration, condition ConditionWithContextFunc) error {
 	for backoff.Steps > 0 {
 		select {
 		case <-ctx.Done():
@@ -735,7 +776,7 @@ func ExponentialBackoffWithContext(ctx context.Context, backoff Backoff, conditi
 		default:
 		}
 
-		if ok, err := runConditionWithCrashProtection(condition); err != nil || ok {
+		if ok, err := runAttemptWithTimeout(ctx, attemptTimeout, condition); err != nil || ok {
 			return err
 		}
 
@@ -753,3 +794,19 @@ func ExponentialBackoffWithContext(ctx context.Context, backoff Backoff, conditi
 
 	return ErrWaitTimeout
 }
+
+// runAttemptWithTimeout runs condition with crash protection and, if timeout is positive, a context
+// that expires after timeout. Errors caused by the expiry of that context are reported as an
+// unsatisfied condition.
+func runAttemptWithTimeout(ctx context.Context, timeout time.Duration, condition ConditionWithContextFunc) (bool, error) {
+	if timeout <= 0 {
+		return runConditionWithCrashProtectionWithContext(ctx, condition)
+	}
+	attemptCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	ok, err := runConditionWithCrashProtectionWithContext(attemptCtx, condition)
+	if err != nil && errors.Is(err, context.DeadlineExceeded) && attemptCtx.Err() != nil && ctx.Err() == nil {
+		return false, nil
+	}
+	return ok, err
+}
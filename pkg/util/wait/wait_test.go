@@ -21,6 +21,7 @@ import (
 	"errors"
 	"fmt"
 	"math/rand"
+	"reflect"
 	"sync"
 	"sync/atomic"
 	"testing"
@@ -379,6 +380,47 @@ func TestPollError(t *testing.T) {
 	}
 }
 
+func TestObserveFailedAttempts(t *testing.T) {
+	type observation struct {
+		attempt int
+		err     error
+	}
+	expectedError := errors.New("Expected error")
+
+	var observed []observation
+	invocations := 0
+	f := ConditionFunc(func() (bool, error) {
+		invocations++
+		return invocations == 3, nil
+	}).ObserveFailedAttempts(func(attempt int, err error) {
+		observed = append(observed, observation{attempt, err})
+	})
+	if err := PollImmediate(time.Microsecond, ForeverTestTimeout, f); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if expected := []observation{{1, nil}, {2, nil}}; !reflect.DeepEqual(expected, observed) {
+		t.Errorf("expected observations %v, got %v", expected, observed)
+	}
+
+	observed = nil
+	invocations = 0
+	fc := ConditionWithContextFunc(func(context.Context) (bool, error) {
+		invocations++
+		if invocations == 2 {
+			return false, expectedError
+		}
+		return false, nil
+	}).ObserveFailedAttempts(func(attempt int, err error) {
+		observed = append(observed, observation{attempt, err})
+	})
+	if err := PollImmediateWithContext(context.Background(), time.Microsecond, ForeverTestTimeout, fc); err != expectedError {
+		t.Fatalf("expected error %v, got %v", expectedError, err)
+	}
+	if expected := []observation{{1, nil}, {2, expectedError}}; !reflect.DeepEqual(expected, observed) {
+		t.Errorf("expected observations %v, got %v", expected, observed)
+	}
+}
+
 func TestPollImmediate(t *testing.T) {
 	invocations := 0
 	f := ConditionFunc(func() (bool, error) {
@@ -891,6 +933,86 @@ func TestExponentialBackoffWithContext(t *testing.T) {
 	}
 }
 
+func TestExponentialBackoffWithAttemptTimeout(t *testing.T) {
+	conditionErr := errors.New("condition failed")
+	tests := []struct {
+		name             string
+		attemptTimeout   time.Duration
+		callback         func(ctx context.Context, attempts int) (bool, error)
+		attemptsExpected int
+		errExpected      error
+	}{
+		{
+			name:           "hung attempts are retried",
+			attemptTimeout: 10 * time.Millisecond,
+			callback: func(ctx context.Context, attempts int) (bool, error) {
+				if attempts < 3 {
+					<-ctx.Done()
+					return false, ctx.Err()
+				}
+				return true, nil
+			},
+			attemptsExpected: 3,
+		},
+		{
+			name:           "hung attempts exhaust the steps",
+			attemptTimeout: 10 * time.Millisecond,
+			callback: func(ctx context.Context, _ int) (bool, error) {
+				<-ctx.Done()
+				return false, fmt.Errorf("wrapped: %w", ctx.Err())
+			},
+			attemptsExpected: 5,
+			errExpected:      ErrWaitTimeout,
+		},
+		{
+			name:           "other errors abort the backoff",
+			attemptTimeout: time.Minute,
+			callback: func(_ context.Context, _ int) (bool, error) {
+				return false, conditionErr
+			},
+			attemptsExpected: 1,
+			errExpected:      conditionErr,
+		},
+		{
+			name: "no attempt timeout",
+			callback: func(ctx context.Context, _ int) (bool, error) {
+				if _, ok := ctx.Deadline(); ok {
+					return false, errors.New("unexpected deadline")
+				}
+				return true, nil
+			},
+			attemptsExpected: 1,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			backoff := Backoff{Duration: 1 * time.Millisecond, Factor: 1.0, Steps: 5}
+			attempts := 0
+			err := ExponentialBackoffWithAttemptTimeout(context.Background(), backoff, test.attemptTimeout, func(ctx context.Context) (bool, error) {
+				attempts++
+				return test.callback(ctx, attempts)
+			})
+			if !errors.Is(err, test.errExpected) {
+				t.Errorf("expected error: %v, got: %v", test.errExpected, err)
+			}
+			if test.attemptsExpected != attempts {
+				t.Errorf("expected attempts count: %d, got: %d", test.attemptsExpected, attempts)
+			}
+		})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	err := ExponentialBackoffWithAttemptTimeout(ctx, Backoff{Duration: time.Millisecond, Steps: 100}, time.Minute, func(ctx context.Context) (bool, error) {
+		<-ctx.Done()
+		return false, ctx.Err()
+	})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected the expiry of the overall context to abort the backoff, got: %v", err)
+	}
+}
+
 func TestPollImmediateUntilWithContext(t *testing.T) {
 	fakeErr := errors.New("my error")
 	tests := []struct {
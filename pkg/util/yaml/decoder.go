@@ -22,6 +22,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"mime"
 	"strings"
 	"unicode"
 
@@ -241,8 +242,21 @@ type YAMLOrJSONDecoder struct {
 	bufferSize int
 
 	decoder decoder
+	format  Format
 }
 
+// Format is the format of a stream decoded by a YAMLOrJSONDecoder.
+type Format string
+
+const (
+	// FormatUnknown is the format of streams that were not sniffed yet.
+	FormatUnknown Format = ""
+	// FormatJSON is the format of JSON streams.
+	FormatJSON Format = "JSON"
+	// FormatYAML is the format of YAML streams.
+	FormatYAML Format = "YAML"
+)
+
 type JSONSyntaxError struct {
 	Offset int64
 	Err    error
@@ -271,17 +285,65 @@ func NewYAMLOrJSONDecoder(r io.Reader, bufferSize int) *YAMLOrJSONDecoder {
 	}
 }
 
+// NewYAMLOrJSONDecoderWithContentType is like NewYAMLOrJSONDecoder, but skips
+// sniffing the stream if contentType is a JSON or YAML media type, such as
+// application/json, application/yaml or application/merge-patch+json. Other
+// content types, including empty ones, are ignored.
+func NewYAMLOrJSONDecoderWithContentType(r io.Reader, bufferSize int, contentType string) *YAMLOrJSONDecoder {
+	d := NewYAMLOrJSONDecoder(r, bufferSize)
+	switch formatForContentType(contentType) {
+	case FormatJSON:
+		d.decoder, d.format = json.NewDecoder(r), FormatJSON
+	case FormatYAML:
+		d.decoder, d.format = NewYAMLToJSONDecoder(r), FormatYAML
+	}
+	return d
+}
+
+func formatForContentType(contentType string) Format {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return FormatUnknown
+	}
+	switch {
+	case mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"):
+		return FormatJSON
+	case mediaType == "application/yaml" || mediaType == "application/x-yaml" || mediaType == "text/yaml" ||
+		mediaType == "text/x-yaml" || strings.HasSuffix(mediaType, "+yaml"):
+		return FormatYAML
+	}
+	return FormatUnknown
+}
+
+// Format returns the format of the stream, sniffing it if needed. Sniffing
+// blocks until bufferSize bytes or the end of the stream are read. It returns
+// FormatUnknown if the stream is empty or can't be read.
+func (d *YAMLOrJSONDecoder) Format() Format {
+	d.init()
+	return d.format
+}
+
+func (d *YAMLOrJSONDecoder) init() {
+	if d.decoder != nil {
+		return
+	}
+	buffer, b, isJSON := GuessJSONStream(d.r, d.bufferSize)
+	switch {
+	case isJSON:
+		d.decoder, d.format = json.NewDecoder(buffer), FormatJSON
+	default:
+		d.decoder = NewYAMLToJSONDecoder(buffer)
+		// the stream is treated as YAML if empty, but its format is only known if it has content.
+		if len(b) > 0 {
+			d.format = FormatYAML
+		}
+	}
+}
+
 // Decode unmarshals the next object from the underlying stream into the
 // provide object, or returns an error.
 func (d *YAMLOrJSONDecoder) Decode(into interface{}) error {
-	if d.decoder == nil {
-		buffer, _, isJSON := GuessJSONStream(d.r, d.bufferSize)
-		if isJSON {
-			d.decoder = json.NewDecoder(buffer)
-		} else {
-			d.decoder = NewYAMLToJSONDecoder(buffer)
-		}
-	}
+	d.init()
 	err := d.decoder.Decode(into)
 	if syntax, ok := err.(*json.SyntaxError); ok {
 		return JSONSyntaxError{
@@ -377,6 +377,45 @@ func TestYAMLOrJSONDecoder(t *testing.T) {
 	}
 }
 
+func TestYAMLOrJSONDecoderFormat(t *testing.T) {
+	testCases := []struct {
+		input       string
+		contentType string
+		expected    Format
+		value       string
+	}{
+		{input: `{"foo": "bar"}`, expected: FormatJSON, value: "bar"},
+		{input: "  \n{\"foo\": \"bar\"}", expected: FormatJSON, value: "bar"},
+		{input: "foo: bar\n", expected: FormatYAML, value: "bar"},
+		{input: "", expected: FormatUnknown},
+		// the hint is trusted over sniffing: JSON is valid YAML.
+		{input: `{"foo": "bar"}`, contentType: "application/yaml", expected: FormatYAML, value: "bar"},
+		{input: `{"foo": "bar"}`, contentType: "application/merge-patch+json; charset=utf-8", expected: FormatJSON, value: "bar"},
+		{input: "foo: bar\n", contentType: "text/plain", expected: FormatYAML, value: "bar"},
+		{input: "foo: bar\n", contentType: "invalid/", expected: FormatYAML, value: "bar"},
+	}
+	for _, tc := range testCases {
+		d := NewYAMLOrJSONDecoderWithContentType(strings.NewReader(tc.input), 100, tc.contentType)
+		if format := d.Format(); format != tc.expected {
+			t.Errorf("%q with %q: expected %q, got %q", tc.input, tc.contentType, tc.expected, format)
+		}
+		if len(tc.value) == 0 {
+			continue
+		}
+		obj := map[string]string{}
+		if err := d.Decode(&obj); err != nil || obj["foo"] != tc.value {
+			t.Errorf("%q with %q: unexpected decoding %v %v", tc.input, tc.contentType, obj, err)
+		}
+		if format := d.Format(); format != tc.expected {
+			t.Errorf("%q with %q: expected %q after decoding, got %q", tc.input, tc.contentType, tc.expected, format)
+		}
+	}
+
+	if format := NewYAMLOrJSONDecoder(strings.NewReader("{}"), 10).Format(); format != FormatJSON {
+		t.Errorf("expected JSON, got %q", format)
+	}
+}
+
 func TestReadSingleLongLine(t *testing.T) {
 	testReadLines(t, []int{128 * 1024})
 }
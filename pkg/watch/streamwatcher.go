@@ -20,8 +20,10 @@ import (
 	"fmt"
 	"io"
 	"sync"
+	"time"
 
 	"k8s.io/klog/v2"
+	"k8s.io/utils/clock"
 
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/net"
@@ -48,6 +50,40 @@ type Reporter interface {
 	AsObject(err error) runtime.Object
 }
 
+// DecodeErrorAction is the handling of errors decoding events by a StreamWatcher.
+type DecodeErrorAction string
+
+const (
+	// DecodeErrorTerminate reports the error as an Error event and ends the watch.
+	DecodeErrorTerminate DecodeErrorAction = "Terminate"
+	// DecodeErrorSkip skips the event that failed to decode and decodes the next one.
+	DecodeErrorSkip DecodeErrorAction = "Skip"
+	// DecodeErrorRetryWithBackoff decodes the next event after a delay growing
+	// exponentially with the number of consecutive errors.
+	DecodeErrorRetryWithBackoff DecodeErrorAction = "RetryWithBackoff"
+)
+
+// DecodeErrorPolicy controls how a StreamWatcher handles errors decoding events. Errors
+// indicating the end of the stream, such as io.EOF, connection resets and timeouts, always
+// end the watch. Skipping or retrying requires a Decoder that can decode the next event
+// after an error, e.g. one decoding framed events.
+type DecodeErrorPolicy struct {
+	// Action is the handling of decode errors. Defaults to DecodeErrorTerminate.
+	Action DecodeErrorAction
+	// MaxConsecutiveErrors, if positive, is the number of consecutive decode errors after
+	// which the watch is terminated regardless of the action.
+	MaxConsecutiveErrors int
+	// InitialBackoff is the delay after the first of consecutive errors when retrying with
+	// backoff, doubled for every further error. Defaults to 100ms.
+	InitialBackoff time.Duration
+	// MaxBackoff caps the delay when retrying with backoff. Defaults to 10s.
+	MaxBackoff time.Duration
+	// OnError, if set, is called with each decode error that does not end the watch.
+	OnError func(err error)
+	// Clock is used to wait when retrying with backoff. Defaults to the real clock.
+	Clock clock.Clock
+}
+
 // StreamWatcher turns any stream for which you can write a Decoder interface
 // into a watch.Interface.
 type StreamWatcher struct {
@@ -56,13 +92,34 @@ type StreamWatcher struct {
 	reporter Reporter
 	result   chan Event
 	done     chan struct{}
+	policy   DecodeErrorPolicy
 }
 
 // NewStreamWatcher creates a StreamWatcher from the given decoder.
+// The watch is terminated on the first decode error.
 func NewStreamWatcher(d Decoder, r Reporter) *StreamWatcher {
+	return NewStreamWatcherWithPolicy(d, r, DecodeErrorPolicy{})
+}
+
+// NewStreamWatcherWithPolicy creates a StreamWatcher from the given decoder,
+// handling decode errors according to policy.
+func NewStreamWatcherWithPolicy(d Decoder, r Reporter, policy DecodeErrorPolicy) *StreamWatcher {
+	if len(policy.Action) == 0 {
+		policy.Action = DecodeErrorTerminate
+	}
+	if policy.InitialBackoff <= 0 {
+		policy.InitialBackoff = 100 * time.Millisecond
+	}
+	if policy.MaxBackoff <= 0 {
+		policy.MaxBackoff = 10 * time.Second
+	}
+	if policy.Clock == nil {
+		policy.Clock = clock.RealClock{}
+	}
 	sw := &StreamWatcher{
 		source:   d,
 		reporter: r,
+		policy:   policy,
 		// It's easy for a consumer to add buffering via an extra
 		// goroutine/channel, but impossible for them to remove it,
 		// so nonbuffered is better.
@@ -101,6 +158,7 @@ func (sw *StreamWatcher) receive() {
 	defer utilruntime.HandleCrash()
 	defer close(sw.result)
 	defer sw.Stop()
+	consecutiveErrors := 0
 	for {
 		action, obj, err := sw.source.Decode()
 		if err != nil {
@@ -112,6 +170,8 @@ func (sw *StreamWatcher) receive() {
 			default:
 				if net.IsProbableEOF(err) || net.IsTimeout(err) {
 					klog.V(5).Infof("Unable to decode an event from the watch stream: %v", err)
+				} else if consecutiveErrors++; sw.continueAfter(err, consecutiveErrors) {
+					continue
 				} else {
 					select {
 					case <-sw.done:
@@ -124,6 +184,7 @@ func (sw *StreamWatcher) receive() {
 			}
 			return
 		}
+		consecutiveErrors = 0
 		select {
 		case <-sw.done:
 			return
@@ -134,3 +195,37 @@ func (sw *StreamWatcher) receive() {
 		}
 	}
 }
+
+// continueAfter returns true if the watch continues after the given decode error,
+// waiting for the backoff of the policy if needed.
+func (sw *StreamWatcher) continueAfter(err error, consecutiveErrors int) bool {
+	if sw.policy.Action == DecodeErrorTerminate {
+		return false
+	}
+	if sw.policy.MaxConsecutiveErrors > 0 && consecutiveErrors > sw.policy.MaxConsecutiveErrors {
+		return false
+	}
+	klog.V(3).Infof("Unable to decode an event from the watch stream, continuing: %v", err)
+	if sw.policy.OnError != nil {
+		sw.policy.OnError(err)
+	}
+	if sw.policy.Action != DecodeErrorRetryWithBackoff {
+		return true
+	}
+
+	delay := sw.policy.InitialBackoff
+	for i := 1; i < consecutiveErrors && delay < sw.policy.MaxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > sw.policy.MaxBackoff {
+		delay = sw.policy.MaxBackoff
+	}
+	timer := sw.policy.Clock.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-sw.done:
+		return false
+	case <-timer.C():
+		return true
+	}
+}
@@ -24,7 +24,9 @@ import (
 	"time"
 
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
 	. "k8s.io/apimachinery/pkg/watch"
+	testingclock "k8s.io/utils/clock/testing"
 )
 
 type fakeDecoder struct {
@@ -119,3 +121,128 @@ func TestStreamWatcherRace(t *testing.T) {
 		t.Fatalf("unexpected pending send")
 	}
 }
+
+// scriptedDecoder returns the results of a script, then io.EOF.
+type scriptedDecoder struct {
+	results chan scriptedResult
+}
+
+type scriptedResult struct {
+	event Event
+	err   error
+}
+
+func (d *scriptedDecoder) Decode() (EventType, runtime.Object, error) {
+	r, ok := <-d.results
+	if !ok {
+		return "", nil, io.EOF
+	}
+	return r.event.Type, r.event.Object, r.err
+}
+
+func (d *scriptedDecoder) Close() {}
+
+func newScriptedDecoder(results ...scriptedResult) *scriptedDecoder {
+	d := &scriptedDecoder{results: make(chan scriptedResult, len(results))}
+	for _, r := range results {
+		d.results <- r
+	}
+	close(d.results)
+	return d
+}
+
+func collectEvents(t *testing.T, w Interface) []Event {
+	var events []Event
+	timeout := time.After(wait.ForeverTestTimeout)
+	for {
+		select {
+		case event, ok := <-w.ResultChan():
+			if !ok {
+				return events
+			}
+			events = append(events, event)
+		case <-timeout:
+			t.Fatalf("timed out waiting for the watch to end")
+		}
+	}
+}
+
+func TestStreamWatcherDecodeErrorPolicy(t *testing.T) {
+	malformed := fmt.Errorf("malformed event")
+	script := []scriptedResult{
+		{event: Event{Type: Added, Object: testType("a")}},
+		{err: malformed},
+		{err: malformed},
+		{event: Event{Type: Modified, Object: testType("a")}},
+		{err: malformed},
+		{event: Event{Type: Deleted, Object: testType("a")}},
+	}
+
+	testCases := []struct {
+		name     string
+		policy   DecodeErrorPolicy
+		expected []EventType
+		reported int
+	}{
+		{name: "terminate", policy: DecodeErrorPolicy{}, expected: []EventType{Added, Error}},
+		{name: "skip", policy: DecodeErrorPolicy{Action: DecodeErrorSkip}, expected: []EventType{Added, Modified, Deleted}, reported: 3},
+		{name: "max consecutive", policy: DecodeErrorPolicy{Action: DecodeErrorSkip, MaxConsecutiveErrors: 1}, expected: []EventType{Added, Error}, reported: 1},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var reported []error
+			tc.policy.OnError = func(err error) { reported = append(reported, err) }
+			reporter := &fakeReporter{}
+			sw := NewStreamWatcherWithPolicy(newScriptedDecoder(script...), reporter, tc.policy)
+			var types []EventType
+			for _, event := range collectEvents(t, sw) {
+				types = append(types, event.Type)
+			}
+			if !reflect.DeepEqual(tc.expected, types) {
+				t.Errorf("expected %v, got %v", tc.expected, types)
+			}
+			if len(reported) != tc.reported {
+				t.Errorf("expected %d reported errors, got %v", tc.reported, reported)
+			}
+		})
+	}
+}
+
+func TestStreamWatcherDecodeErrorBackoff(t *testing.T) {
+	fakeClock := testingclock.NewFakeClock(time.Now())
+	malformed := fmt.Errorf("malformed event")
+	sw := NewStreamWatcherWithPolicy(newScriptedDecoder(
+		scriptedResult{err: malformed},
+		scriptedResult{err: malformed},
+		scriptedResult{event: Event{Type: Added, Object: testType("a")}},
+	), &fakeReporter{}, DecodeErrorPolicy{
+		Action:         DecodeErrorRetryWithBackoff,
+		InitialBackoff: time.Second,
+		MaxBackoff:     time.Minute,
+		Clock:          fakeClock,
+	})
+	defer sw.Stop()
+
+	for _, delay := range []time.Duration{time.Second, 2 * time.Second} {
+		if err := wait.PollImmediate(time.Millisecond, wait.ForeverTestTimeout, func() (bool, error) {
+			return fakeClock.HasWaiters(), nil
+		}); err != nil {
+			t.Fatal(err)
+		}
+		fakeClock.Step(delay - time.Millisecond)
+		select {
+		case event := <-sw.ResultChan():
+			t.Fatalf("unexpected event before the backoff elapsed: %v", event)
+		case <-time.After(10 * time.Millisecond):
+		}
+		fakeClock.Step(time.Millisecond)
+	}
+	select {
+	case event := <-sw.ResultChan():
+		if event.Type != Added {
+			t.Errorf("unexpected event %v", event)
+		}
+	case <-time.After(wait.ForeverTestTimeout):
+		t.Fatal("expected an event after the backoff")
+	}
+}
@@ -19,8 +19,11 @@ package watch
 import (
 	"fmt"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"k8s.io/klog/v2"
+	"k8s.io/utils/clock"
 
 	"k8s.io/apimachinery/pkg/runtime"
 )
@@ -94,17 +97,28 @@ type FakeWatcher struct {
 	result  chan Event
 	stopped bool
 	sync.Mutex
+
+	// stopCh is closed by Stop, to abort scripts.
+	stopCh chan struct{}
+	// scripts tracks the goroutines sending scripted events, which must
+	// return before result is closed.
+	scripts sync.WaitGroup
+	// sendsAfterStop counts the scripted events that could not be sent
+	// because the watcher was stopped.
+	sendsAfterStop int32
 }
 
 func NewFake() *FakeWatcher {
 	return &FakeWatcher{
 		result: make(chan Event),
+		stopCh: make(chan struct{}),
 	}
 }
 
 func NewFakeWithChanSize(size int, blocking bool) *FakeWatcher {
 	return &FakeWatcher{
 		result: make(chan Event, size),
+		stopCh: make(chan struct{}),
 	}
 }
 
@@ -114,6 +128,8 @@ func (f *FakeWatcher) Stop() {
 	defer f.Unlock()
 	if !f.stopped {
 		klog.V(4).Infof("Stopping fake watcher.")
+		close(f.stopChLocked())
+		f.scripts.Wait()
 		close(f.result)
 		f.stopped = true
 	}
@@ -125,12 +141,31 @@ func (f *FakeWatcher) IsStopped() bool {
 	return f.stopped
 }
 
+// StoppedChan returns a channel that is closed when Stop is called, so that
+// tests can wait for the consumer of the watch to stop it.
+func (f *FakeWatcher) StoppedChan() <-chan struct{} {
+	f.Lock()
+	defer f.Unlock()
+	return f.stopChLocked()
+}
+
+// stopChLocked returns stopCh, creating it if needed. It must be called with
+// the lock held.
+func (f *FakeWatcher) stopChLocked() chan struct{} {
+	if f.stopCh == nil {
+		f.stopCh = make(chan struct{})
+	}
+	return f.stopCh
+}
+
 // Reset prepares the watcher to be reused.
 func (f *FakeWatcher) Reset() {
 	f.Lock()
 	defer f.Unlock()
 	f.stopped = false
 	f.result = make(chan Event)
+	f.stopCh = make(chan struct{})
+	atomic.StoreInt32(&f.sendsAfterStop, 0)
 }
 
 func (f *FakeWatcher) ResultChan() <-chan Event {
@@ -162,6 +197,62 @@ func (f *FakeWatcher) Action(action EventType, obj runtime.Object) {
 	f.result <- Event{action, obj}
 }
 
+// ScriptedEvent is an event sent by FakeWatcher.Play after a delay.
+type ScriptedEvent struct {
+	Event
+	// Delay is the time waited on the clock passed to Play before sending
+	// the event, after the previous event was received.
+	Delay time.Duration
+}
+
+// Play sends events in order in a separate goroutine and returns a channel
+// that is closed once all events have been received by the consumer or the
+// watcher was stopped. Delays are measured with c, so that tests can drive
+// the script deterministically by stepping a fake clock once it has waiters.
+// Events that cannot be sent because Stop was called are counted by
+// SendsAfterStop instead of panicking.
+func (f *FakeWatcher) Play(c clock.Clock, events ...ScriptedEvent) <-chan struct{} {
+	done := make(chan struct{})
+
+	f.Lock()
+	defer f.Unlock()
+	if f.stopped {
+		atomic.AddInt32(&f.sendsAfterStop, int32(len(events)))
+		close(done)
+		return done
+	}
+	result, stopCh := f.result, f.stopChLocked()
+	f.scripts.Add(1)
+
+	go func() {
+		defer close(done)
+		defer f.scripts.Done()
+		for i, event := range events {
+			if event.Delay > 0 {
+				select {
+				case <-c.After(event.Delay):
+				case <-stopCh:
+					atomic.AddInt32(&f.sendsAfterStop, int32(len(events)-i))
+					return
+				}
+			}
+			select {
+			case result <- event.Event:
+			case <-stopCh:
+				atomic.AddInt32(&f.sendsAfterStop, int32(len(events)-i))
+				return
+			}
+		}
+	}()
+	return done
+}
+
+// SendsAfterStop returns the number of scripted events that were not sent
+// because the watcher was stopped before they could be received.
+func (f *FakeWatcher) SendsAfterStop() int {
+	return int(atomic.LoadInt32(&f.sendsAfterStop))
+}
+
 // RaceFreeFakeWatcher lets you test anything that consumes a watch.Interface; threadsafe.
 type RaceFreeFakeWatcher struct {
 	result  chan Event
@@ -19,10 +19,13 @@ package watch_test
 import (
 	"reflect"
 	"testing"
+	"time"
 
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
 	. "k8s.io/apimachinery/pkg/watch"
+	testingclock "k8s.io/utils/clock/testing"
 )
 
 type testType string
@@ -77,6 +80,65 @@ func TestFake(t *testing.T) {
 	consumer(f)
 }
 
+func TestFakePlay(t *testing.T) {
+	fc := testingclock.NewFakeClock(time.Now())
+	f := NewFake()
+	done := f.Play(fc,
+		ScriptedEvent{Event: Event{Type: Added, Object: testType("foo")}},
+		ScriptedEvent{Event: Event{Type: Modified, Object: testType("bar")}, Delay: time.Minute},
+		ScriptedEvent{Event: Event{Type: Deleted, Object: testType("bar")}, Delay: time.Minute},
+	)
+
+	if got := <-f.ResultChan(); got.Type != Added || got.Object != testType("foo") {
+		t.Fatalf("unexpected event %#v", got)
+	}
+	if err := wait.PollImmediate(time.Millisecond, wait.ForeverTestTimeout, func() (bool, error) { return fc.HasWaiters(), nil }); err != nil {
+		t.Fatalf("the script did not wait for the delay: %v", err)
+	}
+	select {
+	case got := <-f.ResultChan():
+		t.Fatalf("unexpected event %#v before the delay elapsed", got)
+	default:
+	}
+	fc.Step(time.Minute)
+	if got := <-f.ResultChan(); got.Type != Modified || got.Object != testType("bar") {
+		t.Fatalf("unexpected event %#v", got)
+	}
+
+	// The consumer stops before the last event is sent.
+	f.Stop()
+	<-done
+	<-f.StoppedChan()
+	if !f.IsStopped() {
+		t.Errorf("expected the watcher to be stopped")
+	}
+	if _, open := <-f.ResultChan(); open {
+		t.Errorf("expected the result channel to be closed")
+	}
+	if e, a := 1, f.SendsAfterStop(); e != a {
+		t.Errorf("expected %d sends after stop, got %d", e, a)
+	}
+
+	<-f.Play(fc, ScriptedEvent{Event: Event{Type: Added, Object: testType("baz")}})
+	if e, a := 2, f.SendsAfterStop(); e != a {
+		t.Errorf("expected %d sends after stop, got %d", e, a)
+	}
+
+	f.Reset()
+	if f.SendsAfterStop() != 0 || f.IsStopped() {
+		t.Errorf("expected reset to clear the stop state")
+	}
+	done = f.Play(fc, ScriptedEvent{Event: Event{Type: Added, Object: testType("baz")}})
+	if got := <-f.ResultChan(); got.Object != testType("baz") {
+		t.Fatalf("unexpected event %#v", got)
+	}
+	<-done
+	f.Stop()
+	if f.SendsAfterStop() != 0 {
+		t.Errorf("expected no sends after stop, got %d", f.SendsAfterStop())
+	}
+}
+
 func TestRaceFreeFake(t *testing.T) {
 	f := NewRaceFreeFake()
 